@@ -0,0 +1,257 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ensurascript/ensura/pkg/adapters"
+	"github.com/ensurascript/ensura/pkg/ast"
+	"github.com/ensurascript/ensura/pkg/binder"
+	"github.com/ensurascript/ensura/pkg/graph"
+	"github.com/ensurascript/ensura/pkg/imply"
+	"github.com/ensurascript/ensura/pkg/lexer"
+	"github.com/ensurascript/ensura/pkg/parser"
+	"github.com/ensurascript/ensura/pkg/planner"
+	"github.com/ensurascript/ensura/pkg/runtime"
+)
+
+type CodeLensParams struct {
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+}
+
+type CodeLens struct {
+	Range   Range    `json:"range"`
+	Command *Command `json:"command,omitempty"`
+}
+
+type Command struct {
+	Title     string        `json:"title"`
+	Command   string        `json:"command"`
+	Arguments []interface{} `json:"arguments,omitempty"`
+}
+
+type CodeLensOptions struct {
+	ResolveProvider bool `json:"resolveProvider"`
+}
+
+type ExecuteCommandOptions struct {
+	Commands []string `json:"commands"`
+}
+
+type ExecuteCommandParams struct {
+	Command   string            `json:"command"`
+	Arguments []json.RawMessage `json:"arguments,omitempty"`
+}
+
+// Commands offered through code lenses.
+const (
+	commandCheckBlock = "ensura.checkBlock"
+	commandShowPlan   = "ensura.showPlan"
+)
+
+// blockCommandArgs identifies the block a lens command operates on: the
+// document and the 1-based source line span of the block.
+type blockCommandArgs struct {
+	URI       string `json:"uri"`
+	StartLine int    `json:"startLine"`
+	EndLine   int    `json:"endLine"`
+}
+
+// getCodeLenses places "Check this block" and "Show plan" lenses above
+// every top-level on-block and invariant block.
+func (s *Server) getCodeLenses(uri string) []CodeLens {
+	s.mu.RLock()
+	content, ok := s.documents[uri]
+	s.mu.RUnlock()
+
+	if !ok {
+		return nil
+	}
+
+	l := lexer.New(content)
+	p := parser.New(l)
+	program := p.Parse()
+	if program == nil {
+		return nil
+	}
+
+	lenses := []CodeLens{}
+	for _, stmt := range program.Statements {
+		switch stmt.(type) {
+		case *ast.OnBlock, *ast.InvariantBlock:
+		default:
+			continue
+		}
+		args := blockCommandArgs{
+			URI:       uri,
+			StartLine: stmt.Pos().Line,
+			EndLine:   stmt.End().Line,
+		}
+		headerLine := Range{
+			Start: Position{Line: stmt.Pos().Line - 1, Character: 0},
+			End:   Position{Line: stmt.Pos().Line - 1, Character: 0},
+		}
+		lenses = append(lenses,
+			CodeLens{Range: headerLine, Command: &Command{
+				Title:     "Check this block",
+				Command:   commandCheckBlock,
+				Arguments: []interface{}{args},
+			}},
+			CodeLens{Range: headerLine, Command: &Command{
+				Title:     "Show plan",
+				Command:   commandShowPlan,
+				Arguments: []interface{}{args},
+			}},
+		)
+	}
+	return lenses
+}
+
+// executeCommand runs a code lens command. Check results are published
+// as diagnostics on the block; the plan is returned as text for the
+// client to show in a virtual document.
+func (s *Server) executeCommand(params ExecuteCommandParams) (interface{}, error) {
+	if len(params.Arguments) == 0 {
+		return nil, fmt.Errorf("missing command arguments")
+	}
+	var args blockCommandArgs
+	if err := json.Unmarshal(params.Arguments[0], &args); err != nil {
+		return nil, fmt.Errorf("invalid command arguments: %w", err)
+	}
+
+	s.mu.RLock()
+	content, ok := s.documents[args.URI]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("document not open: %s", args.URI)
+	}
+
+	plan, err := compilePlan(content)
+	if err != nil {
+		return nil, err
+	}
+	steps := stepsInLines(plan, args.StartLine, args.EndLine)
+
+	switch params.Command {
+	case commandShowPlan:
+		return renderPlan(steps), nil
+	case commandCheckBlock:
+		return s.checkSteps(args.URI, plan, steps)
+	}
+	return nil, fmt.Errorf("unknown command: %s", params.Command)
+}
+
+// compilePlan runs the compile pipeline over document content and
+// produces an execution plan.
+func compilePlan(content string) (*planner.Plan, error) {
+	l := lexer.New(content)
+	p := parser.New(l)
+	program := p.Parse()
+	if errs := p.Errors(); len(errs) > 0 {
+		return nil, fmt.Errorf("parse failed: %s", errs[0])
+	}
+
+	b := binder.New()
+	b.Bind(program)
+	if errs := b.Errors(); len(errs) > 0 {
+		return nil, fmt.Errorf("bind failed: %s", errs[0])
+	}
+	b.ExpandPolicies(program)
+
+	expander := imply.NewExpander()
+	expander.Expand(program)
+	if errs := expander.Errors(); len(errs) > 0 {
+		return nil, fmt.Errorf("expansion failed: %s", errs[0])
+	}
+	if conflicts := expander.CheckConflicts(program); len(conflicts) > 0 {
+		return nil, fmt.Errorf("conflict: %s", conflicts[0])
+	}
+
+	g := graph.Build(program)
+	if errs := g.Errors(); len(errs) > 0 {
+		return nil, fmt.Errorf("graph failed: %s", errs[0])
+	}
+
+	pl := planner.New()
+	pl.SetHandlerCapabilities(adapters.NewDefaultRegistry().Capabilities())
+	plan, err := pl.CreatePlan(g, program)
+	if err != nil {
+		return nil, fmt.Errorf("planning failed: %w", err)
+	}
+	return plan, nil
+}
+
+// stepsInLines keeps the steps whose source statement lies within the
+// given 1-based line span.
+func stepsInLines(plan *planner.Plan, startLine, endLine int) []*planner.Step {
+	var steps []*planner.Step
+	for _, step := range plan.Steps {
+		if step.Guarantee == nil || step.Guarantee.Statement == nil {
+			continue
+		}
+		line := step.Guarantee.Statement.Position.Line
+		if line >= startLine && line <= endLine {
+			steps = append(steps, step)
+		}
+	}
+	return steps
+}
+
+func renderPlan(steps []*planner.Step) string {
+	if len(steps) == 0 {
+		return "No steps planned for this block.\n"
+	}
+	var out strings.Builder
+	for i, step := range steps {
+		fmt.Fprintf(&out, "%d. %s", i+1, step.Description)
+		if step.Handler != "" {
+			fmt.Fprintf(&out, " [%s]", step.Handler)
+		}
+		out.WriteString("\n")
+	}
+	return out.String()
+}
+
+// checkSteps runs a check-only pass over the block's steps and publishes
+// each finding as a diagnostic on its source statement.
+func (s *Server) checkSteps(uri string, plan *planner.Plan, steps []*planner.Step) (interface{}, error) {
+	blockPlan := &planner.Plan{
+		Steps:           steps,
+		GlobalViolation: plan.GlobalViolation,
+	}
+	config := &runtime.Config{
+		DryRun:    true,
+		CheckOnly: true,
+		Redact:    true,
+		Logger:    io.Discard,
+	}
+	rt := runtime.New(blockPlan, adapters.NewDefaultRegistry(), config)
+	result := rt.Check(context.Background())
+
+	diagnostics := []Diagnostic{}
+	for _, stepResult := range result.Steps {
+		if stepResult.Status == runtime.StepSatisfied {
+			continue
+		}
+		stmt := stepResult.Step.Guarantee.Statement
+		msg := stepResult.Message
+		if msg == "" && stepResult.Error != nil {
+			msg = stepResult.Error.Error()
+		}
+		diagnostics = append(diagnostics, Diagnostic{
+			Range:    lexerRange(stmt.Position, stmt.EndPos),
+			Severity: DiagnosticSeverityWarning,
+			Message:  fmt.Sprintf("%s: %s", stepResult.Status, msg),
+			Source:   "ensura-check",
+		})
+	}
+	s.sendNotification("textDocument/publishDiagnostics", PublishDiagnosticsParams{
+		URI:         uri,
+		Diagnostics: diagnostics,
+	})
+
+	return fmt.Sprintf("%d checks, %d failures", result.TotalChecks, result.TotalFailures), nil
+}
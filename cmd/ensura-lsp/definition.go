@@ -0,0 +1,79 @@
+package main
+
+import (
+	"github.com/ensurascript/ensura/pkg/ast"
+	"github.com/ensurascript/ensura/pkg/lexer"
+	"github.com/ensurascript/ensura/pkg/parser"
+)
+
+// nameRange is the range covering a declaration's name, starting at the
+// declaration keyword, for jump targets and selection ranges.
+func nameRange(pos lexer.Position, name string) Range {
+	return Range{
+		Start: Position{Line: pos.Line - 1, Character: pos.Column - 1},
+		End:   Position{Line: pos.Line - 1, Character: pos.Column + len(name)},
+	}
+}
+
+// getDefinition resolves the symbol under the cursor to its declaration:
+// a policy name used in an apply jumps to the policy declaration, and an
+// alias reference jumps to the `resource ... as` declaration. The current
+// document is searched first, then the rest of the workspace.
+func (s *Server) getDefinition(params TextDocumentPositionParams) *Location {
+	s.mu.RLock()
+	content, ok := s.documents[params.TextDocument.URI]
+	s.mu.RUnlock()
+
+	if !ok {
+		return nil
+	}
+
+	word := getWordAtPosition(content, params.Position)
+	if word == "" {
+		return nil
+	}
+
+	if loc := findDeclaration(params.TextDocument.URI, content, word); loc != nil {
+		return loc
+	}
+	for uri, doc := range s.allDocuments() {
+		if uri == params.TextDocument.URI {
+			continue
+		}
+		if loc := findDeclaration(uri, doc, word); loc != nil {
+			return loc
+		}
+	}
+	return nil
+}
+
+// findDeclaration locates the declaration of a policy or alias named
+// word within a single document.
+func findDeclaration(uri, content, word string) *Location {
+	l := lexer.New(content)
+	p := parser.New(l)
+	program := p.Parse()
+	if program == nil {
+		return nil
+	}
+
+	for _, stmt := range program.Statements {
+		switch st := stmt.(type) {
+		case *ast.PolicyDecl:
+			if st.Name == word {
+				return &Location{
+					URI:   uri,
+					Range: nameRange(st.Pos(), st.Name),
+				}
+			}
+		case *ast.ResourceDecl:
+			if st.Alias != "" && st.Alias == word {
+				return &Location{
+					URI:   uri,
+					Range: lexerRange(st.Pos(), st.End()),
+				}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"github.com/ensurascript/ensura/pkg/ast"
+	"github.com/ensurascript/ensura/pkg/lexer"
+	"github.com/ensurascript/ensura/pkg/parser"
+)
+
+type FoldingRangeParams struct {
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+}
+
+type FoldingRange struct {
+	StartLine int    `json:"startLine"`
+	EndLine   int    `json:"endLine"`
+	Kind      string `json:"kind,omitempty"`
+}
+
+type DocumentHighlight struct {
+	Range Range `json:"range"`
+	Kind  int   `json:"kind,omitempty"`
+}
+
+// Document highlight kinds
+const (
+	DocumentHighlightText = 1
+)
+
+// getFoldingRanges computes folding ranges for every block construct in
+// the document, including ones nested in other blocks.
+func (s *Server) getFoldingRanges(uri string) []FoldingRange {
+	s.mu.RLock()
+	content, ok := s.documents[uri]
+	s.mu.RUnlock()
+
+	if !ok {
+		return nil
+	}
+
+	l := lexer.New(content)
+	p := parser.New(l)
+	program := p.Parse()
+	if program == nil {
+		return nil
+	}
+
+	ranges := []FoldingRange{}
+	collectFoldingRanges(program.Statements, &ranges)
+	return ranges
+}
+
+// collectFoldingRanges appends a folding range for each block statement,
+// recursing into the block bodies. The closing brace line stays visible,
+// so the range ends one line short of the block's end.
+func collectFoldingRanges(stmts []ast.Statement, ranges *[]FoldingRange) {
+	for _, stmt := range stmts {
+		var body []ast.Statement
+		switch st := stmt.(type) {
+		case *ast.OnBlock:
+			body = st.Statements
+		case *ast.PolicyDecl:
+			body = st.Statements
+		case *ast.InvariantBlock:
+			body = st.Statements
+		case *ast.ParallelBlock:
+			body = st.Statements
+		case *ast.ForEachStmt:
+			body = st.Statements
+		default:
+			continue
+		}
+		start := stmt.Pos().Line - 1
+		end := stmt.End().Line - 2
+		if end > start {
+			*ranges = append(*ranges, FoldingRange{
+				StartLine: start,
+				EndLine:   end,
+				Kind:      "region",
+			})
+		}
+		collectFoldingRanges(body, ranges)
+	}
+}
+
+// getDocumentHighlights marks every occurrence of the identifier under
+// the cursor within the current document.
+func (s *Server) getDocumentHighlights(params TextDocumentPositionParams) []DocumentHighlight {
+	s.mu.RLock()
+	content, ok := s.documents[params.TextDocument.URI]
+	s.mu.RUnlock()
+
+	if !ok {
+		return nil
+	}
+
+	word := getWordAtPosition(content, params.Position)
+	if word == "" {
+		return nil
+	}
+
+	highlights := []DocumentHighlight{}
+	for _, r := range occurrences(content, word) {
+		highlights = append(highlights, DocumentHighlight{
+			Range: r,
+			Kind:  DocumentHighlightText,
+		})
+	}
+	return highlights
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/ensurascript/ensura/pkg/ast"
+	"github.com/ensurascript/ensura/pkg/lexer"
+	"github.com/ensurascript/ensura/pkg/parser"
+)
+
+// symbolHover builds hover markdown for a user-defined symbol: a policy
+// shows its signature, doc comment, and the ensures it expands to; an
+// alias shows the underlying resource declaration.
+func (s *Server) symbolHover(word string) *Hover {
+	for _, content := range s.allDocuments() {
+		l := lexer.New(content)
+		p := parser.New(l)
+		program := p.Parse()
+		if program == nil {
+			continue
+		}
+		for _, stmt := range program.Statements {
+			switch st := stmt.(type) {
+			case *ast.PolicyDecl:
+				if st.Name == word {
+					return markdownHover(policyHover(st))
+				}
+			case *ast.ResourceDecl:
+				if st.Alias != "" && st.Alias == word {
+					return markdownHover(resourceHover(st))
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func markdownHover(value string) *Hover {
+	return &Hover{
+		Contents: MarkupContent{
+			Kind:  "markdown",
+			Value: value,
+		},
+	}
+}
+
+func policyHover(pol *ast.PolicyDecl) string {
+	var out strings.Builder
+	out.WriteString("**policy " + pol.Name + "(")
+	for i, param := range pol.Params {
+		if i > 0 {
+			out.WriteString(", ")
+		}
+		out.WriteString(param.Name)
+		if param.HasDefault {
+			out.WriteString(" = " + param.Default)
+		}
+	}
+	out.WriteString(")**")
+
+	if pol.DocComment != "" {
+		out.WriteString("\n\n" + pol.DocComment)
+	}
+
+	ensures := collectEnsures(pol.Statements)
+	if len(ensures) > 0 {
+		out.WriteString("\n\nEnsures:\n```ens\n")
+		for _, e := range ensures {
+			out.WriteString(e.String() + "\n")
+		}
+		out.WriteString("```")
+	}
+	return out.String()
+}
+
+func resourceHover(decl *ast.ResourceDecl) string {
+	var out strings.Builder
+	out.WriteString("**" + decl.Alias + "**\n\n```ens\n" + decl.String() + "\n```")
+	if decl.DocComment != "" {
+		out.WriteString("\n\n" + decl.DocComment)
+	}
+	return out.String()
+}
+
+// collectEnsures gathers ensure statements from a statement list,
+// including those nested in on-blocks, guards, and loops.
+func collectEnsures(stmts []ast.Statement) []*ast.EnsureStmt {
+	var ensures []*ast.EnsureStmt
+	for _, stmt := range stmts {
+		ast.Inspect(stmt, func(node ast.Node) bool {
+			if e, ok := node.(*ast.EnsureStmt); ok {
+				ensures = append(ensures, e)
+			}
+			return true
+		})
+	}
+	return ensures
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/ensurascript/ensura/pkg/imply"
+	"github.com/ensurascript/ensura/pkg/lexer"
+	"github.com/ensurascript/ensura/pkg/parser"
+)
+
+type InlayHintParams struct {
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+	Range        Range                  `json:"range"`
+}
+
+type InlayHint struct {
+	Position    Position `json:"position"`
+	Label       string   `json:"label"`
+	PaddingLeft bool     `json:"paddingLeft,omitempty"`
+}
+
+// getInlayHints renders ghost annotations after ensure statements: the
+// guarantees the condition implies, and the default handler that will be
+// used when none is written out.
+func (s *Server) getInlayHints(params InlayHintParams) []InlayHint {
+	s.mu.RLock()
+	content, ok := s.documents[params.TextDocument.URI]
+	s.mu.RUnlock()
+
+	if !ok {
+		return nil
+	}
+
+	l := lexer.New(content)
+	p := parser.New(l)
+	program := p.Parse()
+	if program == nil {
+		return nil
+	}
+
+	registry := imply.NewRegistry()
+	hints := []InlayHint{}
+	for _, ensure := range collectEnsures(program.Statements) {
+		line := ensure.End().Line - 1
+		if line < params.Range.Start.Line || line > params.Range.End.Line {
+			continue
+		}
+		pos := Position{Line: line, Character: ensure.End().Column - 1}
+
+		if implied := impliedConditions(registry, ensure.Condition); len(implied) > 0 {
+			hints = append(hints, InlayHint{
+				Position:    pos,
+				Label:       "⇒ " + strings.Join(implied, ", "),
+				PaddingLeft: true,
+			})
+		}
+		if ensure.Handler == nil {
+			if meta, ok := registry.Get(ensure.Condition); ok && meta.DefaultHandler != "" {
+				hints = append(hints, InlayHint{
+					Position:    pos,
+					Label:       "handler: " + meta.DefaultHandler + " (default)",
+					PaddingLeft: true,
+				})
+			}
+		}
+	}
+	return hints
+}
+
+// impliedConditions returns the transitive closure of guarantees implied
+// by condition, sorted, excluding the condition itself.
+func impliedConditions(registry *imply.Registry, condition string) []string {
+	seen := map[string]bool{condition: true}
+	queue := []string{condition}
+	var implied []string
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		meta, ok := registry.Get(name)
+		if !ok {
+			continue
+		}
+		for _, imp := range meta.Implies {
+			if seen[imp] {
+				continue
+			}
+			seen[imp] = true
+			implied = append(implied, imp)
+			queue = append(queue, imp)
+		}
+	}
+	sort.Strings(implied)
+	return implied
+}
@@ -6,23 +6,25 @@ import (
 	"fmt"
 	"io"
 	"os"
-	"strconv"
 	"strings"
 	"sync"
 
 	"github.com/ensurascript/ensura/pkg/ast"
+	"github.com/ensurascript/ensura/pkg/binder"
+	"github.com/ensurascript/ensura/pkg/graph"
+	"github.com/ensurascript/ensura/pkg/imply"
 	"github.com/ensurascript/ensura/pkg/lexer"
 	"github.com/ensurascript/ensura/pkg/parser"
 )
 
 // LSP message types
 type Message struct {
-	JSONRPC string          `json:"jsonrpc"`
+	JSONRPC string           `json:"jsonrpc"`
 	ID      *json.RawMessage `json:"id,omitempty"`
-	Method  string          `json:"method,omitempty"`
-	Params  json.RawMessage `json:"params,omitempty"`
-	Result  interface{}     `json:"result,omitempty"`
-	Error   *ResponseError  `json:"error,omitempty"`
+	Method  string           `json:"method,omitempty"`
+	Params  json.RawMessage  `json:"params,omitempty"`
+	Result  interface{}      `json:"result,omitempty"`
+	Error   *ResponseError   `json:"error,omitempty"`
 }
 
 type ResponseError struct {
@@ -64,11 +66,21 @@ type InitializeResult struct {
 }
 
 type ServerCapabilities struct {
-	TextDocumentSync           int                     `json:"textDocumentSync"`
-	HoverProvider              bool                    `json:"hoverProvider"`
-	CompletionProvider         *CompletionOptions      `json:"completionProvider,omitempty"`
-	DefinitionProvider         bool                    `json:"definitionProvider"`
-	DocumentSymbolProvider     bool                    `json:"documentSymbolProvider"`
+	TextDocumentSync          int                    `json:"textDocumentSync"`
+	HoverProvider             bool                   `json:"hoverProvider"`
+	CompletionProvider        *CompletionOptions     `json:"completionProvider,omitempty"`
+	DefinitionProvider        bool                   `json:"definitionProvider"`
+	DocumentSymbolProvider    bool                   `json:"documentSymbolProvider"`
+	ReferencesProvider        bool                   `json:"referencesProvider"`
+	RenameProvider            bool                   `json:"renameProvider"`
+	SemanticTokensProvider    *SemanticTokensOptions `json:"semanticTokensProvider,omitempty"`
+	WorkspaceSymbolProvider   bool                   `json:"workspaceSymbolProvider"`
+	InlayHintProvider         bool                   `json:"inlayHintProvider"`
+	CodeLensProvider          *CodeLensOptions       `json:"codeLensProvider,omitempty"`
+	ExecuteCommandProvider    *ExecuteCommandOptions `json:"executeCommandProvider,omitempty"`
+	SignatureHelpProvider     *SignatureHelpOptions  `json:"signatureHelpProvider,omitempty"`
+	FoldingRangeProvider      bool                   `json:"foldingRangeProvider"`
+	DocumentHighlightProvider bool                   `json:"documentHighlightProvider"`
 }
 
 type CompletionOptions struct {
@@ -196,6 +208,7 @@ const (
 // Server state
 type Server struct {
 	documents map[string]string
+	workspace map[string]string // .ens files indexed from rootUri
 	mu        sync.RWMutex
 	writer    io.Writer
 	writeMu   sync.Mutex
@@ -204,6 +217,7 @@ type Server struct {
 func NewServer(w io.Writer) *Server {
 	return &Server{
 		documents: make(map[string]string),
+		workspace: make(map[string]string),
 		writer:    w,
 	}
 }
@@ -222,9 +236,29 @@ func (s *Server) handleMessage(msg Message) {
 				HoverProvider:          true,
 				DefinitionProvider:     true,
 				DocumentSymbolProvider: true,
+				ReferencesProvider:     true,
+				RenameProvider:         true,
 				CompletionProvider: &CompletionOptions{
 					TriggerCharacters: []string{" ", "\""},
 				},
+				SemanticTokensProvider: &SemanticTokensOptions{
+					Legend: SemanticTokensLegend{
+						TokenTypes:     semanticTokenTypes,
+						TokenModifiers: []string{},
+					},
+					Full: true,
+				},
+				WorkspaceSymbolProvider: true,
+				InlayHintProvider:       true,
+				CodeLensProvider:        &CodeLensOptions{},
+				ExecuteCommandProvider: &ExecuteCommandOptions{
+					Commands: []string{commandCheckBlock, commandShowPlan},
+				},
+				SignatureHelpProvider: &SignatureHelpOptions{
+					TriggerCharacters: []string{" ", ":"},
+				},
+				FoldingRangeProvider:      true,
+				DocumentHighlightProvider: true,
 			},
 			ServerInfo: &ServerInfo{
 				Name:    "ensura-lsp",
@@ -232,6 +266,7 @@ func (s *Server) handleMessage(msg Message) {
 			},
 		}
 		s.sendResult(msg.ID, result)
+		s.indexWorkspace(params.RootURI)
 
 	case "initialized":
 		// Client acknowledged initialization
@@ -293,6 +328,107 @@ func (s *Server) handleMessage(msg Message) {
 		completions := s.getCompletions(params)
 		s.sendResult(msg.ID, completions)
 
+	case "textDocument/references":
+		var params ReferenceParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			s.sendError(msg.ID, -32602, "Invalid params")
+			return
+		}
+		s.sendResult(msg.ID, s.getReferences(params))
+
+	case "textDocument/rename":
+		var params RenameParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			s.sendError(msg.ID, -32602, "Invalid params")
+			return
+		}
+		s.sendResult(msg.ID, s.renameSymbol(params))
+
+	case "textDocument/definition":
+		var params TextDocumentPositionParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			s.sendError(msg.ID, -32602, "Invalid params")
+			return
+		}
+		location := s.getDefinition(params)
+		s.sendResult(msg.ID, location)
+
+	case "workspace/didChangeWatchedFiles":
+		var params DidChangeWatchedFilesParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return
+		}
+		s.handleWatchedFiles(params)
+
+	case "workspace/symbol":
+		var params WorkspaceSymbolParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			s.sendError(msg.ID, -32602, "Invalid params")
+			return
+		}
+		s.sendResult(msg.ID, s.getWorkspaceSymbols(params))
+
+	case "textDocument/foldingRange":
+		var params FoldingRangeParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			s.sendError(msg.ID, -32602, "Invalid params")
+			return
+		}
+		s.sendResult(msg.ID, s.getFoldingRanges(params.TextDocument.URI))
+
+	case "textDocument/documentHighlight":
+		var params TextDocumentPositionParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			s.sendError(msg.ID, -32602, "Invalid params")
+			return
+		}
+		s.sendResult(msg.ID, s.getDocumentHighlights(params))
+
+	case "textDocument/signatureHelp":
+		var params TextDocumentPositionParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			s.sendError(msg.ID, -32602, "Invalid params")
+			return
+		}
+		s.sendResult(msg.ID, s.getSignatureHelp(params))
+
+	case "textDocument/codeLens":
+		var params CodeLensParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			s.sendError(msg.ID, -32602, "Invalid params")
+			return
+		}
+		s.sendResult(msg.ID, s.getCodeLenses(params.TextDocument.URI))
+
+	case "workspace/executeCommand":
+		var params ExecuteCommandParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			s.sendError(msg.ID, -32602, "Invalid params")
+			return
+		}
+		result, err := s.executeCommand(params)
+		if err != nil {
+			s.sendError(msg.ID, -32603, err.Error())
+			return
+		}
+		s.sendResult(msg.ID, result)
+
+	case "textDocument/inlayHint":
+		var params InlayHintParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			s.sendError(msg.ID, -32602, "Invalid params")
+			return
+		}
+		s.sendResult(msg.ID, s.getInlayHints(params))
+
+	case "textDocument/semanticTokens/full":
+		var params SemanticTokensParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			s.sendError(msg.ID, -32602, "Invalid params")
+			return
+		}
+		s.sendResult(msg.ID, s.getSemanticTokens(params.TextDocument.URI))
+
 	case "textDocument/documentSymbol":
 		var params struct {
 			TextDocument TextDocumentIdentifier `json:"textDocument"`
@@ -315,48 +451,95 @@ func (s *Server) publishDiagnostics(uri string) {
 		return
 	}
 
+	params := PublishDiagnosticsParams{
+		URI:         uri,
+		Diagnostics: compileDiagnostics(content),
+	}
+
+	s.sendNotification("textDocument/publishDiagnostics", params)
+}
+
+// compileDiagnostics runs the full compile pipeline over the document and
+// collects each stage's structured diagnostics. Later stages are skipped
+// once an earlier one reports errors, since they would only be operating
+// on a broken program.
+func compileDiagnostics(content string) []Diagnostic {
 	diagnostics := []Diagnostic{}
+	report := func(diags []ast.Diag) {
+		for _, d := range diags {
+			diagnostics = append(diagnostics, Diagnostic{
+				Range:    diagRange(d),
+				Severity: DiagnosticSeverityError,
+				Message:  d.Msg,
+				Source:   "ensura",
+			})
+		}
+	}
 
-	// Parse the document
 	l := lexer.New(content)
 	p := parser.New(l)
-	_ = p.Parse()
-
-	for _, err := range p.Errors() {
-		// Try to extract line info from error message
-		line := 0
-		col := 0
-		msg := err
-
-		// Simple parsing of "line X" pattern
-		if strings.Contains(err, "line ") {
-			parts := strings.Split(err, "line ")
-			if len(parts) > 1 {
-				numPart := strings.Split(parts[1], ":")[0]
-				numPart = strings.Split(numPart, " ")[0]
-				if n, e := strconv.Atoi(numPart); e == nil {
-					line = n - 1 // LSP uses 0-based lines
-				}
-			}
-		}
+	program := p.Parse()
+	report(p.Diagnostics())
+	if len(diagnostics) > 0 {
+		return diagnostics
+	}
 
+	b := binder.New()
+	b.Bind(program)
+	report(b.Diagnostics())
+	if len(diagnostics) > 0 {
+		return diagnostics
+	}
+	b.ExpandPolicies(program)
+
+	exp := imply.NewExpander()
+	exp.Expand(program)
+	exp.CheckConflicts(program)
+	report(exp.Diagnostics())
+	if len(diagnostics) > 0 {
+		return diagnostics
+	}
+
+	g := graph.Build(program)
+	report(g.Diagnostics())
+	if len(diagnostics) > 0 {
+		return diagnostics
+	}
+	if _, err := g.TopoSort(); err != nil {
+		// Cycle errors carry no position; anchor them at the top of the file.
 		diagnostics = append(diagnostics, Diagnostic{
-			Range: Range{
-				Start: Position{Line: line, Character: col},
-				End:   Position{Line: line, Character: col + 10},
-			},
 			Severity: DiagnosticSeverityError,
-			Message:  msg,
+			Message:  err.Error(),
 			Source:   "ensura",
 		})
 	}
 
-	params := PublishDiagnosticsParams{
-		URI:         uri,
-		Diagnostics: diagnostics,
+	return diagnostics
+}
+
+// diagRange converts a diagnostic's source range to LSP coordinates,
+// clamping unset positions (line or column zero) to the start of the file.
+func diagRange(d ast.Diag) Range {
+	r := lexerRange(d.Pos, d.End)
+	if r.Start.Line < 0 {
+		r.Start.Line = 0
+	}
+	if r.Start.Character < 0 {
+		r.Start.Character = 0
+	}
+	if r.End.Line < r.Start.Line ||
+		(r.End.Line == r.Start.Line && r.End.Character < r.Start.Character) {
+		r.End = r.Start
 	}
+	return r
+}
 
-	s.sendNotification("textDocument/publishDiagnostics", params)
+// lexerRange converts a 1-based lexer position pair into a 0-based LSP range.
+func lexerRange(start, end lexer.Position) Range {
+	return Range{
+		Start: Position{Line: start.Line - 1, Character: start.Column - 1},
+		End:   Position{Line: end.Line - 1, Character: end.Column - 1},
+	}
 }
 
 func (s *Server) getHover(params TextDocumentPositionParams) *Hover {
@@ -414,7 +597,8 @@ func (s *Server) getHover(params TextDocumentPositionParams) *Hover {
 		}
 	}
 
-	return nil
+	// Fall back to user-defined policies and aliases in the workspace
+	return s.symbolHover(word)
 }
 
 func (s *Server) getCompletions(params TextDocumentPositionParams) *CompletionList {
@@ -483,12 +667,9 @@ func (s *Server) getDocumentSymbols(uri string) []DocumentSymbol {
 		case *ast.PolicyDecl:
 			pos := st.Pos()
 			symbols = append(symbols, DocumentSymbol{
-				Name: st.Name,
-				Kind: SymbolKindFunction,
-				Range: Range{
-					Start: Position{Line: pos.Line - 1, Character: pos.Column - 1},
-					End:   Position{Line: pos.Line - 1, Character: pos.Column + len(st.Name)},
-				},
+				Name:  st.Name,
+				Kind:  SymbolKindFunction,
+				Range: lexerRange(pos, st.End()),
 				SelectionRange: Range{
 					Start: Position{Line: pos.Line - 1, Character: pos.Column - 1},
 					End:   Position{Line: pos.Line - 1, Character: pos.Column + len(st.Name)},
@@ -498,12 +679,9 @@ func (s *Server) getDocumentSymbols(uri string) []DocumentSymbol {
 			pos := st.Pos()
 			name := fmt.Sprintf("%s: %s", st.Subject.ResourceType, st.Subject.Path)
 			symbols = append(symbols, DocumentSymbol{
-				Name: name,
-				Kind: SymbolKindObject,
-				Range: Range{
-					Start: Position{Line: pos.Line - 1, Character: pos.Column - 1},
-					End:   Position{Line: pos.Line - 1, Character: pos.Column + len(name)},
-				},
+				Name:  name,
+				Kind:  SymbolKindObject,
+				Range: lexerRange(pos, st.End()),
 				SelectionRange: Range{
 					Start: Position{Line: pos.Line - 1, Character: pos.Column - 1},
 					End:   Position{Line: pos.Line - 1, Character: pos.Column + len(name)},
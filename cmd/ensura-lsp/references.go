@@ -0,0 +1,151 @@
+package main
+
+import (
+	"github.com/ensurascript/ensura/pkg/ast"
+	"github.com/ensurascript/ensura/pkg/lexer"
+	"github.com/ensurascript/ensura/pkg/parser"
+)
+
+type ReferenceParams struct {
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+	Position     Position               `json:"position"`
+}
+
+type RenameParams struct {
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+	Position     Position               `json:"position"`
+	NewName      string                 `json:"newName"`
+}
+
+type TextEdit struct {
+	Range   Range  `json:"range"`
+	NewText string `json:"newText"`
+}
+
+type WorkspaceEdit struct {
+	Changes map[string][]TextEdit `json:"changes"`
+}
+
+// allDocuments returns a snapshot of every known document: the indexed
+// workspace files, overlaid with the open documents' in-editor content.
+func (s *Server) allDocuments() map[string]string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	docs := make(map[string]string, len(s.workspace)+len(s.documents))
+	for uri, content := range s.workspace {
+		docs[uri] = content
+	}
+	for uri, content := range s.documents {
+		docs[uri] = content
+	}
+	return docs
+}
+
+// isDeclaredSymbol reports whether name is declared as a policy or a
+// resource alias anywhere in the workspace.
+func (s *Server) isDeclaredSymbol(name string) bool {
+	for _, content := range s.allDocuments() {
+		l := lexer.New(content)
+		p := parser.New(l)
+		program := p.Parse()
+		if program == nil {
+			continue
+		}
+		for _, stmt := range program.Statements {
+			switch st := stmt.(type) {
+			case *ast.PolicyDecl:
+				if st.Name == name {
+					return true
+				}
+			case *ast.ResourceDecl:
+				if st.Alias == name {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// occurrences returns the ranges of every identifier token spelling name.
+func occurrences(content, name string) []Range {
+	l := lexer.New(content)
+	var ranges []Range
+	for {
+		tok := l.NextToken()
+		if tok.Type == lexer.EOF {
+			break
+		}
+		if tok.Type == lexer.IDENT && tok.Literal == name {
+			ranges = append(ranges, lexerRange(tok.Pos, tok.End))
+		}
+	}
+	return ranges
+}
+
+// getReferences finds every use of the policy or alias under the cursor
+// across the workspace, including the declaration.
+func (s *Server) getReferences(params ReferenceParams) []Location {
+	s.mu.RLock()
+	content, ok := s.documents[params.TextDocument.URI]
+	s.mu.RUnlock()
+
+	if !ok {
+		return nil
+	}
+
+	word := getWordAtPosition(content, params.Position)
+	if word == "" || !s.isDeclaredSymbol(word) {
+		return nil
+	}
+
+	var locations []Location
+	for uri, doc := range s.allDocuments() {
+		for _, r := range occurrences(doc, word) {
+			locations = append(locations, Location{URI: uri, Range: r})
+		}
+	}
+	return locations
+}
+
+// renameSymbol renames the policy or alias under the cursor at every use
+// site across the workspace.
+func (s *Server) renameSymbol(params RenameParams) *WorkspaceEdit {
+	s.mu.RLock()
+	content, ok := s.documents[params.TextDocument.URI]
+	s.mu.RUnlock()
+
+	if !ok || !validIdentifier(params.NewName) {
+		return nil
+	}
+
+	word := getWordAtPosition(content, params.Position)
+	if word == "" || !s.isDeclaredSymbol(word) {
+		return nil
+	}
+
+	changes := make(map[string][]TextEdit)
+	for uri, doc := range s.allDocuments() {
+		for _, r := range occurrences(doc, word) {
+			changes[uri] = append(changes[uri], TextEdit{Range: r, NewText: params.NewName})
+		}
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+	return &WorkspaceEdit{Changes: changes}
+}
+
+// validIdentifier reports whether name can stand as an EnsuraScript
+// identifier, and is not a keyword.
+func validIdentifier(name string) bool {
+	if name == "" {
+		return false
+	}
+	l := lexer.New(name)
+	tok := l.NextToken()
+	if tok.Type != lexer.IDENT || tok.Literal != name {
+		return false
+	}
+	return l.NextToken().Type == lexer.EOF
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"sort"
+
+	"github.com/ensurascript/ensura/pkg/ast"
+	"github.com/ensurascript/ensura/pkg/lexer"
+	"github.com/ensurascript/ensura/pkg/parser"
+)
+
+type SemanticTokensParams struct {
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+}
+
+type SemanticTokens struct {
+	Data []int `json:"data"`
+}
+
+type SemanticTokensOptions struct {
+	Legend SemanticTokensLegend `json:"legend"`
+	Full   bool                 `json:"full"`
+}
+
+type SemanticTokensLegend struct {
+	TokenTypes     []string `json:"tokenTypes"`
+	TokenModifiers []string `json:"tokenModifiers"`
+}
+
+// Semantic token type indices into semanticTokenTypes. The legend order is
+// part of the protocol contract with the client, so keep the two in sync.
+const (
+	semKeyword = iota
+	semString
+	semNumber
+	semComment
+	semFunction // handlers and policy names
+	semType     // resource type keywords
+	semVariable // resource aliases
+	semProperty // conditions
+	semOperator
+)
+
+var semanticTokenTypes = []string{
+	"keyword",
+	"string",
+	"number",
+	"comment",
+	"function",
+	"type",
+	"variable",
+	"property",
+	"operator",
+}
+
+func (s *Server) getSemanticTokens(uri string) *SemanticTokens {
+	s.mu.RLock()
+	content, ok := s.documents[uri]
+	s.mu.RUnlock()
+
+	if !ok {
+		return nil
+	}
+	return &SemanticTokens{Data: encodeSemanticTokens(content)}
+}
+
+// semToken is a classified token before delta encoding.
+type semToken struct {
+	line, col, length, kind int
+}
+
+// encodeSemanticTokens lexes the document, classifies each token, and
+// returns the LSP delta-encoded token stream (five integers per token).
+func encodeSemanticTokens(content string) []int {
+	policies, aliases := declaredNames(content)
+
+	var tokens []semToken
+	l := lexer.New(content)
+	var prev lexer.Token
+	for {
+		tok := l.NextToken()
+		if tok.Type == lexer.EOF {
+			break
+		}
+		// Tokens spanning lines (raw strings) cannot be expressed in
+		// the single-line encoding; skip them rather than corrupt the
+		// stream.
+		if tok.End.Line != tok.Pos.Line {
+			prev = tok
+			continue
+		}
+		kind := classifyToken(tok, prev, policies, aliases)
+		if kind >= 0 {
+			tokens = append(tokens, semToken{
+				line:   tok.Pos.Line - 1,
+				col:    tok.Pos.Column - 1,
+				length: tok.End.Column - tok.Pos.Column,
+				kind:   kind,
+			})
+		}
+		prev = tok
+	}
+
+	sort.Slice(tokens, func(i, j int) bool {
+		if tokens[i].line != tokens[j].line {
+			return tokens[i].line < tokens[j].line
+		}
+		return tokens[i].col < tokens[j].col
+	})
+
+	data := []int{}
+	prevLine, prevCol := 0, 0
+	for _, t := range tokens {
+		deltaLine := t.line - prevLine
+		deltaCol := t.col
+		if deltaLine == 0 {
+			deltaCol = t.col - prevCol
+		}
+		data = append(data, deltaLine, deltaCol, t.length, t.kind, 0)
+		prevLine, prevCol = t.line, t.col
+	}
+	return data
+}
+
+// classifyToken maps a lexer token to a semantic token type, or -1 for
+// tokens that should not be highlighted. Identifiers are classified by
+// the preceding token and by the policy and alias names declared in the
+// document.
+func classifyToken(tok, prev lexer.Token, policies, aliases map[string]bool) int {
+	switch tok.Type {
+	case lexer.STRING:
+		return semString
+	case lexer.NUMBER, lexer.DURATION, lexer.SIZE:
+		return semNumber
+	case lexer.BOOLEAN:
+		return semKeyword
+	case lexer.COMMENT:
+		return semComment
+	case lexer.EQUALS, lexer.NOTEQUALS, lexer.ASSIGN:
+		return semOperator
+	case lexer.DIRECTORY, lexer.FILE, lexer.HTTP, lexer.DATABASE,
+		lexer.SERVICE, lexer.PROCESS, lexer.CRON, lexer.ENVIRONMENT:
+		return semType
+	case lexer.IDENT:
+		switch {
+		case prev.Type == lexer.ENSURE:
+			return semProperty
+		case prev.Type == lexer.WITH:
+			return semFunction
+		case prev.Type == lexer.POLICY || prev.Type == lexer.APPLY:
+			return semFunction
+		case prev.Type == lexer.AS:
+			return semVariable
+		case policies[tok.Literal]:
+			return semFunction
+		case aliases[tok.Literal]:
+			return semVariable
+		}
+		return -1
+	}
+	if tok.Type >= lexer.RESOURCE {
+		return semKeyword
+	}
+	return -1
+}
+
+// declaredNames parses the document and collects the policy names and
+// resource aliases it declares, so later identifier occurrences can be
+// classified even without a tell-tale preceding keyword.
+func declaredNames(content string) (policies, aliases map[string]bool) {
+	policies = make(map[string]bool)
+	aliases = make(map[string]bool)
+
+	l := lexer.New(content)
+	p := parser.New(l)
+	program := p.Parse()
+	if program == nil {
+		return policies, aliases
+	}
+	for _, stmt := range program.Statements {
+		switch st := stmt.(type) {
+		case *ast.PolicyDecl:
+			policies[st.Name] = true
+		case *ast.ResourceDecl:
+			if st.Alias != "" {
+				aliases[st.Alias] = true
+			}
+		}
+	}
+	return policies, aliases
+}
@@ -0,0 +1,219 @@
+package main
+
+import (
+	"strings"
+)
+
+type SignatureHelp struct {
+	Signatures      []SignatureInformation `json:"signatures"`
+	ActiveSignature int                    `json:"activeSignature"`
+	ActiveParameter int                    `json:"activeParameter"`
+}
+
+type SignatureInformation struct {
+	Label         string                 `json:"label"`
+	Documentation string                 `json:"documentation,omitempty"`
+	Parameters    []ParameterInformation `json:"parameters,omitempty"`
+}
+
+type ParameterInformation struct {
+	Label         string `json:"label"`
+	Documentation string `json:"documentation,omitempty"`
+}
+
+type SignatureHelpOptions struct {
+	TriggerCharacters []string `json:"triggerCharacters,omitempty"`
+}
+
+// handlerArg describes one argument key a handler understands.
+type handlerArg struct {
+	name string
+	doc  string
+}
+
+// handlerMetadata is the argument table for the built-in handlers,
+// keyed by handler name as written after `with`.
+var handlerMetadata = map[string][]handlerArg{
+	"AES:256": {
+		{"key", "Encryption key reference (e.g. env:VAR or file:path)"},
+		{"key_id", "Identifier recorded in the envelope header"},
+		{"old_key", "Previous key to rotate away from"},
+		{"backup", "Keep a backup copy of the plaintext"},
+	},
+	"age": {
+		{"recipients", "Comma-separated age recipient public keys"},
+	},
+	"gpg": {
+		{"recipient", "GPG recipient key"},
+		{"armor", "Use ASCII-armored output"},
+	},
+	"posix": {
+		{"mode", "Octal permission mode (e.g. 0600)"},
+	},
+	"fs.native": {
+		{"bytes", "Expected size in bytes"},
+		{"content", "Expected file content"},
+		{"pattern", "Pattern the content must match"},
+		{"substring", "Substring the content must contain"},
+		{"target", "Symlink target path"},
+		{"age", "Maximum file age"},
+		{"within", "Freshness duration"},
+		{"count", "Expected number of entries"},
+		{"backup", "Keep a backup before repairs"},
+	},
+	"http.get": {
+		{"expected_status", "Expected HTTP status code"},
+		{"timeout", "Request timeout"},
+		{"retries", "Number of retries before reporting failure"},
+		{"retry_interval", "Delay between retries"},
+		{"substring", "Substring the response body must contain"},
+		{"method", "HTTP method (default GET)"},
+		{"body", "Request body"},
+		{"auth_env", "Environment variable holding credentials"},
+		{"issuer", "Expected TLS certificate issuer"},
+		{"follow_redirects", "Follow HTTP redirects"},
+	},
+	"dns.native": {
+		{"a", "Expected A record value"},
+		{"aaaa", "Expected AAAA record value"},
+		{"cname", "Expected CNAME target"},
+		{"nameserver", "Nameserver to query"},
+		{"timeout", "Lookup timeout"},
+	},
+	"tcp.native": {
+		{"timeout", "Connection timeout"},
+		{"start", "Command that starts the service"},
+		{"stop", "Command that stops the service"},
+	},
+	"cert.native": {
+		{"issuer", "Expected certificate issuer"},
+		{"duration", "Minimum remaining validity"},
+		{"name", "Expected subject name"},
+		{"renew", "Command that renews the certificate"},
+	},
+	"cron.native": {
+		{"schedule", "Cron schedule expression"},
+		{"command", "Command the job must run"},
+		{"user", "User the job runs as"},
+	},
+	"env.native": {
+		{"value", "Expected variable value"},
+		{"expected", "Expected variable value"},
+		{"pattern", "Pattern the value must match"},
+	},
+	"exec": {
+		{"check", "Command whose exit status performs the check"},
+		{"repair", "Command run to repair a violation"},
+	},
+	"pkg.native": {
+		{"version", "Required package version"},
+	},
+	"account.native": {
+		{"group", "Primary group the account must belong to"},
+		{"value", "Expected account property value"},
+	},
+	"mount.native": {
+		{"device", "Device that must be mounted"},
+		{"options", "Required mount options"},
+		{"bytes", "Minimum free space in bytes"},
+		{"percent", "Minimum free space percentage"},
+	},
+	"s3.aws": {
+		{"region", "AWS region"},
+		{"profile", "AWS credentials profile"},
+		{"endpoint", "Custom S3 endpoint"},
+		{"source", "Local file to sync from"},
+		{"expected", "Expected object content hash"},
+		{"duration", "Maximum object age"},
+	},
+	"sysctl.native": {
+		{"expected", "Expected kernel parameter value"},
+		{"persist", "Persist the value across reboots"},
+	},
+	"datastore.native": {
+		{"timeout", "Connection timeout"},
+		{"password", "Password reference"},
+		{"banner", "Expected server banner"},
+	},
+	"template.native": {
+		{"template", "Template file rendered into the resource"},
+	},
+	"plugin": {
+		{"name", "Plugin binary to invoke"},
+	},
+}
+
+// getSignatureHelp shows the argument keys a handler accepts while the
+// cursor sits inside a `with <handler> ...` clause.
+func (s *Server) getSignatureHelp(params TextDocumentPositionParams) *SignatureHelp {
+	s.mu.RLock()
+	content, ok := s.documents[params.TextDocument.URI]
+	s.mu.RUnlock()
+
+	if !ok {
+		return nil
+	}
+
+	lines := strings.Split(content, "\n")
+	if params.Position.Line >= len(lines) {
+		return nil
+	}
+	line := lines[params.Position.Line]
+	if params.Position.Character < len(line) {
+		line = line[:params.Position.Character]
+	}
+
+	handler := handlerBeforeCursor(line)
+	if handler == "" {
+		return nil
+	}
+	args, ok := handlerMetadata[handler]
+	if !ok {
+		return nil
+	}
+
+	sig := SignatureInformation{
+		Label:         handler + " " + signatureLabel(args),
+		Documentation: "Arguments accepted by " + handler,
+	}
+	for _, arg := range args {
+		sig.Parameters = append(sig.Parameters, ParameterInformation{
+			Label:         arg.name,
+			Documentation: arg.doc,
+		})
+	}
+	return &SignatureHelp{
+		Signatures:      []SignatureInformation{sig},
+		ActiveParameter: argCountAfterHandler(line, handler),
+	}
+}
+
+// handlerBeforeCursor returns the handler named in the nearest `with`
+// clause before the cursor on the current line.
+func handlerBeforeCursor(line string) string {
+	fields := strings.Fields(line)
+	for i := len(fields) - 1; i > 0; i-- {
+		if fields[i-1] == "with" {
+			return fields[i]
+		}
+	}
+	return ""
+}
+
+// argCountAfterHandler counts the key:value arguments already typed
+// after the handler name, to highlight the next expected parameter.
+func argCountAfterHandler(line, handler string) int {
+	idx := strings.LastIndex(line, handler)
+	if idx < 0 {
+		return 0
+	}
+	return strings.Count(line[idx+len(handler):], ":")
+}
+
+func signatureLabel(args []handlerArg) string {
+	names := make([]string, len(args))
+	for i, arg := range args {
+		names[i] = arg.name + ":<value>"
+	}
+	return strings.Join(names, " ")
+}
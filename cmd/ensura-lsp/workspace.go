@@ -0,0 +1,139 @@
+package main
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ensurascript/ensura/pkg/ast"
+	"github.com/ensurascript/ensura/pkg/lexer"
+	"github.com/ensurascript/ensura/pkg/parser"
+)
+
+type DidChangeWatchedFilesParams struct {
+	Changes []FileEvent `json:"changes"`
+}
+
+type FileEvent struct {
+	URI  string `json:"uri"`
+	Type int    `json:"type"`
+}
+
+// File change types
+const (
+	FileChangeCreated = 1
+	FileChangeChanged = 2
+	FileChangeDeleted = 3
+)
+
+type WorkspaceSymbolParams struct {
+	Query string `json:"query"`
+}
+
+type SymbolInformation struct {
+	Name     string   `json:"name"`
+	Kind     int      `json:"kind"`
+	Location Location `json:"location"`
+}
+
+// uriToPath converts a file:// URI to a filesystem path.
+func uriToPath(uri string) string {
+	return strings.TrimPrefix(uri, "file://")
+}
+
+// pathToURI converts a filesystem path to a file:// URI.
+func pathToURI(path string) string {
+	return "file://" + path
+}
+
+// indexWorkspace reads every .ens file under root into the workspace
+// index so cross-file symbols resolve before their files are opened.
+func (s *Server) indexWorkspace(rootURI string) {
+	if rootURI == "" {
+		return
+	}
+	root := uriToPath(rootURI)
+
+	indexed := make(map[string]string)
+	filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || filepath.Ext(path) != ".ens" {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil
+		}
+		indexed[pathToURI(path)] = string(data)
+		return nil
+	})
+
+	s.mu.Lock()
+	s.workspace = indexed
+	s.mu.Unlock()
+}
+
+// handleWatchedFiles keeps the workspace index in sync with file events
+// reported by the client.
+func (s *Server) handleWatchedFiles(params DidChangeWatchedFilesParams) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, change := range params.Changes {
+		if filepath.Ext(uriToPath(change.URI)) != ".ens" {
+			continue
+		}
+		switch change.Type {
+		case FileChangeCreated, FileChangeChanged:
+			data, err := os.ReadFile(uriToPath(change.URI))
+			if err != nil {
+				delete(s.workspace, change.URI)
+				continue
+			}
+			s.workspace[change.URI] = string(data)
+		case FileChangeDeleted:
+			delete(s.workspace, change.URI)
+		}
+	}
+}
+
+// getWorkspaceSymbols returns every policy and resource declaration in
+// the workspace whose name contains the query, case-insensitively. An
+// empty query matches everything.
+func (s *Server) getWorkspaceSymbols(params WorkspaceSymbolParams) []SymbolInformation {
+	query := strings.ToLower(params.Query)
+
+	symbols := []SymbolInformation{}
+	for uri, content := range s.allDocuments() {
+		l := lexer.New(content)
+		p := parser.New(l)
+		program := p.Parse()
+		if program == nil {
+			continue
+		}
+		for _, stmt := range program.Statements {
+			switch st := stmt.(type) {
+			case *ast.PolicyDecl:
+				if strings.Contains(strings.ToLower(st.Name), query) {
+					symbols = append(symbols, SymbolInformation{
+						Name:     st.Name,
+						Kind:     SymbolKindFunction,
+						Location: Location{URI: uri, Range: nameRange(st.Pos(), st.Name)},
+					})
+				}
+			case *ast.ResourceDecl:
+				name := st.Alias
+				if name == "" {
+					name = st.Path
+				}
+				if strings.Contains(strings.ToLower(name), query) {
+					symbols = append(symbols, SymbolInformation{
+						Name:     name,
+						Kind:     SymbolKindObject,
+						Location: Location{URI: uri, Range: lexerRange(st.Pos(), st.End())},
+					})
+				}
+			}
+		}
+	}
+	return symbols
+}
@@ -0,0 +1,223 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// completionCommand describes a subcommand to the completion generators:
+// its name, a one-line description, and the flags it accepts.
+type completionCommand struct {
+	name        string
+	description string
+	flags       []string
+}
+
+// completionCommands drives every generated script. Keep it in sync with
+// the switch in main and the flag sets of the individual commands.
+var completionCommands = []completionCommand{
+	{"compile", "Validate and print the resolved guarantee graph", []string{"-json", "-graph", "-graph-format", "-format"}},
+	{"validate", "Report all compile diagnostics as structured JSON", []string{"-json"}},
+	{"init", "Generate a starter .ens file", []string{"-scenario", "-o", "-force", "-list"}},
+	{"explain", "Show implied guarantees and chosen handlers", []string{"-json", "-why"}},
+	{"plan", "Print the deterministic sequential execution plan", []string{"-json", "-out"}},
+	{"graph", "Query the dependency graph", []string{"-what-depends-on", "-dependencies-of", "-dependents-of"}},
+	{"run", "Run the continuous enforcement loop", []string{"-interval", "-retries", "-step-timeout", "-breaker", "-breaker-cooldown", "-renotify", "-mode", "-from-plan", "-state-dir", "-metrics", "-watch-config", "-log-format", "-only-tags", "-skip-tags"}},
+	{"check", "Check guarantees without enforcing", []string{"-json", "-format"}},
+	{"lint", "Report style and safety issues beyond hard errors", []string{"-json", "-enable", "-disable"}},
+	{"policies", "Browse the built-in policy library", nil},
+	{"get", "Vendor policy modules into ensura_modules/", nil},
+	{"test", "Run the plan against mock handlers", []string{"-scenario", "-json"}},
+	{"diff", "Show plan differences between two config files", []string{"-json"}},
+	{"history", "List past enforcement runs", []string{"-state-dir", "-json"}},
+	{"drift", "Report guarantees flipping between satisfied and violated", []string{"-state-dir", "-last", "-json"}},
+	{"rotate", "Re-encrypt files with a new key", []string{"-old-key", "-new-key", "-key-id"}},
+	{"encrypt", "Encrypt a file in place", []string{"-key", "-key-id"}},
+	{"decrypt", "Decrypt a file to stdout or -o", []string{"-key", "-o"}},
+	{"serve", "Run the enforcement loop with an HTTP control API", []string{"-addr", "-interval", "-retries", "-breaker", "-breaker-cooldown", "-renotify", "-metrics"}},
+	{"completion", "Generate a shell completion script", nil},
+	{"version", "Print version information", nil},
+	{"help", "Show help", nil},
+}
+
+// cmdCompletion prints a completion script for the requested shell.
+func cmdCompletion(args []string) {
+	if len(args) < 1 {
+		fmt.Fprintln(os.Stderr, "Usage: ensura completion <bash|zsh|fish|powershell>")
+		os.Exit(exitCompile)
+	}
+
+	switch args[0] {
+	case "bash":
+		writeBashCompletion()
+	case "zsh":
+		writeZshCompletion()
+	case "fish":
+		writeFishCompletion()
+	case "powershell":
+		writePowershellCompletion()
+	default:
+		fmt.Fprintf(os.Stderr, "Error: unknown shell %q (want bash, zsh, fish, or powershell)\n", args[0])
+		os.Exit(exitCompile)
+	}
+}
+
+func completionNames() []string {
+	names := make([]string, len(completionCommands))
+	for i, c := range completionCommands {
+		names[i] = c.name
+	}
+	return names
+}
+
+func writeBashCompletion() {
+	fmt.Println(`# bash completion for ensura.
+# Install with: ensura completion bash > /etc/bash_completion.d/ensura
+_ensura() {
+    local cur cmd flags
+    cur="${COMP_WORDS[COMP_CWORD]}"
+
+    if [ "$COMP_CWORD" -eq 1 ]; then
+        COMPREPLY=($(compgen -W "` + strings.Join(completionNames(), " ") + `" -- "$cur"))
+        return
+    fi
+
+    cmd="${COMP_WORDS[1]}"
+    case "$cur" in
+    -*)
+        case "$cmd" in`)
+	for _, c := range completionCommands {
+		if len(c.flags) == 0 {
+			continue
+		}
+		fmt.Printf("        %s) flags=\"%s\" ;;\n", c.name, strings.Join(c.flags, " "))
+	}
+	fmt.Println(`        esac
+        COMPREPLY=($(compgen -W "$flags -conditions" -- "$cur"))
+        ;;
+    *)
+        if [ "$cmd" = "completion" ]; then
+            COMPREPLY=($(compgen -W "bash zsh fish powershell" -- "$cur"))
+            return
+        fi
+        # Complete .ens files and directories.
+        COMPREPLY=($(compgen -f -X '!*.ens' -- "$cur") $(compgen -d -- "$cur"))
+        ;;
+    esac
+}
+complete -o filenames -F _ensura ensura`)
+}
+
+func writeZshCompletion() {
+	fmt.Println(`#compdef ensura
+# zsh completion for ensura.
+# Install with: ensura completion zsh > "${fpath[1]}/_ensura"
+_ensura() {
+    local -a commands
+    commands=(`)
+	for _, c := range completionCommands {
+		fmt.Printf("        '%s:%s'\n", c.name, strings.ReplaceAll(c.description, "'", ""))
+	}
+	fmt.Println(`    )
+
+    if (( CURRENT == 2 )); then
+        _describe 'command' commands
+        return
+    fi
+
+    local -a flags
+    case "$words[2]" in`)
+	for _, c := range completionCommands {
+		if len(c.flags) == 0 {
+			continue
+		}
+		quoted := make([]string, len(c.flags))
+		for i, f := range c.flags {
+			quoted[i] = "'" + f + "'"
+		}
+		fmt.Printf("    %s) flags=(%s) ;;\n", c.name, strings.Join(quoted, " "))
+	}
+	fmt.Println(`    esac
+
+    if [[ "$words[2]" == completion ]]; then
+        compadd bash zsh fish powershell
+        return
+    fi
+
+    if [[ "$words[CURRENT]" == -* ]]; then
+        compadd -- "${flags[@]}" -conditions
+    else
+        _files -g '*.ens'
+    fi
+}
+_ensura "$@"`)
+}
+
+func writeFishCompletion() {
+	fmt.Println("# fish completion for ensura.")
+	fmt.Println("# Install with: ensura completion fish > ~/.config/fish/completions/ensura.fish")
+	for _, c := range completionCommands {
+		fmt.Printf("complete -c ensura -n '__fish_use_subcommand' -a %s -d '%s'\n",
+			c.name, strings.ReplaceAll(c.description, "'", ""))
+	}
+	for _, c := range completionCommands {
+		for _, f := range c.flags {
+			fmt.Printf("complete -c ensura -n '__fish_seen_subcommand_from %s' -o %s\n",
+				c.name, strings.TrimPrefix(f, "-"))
+		}
+	}
+	fmt.Println("complete -c ensura -n '__fish_seen_subcommand_from completion' -f -a 'bash zsh fish powershell'")
+	fmt.Println("complete -c ensura -o conditions -r")
+}
+
+func writePowershellCompletion() {
+	var flagCases strings.Builder
+	for _, c := range completionCommands {
+		if len(c.flags) == 0 {
+			continue
+		}
+		quoted := make([]string, len(c.flags))
+		for i, f := range c.flags {
+			quoted[i] = "'" + f + "'"
+		}
+		fmt.Fprintf(&flagCases, "        '%s' { $flags = @(%s) }\n", c.name, strings.Join(quoted, ", "))
+	}
+
+	quotedNames := make([]string, len(completionCommands))
+	for i, c := range completionCommands {
+		quotedNames[i] = "'" + c.name + "'"
+	}
+
+	fmt.Println(`# PowerShell completion for ensura.
+# Install by adding this script's output to your $PROFILE.
+Register-ArgumentCompleter -Native -CommandName ensura -ScriptBlock {
+    param($wordToComplete, $commandAst, $cursorPosition)
+    $elements = $commandAst.CommandElements
+    $commands = @(` + strings.Join(quotedNames, ", ") + `)
+
+    if ($elements.Count -le 2) {
+        $commands | Where-Object { $_ -like "$wordToComplete*" } |
+            ForEach-Object { [System.Management.Automation.CompletionResult]::new($_, $_, 'ParameterValue', $_) }
+        return
+    }
+
+    $flags = @()
+    switch ($elements[1].Value) {
+` + flagCases.String() + `    }
+
+    if ($elements[1].Value -eq 'completion') {
+        @('bash', 'zsh', 'fish', 'powershell') | Where-Object { $_ -like "$wordToComplete*" } |
+            ForEach-Object { [System.Management.Automation.CompletionResult]::new($_, $_, 'ParameterValue', $_) }
+        return
+    }
+
+    if ($wordToComplete -like '-*') {
+        ($flags + '-conditions') | Where-Object { $_ -like "$wordToComplete*" } |
+            ForEach-Object { [System.Management.Automation.CompletionResult]::new($_, $_, 'ParameterValue', $_) }
+    } else {
+        Get-ChildItem -Path "$wordToComplete*" -Include '*.ens' -ErrorAction SilentlyContinue |
+            ForEach-Object { [System.Management.Automation.CompletionResult]::new($_.Name, $_.Name, 'ProviderItem', $_.Name) }
+    }
+}`)
+}
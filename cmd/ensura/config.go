@@ -0,0 +1,163 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// globalConfig holds fleet-wide defaults loaded from the optional config
+// file at ~/.config/ensura/config.yaml (or $ENSURA_CONFIG). Settings map
+// onto subcommand flag names and apply only when the flag was not given
+// on the command line, so explicit flags always win. A named profile
+// selected with the global -profile flag overlays the base settings.
+//
+//	interval: 60s
+//	retries: 5
+//	profiles:
+//	  prod:
+//	    interval: 30s
+//	    retries: 10
+type globalConfig struct {
+	settings map[string]string
+	profiles map[string]map[string]string
+}
+
+// configProfile holds the profile name set with the global -profile flag.
+var configProfile string
+
+// loadedConfig is the parsed global config, or nil when no file exists.
+var loadedConfig *globalConfig
+
+// globalConfigPath returns the config file location: $ENSURA_CONFIG when
+// set, otherwise ~/.config/ensura/config.yaml.
+func globalConfigPath() string {
+	if path := os.Getenv("ENSURA_CONFIG"); path != "" {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".config", "ensura", "config.yaml")
+}
+
+// loadGlobalConfig reads and parses the global config file. A missing
+// file is not an error; a file named via ENSURA_CONFIG must exist.
+func loadGlobalConfig() (*globalConfig, error) {
+	path := globalConfigPath()
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) && os.Getenv("ENSURA_CONFIG") == "" {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read config %s: %w", path, err)
+	}
+	config, err := parseGlobalConfig(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return config, nil
+}
+
+// parseGlobalConfig parses the subset of YAML the config file uses: flat
+// "key: value" settings, plus a "profiles:" section holding one level of
+// named settings maps. Comments and blank lines are skipped.
+func parseGlobalConfig(source string) (*globalConfig, error) {
+	config := &globalConfig{
+		settings: map[string]string{},
+		profiles: map[string]map[string]string{},
+	}
+
+	inProfiles := false
+	var currentProfile map[string]string
+
+	for n, raw := range strings.Split(source, "\n") {
+		line := strings.TrimRight(raw, " \t\r")
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		indent := len(line) - len(strings.TrimLeft(line, " \t"))
+		key, value, ok := strings.Cut(trimmed, ":")
+		if !ok {
+			return nil, fmt.Errorf("line %d: want key: value, got %q", n+1, trimmed)
+		}
+		key = strings.TrimSpace(key)
+		value = strings.Trim(strings.TrimSpace(value), `"'`)
+
+		switch {
+		case indent == 0 && key == "profiles" && value == "":
+			inProfiles = true
+			currentProfile = nil
+		case indent == 0:
+			if value == "" {
+				return nil, fmt.Errorf("line %d: %q has no value", n+1, key)
+			}
+			inProfiles = false
+			config.settings[key] = value
+		case inProfiles && value == "":
+			currentProfile = map[string]string{}
+			config.profiles[key] = currentProfile
+		case inProfiles && currentProfile != nil:
+			currentProfile[key] = value
+		default:
+			return nil, fmt.Errorf("line %d: unexpected indented line %q", n+1, trimmed)
+		}
+	}
+
+	return config, nil
+}
+
+// effectiveSettings returns the base settings overlaid with the selected
+// profile, or an error if that profile is not defined.
+func (c *globalConfig) effectiveSettings(profile string) (map[string]string, error) {
+	merged := map[string]string{}
+	for key, value := range c.settings {
+		merged[key] = value
+	}
+	if profile != "" {
+		overlay, ok := c.profiles[profile]
+		if !ok {
+			return nil, fmt.Errorf("profile %q not defined in %s", profile, globalConfigPath())
+		}
+		for key, value := range overlay {
+			merged[key] = value
+		}
+	}
+	return merged, nil
+}
+
+// applyConfigDefaults seeds fs with values from the global config for
+// every flag the user did not set explicitly. Call it after fs has
+// parsed the command line. Settings that do not name a flag of this
+// subcommand are ignored, so one config serves all subcommands.
+func applyConfigDefaults(fs *flag.FlagSet) {
+	if loadedConfig == nil {
+		return
+	}
+	settings, err := loadedConfig.effectiveSettings(configProfile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitCompile)
+	}
+
+	explicit := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { explicit[f.Name] = true })
+
+	for key, value := range settings {
+		if explicit[key] || fs.Lookup(key) == nil {
+			continue
+		}
+		if err := fs.Set(key, value); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: config setting %s: %v\n", key, err)
+			os.Exit(exitCompile)
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ensurascript/ensura/pkg/planner"
+)
+
+// doctorFinding is one host-environment check and its outcome.
+type doctorFinding struct {
+	status  string // "ok", "warn", or "fail"
+	message string
+}
+
+// cmdDoctor verifies the host can actually enforce a config before a run:
+// required tools on PATH, write access to target paths, reachable HTTP
+// endpoints, and environment variables referenced as keys.
+func cmdDoctor(args []string) {
+	fs := flag.NewFlagSet("doctor", flag.ExitOnError)
+	timeout := fs.Duration("timeout", 5*time.Second, "Timeout for HTTP reachability probes")
+	fs.Parse(args)
+
+	if fs.NArg() < 1 {
+		fmt.Fprintln(os.Stderr, "Usage: ensura doctor [options] <file.ens|dir>...")
+		os.Exit(exitCompile)
+	}
+
+	result, err := loadAndCompile(fs.Args()...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitCompile)
+	}
+
+	findings := diagnoseHost(result.plan, *timeout)
+
+	fmt.Printf("Checking host environment for %s (%d guarantees)\n\n", strings.Join(fs.Args(), " "), len(result.plan.Steps))
+	failures := 0
+	for _, f := range findings {
+		fmt.Printf("  %-4s  %s\n", f.status, f.message)
+		if f.status == "fail" {
+			failures++
+		}
+	}
+	if len(findings) == 0 {
+		fmt.Println("  nothing to check for this config")
+	}
+
+	fmt.Println()
+	if failures > 0 {
+		fmt.Printf("%d problems found. A run on this host would not be able to enforce every guarantee.\n", failures)
+		os.Exit(exitViolations)
+	}
+	fmt.Println("No problems found.")
+}
+
+// diagnoseHost derives environment checks from the plan and runs them.
+func diagnoseHost(plan *planner.Plan, timeout time.Duration) []doctorFinding {
+	var findings []doctorFinding
+	add := func(status, format string, args ...interface{}) {
+		findings = append(findings, doctorFinding{status, fmt.Sprintf(format, args...)})
+	}
+
+	// Each kind of check runs at most once per distinct input.
+	checkedTools := map[string]bool{}
+	checkedPaths := map[string]bool{}
+	checkedURLs := map[string]bool{}
+	checkedEnv := map[string]bool{}
+
+	checkTool := func(tool, neededBy string) {
+		if checkedTools[tool] {
+			return
+		}
+		checkedTools[tool] = true
+		if path, err := exec.LookPath(tool); err == nil {
+			add("ok", "%s available (%s)", tool, path)
+		} else {
+			add("fail", "%s not found on PATH (needed by %s)", tool, neededBy)
+		}
+	}
+
+	for _, step := range plan.Steps {
+		stmt := step.Guarantee.Statement
+		subject := stmt.Subject
+
+		if strings.HasPrefix(step.Handler, "cron.") {
+			checkTool("crontab", "handler "+step.Handler)
+		}
+		if strings.HasPrefix(step.Handler, "mount.") {
+			checkTool("mount", "handler "+step.Handler)
+		}
+		if subject != nil && subject.ResourceType == "service" {
+			checkTool("systemctl", fmt.Sprintf("service %q", subject.Path))
+		}
+
+		// Write access to file and directory targets.
+		if subject != nil && (subject.ResourceType == "file" || subject.ResourceType == "directory") &&
+			subject.Path != "" && !subject.IsIterator && !checkedPaths[subject.Path] {
+			checkedPaths[subject.Path] = true
+			findings = append(findings, checkPathWritable(subject.Path, subject.ResourceType))
+		}
+
+		// HTTP endpoints must answer a HEAD request.
+		if subject != nil && subject.ResourceType == "http" && subject.Path != "" && !checkedURLs[subject.Path] {
+			checkedURLs[subject.Path] = true
+			findings = append(findings, checkEndpoint(subject.Path, timeout))
+		}
+
+		// Environment variables referenced as env: values in handler args.
+		for arg, value := range step.HandlerArgs {
+			name, ok := strings.CutPrefix(value, "env:")
+			if !ok || checkedEnv[name] {
+				continue
+			}
+			checkedEnv[name] = true
+			if os.Getenv(name) != "" {
+				add("ok", "$%s is set", name)
+			} else {
+				add("fail", "$%s not set (referenced by %s of %s)", name, arg, step.Description)
+			}
+		}
+	}
+
+	return findings
+}
+
+// checkPathWritable reports whether the target path, or for missing
+// targets the closest existing parent directory, can be written to.
+func checkPathWritable(path, resourceType string) doctorFinding {
+	info, err := os.Stat(path)
+	if err == nil {
+		if info.IsDir() {
+			if err := probeDirWritable(path); err != nil {
+				return doctorFinding{"fail", fmt.Sprintf("%s %q is not writable: %v", resourceType, path, err)}
+			}
+			return doctorFinding{"ok", fmt.Sprintf("%s %q is writable", resourceType, path)}
+		}
+		f, err := os.OpenFile(path, os.O_WRONLY, 0)
+		if err != nil {
+			return doctorFinding{"fail", fmt.Sprintf("%s %q is not writable: %v", resourceType, path, err)}
+		}
+		f.Close()
+		return doctorFinding{"ok", fmt.Sprintf("%s %q is writable", resourceType, path)}
+	}
+
+	// Target missing: a repair would have to create it, so its nearest
+	// existing ancestor must be writable.
+	dir := filepath.Dir(path)
+	for dir != filepath.Dir(dir) {
+		if _, err := os.Stat(dir); err == nil {
+			break
+		}
+		dir = filepath.Dir(dir)
+	}
+	if err := probeDirWritable(dir); err != nil {
+		return doctorFinding{"fail", fmt.Sprintf("%s %q does not exist and %q is not writable: %v", resourceType, path, dir, err)}
+	}
+	return doctorFinding{"warn", fmt.Sprintf("%s %q does not exist yet, but %q is writable", resourceType, path, dir)}
+}
+
+// probeDirWritable creates and removes a temporary file in dir.
+func probeDirWritable(dir string) error {
+	f, err := os.CreateTemp(dir, ".ensura-doctor-*")
+	if err != nil {
+		return err
+	}
+	name := f.Name()
+	f.Close()
+	return os.Remove(name)
+}
+
+// checkEndpoint sends a HEAD request to the URL.
+func checkEndpoint(url string, timeout time.Duration) doctorFinding {
+	client := &http.Client{Timeout: timeout}
+	resp, err := client.Head(url)
+	if err != nil {
+		return doctorFinding{"fail", fmt.Sprintf("http %q is not reachable: %v", url, err)}
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 500 {
+		return doctorFinding{"warn", fmt.Sprintf("http %q answered HEAD with %d", url, resp.StatusCode)}
+	}
+	return doctorFinding{"ok", fmt.Sprintf("http %q answered HEAD with %d", url, resp.StatusCode)}
+}
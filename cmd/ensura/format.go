@@ -0,0 +1,312 @@
+package main
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/ensurascript/ensura/pkg/runtime"
+)
+
+// githubAnnotations switches compile error reporting to GitHub Actions
+// workflow commands on stdout, set by -format github.
+var githubAnnotations bool
+
+// reportStageErrors prints one line per compile error to stderr, or, in
+// GitHub annotation mode, one workflow command per error to stdout so
+// problems surface inline on pull requests.
+func reportStageErrors(label string, errs []string) {
+	for _, e := range errs {
+		if githubAnnotations {
+			file, line, col, msg := splitPositionedError(e)
+			emitGitHubAnnotation(os.Stdout, "error", file, line, col, msg)
+			continue
+		}
+		fmt.Fprintf(os.Stderr, "%s: %s\n", label, e)
+	}
+}
+
+// positionedError matches the "file:line:col: message" prefix that stage
+// errors carry when the source filename is known.
+var positionedError = regexp.MustCompile(`^(.+):(\d+):(\d+): (.*)$`)
+
+func splitPositionedError(errStr string) (file string, line, col string, msg string) {
+	if m := positionedError.FindStringSubmatch(errStr); m != nil {
+		return m[1], m[2], m[3], m[4]
+	}
+	return "", "", "", errStr
+}
+
+// emitGitHubAnnotation prints a workflow command like
+// ::error file=config.ens,line=3,col=5::message.
+func emitGitHubAnnotation(w io.Writer, level, file, line, col, msg string) {
+	var props []string
+	if file != "" {
+		props = append(props, "file="+file)
+	}
+	if line != "" {
+		props = append(props, "line="+line)
+	}
+	if col != "" {
+		props = append(props, "col="+col)
+	}
+	cmd := "::" + level
+	if len(props) > 0 {
+		cmd += " " + strings.Join(props, ",")
+	}
+	fmt.Fprintf(w, "%s::%s\n", cmd, escapeAnnotation(msg))
+}
+
+// escapeAnnotation escapes the characters GitHub's command parser treats
+// specially in message data.
+func escapeAnnotation(msg string) string {
+	msg = strings.ReplaceAll(msg, "%", "%25")
+	msg = strings.ReplaceAll(msg, "\r", "%0D")
+	msg = strings.ReplaceAll(msg, "\n", "%0A")
+	return msg
+}
+
+// writeGitHubAnnotations emits violated and failed steps as workflow
+// commands located at the ensure statement that declared the guarantee.
+func writeGitHubAnnotations(w io.Writer, result *runtime.RunResult) {
+	for _, step := range result.Steps {
+		if step.Status != runtime.StepViolated && step.Status != runtime.StepFailed {
+			continue
+		}
+		msg := step.Message
+		if msg == "" && step.Error != nil {
+			msg = step.Error.Error()
+		}
+		level := "error"
+		switch step.Step.Severity {
+		case "warning":
+			level = "warning"
+		case "info":
+			level = "notice"
+		}
+		pos := step.Step.Guarantee.Statement.Position
+		var file, line, col string
+		if pos.Filename != "" {
+			file = pos.Filename
+		}
+		if pos.Line > 0 {
+			line = fmt.Sprint(pos.Line)
+			col = fmt.Sprint(pos.Column)
+		}
+		emitGitHubAnnotation(w, level, file, line, col,
+			fmt.Sprintf("%s: %s", step.Step.Description, msg))
+	}
+}
+
+// JUnit XML shapes, as consumed by CI test reporters.
+type junitTestSuite struct {
+	XMLName  xml.Name        `xml:"testsuite"`
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Time     string          `xml:"time,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Body    string `xml:",chardata"`
+}
+
+// writeJUnit emits a check result as JUnit XML: one testcase per
+// guarantee, with violated and failed steps reported as failures.
+func writeJUnit(w io.Writer, result *runtime.RunResult) error {
+	suite := junitTestSuite{
+		Name:     "ensura",
+		Tests:    result.TotalChecks,
+		Failures: result.TotalFailures,
+		Time:     fmt.Sprintf("%.3f", result.EndTime.Sub(result.StartTime).Seconds()),
+	}
+	for _, step := range result.Steps {
+		tc := junitTestCase{
+			Name:      step.Step.Description,
+			ClassName: step.Step.Handler,
+		}
+		if step.Status == runtime.StepViolated || step.Status == runtime.StepFailed {
+			msg := step.Message
+			if msg == "" && step.Error != nil {
+				msg = step.Error.Error()
+			}
+			tc.Failure = &junitFailure{
+				Message: msg,
+				Body:    step.Status.String(),
+			}
+		}
+		suite.Cases = append(suite.Cases, tc)
+	}
+
+	fmt.Fprint(w, xml.Header)
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(suite); err != nil {
+		return err
+	}
+	fmt.Fprintln(w)
+	return nil
+}
+
+// SARIF 2.1.0 shapes, trimmed to what the check report needs.
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string `json:"name"`
+	InformationURI string `json:"informationUri,omitempty"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+// writeSARIF emits violated and failed steps as SARIF results located at
+// the ensure statement that declared the guarantee.
+func writeSARIF(w io.Writer, result *runtime.RunResult, filename string) error {
+	results := []sarifResult{}
+	for _, step := range result.Steps {
+		if step.Status != runtime.StepViolated && step.Status != runtime.StepFailed {
+			continue
+		}
+		msg := step.Message
+		if msg == "" && step.Error != nil {
+			msg = step.Error.Error()
+		}
+		res := sarifResult{
+			RuleID:  step.Step.Guarantee.Statement.Condition,
+			Level:   sarifLevel(step.Step.Severity),
+			Message: sarifMessage{Text: fmt.Sprintf("%s: %s", step.Step.Description, msg)},
+		}
+		pos := step.Step.Guarantee.Statement.Position
+		uri := pos.Filename
+		if uri == "" {
+			uri = filename
+		}
+		if uri != "" {
+			loc := sarifLocation{
+				PhysicalLocation: sarifPhysicalLocation{
+					ArtifactLocation: sarifArtifactLocation{URI: uri},
+				},
+			}
+			if pos.Line > 0 {
+				loc.PhysicalLocation.Region = &sarifRegion{
+					StartLine:   pos.Line,
+					StartColumn: pos.Column,
+				}
+			}
+			res.Locations = []sarifLocation{loc}
+		}
+		results = append(results, res)
+	}
+
+	log := sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs: []sarifRun{{
+			Tool: sarifTool{Driver: sarifDriver{
+				Name:           "ensura",
+				InformationURI: "https://github.com/ensurascript/ensura",
+			}},
+			Results: results,
+		}},
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(log)
+}
+
+// writeNagios prints the single status line a Nagios/Icinga check plugin
+// is expected to produce, with perfdata, and returns the matching exit
+// code: 0 when everything is satisfied, 1 when only warning- or
+// info-level guarantees are violated, and 2 when blocking guarantees are.
+func writeNagios(w io.Writer, result *runtime.RunResult) int {
+	status, code := "OK", 0
+	switch {
+	case result.BlockingFailures() > 0:
+		status, code = "CRITICAL", 2
+	case result.TotalFailures > 0:
+		status, code = "WARNING", 1
+	}
+
+	var summary string
+	switch code {
+	case 0:
+		summary = fmt.Sprintf("all %d guarantees satisfied", result.TotalChecks)
+	default:
+		failed := []string{}
+		for _, step := range result.Steps {
+			if step.Status == runtime.StepViolated || step.Status == runtime.StepFailed {
+				failed = append(failed, step.Step.Description)
+			}
+		}
+		summary = fmt.Sprintf("%d of %d guarantees failing: %s",
+			result.TotalFailures, result.TotalChecks, strings.Join(failed, "; "))
+	}
+
+	fmt.Fprintf(w, "ENSURA %s - %s | checks=%d failures=%d repairs=%d\n",
+		status, summary, result.TotalChecks, result.TotalFailures, result.TotalRepairs)
+	return code
+}
+
+// sarifLevel maps guarantee severities to SARIF levels.
+func sarifLevel(severity string) string {
+	switch severity {
+	case "warning":
+		return "warning"
+	case "info":
+		return "note"
+	default:
+		return "error"
+	}
+}
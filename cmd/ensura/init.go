@@ -0,0 +1,178 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// initScenario is one of the starter configs ensura init can generate.
+type initScenario struct {
+	name        string
+	description string
+	template    string
+}
+
+var initScenarios = []initScenario{
+	{
+		name:        "secrets",
+		description: "Secure a directory of secrets (encryption, permissions)",
+		template: `# Generated by ensura init.
+# Keeps a secrets directory locked down: every file in it must exist
+# encrypted with a key from the environment and readable only by its owner.
+
+on directory "/etc/myapp/secrets" {
+  ensure exists
+  ensure permissions with posix mode "0700"
+}
+
+for each file in directory "/etc/myapp/secrets" {
+  ensure encrypted with AES:256 key "env:SECRET_KEY"
+  ensure permissions with posix mode "0600"
+}
+
+# What to do when a guarantee above is violated and cannot be repaired.
+on violation {
+  retry 3
+  notify "ops"
+}
+`,
+	},
+	{
+		name:        "http",
+		description: "Monitor an HTTP endpoint (reachability, status, TLS)",
+		template: `# Generated by ensura init.
+# Watches a health endpoint: it must answer, answer with 200, and serve
+# a valid TLS certificate. Run with: ensura run <this file> -interval 30s
+
+resource http "https://example.com/health" as health
+
+ensure reachable on http "https://example.com/health"
+ensure status_code on http "https://example.com/health" with http.get expected_status "200"
+ensure tls on http "https://example.com/health"
+
+# What to do when the endpoint stops satisfying a guarantee.
+on violation {
+  retry 5
+  notify "oncall"
+}
+`,
+	},
+	{
+		name:        "backup",
+		description: "Schedule a backup job and protect its output",
+		template: `# Generated by ensura init.
+# Keeps a nightly backup job installed in cron and its output file
+# encrypted and private.
+
+# The backup job itself: installed at 2am daily.
+on cron "nightly_backup" {
+  ensure scheduled with cron.native schedule "0 2 * * *" command "/usr/local/bin/backup.sh"
+}
+
+# The file the job produces.
+on file "/var/backups/data.tar.gz.enc" {
+  ensure exists
+  ensure encrypted with AES:256 key "env:BACKUP_KEY"
+  ensure permissions with posix mode "0600"
+}
+
+# What to do when the job disappears or the backup file is tampered with.
+on violation {
+  retry 3
+  notify "backup-team"
+}
+`,
+	},
+}
+
+// cmdInit generates a starter .ens file for a common scenario, either
+// from the -scenario flag or by prompting interactively.
+func cmdInit(args []string) {
+	fs := flag.NewFlagSet("init", flag.ExitOnError)
+	scenario := fs.String("scenario", "", "Starter scenario: secrets, http, or backup (prompts when omitted)")
+	outFile := fs.String("o", "ensura.ens", "Output file")
+	force := fs.Bool("force", false, "Overwrite the output file if it exists")
+	list := fs.Bool("list", false, "List available scenarios")
+	fs.Parse(args)
+
+	if *list {
+		for _, s := range initScenarios {
+			fmt.Printf("  %-8s %s\n", s.name, s.description)
+		}
+		return
+	}
+
+	name := *scenario
+	if name == "" {
+		name = promptScenario()
+	}
+
+	chosen := findScenario(name)
+	if chosen == nil {
+		fmt.Fprintf(os.Stderr, "Error: unknown scenario %q (available: %s)\n",
+			name, strings.Join(scenarioNames(), ", "))
+		os.Exit(exitCompile)
+	}
+
+	if !*force {
+		if _, err := os.Stat(*outFile); err == nil {
+			fmt.Fprintf(os.Stderr, "Error: %s already exists (use -force to overwrite)\n", *outFile)
+			os.Exit(exitCompile)
+		}
+	}
+
+	if err := os.WriteFile(*outFile, []byte(chosen.template), 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitInternal)
+	}
+
+	fmt.Printf("Wrote %s (%s scenario).\n", *outFile, chosen.name)
+	fmt.Printf("Edit the paths and keys for your environment, then try:\n")
+	fmt.Printf("  ensura check %s\n", *outFile)
+}
+
+// promptScenario asks the user to pick a scenario on stdin.
+func promptScenario() string {
+	fmt.Println("What should the starter config do?")
+	for i, s := range initScenarios {
+		fmt.Printf("  %d) %s - %s\n", i+1, s.name, s.description)
+	}
+	fmt.Print("Choice [1]: ")
+
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return initScenarios[0].name
+	}
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return initScenarios[0].name
+	}
+	for i, s := range initScenarios {
+		if line == fmt.Sprint(i+1) || line == s.name {
+			return s.name
+		}
+	}
+	return line
+}
+
+func findScenario(name string) *initScenario {
+	for i := range initScenarios {
+		if initScenarios[i].name == name {
+			return &initScenarios[i]
+		}
+	}
+	return nil
+}
+
+func scenarioNames() []string {
+	names := make([]string, 0, len(initScenarios))
+	for _, s := range initScenarios {
+		names = append(names, s.name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -6,41 +6,172 @@ import (
 	"encoding/json"
 	"flag"
 	"fmt"
+	"io"
+	iofs "io/fs"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/ensurascript/ensura/pkg/adapters"
+	"github.com/ensurascript/ensura/pkg/adapters/aes"
+	"github.com/ensurascript/ensura/pkg/ast"
 	"github.com/ensurascript/ensura/pkg/binder"
 	"github.com/ensurascript/ensura/pkg/graph"
 	"github.com/ensurascript/ensura/pkg/imply"
+	"github.com/ensurascript/ensura/pkg/lint"
+	"github.com/ensurascript/ensura/pkg/modules"
+	"github.com/ensurascript/ensura/pkg/output"
 	"github.com/ensurascript/ensura/pkg/parser"
 	"github.com/ensurascript/ensura/pkg/planner"
 	"github.com/ensurascript/ensura/pkg/runtime"
+	"github.com/ensurascript/ensura/pkg/state"
+	"github.com/ensurascript/ensura/pkg/stdlib"
+	enstest "github.com/ensurascript/ensura/pkg/testing"
 )
 
 const version = "1.0.0"
 
+// Exit codes form a contract scripts can rely on to tell failure classes
+// apart. The nagios check format is the one exception: it follows the
+// plugin convention (0 OK, 1 WARNING, 2 CRITICAL) instead.
+const (
+	exitOK          = 0   // guarantees satisfied / command succeeded
+	exitViolations  = 1   // guarantees violated or findings reported
+	exitCompile     = 2   // config could not be compiled, or CLI misuse
+	exitInternal    = 3   // runtime or internal error
+	exitInterrupted = 130 // stopped by SIGINT/SIGTERM
+)
+
+// conditionsFile holds the path of an optional user-defined conditions
+// document, set with the global -conditions flag.
+var conditionsFile string
+
+// injectedVars holds variables set with the global -var and -var-file
+// flags. They seed guard evaluation (as implicit assumptions) and env:
+// reference resolution, taking precedence over process env vars:
+// -var beats -var-file beats the environment.
+var injectedVars = map[string]string{}
+
 func main() {
-	if len(os.Args) < 2 {
+	args := os.Args[1:]
+
+	// Extract the global -conditions, -var, and -var-file flags so they
+	// work with any command.
+	fileVars := map[string]string{}
+	cliVars := map[string]string{}
+	consume := func(i int, name string) string {
+		if i+1 >= len(args) {
+			fmt.Fprintf(os.Stderr, "Error: %s requires an argument\n", name)
+			os.Exit(exitCompile)
+		}
+		value := args[i+1]
+		args = append(args[:i], args[i+2:]...)
+		return value
+	}
+	for i := 0; i < len(args); {
+		switch args[i] {
+		case "-conditions", "--conditions":
+			conditionsFile = consume(i, "-conditions")
+		case "-var", "--var":
+			assignment := consume(i, "-var")
+			name, value, ok := strings.Cut(assignment, "=")
+			if !ok || name == "" {
+				fmt.Fprintf(os.Stderr, "Error: -var wants name=value, got %q\n", assignment)
+				os.Exit(exitCompile)
+			}
+			cliVars[name] = value
+		case "-var-file", "--var-file":
+			path := consume(i, "-var-file")
+			if err := readVarFile(path, fileVars); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(exitCompile)
+			}
+		case "-profile", "--profile":
+			configProfile = consume(i, "-profile")
+		default:
+			i++
+		}
+	}
+
+	// Load fleet-wide defaults; subcommands overlay them under their
+	// explicit flags via applyConfigDefaults.
+	var err error
+	loadedConfig, err = loadGlobalConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitCompile)
+	}
+	if loadedConfig == nil && configProfile != "" {
+		fmt.Fprintf(os.Stderr, "Error: -profile %s given but no config file at %s\n", configProfile, globalConfigPath())
+		os.Exit(exitCompile)
+	}
+	// CLI vars win over file vars; both win over the process environment
+	// because they are pushed into it for env: resolution.
+	for name, value := range fileVars {
+		injectedVars[name] = value
+	}
+	for name, value := range cliVars {
+		injectedVars[name] = value
+	}
+	for name, value := range injectedVars {
+		os.Setenv(name, value)
+	}
+
+	if len(args) < 1 {
 		printUsage()
-		os.Exit(1)
+		os.Exit(exitCompile)
 	}
 
-	command := os.Args[1]
+	command := args[0]
+	os.Args = append(os.Args[:1], args...)
 
 	switch command {
 	case "compile":
 		cmdCompile(os.Args[2:])
+	case "validate":
+		cmdValidate(os.Args[2:])
+	case "init":
+		cmdInit(os.Args[2:])
+	case "completion":
+		cmdCompletion(os.Args[2:])
+	case "doctor":
+		cmdDoctor(os.Args[2:])
 	case "explain":
 		cmdExplain(os.Args[2:])
 	case "plan":
 		cmdPlan(os.Args[2:])
+	case "graph":
+		cmdGraph(os.Args[2:])
 	case "run":
 		cmdRun(os.Args[2:])
 	case "check":
 		cmdCheck(os.Args[2:])
+	case "lint":
+		cmdLint(os.Args[2:])
+	case "policies":
+		cmdPolicies(os.Args[2:])
+	case "get":
+		cmdGet(os.Args[2:])
+	case "test":
+		cmdTest(os.Args[2:])
+	case "diff":
+		cmdDiff(os.Args[2:])
+	case "history":
+		cmdHistory(os.Args[2:])
+	case "drift":
+		cmdDrift(os.Args[2:])
+	case "rotate":
+		cmdRotate(os.Args[2:])
+	case "encrypt":
+		cmdEncrypt(os.Args[2:])
+	case "decrypt":
+		cmdDecrypt(os.Args[2:])
+	case "serve":
+		cmdServe(os.Args[2:])
 	case "version":
 		fmt.Printf("ensura version %s\n", version)
 	case "help", "-h", "--help":
@@ -48,7 +179,7 @@ func main() {
 	default:
 		fmt.Fprintf(os.Stderr, "Unknown command: %s\n", command)
 		printUsage()
-		os.Exit(1)
+		os.Exit(exitCompile)
 	}
 }
 
@@ -60,38 +191,143 @@ Usage:
 
 Commands:
   compile   Validate and print the resolved guarantee graph
+  validate  Report all compile diagnostics as structured JSON
+  init      Generate a starter .ens file (init -scenario secrets|http|backup)
+  doctor    Verify the host can enforce a config (tools, paths, endpoints, keys)
   explain   Show implied guarantees and chosen handlers
   plan      Print the deterministic sequential execution plan
+  graph     Query the dependency graph (graph -what-depends-on 'file "x"')
   run       Run the continuous enforcement loop
   check     Check guarantees without enforcing (dry run)
+  lint      Report style and safety issues beyond hard errors
+  policies  Browse the built-in policy library (policies list, policies show <name>)
+  get       Vendor policy modules into ensura_modules/ (get <repo>//<dir>)
+  test      Run the plan against mock handlers with scripted outcomes
+  diff      Show plan differences between two config files
+  history   List past enforcement runs (history show <run-id> for details)
+  drift     Report guarantees flipping between satisfied and violated
+  rotate    Re-encrypt files with a new key (rotate -old-key ... -new-key ...)
+  encrypt   Encrypt a file in place (encrypt -key env:KEY <file>)
+  decrypt   Decrypt a file to stdout or -o (decrypt -key env:KEY <file>)
+  serve     Run the enforcement loop with an HTTP status and control API
+  completion  Generate a shell completion script (completion bash|zsh|fish|powershell)
   version   Print version information
   help      Show this help message
 
 Options:
+  -conditions file     Load user-defined conditions from a JSON file (any command)
+  -var name=value      Inject a variable for guards and env: references (any command, repeatable)
+  -var-file file       Load variables from a KEY=VALUE file (precedence: -var > -var-file > env)
+  -profile name        Select a profile from ~/.config/ensura/config.yaml (or $ENSURA_CONFIG)
   -interval duration   Interval between enforcement loops (default 30s)
   -retries int         Maximum retries per step (default 3)
   -json                Output in JSON format
   -graph               Output dependency graph in DOT format
 
+Exit codes:
+  0    All guarantees satisfied / command succeeded
+  1    Guarantees violated or findings reported
+  2    Configuration could not be compiled, or invalid usage
+  3    Runtime or internal error
+  130  Interrupted by a signal
+  (check -format nagios follows the plugin convention instead: 0/1/2)
+
 Examples:
   ensura compile config.ens
   ensura run config.ens -interval 60s
   ensura check config.ens`)
 }
 
-func loadAndCompile(filename string) (*compileResult, error) {
-	// Read source file
-	source, err := os.ReadFile(filename)
+// readVarFile loads KEY=VALUE lines from a var file into vars. Blank
+// lines and lines starting with # are skipped; later lines and later
+// files override earlier ones.
+func readVarFile(path string, vars map[string]string) error {
+	data, err := os.ReadFile(path)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read file: %w", err)
+		return fmt.Errorf("failed to read var file: %w", err)
+	}
+	for n, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		name, value, ok := strings.Cut(line, "=")
+		if !ok || name == "" {
+			return fmt.Errorf("%s:%d: want KEY=VALUE, got %q", path, n+1, line)
+		}
+		vars[strings.TrimSpace(name)] = strings.TrimSpace(value)
 	}
+	return nil
+}
 
-	// Parse
-	program, parseErrors := parser.ParseFile(string(source), filename)
-	if len(parseErrors) > 0 {
-		for _, e := range parseErrors {
-			fmt.Fprintf(os.Stderr, "Parse error: %s\n", e)
+// expandInputPaths resolves the file and directory arguments of a
+// subcommand into a deterministic list of .ens files: directories are
+// walked recursively and their files taken in sorted order, while
+// explicit files keep the order they were given in.
+func expandInputPaths(paths []string) ([]string, error) {
+	var files []string
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+		var found []string
+		err = filepath.WalkDir(path, func(p string, d iofs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.IsDir() && strings.HasSuffix(p, ".ens") {
+				found = append(found, p)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		if len(found) == 0 {
+			return nil, fmt.Errorf("no .ens files in %s", path)
 		}
+		sort.Strings(found)
+		files = append(files, found...)
+	}
+	return files, nil
+}
+
+// parseInputs parses every input file and merges the results into one
+// program, keeping statement order stable across files. Parse errors are
+// collected across all files so each file's problems are reported.
+func parseInputs(filenames []string) (*ast.Program, []string, error) {
+	merged := &ast.Program{}
+	var parseErrors []string
+	for _, filename := range filenames {
+		source, err := os.ReadFile(filename)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read file: %w", err)
+		}
+		program, errs := parser.ParseFile(string(source), filename)
+		parseErrors = append(parseErrors, errs...)
+		merged.Statements = append(merged.Statements, program.Statements...)
+	}
+	return merged, parseErrors, nil
+}
+
+func loadAndCompile(filenames ...string) (*compileResult, error) {
+	files, err := expandInputPaths(filenames)
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse all inputs into one merged program
+	program, parseErrors, err := parseInputs(files)
+	if err != nil {
+		return nil, err
+	}
+	if len(parseErrors) > 0 {
+		reportStageErrors("Parse error", parseErrors)
 		return nil, fmt.Errorf("parsing failed with %d errors", len(parseErrors))
 	}
 
@@ -99,88 +335,121 @@ func loadAndCompile(filename string) (*compileResult, error) {
 	b := binder.New()
 	program = b.Bind(program)
 	if len(b.Errors()) > 0 {
-		for _, e := range b.Errors() {
-			fmt.Fprintf(os.Stderr, "Binding error: %s\n", e)
-		}
+		reportStageErrors("Binding error", b.Errors())
 		return nil, fmt.Errorf("binding failed with %d errors", len(b.Errors()))
 	}
 
 	// Expand policies
 	program = b.ExpandPolicies(program)
 
-	// Expand implications
+	// Expand implications, including any user-defined conditions
 	expander := imply.NewExpander()
+	if len(injectedVars) > 0 {
+		expander.SetVariables(injectedVars)
+	}
+	var customConditions []*imply.ConditionMeta
+	if conditionsFile != "" {
+		loaded, err := expander.Registry().LoadConditionsFile(conditionsFile)
+		if err != nil {
+			return nil, err
+		}
+		customConditions = loaded
+	}
 	program = expander.Expand(program)
 	if len(expander.Errors()) > 0 {
-		for _, e := range expander.Errors() {
-			fmt.Fprintf(os.Stderr, "Expansion error: %s\n", e)
-		}
+		reportStageErrors("Expansion error", expander.Errors())
 		return nil, fmt.Errorf("expansion failed with %d errors", len(expander.Errors()))
 	}
 
 	// Check conflicts
 	conflicts := expander.CheckConflicts(program)
 	if len(conflicts) > 0 {
-		for _, c := range conflicts {
-			fmt.Fprintf(os.Stderr, "Conflict: %s\n", c)
-		}
+		reportStageErrors("Conflict", conflicts)
 		return nil, fmt.Errorf("found %d conflicting conditions", len(conflicts))
 	}
 
 	// Build graph
 	g := graph.Build(program)
 	if len(g.Errors()) > 0 {
-		for _, e := range g.Errors() {
-			fmt.Fprintf(os.Stderr, "Graph error: %s\n", e)
-		}
+		reportStageErrors("Graph error", g.Errors())
 		return nil, fmt.Errorf("graph building failed with %d errors", len(g.Errors()))
 	}
 
 	// Create plan
 	p := planner.New()
+	p.SetHandlerCapabilities(adapters.NewDefaultRegistry().Capabilities())
+	for _, meta := range customConditions {
+		if meta.DefaultHandler != "" {
+			p.RegisterDefaultHandler(meta.Name, meta.DefaultHandler)
+		}
+	}
 	plan, err := p.CreatePlan(g, program)
 	if err != nil {
 		return nil, fmt.Errorf("planning failed: %w", err)
 	}
+	if len(p.Errors()) > 0 {
+		reportStageErrors("Planning error", p.Errors())
+		return nil, fmt.Errorf("planning failed with %d errors", len(p.Errors()))
+	}
 
 	return &compileResult{
-		graph: g,
-		plan:  plan,
+		graph:       g,
+		plan:        plan,
+		assumptions: expander.Assumptions(),
 	}, nil
 }
 
 type compileResult struct {
-	graph *graph.Graph
-	plan  *planner.Plan
+	graph       *graph.Graph
+	plan        *planner.Plan
+	assumptions []*ast.AssumeStmt
 }
 
 func cmdCompile(args []string) {
 	fs := flag.NewFlagSet("compile", flag.ExitOnError)
 	jsonOutput := fs.Bool("json", false, "Output in JSON format")
 	graphOutput := fs.Bool("graph", false, "Output dependency graph in DOT format")
+	graphFormat := fs.String("graph-format", "", "Output dependency graph in the given format (dot, json, mermaid)")
+	format := fs.String("format", "", "Output format: github emits workflow command annotations for errors")
 	fs.Parse(args)
 
 	if fs.NArg() < 1 {
-		fmt.Fprintln(os.Stderr, "Usage: ensura compile [options] <file.ens>")
-		os.Exit(1)
+		fmt.Fprintln(os.Stderr, "Usage: ensura compile [options] <file.ens|dir>...")
+		os.Exit(exitCompile)
 	}
+	githubAnnotations = *format == "github"
 
-	result, err := loadAndCompile(fs.Arg(0))
+	result, err := loadAndCompile(fs.Args()...)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitCompile)
 	}
 
-	if *graphOutput {
+	if *graphOutput && *graphFormat == "" {
+		*graphFormat = "dot"
+	}
+	switch *graphFormat {
+	case "":
+	case "dot":
 		fmt.Println(result.graph.Visualize())
 		return
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(result.graph.ToJSON())
+		return
+	case "mermaid":
+		fmt.Println(result.graph.VisualizeMermaid())
+		return
+	default:
+		fmt.Fprintf(os.Stderr, "Error: unknown graph format %q (want dot, json, or mermaid)\n", *graphFormat)
+		os.Exit(exitCompile)
 	}
 
 	if *jsonOutput {
-		output := result.plan.ToJSON()
 		enc := json.NewEncoder(os.Stdout)
 		enc.SetIndent("", "  ")
-		enc.Encode(output)
+		enc.Encode(output.NewPlanOutput(result.plan))
 		return
 	}
 
@@ -193,17 +462,26 @@ func cmdCompile(args []string) {
 func cmdExplain(args []string) {
 	fs := flag.NewFlagSet("explain", flag.ExitOnError)
 	jsonOutput := fs.Bool("json", false, "Output in JSON format")
+	why := fs.String("why", "", "Trace one guarantee, e.g. 'encrypted on file \"secrets.db\"'")
 	fs.Parse(args)
 
 	if fs.NArg() < 1 {
-		fmt.Fprintln(os.Stderr, "Usage: ensura explain [options] <file.ens>")
-		os.Exit(1)
+		fmt.Fprintln(os.Stderr, "Usage: ensura explain [options] <file.ens|dir>...")
+		os.Exit(exitCompile)
 	}
 
-	result, err := loadAndCompile(fs.Arg(0))
+	result, err := loadAndCompile(fs.Args()...)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitCompile)
+	}
+
+	if *why != "" {
+		if !explainWhy(result, *why) {
+			fmt.Fprintf(os.Stderr, "No guarantee matches %q\n", *why)
+			os.Exit(exitViolations)
+		}
+		return
 	}
 
 	if *jsonOutput {
@@ -217,8 +495,14 @@ func cmdExplain(args []string) {
 			if step.Guarantee.Statement.Subject != nil {
 				exp["subject"] = step.Guarantee.Statement.Subject.String()
 			}
+			if doc := step.Guarantee.Statement.DocComment; doc != "" {
+				exp["doc"] = doc
+			}
 			if step.Guarantee.IsImplied {
 				exp["implied"] = true
+				if by := step.Guarantee.Statement.ImpliedBy; by != "" {
+					exp["impliedBy"] = by
+				}
 			}
 			if step.IsInvariant {
 				exp["invariant"] = true
@@ -235,6 +519,14 @@ func cmdExplain(args []string) {
 	fmt.Println("======================")
 	fmt.Println()
 
+	if len(result.assumptions) > 0 {
+		fmt.Println("Active assumptions:")
+		for _, assume := range result.assumptions {
+			fmt.Printf("  %s\n", assume.String())
+		}
+		fmt.Println()
+	}
+
 	for i, step := range result.plan.Steps {
 		marker := ""
 		if step.IsInvariant {
@@ -245,7 +537,15 @@ func cmdExplain(args []string) {
 		}
 
 		fmt.Printf("%d. %s%s\n", i+1, step.Description, marker)
+		if doc := step.Guarantee.Statement.DocComment; doc != "" {
+			for _, line := range strings.Split(doc, "\n") {
+				fmt.Printf("   # %s\n", line)
+			}
+		}
 		fmt.Printf("   Handler: %s\n", step.Handler)
+		if step.Guarantee.IsImplied && step.Guarantee.Statement.ImpliedBy != "" {
+			fmt.Printf("   Implied by: %s\n", step.Guarantee.Statement.ImpliedBy)
+		}
 		if len(step.HandlerArgs) > 0 {
 			fmt.Printf("   Arguments:\n")
 			for k, v := range step.HandlerArgs {
@@ -256,26 +556,151 @@ func cmdExplain(args []string) {
 	}
 }
 
+// explainWhy prints the full derivation of the guarantees matching a
+// selector of the form `condition` or `condition on type "path"`. It
+// reports where each came from, what implied it, which edges order it, and
+// how its handler was chosen. Returns false when nothing matches.
+func explainWhy(result *compileResult, selector string) bool {
+	condition, subjectStr, _ := strings.Cut(selector, " on ")
+	condition = strings.TrimSpace(condition)
+	subjectStr = strings.TrimSpace(subjectStr)
+
+	matched := false
+	for _, step := range result.plan.Steps {
+		stmt := step.Guarantee.Statement
+		if stmt.Condition != condition {
+			continue
+		}
+		if subjectStr != "" && (stmt.Subject == nil || stmt.Subject.String() != subjectStr) {
+			continue
+		}
+		matched = true
+
+		fmt.Printf("%s\n", step.Description)
+		fmt.Printf("  Declared at: %s\n", stmt.Position)
+		if step.Guarantee.IsImplied && stmt.ImpliedBy != "" {
+			fmt.Printf("  Origin: implied by '%s'\n", stmt.ImpliedBy)
+		} else {
+			fmt.Println("  Origin: declared explicitly")
+		}
+
+		if stmt.Handler != nil {
+			fmt.Printf("  Handler: %s (explicit)\n", step.Handler)
+		} else {
+			fmt.Printf("  Handler: %s (default for '%s')\n", step.Handler, stmt.Condition)
+		}
+		if len(step.HandlerArgs) > 0 {
+			fmt.Println("  Arguments:")
+			for k, v := range step.HandlerArgs {
+				fmt.Printf("    %s: %s\n", k, v)
+			}
+		}
+
+		for _, edge := range result.graph.Edges {
+			if edge.To == step.ID {
+				if from, ok := result.graph.Nodes[edge.From]; ok {
+					fmt.Printf("  Runs after (%s): %s\n", edge.Type, from.Statement.String())
+				}
+			}
+			if edge.From == step.ID {
+				if to, ok := result.graph.Nodes[edge.To]; ok {
+					fmt.Printf("  Runs before (%s): %s\n", edge.Type, to.Statement.String())
+				}
+			}
+		}
+		fmt.Println()
+	}
+	return matched
+}
+
+func cmdGraph(args []string) {
+	fs := flag.NewFlagSet("graph", flag.ExitOnError)
+	whatDependsOn := fs.String("what-depends-on", "", "List guarantees impacted by a resource, e.g. 'file \"secrets.db\"'")
+	dependenciesOf := fs.String("dependencies-of", "", "List what a guarantee ID transitively depends on")
+	dependentsOf := fs.String("dependents-of", "", "List what transitively depends on a guarantee ID")
+	fs.Parse(args)
+
+	if fs.NArg() < 1 {
+		fmt.Fprintln(os.Stderr, "Usage: ensura graph [options] <file.ens|dir>...")
+		os.Exit(exitCompile)
+	}
+
+	result, err := loadAndCompile(fs.Args()...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitCompile)
+	}
+	g := result.graph
+
+	printNodes := func(ids []string) {
+		for _, id := range ids {
+			if node, ok := g.Nodes[id]; ok {
+				fmt.Printf("  %s  (%s)\n", node.Statement.String(), node.Statement.Position)
+			}
+		}
+	}
+
+	switch {
+	case *whatDependsOn != "":
+		ids := g.ImpactOf(*whatDependsOn)
+		if len(ids) == 0 {
+			fmt.Printf("Nothing depends on %s\n", *whatDependsOn)
+			return
+		}
+		fmt.Printf("Impacted by %s:\n", *whatDependsOn)
+		printNodes(ids)
+
+	case *dependenciesOf != "":
+		fmt.Printf("Dependencies of %s:\n", *dependenciesOf)
+		printNodes(g.DependenciesOf(*dependenciesOf))
+
+	case *dependentsOf != "":
+		fmt.Printf("Dependents of %s:\n", *dependentsOf)
+		printNodes(g.DependentsOf(*dependentsOf))
+
+	default:
+		// With no query, list every guarantee and its ID
+		for _, step := range result.plan.Steps {
+			fmt.Printf("%s  %s\n", step.ID, step.Description)
+		}
+	}
+}
+
 func cmdPlan(args []string) {
 	fs := flag.NewFlagSet("plan", flag.ExitOnError)
 	jsonOutput := fs.Bool("json", false, "Output in JSON format")
+	outFile := fs.String("out", "", "Write a versioned plan document to this file")
 	fs.Parse(args)
 
 	if fs.NArg() < 1 {
-		fmt.Fprintln(os.Stderr, "Usage: ensura plan [options] <file.ens>")
-		os.Exit(1)
+		fmt.Fprintln(os.Stderr, "Usage: ensura plan [options] <file.ens|dir>...")
+		os.Exit(exitCompile)
 	}
 
-	result, err := loadAndCompile(fs.Arg(0))
+	result, err := loadAndCompile(fs.Args()...)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitCompile)
+	}
+
+	if *outFile != "" {
+		data, err := result.plan.MarshalDocument()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to serialize plan: %v\n", err)
+			os.Exit(exitInternal)
+		}
+		if err := os.WriteFile(*outFile, data, 0644); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to write plan file: %v\n", err)
+			os.Exit(exitInternal)
+		}
+		fmt.Printf("Plan written to %s (%d steps)\n", *outFile, len(result.plan.Steps))
+		return
 	}
 
 	if *jsonOutput {
 		enc := json.NewEncoder(os.Stdout)
 		enc.SetIndent("", "  ")
-		enc.Encode(result.plan.ToJSON())
+		enc.Encode(output.NewPlanOutput(result.plan))
 		return
 	}
 
@@ -286,32 +711,112 @@ func cmdRun(args []string) {
 	fs := flag.NewFlagSet("run", flag.ExitOnError)
 	interval := fs.Duration("interval", 30*time.Second, "Interval between enforcement loops")
 	retries := fs.Int("retries", 3, "Maximum retries per step")
-	fs.Parse(args)
+	stepTimeout := fs.Duration("step-timeout", 0, "Default timeout per step (0 = none)")
+	breaker := fs.Int("breaker", 0, "Consecutive failures before a step is suppressed (0 = disabled)")
+	breakerCooldown := fs.Duration("breaker-cooldown", runtime.DefaultBreakerCooldown, "How long a suppressed step stays skipped")
+	renotify := fs.Duration("renotify", 0, "Re-send notifications for persistent violations at most this often (0 = transitions only)")
+	mode := fs.String("mode", "interval", "Enforcement mode: interval or watch")
+	fromPlan := fs.String("from-plan", "", "Execute a previously saved plan document instead of compiling")
+	stateDir := fs.String("state-dir", state.DefaultDir(), "Directory for run history (empty to disable)")
+	metricsAddr := fs.String("metrics", "", "Address for the Prometheus /metrics listener (e.g. :9090)")
+	watchConfig := fs.Bool("watch-config", false, "Reload the config file automatically when it changes")
+	logFormat := fs.String("log-format", "text", "Log format: text or json")
+	onlyTags := fs.String("only-tags", "", "Run only steps carrying one of these comma-separated tags")
+	skipTags := fs.String("skip-tags", "", "Skip steps carrying any of these comma-separated tags")
+	var onlyPatterns, skipPatterns multiFlag
+	fs.Var(&onlyPatterns, "only", "Run only steps matching this glob (resource, condition, tag, or ID; repeatable)")
+	fs.Var(&skipPatterns, "skip", "Skip steps matching this glob (repeatable)")
+	verbose := fs.Bool("v", false, "Verbose: log at debug level")
+	quiet := fs.Bool("q", false, "Quiet: log errors only")
+	logLevel := fs.String("log-level", "", "Minimum log level: debug, info, warn, or error")
+	inputs := parseInterleaved(fs, args)
+	applyConfigDefaults(fs)
 
-	if fs.NArg() < 1 {
-		fmt.Fprintln(os.Stderr, "Usage: ensura run [options] <file.ens>")
-		os.Exit(1)
+	if *mode != "interval" && *mode != "watch" {
+		fmt.Fprintf(os.Stderr, "Error: unknown mode %q (expected interval or watch)\n", *mode)
+		os.Exit(exitCompile)
 	}
 
-	result, err := loadAndCompile(fs.Arg(0))
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+	var plan *planner.Plan
+	if *fromPlan != "" {
+		data, err := os.ReadFile(*fromPlan)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to read plan file: %v\n", err)
+			os.Exit(exitCompile)
+		}
+		plan, err = planner.LoadPlan(data)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(exitCompile)
+		}
+	} else {
+		if len(inputs) < 1 {
+			fmt.Fprintln(os.Stderr, "Usage: ensura run [options] <file.ens|dir>...")
+			os.Exit(exitCompile)
+		}
+
+		result, err := loadAndCompile(inputs...)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(exitCompile)
+		}
+		plan = result.plan
+	}
+
+	if *onlyTags != "" || *skipTags != "" {
+		plan = plan.FilterByTags(splitTags(*onlyTags), splitTags(*skipTags))
+		if len(plan.Steps) == 0 {
+			fmt.Fprintln(os.Stderr, "Error: tag filters matched no steps")
+			os.Exit(exitCompile)
+		}
+	}
+	if len(onlyPatterns) > 0 || len(skipPatterns) > 0 {
+		plan = plan.FilterByPatterns(onlyPatterns, skipPatterns)
+		if len(plan.Steps) == 0 {
+			fmt.Fprintln(os.Stderr, "Error: step filters matched no steps")
+			os.Exit(exitCompile)
+		}
 	}
 
 	// Create runtime configuration
 	config := &runtime.Config{
-		Interval:   *interval,
-		MaxRetries: *retries,
-		DryRun:     false,
-		CheckOnly:  false,
-		Redact:     true,
-		Logger:     os.Stdout,
+		Interval:         *interval,
+		MaxRetries:       *retries,
+		StepTimeout:      *stepTimeout,
+		BreakerThreshold: *breaker,
+		BreakerCooldown:  *breakerCooldown,
+		RenotifyInterval: *renotify,
+		WatchMode:        *mode == "watch",
+		DryRun:           false,
+		CheckOnly:        false,
+		Redact:           true,
+		Logger:           os.Stdout,
+		LogFormat:        *logFormat,
+		LogLevel:         resolveLogLevel(*verbose, *quiet, *logLevel),
+		MetricsAddr:      *metricsAddr,
+	}
+
+	// Record run history
+	source := *fromPlan
+	if source == "" {
+		source = strings.Join(inputs, " ")
+	}
+	if *stateDir != "" {
+		store, err := state.Open(*stateDir)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: run history disabled: %v\n", err)
+		} else {
+			config.OnResult = func(result *runtime.RunResult) {
+				if _, err := store.Record(source, result); err != nil {
+					fmt.Fprintf(os.Stderr, "Warning: failed to record run: %v\n", err)
+				}
+			}
+		}
 	}
 
 	// Create runtime with default handlers
 	registry := adapters.NewDefaultRegistry()
-	rt := runtime.New(result.plan, registry, config)
+	rt := runtime.New(plan, registry, config)
 
 	// Set up signal handling
 	ctx, cancel := context.WithCancel(context.Background())
@@ -326,74 +831,695 @@ func cmdRun(args []string) {
 		cancel()
 	}()
 
+	// Reload the config on SIGHUP (and optionally on file change), keeping
+	// the old plan if recompilation fails. Not applicable when executing a
+	// saved plan document.
+	if *fromPlan == "" {
+		configFiles := inputs
+
+		reload := func(reason string) {
+			result, err := loadAndCompile(configFiles...)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Reload failed (%s), keeping current plan: %v\n", reason, err)
+				return
+			}
+			rt.SwapPlan(result.plan)
+			fmt.Printf("Reloaded %s (%s): %d steps\n", strings.Join(configFiles, " "), reason, len(result.plan.Steps))
+		}
+
+		hupChan := make(chan os.Signal, 1)
+		signal.Notify(hupChan, syscall.SIGHUP)
+		go func() {
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-hupChan:
+					reload("SIGHUP")
+				}
+			}
+		}()
+
+		if *watchConfig {
+			go func() {
+				lastMod := latestModTime(configFiles)
+				ticker := time.NewTicker(2 * time.Second)
+				defer ticker.Stop()
+				for {
+					select {
+					case <-ctx.Done():
+						return
+					case <-ticker.C:
+						if mod := latestModTime(configFiles); mod.After(lastMod) {
+							lastMod = mod
+							reload("file changed")
+						}
+					}
+				}
+			}()
+		}
+	}
+
 	fmt.Printf("Starting enforcement loop (interval: %s, retries: %d)\n", *interval, *retries)
 	fmt.Println("Press Ctrl+C to stop")
 	fmt.Println()
 
-	if err := rt.Run(ctx); err != nil && err != context.Canceled {
+	if err := rt.Run(ctx); err != nil {
+		// A cancelled context here can only mean the signal handler fired.
+		if err == context.Canceled {
+			os.Exit(exitInterrupted)
+		}
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitInternal)
 	}
 }
 
-func cmdCheck(args []string) {
-	fs := flag.NewFlagSet("check", flag.ExitOnError)
+// latestModTime returns the newest modification time across the .ens
+// files the given paths expand to. New and deleted files in a watched
+// directory also move the result forward via the directory itself.
+func latestModTime(paths []string) time.Time {
+	var latest time.Time
+	consider := func(path string) {
+		if info, err := os.Stat(path); err == nil && info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	for _, path := range paths {
+		consider(path)
+	}
+	if files, err := expandInputPaths(paths); err == nil {
+		for _, file := range files {
+			consider(file)
+		}
+	}
+	return latest
+}
+
+// resolveLogLevel maps the -v, -q, and -log-level flags onto a runtime
+// log level. An explicit -log-level name wins over the shorthands.
+func resolveLogLevel(verbose, quiet bool, name string) runtime.LogLevel {
+	if name != "" {
+		level, err := runtime.ParseLogLevel(name)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(exitCompile)
+		}
+		return level
+	}
+	if quiet {
+		return runtime.LogError
+	}
+	if verbose {
+		return runtime.LogDebug
+	}
+	return runtime.LogDefault
+}
+
+// parseInterleaved parses flags that may appear before and after the
+// positional arguments (ensura check config.ens -only ...), which the
+// standard flag package alone does not allow. It returns the positional
+// arguments in order.
+func parseInterleaved(fs *flag.FlagSet, args []string) []string {
+	var positional []string
+	rest := args
+	for len(rest) > 0 {
+		fs.Parse(rest)
+		rest = fs.Args()
+		if len(rest) > 0 {
+			positional = append(positional, rest[0])
+			rest = rest[1:]
+		}
+	}
+	return positional
+}
+
+// multiFlag collects the values of a repeatable string flag.
+type multiFlag []string
+
+func (m *multiFlag) String() string { return strings.Join(*m, ", ") }
+
+func (m *multiFlag) Set(value string) error {
+	*m = append(*m, value)
+	return nil
+}
+
+// splitTags splits a comma-separated tag list, trimming whitespace and
+// dropping empty entries.
+func splitTags(s string) []string {
+	var tags []string
+	for _, tag := range strings.Split(s, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+func cmdRotate(args []string) {
+	fs := flag.NewFlagSet("rotate", flag.ExitOnError)
+	oldKey := fs.String("old-key", "", "Key reference the files are currently encrypted with")
+	newKey := fs.String("new-key", "", "Key reference to re-encrypt with")
+	keyID := fs.String("key-id", "", "Key ID to embed in the rotated files")
+	fs.Parse(args)
+
+	if *oldKey == "" || *newKey == "" || fs.NArg() < 1 {
+		fmt.Fprintln(os.Stderr, "Usage: ensura rotate -old-key <ref> -new-key <ref> [-key-id <id>] <file>...")
+		os.Exit(exitCompile)
+	}
+
+	failed := false
+	for _, path := range fs.Args() {
+		if err := aes.Rotate(path, *oldKey, *newKey, *keyID); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s: %v\n", path, err)
+			failed = true
+			continue
+		}
+		fmt.Printf("Rotated %s\n", path)
+	}
+	if failed {
+		os.Exit(exitInternal)
+	}
+}
+
+func cmdEncrypt(args []string) {
+	fs := flag.NewFlagSet("encrypt", flag.ExitOnError)
+	key := fs.String("key", "", "Key reference (env:NAME, file:/path, vault:..., or literal)")
+	keyID := fs.String("key-id", "", "Key ID to embed in the file header")
+	fs.Parse(args)
+
+	if *key == "" || fs.NArg() < 1 {
+		fmt.Fprintln(os.Stderr, "Usage: ensura encrypt -key <ref> [-key-id <id>] <file>...")
+		os.Exit(exitCompile)
+	}
+
+	failed := false
+	for _, path := range fs.Args() {
+		if err := aes.EncryptFile(path, *key, *keyID); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s: %v\n", path, err)
+			failed = true
+			continue
+		}
+		fmt.Printf("Encrypted %s\n", path)
+	}
+	if failed {
+		os.Exit(exitInternal)
+	}
+}
+
+func cmdDecrypt(args []string) {
+	fs := flag.NewFlagSet("decrypt", flag.ExitOnError)
+	key := fs.String("key", "", "Key reference (env:NAME, file:/path, vault:..., or literal)")
+	output := fs.String("o", "", "Write plaintext to this file instead of stdout")
+	fs.Parse(args)
+
+	if *key == "" || fs.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "Usage: ensura decrypt -key <ref> [-o <out>] <file>")
+		os.Exit(exitCompile)
+	}
+
+	plaintext, err := aes.DecryptFile(fs.Arg(0), *key)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitInternal)
+	}
+
+	if *output == "" {
+		os.Stdout.Write(plaintext)
+		return
+	}
+	if err := os.WriteFile(*output, plaintext, 0600); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitInternal)
+	}
+	fmt.Printf("Decrypted %s to %s\n", fs.Arg(0), *output)
+}
+
+func cmdLint(args []string) {
+	fs := flag.NewFlagSet("lint", flag.ExitOnError)
 	jsonOutput := fs.Bool("json", false, "Output in JSON format")
+	enable := fs.String("enable", "", "Comma-separated list of rules to enable (default: all)")
+	disable := fs.String("disable", "", "Comma-separated list of rules to disable")
 	fs.Parse(args)
 
 	if fs.NArg() < 1 {
-		fmt.Fprintln(os.Stderr, "Usage: ensura check [options] <file.ens>")
-		os.Exit(1)
+		fmt.Fprintln(os.Stderr, "Usage: ensura lint [options] <file.ens|dir>...")
+		os.Exit(exitCompile)
 	}
 
-	result, err := loadAndCompile(fs.Arg(0))
+	files, err := expandInputPaths(fs.Args())
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitCompile)
 	}
 
-	// Create runtime configuration for check-only
-	config := &runtime.Config{
-		DryRun:    true,
-		CheckOnly: true,
-		Redact:    true,
-		Logger:    os.Stdout,
+	program, parseErrors, err := parseInputs(files)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitCompile)
+	}
+	if len(parseErrors) > 0 {
+		for _, e := range parseErrors {
+			fmt.Fprintf(os.Stderr, "Parse error: %s\n", e)
+		}
+		os.Exit(exitCompile)
 	}
 
-	// Create runtime with default handlers
-	registry := adapters.NewDefaultRegistry()
-	rt := runtime.New(result.plan, registry, config)
+	l := lint.New()
+	if *enable != "" {
+		for _, rule := range lint.AllRules {
+			l.Disable(rule)
+		}
+		for _, rule := range strings.Split(*enable, ",") {
+			l.Enable(strings.TrimSpace(rule))
+		}
+	}
+	if *disable != "" {
+		for _, rule := range strings.Split(*disable, ",") {
+			l.Disable(strings.TrimSpace(rule))
+		}
+	}
 
-	ctx := context.Background()
-	runResult := rt.Check(ctx)
+	issues := l.Lint(program)
+
+	if *jsonOutput {
+		output := make([]map[string]interface{}, len(issues))
+		for i, issue := range issues {
+			output[i] = map[string]interface{}{
+				"rule":     issue.Rule,
+				"position": issue.Position.String(),
+				"message":  issue.Message,
+			}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(output)
+	} else {
+		for _, issue := range issues {
+			fmt.Println(issue.String())
+		}
+		if len(issues) == 0 {
+			fmt.Println("No lint issues found.")
+		}
+	}
+
+	if len(issues) > 0 {
+		os.Exit(exitViolations)
+	}
+}
+
+func cmdPolicies(args []string) {
+	sub := "list"
+	if len(args) > 0 {
+		sub = args[0]
+	}
+
+	switch sub {
+	case "list":
+		for _, name := range stdlib.Names() {
+			fmt.Printf("std.%-22s %s\n", name, stdlib.Summary(name))
+		}
+	case "show":
+		if len(args) < 2 {
+			fmt.Fprintln(os.Stderr, "Usage: ensura policies show <name>")
+			os.Exit(exitCompile)
+		}
+		name := strings.TrimPrefix(args[1], "std.")
+		src, ok := stdlib.Source(name)
+		if !ok {
+			fmt.Fprintf(os.Stderr, "Unknown built-in policy: %s\n", args[1])
+			os.Exit(exitCompile)
+		}
+		fmt.Println(src)
+	default:
+		fmt.Fprintln(os.Stderr, "Usage: ensura policies list | show <name>")
+		os.Exit(exitCompile)
+	}
+}
+
+func cmdGet(args []string) {
+	if len(args) < 1 {
+		fmt.Fprintln(os.Stderr, "Usage: ensura get <module-reference>...")
+		os.Exit(exitCompile)
+	}
+
+	for _, ref := range args {
+		dir, err := modules.Vendor(ref)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(exitInternal)
+		}
+		sum, err := modules.Checksum(dir)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(exitInternal)
+		}
+		fmt.Printf("Vendored %s into %s\n", ref, dir)
+		fmt.Printf("  sha256 %s\n", sum)
+	}
+}
+
+func cmdHistory(args []string) {
+	fs := flag.NewFlagSet("history", flag.ExitOnError)
+	stateDir := fs.String("state-dir", state.DefaultDir(), "Directory containing run history")
+	jsonOutput := fs.Bool("json", false, "Output in JSON format")
+	fs.Parse(args)
+
+	store, err := state.Open(*stateDir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitInternal)
+	}
+
+	// history show <run-id>
+	if fs.NArg() >= 2 && fs.Arg(0) == "show" {
+		record, err := store.Get(fs.Arg(1))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(exitInternal)
+		}
+
+		if *jsonOutput {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			enc.Encode(record)
+			return
+		}
+
+		fmt.Printf("Run %s\n", record.ID)
+		if record.Source != "" {
+			fmt.Printf("  Source: %s\n", record.Source)
+		}
+		fmt.Printf("  Started: %s\n", record.StartTime.Format(time.RFC3339))
+		fmt.Printf("  Duration: %s\n", record.EndTime.Sub(record.StartTime))
+		fmt.Printf("  Checks: %d, Repairs: %d, Failures: %d\n",
+			record.TotalChecks, record.TotalRepairs, record.TotalFailures)
+		fmt.Println("  Steps:")
+		for _, step := range record.Steps {
+			fmt.Printf("    [%s] %s\n", step.Status, step.Description)
+			if step.Message != "" {
+				fmt.Printf("        %s\n", step.Message)
+			}
+			if step.Error != "" {
+				fmt.Printf("        Error: %s\n", step.Error)
+			}
+		}
+		return
+	}
+
+	records, err := store.List()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitInternal)
+	}
+
+	if *jsonOutput {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(records)
+		return
+	}
+
+	if len(records) == 0 {
+		fmt.Println("No runs recorded.")
+		return
+	}
+
+	for _, record := range records {
+		status := "OK"
+		if !record.AllSatisfied {
+			status = "FAIL"
+		}
+		fmt.Printf("%s  %-4s  checks=%d repairs=%d failures=%d  %s\n",
+			record.ID, status, record.TotalChecks, record.TotalRepairs,
+			record.TotalFailures, record.Source)
+	}
+}
+
+func cmdDrift(args []string) {
+	fs := flag.NewFlagSet("drift", flag.ExitOnError)
+	stateDir := fs.String("state-dir", state.DefaultDir(), "Directory containing run history")
+	last := fs.Int("last", 0, "Only analyze the most recent N runs (0 = all)")
+	jsonOutput := fs.Bool("json", false, "Output in JSON format")
+	fs.Parse(args)
+
+	store, err := state.Open(*stateDir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitInternal)
+	}
+
+	records, err := store.List()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitInternal)
+	}
+	if *last > 0 && len(records) > *last {
+		records = records[:*last]
+	}
+
+	report := state.Drift(records)
+
+	if *jsonOutput {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(report)
+		return
+	}
+
+	fmt.Print(report.String())
+}
+
+func cmdDiff(args []string) {
+	fs := flag.NewFlagSet("diff", flag.ExitOnError)
+	jsonOutput := fs.Bool("json", false, "Output in JSON format")
+	fs.Parse(args)
+
+	if fs.NArg() < 2 {
+		fmt.Fprintln(os.Stderr, "Usage: ensura diff [options] <old.ens> <new.ens>")
+		os.Exit(exitCompile)
+	}
+
+	oldResult, err := loadAndCompile(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error compiling %s: %v\n", fs.Arg(0), err)
+		os.Exit(exitCompile)
+	}
+
+	newResult, err := loadAndCompile(fs.Arg(1))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error compiling %s: %v\n", fs.Arg(1), err)
+		os.Exit(exitCompile)
+	}
+
+	diff := oldResult.plan.Diff(newResult.plan)
+
+	if *jsonOutput {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(diff.ToJSON())
+	} else {
+		fmt.Print(diff.String())
+	}
+
+	if !diff.Empty() {
+		os.Exit(exitViolations)
+	}
+}
+
+// testScenario describes scripted handler outcomes for ensura test.
+type testScenario struct {
+	Default  *testOutcome  `json:"default,omitempty"`
+	Outcomes []testOutcome `json:"outcomes,omitempty"`
+}
+
+type testOutcome struct {
+	Condition string `json:"condition,omitempty"`
+	Path      string `json:"path,omitempty"`
+	Check     bool   `json:"check"`
+	Enforce   bool   `json:"enforce"`
+}
+
+func cmdTest(args []string) {
+	fs := flag.NewFlagSet("test", flag.ExitOnError)
+	scenarioFile := fs.String("scenario", "", "JSON file with scripted check/enforce outcomes")
+	jsonOutput := fs.Bool("json", false, "Output in JSON format")
+	fs.Parse(args)
+
+	if fs.NArg() < 1 {
+		fmt.Fprintln(os.Stderr, "Usage: ensura test [options] <file.ens|dir>...")
+		os.Exit(exitCompile)
+	}
+
+	result, err := loadAndCompile(fs.Args()...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitCompile)
+	}
+
+	var scenario testScenario
+	if *scenarioFile != "" {
+		data, err := os.ReadFile(*scenarioFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to read scenario: %v\n", err)
+			os.Exit(exitCompile)
+		}
+		if err := json.Unmarshal(data, &scenario); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: invalid scenario file: %v\n", err)
+			os.Exit(exitCompile)
+		}
+	}
+
+	// Create one mock per handler referenced by the plan.
+	mocks := make(map[string]*enstest.MockHandler)
+	registry := runtime.NewHandlerRegistry()
+	for _, step := range result.plan.Steps {
+		if _, ok := mocks[step.Handler]; !ok {
+			mock := enstest.NewMockHandler(step.Handler)
+			if scenario.Default != nil {
+				mock.SetDefault(enstest.Outcome{
+					CheckSuccess:   scenario.Default.Check,
+					EnforceSuccess: scenario.Default.Enforce,
+				})
+			}
+			for _, o := range scenario.Outcomes {
+				mock.Script(o.Condition, o.Path, enstest.Outcome{
+					CheckSuccess:   o.Check,
+					EnforceSuccess: o.Enforce,
+				})
+			}
+			mocks[step.Handler] = mock
+			registry.Register(mock)
+		}
+	}
+
+	config := &runtime.Config{
+		MaxRetries: 1,
+		Redact:     true,
+		Logger:     os.Stdout,
+	}
+	rt := runtime.New(result.plan, registry, config)
+	runResult := rt.RunOnce(context.Background())
 
 	if *jsonOutput {
 		output := map[string]interface{}{
 			"allSatisfied":  runResult.AllSatisfied,
 			"totalChecks":   runResult.TotalChecks,
+			"totalRepairs":  runResult.TotalRepairs,
 			"totalFailures": runResult.TotalFailures,
-			"duration":      runResult.EndTime.Sub(runResult.StartTime).String(),
 			"steps":         make([]map[string]interface{}, len(runResult.Steps)),
 		}
 		for i, step := range runResult.Steps {
-			stepOutput := map[string]interface{}{
+			output["steps"].([]map[string]interface{})[i] = map[string]interface{}{
 				"description": step.Step.Description,
 				"status":      step.Status.String(),
 			}
-			if step.Message != "" {
-				stepOutput["message"] = step.Message
-			}
-			if step.Error != nil {
-				stepOutput["error"] = step.Error.Error()
-			}
-			output["steps"].([]map[string]interface{})[i] = stepOutput
 		}
 		enc := json.NewEncoder(os.Stdout)
 		enc.SetIndent("", "  ")
 		enc.Encode(output)
+	} else {
+		fmt.Println("Test Results")
+		fmt.Println("============")
+		for _, step := range runResult.Steps {
+			fmt.Printf("  [%s] %s\n", step.Status, step.Step.Description)
+		}
+		fmt.Printf("\nChecks: %d, Repairs: %d, Failures: %d\n",
+			runResult.TotalChecks, runResult.TotalRepairs, runResult.TotalFailures)
 	}
 
 	if !runResult.AllSatisfied {
-		os.Exit(1)
+		os.Exit(exitViolations)
+	}
+}
+
+func cmdCheck(args []string) {
+	fs := flag.NewFlagSet("check", flag.ExitOnError)
+	jsonOutput := fs.Bool("json", false, "Output in JSON format")
+	format := fs.String("format", "", "Output format: json, junit, sarif, github, or nagios")
+	var onlyPatterns, skipPatterns multiFlag
+	fs.Var(&onlyPatterns, "only", "Check only steps matching this glob (resource, condition, tag, or ID; repeatable)")
+	fs.Var(&skipPatterns, "skip", "Skip steps matching this glob (repeatable)")
+	verbose := fs.Bool("v", false, "Verbose: log at debug level")
+	quiet := fs.Bool("q", false, "Quiet: log errors only")
+	logLevel := fs.String("log-level", "", "Minimum log level: debug, info, warn, or error")
+	inputs := parseInterleaved(fs, args)
+	applyConfigDefaults(fs)
+
+	if len(inputs) < 1 {
+		fmt.Fprintln(os.Stderr, "Usage: ensura check [options] <file.ens|dir>...")
+		os.Exit(exitCompile)
+	}
+	if *jsonOutput && *format == "" {
+		*format = "json"
+	}
+	switch *format {
+	case "", "json", "junit", "sarif", "github", "nagios":
+	default:
+		fmt.Fprintf(os.Stderr, "Error: unknown format %q (want json, junit, sarif, github, or nagios)\n", *format)
+		os.Exit(exitCompile)
+	}
+	githubAnnotations = *format == "github"
+
+	result, err := loadAndCompile(inputs...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitCompile)
+	}
+
+	plan := result.plan
+	if len(onlyPatterns) > 0 || len(skipPatterns) > 0 {
+		plan = plan.FilterByPatterns(onlyPatterns, skipPatterns)
+		if len(plan.Steps) == 0 {
+			fmt.Fprintln(os.Stderr, "Error: step filters matched no steps")
+			os.Exit(exitCompile)
+		}
+	}
+
+	// Create runtime configuration for check-only; machine-readable
+	// formats own stdout, so step logging is suppressed for them
+	config := &runtime.Config{
+		DryRun:    true,
+		CheckOnly: true,
+		Redact:    true,
+		Logger:    os.Stdout,
+		LogLevel:  resolveLogLevel(*verbose, *quiet, *logLevel),
+	}
+	if *format != "" {
+		config.Logger = io.Discard
+	}
+
+	// Create runtime with default handlers
+	registry := adapters.NewDefaultRegistry()
+	rt := runtime.New(plan, registry, config)
+
+	ctx := context.Background()
+	runResult := rt.Check(ctx)
+
+	switch *format {
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(output.NewCheckOutput(runResult))
+	case "junit":
+		if err := writeJUnit(os.Stdout, runResult); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(exitInternal)
+		}
+	case "sarif":
+		if err := writeSARIF(os.Stdout, runResult, inputs[0]); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(exitInternal)
+		}
+	case "github":
+		writeGitHubAnnotations(os.Stdout, runResult)
+	case "nagios":
+		// Nagios owns the exit code contract: 0 OK, 1 WARNING, 2 CRITICAL.
+		os.Exit(writeNagios(os.Stdout, runResult))
+	}
+
+	// Warning- and info-level violations are reported but do not fail the
+	// check; only high and critical ones affect the exit status.
+	if runResult.BlockingFailures() > 0 {
+		os.Exit(exitViolations)
 	}
 }
@@ -0,0 +1,274 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/ensurascript/ensura/pkg/adapters"
+	"github.com/ensurascript/ensura/pkg/output"
+	"github.com/ensurascript/ensura/pkg/planner"
+	"github.com/ensurascript/ensura/pkg/runtime"
+)
+
+// daemon runs the enforcement loop and serves the local control API.
+type daemon struct {
+	filenames []string
+	registry  *runtime.HandlerRegistry
+	config    *runtime.Config
+
+	mu         sync.Mutex
+	plan       *planner.Plan
+	rt         *runtime.Runtime
+	lastResult *runtime.RunResult
+
+	trigger chan chan *runtime.RunResult
+}
+
+func newDaemon(filenames []string, plan *planner.Plan, config *runtime.Config) *daemon {
+	registry := adapters.NewDefaultRegistry()
+	return &daemon{
+		filenames: filenames,
+		registry:  registry,
+		config:    config,
+		plan:      plan,
+		rt:        runtime.New(plan, registry, config),
+		trigger:   make(chan chan *runtime.RunResult),
+	}
+}
+
+// loop runs enforcement passes on the configured interval, and immediately
+// when triggered via the control API.
+func (d *daemon) loop(ctx context.Context) {
+	d.runOnce(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case reply := <-d.trigger:
+			// Triggered pass: run now and send the result back.
+			reply <- d.runOnce(ctx)
+		case <-time.After(d.config.Interval):
+			d.runOnce(ctx)
+		}
+	}
+}
+
+func (d *daemon) runOnce(ctx context.Context) *runtime.RunResult {
+	d.mu.Lock()
+	rt := d.rt
+	d.mu.Unlock()
+
+	result := rt.RunOnce(ctx)
+
+	d.mu.Lock()
+	d.lastResult = result
+	d.mu.Unlock()
+
+	return result
+}
+
+// reload recompiles the config files and swaps in the new plan, keeping the
+// old plan if compilation fails.
+func (d *daemon) reload() error {
+	result, err := loadAndCompile(d.filenames...)
+	if err != nil {
+		return err
+	}
+
+	d.mu.Lock()
+	d.plan = result.plan
+	d.rt = runtime.New(result.plan, d.registry, d.config)
+	d.mu.Unlock()
+
+	return nil
+}
+
+func runResultJSON(result *runtime.RunResult) map[string]interface{} {
+	output := map[string]interface{}{
+		"allSatisfied":    result.AllSatisfied,
+		"totalChecks":     result.TotalChecks,
+		"totalRepairs":    result.TotalRepairs,
+		"totalFailures":   result.TotalFailures,
+		"totalSuppressed": result.TotalSuppressed,
+		"startTime":       result.StartTime.Format(time.RFC3339),
+		"endTime":         result.EndTime.Format(time.RFC3339),
+		"steps":           make([]map[string]interface{}, len(result.Steps)),
+	}
+	for i, step := range result.Steps {
+		stepOutput := map[string]interface{}{
+			"description": step.Step.Description,
+			"status":      step.Status.String(),
+		}
+		if step.Message != "" {
+			stepOutput["message"] = step.Message
+		}
+		if step.Error != nil {
+			stepOutput["error"] = step.Error.Error()
+		}
+		output["steps"].([]map[string]interface{})[i] = stepOutput
+	}
+	return output
+}
+
+func (d *daemon) handler() http.Handler {
+	mux := http.NewServeMux()
+
+	writeJSON := func(w http.ResponseWriter, status int, v interface{}) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		enc.Encode(v)
+	}
+
+	mux.HandleFunc("/status", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		d.mu.Lock()
+		result := d.lastResult
+		d.mu.Unlock()
+
+		if result == nil {
+			writeJSON(w, http.StatusOK, map[string]interface{}{"status": "no runs yet"})
+			return
+		}
+		writeJSON(w, http.StatusOK, runResultJSON(result))
+	})
+
+	mux.HandleFunc("/run", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		reply := make(chan *runtime.RunResult, 1)
+		select {
+		case d.trigger <- reply:
+			writeJSON(w, http.StatusOK, runResultJSON(<-reply))
+		case <-r.Context().Done():
+		}
+	})
+
+	mux.HandleFunc("/reload", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if err := d.reload(); err != nil {
+			writeJSON(w, http.StatusUnprocessableEntity, map[string]interface{}{
+				"error": err.Error(),
+			})
+			return
+		}
+		d.mu.Lock()
+		steps := len(d.plan.Steps)
+		d.mu.Unlock()
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"reloaded": true,
+			"steps":    steps,
+		})
+	})
+
+	mux.HandleFunc("/plan", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		d.mu.Lock()
+		plan := d.plan
+		d.mu.Unlock()
+		writeJSON(w, http.StatusOK, output.NewPlanOutput(plan))
+	})
+
+	return mux
+}
+
+func cmdServe(args []string) {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := fs.String("addr", "127.0.0.1:7777", "Address for the status and control API")
+	interval := fs.Duration("interval", 30*time.Second, "Interval between enforcement loops")
+	retries := fs.Int("retries", 3, "Maximum retries per step")
+	breaker := fs.Int("breaker", 0, "Consecutive failures before a step is suppressed (0 = disabled)")
+	breakerCooldown := fs.Duration("breaker-cooldown", runtime.DefaultBreakerCooldown, "How long a suppressed step stays skipped")
+	renotify := fs.Duration("renotify", 0, "Re-send notifications for persistent violations at most this often (0 = transitions only)")
+	metricsAddr := fs.String("metrics", "", "Address for the Prometheus /metrics listener (e.g. :9090)")
+	verbose := fs.Bool("v", false, "Verbose: log at debug level")
+	quiet := fs.Bool("q", false, "Quiet: log errors only")
+	logLevel := fs.String("log-level", "", "Minimum log level: debug, info, warn, or error")
+	fs.Parse(args)
+	applyConfigDefaults(fs)
+
+	if fs.NArg() < 1 {
+		fmt.Fprintln(os.Stderr, "Usage: ensura serve [options] <file.ens|dir>...")
+		os.Exit(exitCompile)
+	}
+
+	result, err := loadAndCompile(fs.Args()...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitCompile)
+	}
+
+	config := &runtime.Config{
+		Interval:         *interval,
+		MaxRetries:       *retries,
+		BreakerThreshold: *breaker,
+		BreakerCooldown:  *breakerCooldown,
+		RenotifyInterval: *renotify,
+		Redact:           true,
+		Logger:           os.Stdout,
+		LogLevel:         resolveLogLevel(*verbose, *quiet, *logLevel),
+		MetricsAddr:      *metricsAddr,
+	}
+
+	d := newDaemon(fs.Args(), result.plan, config)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// These flags decide the exit code once the loop stops; the writes are
+	// ordered before cancel(), which the loop observes before returning.
+	var interrupted, listenerFailed bool
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-sigChan
+		fmt.Println("\nReceived shutdown signal, stopping...")
+		interrupted = true
+		cancel()
+	}()
+
+	server := &http.Server{Addr: *addr, Handler: d.handler()}
+	go func() {
+		<-ctx.Done()
+		server.Close()
+	}()
+	go func() {
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			fmt.Fprintf(os.Stderr, "Error: API listener failed: %v\n", err)
+			listenerFailed = true
+			cancel()
+		}
+	}()
+
+	fmt.Printf("Serving control API on http://%s (interval: %s)\n", *addr, *interval)
+	d.loop(ctx)
+
+	if interrupted {
+		os.Exit(exitInterrupted)
+	}
+	if listenerFailed {
+		os.Exit(exitInternal)
+	}
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/ensurascript/ensura/pkg/ast"
+	"github.com/ensurascript/ensura/pkg/binder"
+	"github.com/ensurascript/ensura/pkg/graph"
+	"github.com/ensurascript/ensura/pkg/imply"
+	"github.com/ensurascript/ensura/pkg/lexer"
+	"github.com/ensurascript/ensura/pkg/parser"
+)
+
+// validateDiagnostic is one finding in the validate report.
+type validateDiagnostic struct {
+	Stage     string `json:"stage"`
+	File      string `json:"file,omitempty"`
+	Line      int    `json:"line,omitempty"`
+	Column    int    `json:"column,omitempty"`
+	EndLine   int    `json:"endLine,omitempty"`
+	EndColumn int    `json:"endColumn,omitempty"`
+	Message   string `json:"message"`
+}
+
+// validateReport is the machine-readable output of ensura validate.
+type validateReport struct {
+	Valid       bool                 `json:"valid"`
+	Diagnostics []validateDiagnostic `json:"diagnostics"`
+}
+
+// cmdValidate runs parse, bind, imply, conflict, and graph checks
+// without planning or execution, and reports every stage's findings
+// rather than stopping at the first failing stage.
+func cmdValidate(args []string) {
+	fs := flag.NewFlagSet("validate", flag.ExitOnError)
+	jsonOutput := fs.Bool("json", true, "Output in JSON format")
+	fs.Parse(args)
+
+	if fs.NArg() < 1 {
+		fmt.Fprintln(os.Stderr, "Usage: ensura validate [options] <file.ens|dir>...")
+		os.Exit(exitCompile)
+	}
+
+	files, err := expandInputPaths(fs.Args())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitCompile)
+	}
+
+	report, err := validateFiles(files)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitCompile)
+	}
+
+	if *jsonOutput {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(report)
+	} else {
+		for _, d := range report.Diagnostics {
+			if d.Line > 0 {
+				fmt.Printf("%s:%d:%d: %s: %s\n", d.File, d.Line, d.Column, d.Stage, d.Message)
+			} else {
+				fmt.Printf("%s: %s\n", d.Stage, d.Message)
+			}
+		}
+		if report.Valid {
+			fmt.Println("No problems found.")
+		}
+	}
+
+	if !report.Valid {
+		os.Exit(exitCompile)
+	}
+}
+
+// validateFiles runs every compile stage over the merged program and
+// collects the diagnostics of all of them, tagged with their source file.
+func validateFiles(filenames []string) (validateReport, error) {
+	var diagnostics []validateDiagnostic
+	collect := func(stage string, diags []ast.Diag) {
+		for _, d := range diags {
+			diagnostics = append(diagnostics, validateDiagnostic{
+				Stage:     stage,
+				File:      d.Pos.Filename,
+				Line:      d.Pos.Line,
+				Column:    d.Pos.Column,
+				EndLine:   d.End.Line,
+				EndColumn: d.End.Column,
+				Message:   d.Msg,
+			})
+		}
+	}
+
+	program := &ast.Program{}
+	for _, filename := range filenames {
+		source, err := os.ReadFile(filename)
+		if err != nil {
+			return validateReport{}, err
+		}
+		l := lexer.NewWithFilename(string(source), filename)
+		p := parser.New(l)
+		parsed := p.Parse()
+		collect("parse", p.Diagnostics())
+		program.Statements = append(program.Statements, parsed.Statements...)
+	}
+
+	b := binder.New()
+	program = b.Bind(program)
+	collect("bind", b.Diagnostics())
+	program = b.ExpandPolicies(program)
+
+	expander := imply.NewExpander()
+	if len(injectedVars) > 0 {
+		expander.SetVariables(injectedVars)
+	}
+	if conditionsFile != "" {
+		if _, err := expander.Registry().LoadConditionsFile(conditionsFile); err != nil {
+			diagnostics = append(diagnostics, validateDiagnostic{
+				Stage:   "imply",
+				Message: err.Error(),
+			})
+		}
+	}
+	program = expander.Expand(program)
+	collect("imply", expander.Diagnostics())
+	before := len(expander.Diagnostics())
+	expander.CheckConflicts(program)
+	collect("conflict", expander.Diagnostics()[before:])
+
+	g := graph.Build(program)
+	collect("graph", g.Diagnostics())
+	if _, err := g.TopoSort(); err != nil {
+		diagnostics = append(diagnostics, validateDiagnostic{
+			Stage:   "graph",
+			Message: err.Error(),
+		})
+	}
+
+	return validateReport{
+		Valid:       len(diagnostics) == 0,
+		Diagnostics: diagnosticsOrEmpty(diagnostics),
+	}, nil
+}
+
+func diagnosticsOrEmpty(diags []validateDiagnostic) []validateDiagnostic {
+	if diags == nil {
+		return []validateDiagnostic{}
+	}
+	return diags
+}
@@ -0,0 +1,328 @@
+// Package account provides the user and group handler for EnsuraScript.
+//
+// It backs account guarantees on `user` and `group` resources:
+//
+//	ensure exists on user "app"
+//	ensure uid on user "app" with account.native value "1000"
+//	ensure member_of on user "app" with account.native group "docker"
+//	ensure shell on user "app" with account.native value "/bin/bash"
+//
+// Enforcement shells out to useradd/usermod/groupadd/groupmod, which only
+// works when running with sufficient privileges.
+package account
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/user"
+	"strings"
+
+	"github.com/ensurascript/ensura/pkg/ast"
+	pkgruntime "github.com/ensurascript/ensura/pkg/runtime"
+)
+
+// Handler implements user and group account checks.
+type Handler struct{}
+
+// New creates a new account handler.
+func New() *Handler {
+	return &Handler{}
+}
+
+// Name returns the handler name.
+func (h *Handler) Name() string {
+	return "account.native"
+}
+
+// SupportedConditions lists the conditions this handler understands.
+func (h *Handler) SupportedConditions() []string {
+	return []string{"exists", "uid", "gid", "member_of", "shell", "home"}
+}
+
+// Check verifies an account condition.
+func (h *Handler) Check(ctx context.Context, subject *ast.ResourceRef, condition string, args map[string]string) pkgruntime.HandlerResult {
+	if subject == nil || subject.Path == "" {
+		return pkgruntime.HandlerResult{
+			Success: false,
+			Error:   fmt.Errorf("no subject specified"),
+		}
+	}
+
+	name := subject.Path
+	isGroup := subject.ResourceType == "group"
+
+	switch condition {
+	case "exists":
+		if isGroup {
+			if _, err := user.LookupGroup(name); err != nil {
+				return pkgruntime.HandlerResult{
+					Success: false,
+					Message: fmt.Sprintf("group %s does not exist", name),
+				}
+			}
+			return pkgruntime.HandlerResult{
+				Success: true,
+				Message: fmt.Sprintf("group %s exists", name),
+			}
+		}
+		if _, err := user.Lookup(name); err != nil {
+			return pkgruntime.HandlerResult{
+				Success: false,
+				Message: fmt.Sprintf("user %s does not exist", name),
+			}
+		}
+		return pkgruntime.HandlerResult{
+			Success: true,
+			Message: fmt.Sprintf("user %s exists", name),
+		}
+
+	case "uid":
+		want := args["value"]
+		if want == "" {
+			return pkgruntime.HandlerResult{
+				Success: false,
+				Error:   fmt.Errorf("value argument required for uid"),
+			}
+		}
+		u, err := user.Lookup(name)
+		if err != nil {
+			return pkgruntime.HandlerResult{
+				Success: false,
+				Message: fmt.Sprintf("user %s does not exist", name),
+			}
+		}
+		if u.Uid != want {
+			return pkgruntime.HandlerResult{
+				Success: false,
+				Message: fmt.Sprintf("user %s has uid %s, expected %s", name, u.Uid, want),
+			}
+		}
+		return pkgruntime.HandlerResult{
+			Success: true,
+			Message: fmt.Sprintf("user %s has uid %s", name, want),
+		}
+
+	case "gid":
+		want := args["value"]
+		if want == "" {
+			return pkgruntime.HandlerResult{
+				Success: false,
+				Error:   fmt.Errorf("value argument required for gid"),
+			}
+		}
+		var gid string
+		if isGroup {
+			g, err := user.LookupGroup(name)
+			if err != nil {
+				return pkgruntime.HandlerResult{
+					Success: false,
+					Message: fmt.Sprintf("group %s does not exist", name),
+				}
+			}
+			gid = g.Gid
+		} else {
+			u, err := user.Lookup(name)
+			if err != nil {
+				return pkgruntime.HandlerResult{
+					Success: false,
+					Message: fmt.Sprintf("user %s does not exist", name),
+				}
+			}
+			gid = u.Gid
+		}
+		if gid != want {
+			return pkgruntime.HandlerResult{
+				Success: false,
+				Message: fmt.Sprintf("%s %s has gid %s, expected %s", subject.ResourceType, name, gid, want),
+			}
+		}
+		return pkgruntime.HandlerResult{
+			Success: true,
+			Message: fmt.Sprintf("%s %s has gid %s", subject.ResourceType, name, want),
+		}
+
+	case "member_of":
+		group := args["group"]
+		if group == "" {
+			return pkgruntime.HandlerResult{
+				Success: false,
+				Error:   fmt.Errorf("group argument required for member_of"),
+			}
+		}
+		member, err := h.isMember(name, group)
+		if err != nil {
+			return pkgruntime.HandlerResult{Success: false, Error: err}
+		}
+		if !member {
+			return pkgruntime.HandlerResult{
+				Success: false,
+				Message: fmt.Sprintf("user %s is not a member of %s", name, group),
+			}
+		}
+		return pkgruntime.HandlerResult{
+			Success: true,
+			Message: fmt.Sprintf("user %s is a member of %s", name, group),
+		}
+
+	case "shell":
+		want := args["value"]
+		if want == "" {
+			return pkgruntime.HandlerResult{
+				Success: false,
+				Error:   fmt.Errorf("value argument required for shell"),
+			}
+		}
+		shell, err := loginShell(name)
+		if err != nil {
+			return pkgruntime.HandlerResult{Success: false, Error: err}
+		}
+		if shell != want {
+			return pkgruntime.HandlerResult{
+				Success: false,
+				Message: fmt.Sprintf("user %s has shell %s, expected %s", name, shell, want),
+			}
+		}
+		return pkgruntime.HandlerResult{
+			Success: true,
+			Message: fmt.Sprintf("user %s has shell %s", name, want),
+		}
+
+	case "home":
+		want := args["value"]
+		if want == "" {
+			return pkgruntime.HandlerResult{
+				Success: false,
+				Error:   fmt.Errorf("value argument required for home"),
+			}
+		}
+		u, err := user.Lookup(name)
+		if err != nil {
+			return pkgruntime.HandlerResult{
+				Success: false,
+				Message: fmt.Sprintf("user %s does not exist", name),
+			}
+		}
+		if u.HomeDir != want {
+			return pkgruntime.HandlerResult{
+				Success: false,
+				Message: fmt.Sprintf("user %s has home %s, expected %s", name, u.HomeDir, want),
+			}
+		}
+		return pkgruntime.HandlerResult{
+			Success: true,
+			Message: fmt.Sprintf("user %s has home %s", name, want),
+		}
+
+	default:
+		return pkgruntime.HandlerResult{
+			Success: false,
+			Error:   fmt.Errorf("unknown condition: %s", condition),
+		}
+	}
+}
+
+// Enforce repairs an account condition via useradd/usermod/groupadd/groupmod.
+func (h *Handler) Enforce(ctx context.Context, subject *ast.ResourceRef, condition string, args map[string]string) pkgruntime.HandlerResult {
+	if subject == nil || subject.Path == "" {
+		return pkgruntime.HandlerResult{
+			Success: false,
+			Error:   fmt.Errorf("no subject specified"),
+		}
+	}
+
+	name := subject.Path
+	isGroup := subject.ResourceType == "group"
+
+	var cmdline []string
+	switch condition {
+	case "exists":
+		if isGroup {
+			cmdline = []string{"groupadd", name}
+		} else {
+			cmdline = []string{"useradd", name}
+		}
+	case "uid":
+		cmdline = []string{"usermod", "-u", args["value"], name}
+	case "gid":
+		if isGroup {
+			cmdline = []string{"groupmod", "-g", args["value"], name}
+		} else {
+			cmdline = []string{"usermod", "-g", args["value"], name}
+		}
+	case "member_of":
+		cmdline = []string{"usermod", "-aG", args["group"], name}
+	case "shell":
+		cmdline = []string{"usermod", "-s", args["value"], name}
+	case "home":
+		cmdline = []string{"usermod", "-d", args["value"], name}
+	default:
+		return pkgruntime.HandlerResult{
+			Success: false,
+			Error:   fmt.Errorf("cannot enforce condition: %s", condition),
+		}
+	}
+
+	cmd := exec.CommandContext(ctx, cmdline[0], cmdline[1:]...)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return pkgruntime.HandlerResult{
+			Success: false,
+			Message: strings.TrimSpace(string(output)),
+			Error:   fmt.Errorf("%s failed: %w", cmdline[0], err),
+		}
+	}
+
+	return pkgruntime.HandlerResult{
+		Success: true,
+		Message: fmt.Sprintf("enforced %s on %s %s", condition, subject.ResourceType, name),
+	}
+}
+
+// isMember reports whether the user belongs to the named group, either as
+// primary group or supplementary member.
+func (h *Handler) isMember(username, groupname string) (bool, error) {
+	u, err := user.Lookup(username)
+	if err != nil {
+		return false, fmt.Errorf("user %s does not exist", username)
+	}
+	g, err := user.LookupGroup(groupname)
+	if err != nil {
+		return false, fmt.Errorf("group %s does not exist", groupname)
+	}
+
+	if u.Gid == g.Gid {
+		return true, nil
+	}
+
+	gids, err := u.GroupIds()
+	if err != nil {
+		return false, fmt.Errorf("failed to list groups for %s: %w", username, err)
+	}
+	for _, gid := range gids {
+		if gid == g.Gid {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// loginShell reads the user's login shell from /etc/passwd, which os/user
+// does not expose.
+func loginShell(username string) (string, error) {
+	f, err := os.Open("/etc/passwd")
+	if err != nil {
+		return "", fmt.Errorf("failed to read /etc/passwd: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Split(scanner.Text(), ":")
+		if len(fields) >= 7 && fields[0] == username {
+			return fields[6], nil
+		}
+	}
+	return "", fmt.Errorf("user %s not found in /etc/passwd", username)
+}
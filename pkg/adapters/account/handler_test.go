@@ -0,0 +1,92 @@
+package account
+
+import (
+	"context"
+	"os/user"
+	"testing"
+
+	"github.com/ensurascript/ensura/pkg/ast"
+)
+
+func TestCheckUserExists(t *testing.T) {
+	current, err := user.Current()
+	if err != nil {
+		t.Skipf("cannot determine current user: %v", err)
+	}
+
+	h := New()
+	ctx := context.Background()
+
+	subject := &ast.ResourceRef{ResourceType: "user", Path: current.Username}
+	result := h.Check(ctx, subject, "exists", nil)
+	if !result.Success {
+		t.Errorf("Expected exists check to succeed for current user: %s", result.Message)
+	}
+
+	subject = &ast.ResourceRef{ResourceType: "user", Path: "ensura-no-such-user"}
+	result = h.Check(ctx, subject, "exists", nil)
+	if result.Success {
+		t.Error("Expected exists check to fail for nonexistent user")
+	}
+}
+
+func TestCheckUid(t *testing.T) {
+	current, err := user.Current()
+	if err != nil {
+		t.Skipf("cannot determine current user: %v", err)
+	}
+
+	h := New()
+	ctx := context.Background()
+
+	subject := &ast.ResourceRef{ResourceType: "user", Path: current.Username}
+	result := h.Check(ctx, subject, "uid", map[string]string{"value": current.Uid})
+	if !result.Success {
+		t.Errorf("Expected uid check to succeed: %s", result.Message)
+	}
+
+	result = h.Check(ctx, subject, "uid", map[string]string{"value": "99999"})
+	if result.Success {
+		t.Error("Expected uid check to fail for wrong uid")
+	}
+}
+
+func TestCheckMemberOf(t *testing.T) {
+	current, err := user.Current()
+	if err != nil {
+		t.Skipf("cannot determine current user: %v", err)
+	}
+	group, err := user.LookupGroupId(current.Gid)
+	if err != nil {
+		t.Skipf("cannot resolve primary group: %v", err)
+	}
+
+	h := New()
+	ctx := context.Background()
+
+	subject := &ast.ResourceRef{ResourceType: "user", Path: current.Username}
+	result := h.Check(ctx, subject, "member_of", map[string]string{"group": group.Name})
+	if !result.Success {
+		t.Errorf("Expected member_of check to succeed for primary group: %s", result.Message)
+	}
+}
+
+func TestCheckErrors(t *testing.T) {
+	h := New()
+	ctx := context.Background()
+
+	result := h.Check(ctx, nil, "exists", nil)
+	if result.Error == nil {
+		t.Error("Expected error for missing subject")
+	}
+
+	subject := &ast.ResourceRef{ResourceType: "user", Path: "root"}
+	result = h.Check(ctx, subject, "bogus", nil)
+	if result.Error == nil {
+		t.Error("Expected error for unknown condition")
+	}
+	result = h.Check(ctx, subject, "uid", nil)
+	if result.Error == nil {
+		t.Error("Expected error for uid without value")
+	}
+}
@@ -11,15 +11,21 @@ import (
 	"fmt"
 	"io"
 	"os"
-	"strings"
 
 	"github.com/ensurascript/ensura/pkg/ast"
+	"github.com/ensurascript/ensura/pkg/atomicfile"
 	"github.com/ensurascript/ensura/pkg/runtime"
+	"github.com/ensurascript/ensura/pkg/secrets"
 )
 
 // MagicHeader identifies encrypted files.
 var MagicHeader = []byte("ENSURA_AES256_V1")
 
+// MagicHeaderV2 identifies encrypted files that embed a key ID, so checks
+// can detect files still encrypted with a retired key. The header is
+// followed by a single length byte and the key ID itself.
+var MagicHeaderV2 = []byte("ENSURA_AES256_V2")
+
 // Handler implements AES-256 encryption operations.
 type Handler struct{}
 
@@ -33,6 +39,11 @@ func (h *Handler) Name() string {
 	return "AES:256"
 }
 
+// SupportedConditions lists the conditions this handler understands.
+func (h *Handler) SupportedConditions() []string {
+	return []string{"encrypted", "rotate_key"}
+}
+
 // Check verifies encryption status.
 func (h *Handler) Check(ctx context.Context, subject *ast.ResourceRef, condition string, args map[string]string) runtime.HandlerResult {
 	if subject == nil {
@@ -46,7 +57,9 @@ func (h *Handler) Check(ctx context.Context, subject *ast.ResourceRef, condition
 
 	switch condition {
 	case "encrypted":
-		return h.checkEncrypted(path)
+		return h.checkEncrypted(path, args["key_id"])
+	case "rotate_key":
+		return h.checkEncrypted(path, args["key_id"])
 	default:
 		return runtime.HandlerResult{
 			Success: false,
@@ -68,7 +81,9 @@ func (h *Handler) Enforce(ctx context.Context, subject *ast.ResourceRef, conditi
 
 	switch condition {
 	case "encrypted":
-		return h.enforceEncrypted(path, args["key"])
+		return h.enforceEncrypted(path, args["key"], args["key_id"], args["backup"] == "true")
+	case "rotate_key":
+		return h.enforceRotateKey(path, args)
 	default:
 		return runtime.HandlerResult{
 			Success: false,
@@ -77,7 +92,7 @@ func (h *Handler) Enforce(ctx context.Context, subject *ast.ResourceRef, conditi
 	}
 }
 
-func (h *Handler) checkEncrypted(path string) runtime.HandlerResult {
+func (h *Handler) checkEncrypted(path, expectedKeyID string) runtime.HandlerResult {
 	f, err := os.Open(path)
 	if err != nil {
 		return runtime.HandlerResult{
@@ -87,9 +102,9 @@ func (h *Handler) checkEncrypted(path string) runtime.HandlerResult {
 	}
 	defer f.Close()
 
-	// Check for magic header
-	header := make([]byte, len(MagicHeader))
-	n, err := f.Read(header)
+	// Read enough for the longest header plus key ID
+	prefix := make([]byte, len(MagicHeaderV2)+1+255)
+	n, err := f.Read(prefix)
 	if err != nil && err != io.EOF {
 		return runtime.HandlerResult{
 			Success: false,
@@ -97,20 +112,59 @@ func (h *Handler) checkEncrypted(path string) runtime.HandlerResult {
 		}
 	}
 
-	if n == len(MagicHeader) && bytes.Equal(header, MagicHeader) {
+	keyID, _, encrypted := parseHeader(prefix[:n])
+	if !encrypted {
 		return runtime.HandlerResult{
-			Success: true,
-			Message: fmt.Sprintf("%s is encrypted", path),
+			Success: false,
+			Message: fmt.Sprintf("%s is not encrypted", path),
+		}
+	}
+
+	if expectedKeyID != "" && keyID != expectedKeyID {
+		if keyID == "" {
+			return runtime.HandlerResult{
+				Success: false,
+				Message: fmt.Sprintf("%s is encrypted without a key ID, expected key %s", path, expectedKeyID),
+			}
+		}
+		return runtime.HandlerResult{
+			Success: false,
+			Message: fmt.Sprintf("%s is encrypted with retired key %s, expected %s", path, keyID, expectedKeyID),
 		}
 	}
 
 	return runtime.HandlerResult{
-		Success: false,
-		Message: fmt.Sprintf("%s is not encrypted", path),
+		Success: true,
+		Message: fmt.Sprintf("%s is encrypted", path),
 	}
 }
 
-func (h *Handler) enforceEncrypted(path, keyRef string) runtime.HandlerResult {
+// enforceRotateKey re-encrypts the file with a new key. The old_key arg
+// decrypts the current content; key and key_id describe the replacement.
+func (h *Handler) enforceRotateKey(path string, args map[string]string) runtime.HandlerResult {
+	oldKeyRef := args["old_key"]
+	newKeyRef := args["key"]
+	if oldKeyRef == "" || newKeyRef == "" {
+		return runtime.HandlerResult{
+			Success: false,
+			Error:   fmt.Errorf("rotate_key requires old_key and key arguments"),
+		}
+	}
+
+	if err := Rotate(path, oldKeyRef, newKeyRef, args["key_id"]); err != nil {
+		return runtime.HandlerResult{
+			Success: false,
+			Error:   err,
+		}
+	}
+
+	return runtime.HandlerResult{
+		Success: true,
+		Message: fmt.Sprintf("rotated encryption key for %s", path),
+	}
+}
+
+func (h *Handler) enforceEncrypted(path, keyRef, keyID string, backup bool) runtime.HandlerResult {
 	// Get the encryption key
 	key, err := resolveKey(keyRef)
 	if err != nil {
@@ -130,7 +184,7 @@ func (h *Handler) enforceEncrypted(path, keyRef string) runtime.HandlerResult {
 	}
 
 	// Check if already encrypted
-	if len(data) >= len(MagicHeader) && bytes.Equal(data[:len(MagicHeader)], MagicHeader) {
+	if _, _, encrypted := parseHeader(data); encrypted {
 		return runtime.HandlerResult{
 			Success: true,
 			Message: fmt.Sprintf("%s is already encrypted", path),
@@ -146,19 +200,13 @@ func (h *Handler) enforceEncrypted(path, keyRef string) runtime.HandlerResult {
 		}
 	}
 
-	// Write back with magic header
-	output := append(MagicHeader, encrypted...)
+	output := encodeHeader(keyID, encrypted)
 
-	// Get original file permissions
-	info, err := os.Stat(path)
-	if err != nil {
-		return runtime.HandlerResult{
-			Success: false,
-			Error:   err,
-		}
+	if backup {
+		err = atomicfile.WriteWithBackup(path, output, 0600)
+	} else {
+		err = atomicfile.Write(path, output, 0600)
 	}
-
-	err = os.WriteFile(path, output, info.Mode())
 	if err != nil {
 		return runtime.HandlerResult{
 			Success: false,
@@ -172,37 +220,134 @@ func (h *Handler) enforceEncrypted(path, keyRef string) runtime.HandlerResult {
 	}
 }
 
+// parseHeader recognizes both encrypted file formats. For V2 files the
+// embedded key ID is returned; payload is the ciphertext after the header.
+func parseHeader(data []byte) (keyID string, payload []byte, encrypted bool) {
+	if len(data) >= len(MagicHeaderV2) && bytes.Equal(data[:len(MagicHeaderV2)], MagicHeaderV2) {
+		rest := data[len(MagicHeaderV2):]
+		if len(rest) < 1 {
+			return "", nil, false
+		}
+		idLen := int(rest[0])
+		if len(rest) < 1+idLen {
+			return "", nil, false
+		}
+		return string(rest[1 : 1+idLen]), rest[1+idLen:], true
+	}
+	if len(data) >= len(MagicHeader) && bytes.Equal(data[:len(MagicHeader)], MagicHeader) {
+		return "", data[len(MagicHeader):], true
+	}
+	return "", nil, false
+}
+
+// encodeHeader prepends the appropriate header: V2 with the key ID when one
+// is given, the legacy V1 header otherwise.
+func encodeHeader(keyID string, ciphertext []byte) []byte {
+	if keyID == "" {
+		return append(append([]byte{}, MagicHeader...), ciphertext...)
+	}
+	if len(keyID) > 255 {
+		keyID = keyID[:255]
+	}
+	out := append([]byte{}, MagicHeaderV2...)
+	out = append(out, byte(len(keyID)))
+	out = append(out, keyID...)
+	return append(out, ciphertext...)
+}
+
+// EncryptFile encrypts a plaintext file in place with the resolved key,
+// embedding keyID in the header when one is given. Already-encrypted files
+// are left untouched.
+func EncryptFile(path, keyRef, keyID string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	if _, _, encrypted := parseHeader(data); encrypted {
+		return fmt.Errorf("%s is already encrypted", path)
+	}
+
+	key, err := resolveKey(keyRef)
+	if err != nil {
+		return err
+	}
+	ciphertext, err := encrypt(data, key)
+	if err != nil {
+		return err
+	}
+
+	return atomicfile.Write(path, encodeHeader(keyID, ciphertext), 0600)
+}
+
+// DecryptFile decrypts an encrypted file with the resolved key and returns
+// the plaintext without modifying the file.
+func DecryptFile(path, keyRef string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	_, ciphertext, encrypted := parseHeader(data)
+	if !encrypted {
+		return nil, fmt.Errorf("%s is not encrypted", path)
+	}
+
+	key, err := resolveKey(keyRef)
+	if err != nil {
+		return nil, err
+	}
+	return Decrypt(ciphertext, key)
+}
+
+// Rotate decrypts the file with the old key reference and re-encrypts it
+// with the new one, embedding keyID in the header. The file is replaced
+// atomically with its mode preserved.
+func Rotate(path, oldKeyRef, newKeyRef, keyID string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	_, ciphertext, encrypted := parseHeader(data)
+	if !encrypted {
+		return fmt.Errorf("%s is not encrypted", path)
+	}
+
+	oldKey, err := resolveKey(oldKeyRef)
+	if err != nil {
+		return fmt.Errorf("failed to resolve old key: %w", err)
+	}
+	plaintext, err := Decrypt(ciphertext, oldKey)
+	if err != nil {
+		return fmt.Errorf("decryption with old key failed: %w", err)
+	}
+
+	newKey, err := resolveKey(newKeyRef)
+	if err != nil {
+		return fmt.Errorf("failed to resolve new key: %w", err)
+	}
+	reencrypted, err := encrypt(plaintext, newKey)
+	if err != nil {
+		return fmt.Errorf("re-encryption failed: %w", err)
+	}
+
+	return atomicfile.Write(path, encodeHeader(keyID, reencrypted), 0600)
+}
+
+// resolveKey resolves the key reference (env:, file:, vault:, aws:, gcp:,
+// or a literal) and hashes the material to the 32 bytes AES-256 needs.
 func resolveKey(keyRef string) ([]byte, error) {
 	if keyRef == "" {
 		return nil, fmt.Errorf("key reference is empty")
 	}
 
-	// Handle env: prefix
-	if strings.HasPrefix(keyRef, "env:") {
-		envVar := strings.TrimPrefix(keyRef, "env:")
-		value := os.Getenv(envVar)
-		if value == "" {
-			return nil, fmt.Errorf("environment variable %s is not set", envVar)
-		}
-		// Hash the key to ensure it's 32 bytes for AES-256
-		hash := sha256.Sum256([]byte(value))
-		return hash[:], nil
-	}
-
-	// Handle file: prefix
-	if strings.HasPrefix(keyRef, "file:") {
-		filePath := strings.TrimPrefix(keyRef, "file:")
-		data, err := os.ReadFile(filePath)
-		if err != nil {
-			return nil, fmt.Errorf("failed to read key file: %w", err)
-		}
-		// Hash the key to ensure it's 32 bytes for AES-256
-		hash := sha256.Sum256(data)
-		return hash[:], nil
+	material, err := secrets.NewResolver().Resolve(keyRef)
+	if err != nil {
+		return nil, err
 	}
 
-	// Use the key directly (hash it to ensure correct length)
-	hash := sha256.Sum256([]byte(keyRef))
+	hash := sha256.Sum256(material)
 	return hash[:], nil
 }
 
@@ -172,3 +172,108 @@ func TestAlreadyEncrypted(t *testing.T) {
 		t.Error("Expected success for already encrypted file")
 	}
 }
+
+func TestRotateKey(t *testing.T) {
+	h := New()
+	ctx := context.Background()
+
+	tmpDir := t.TempDir()
+	secretFile := filepath.Join(tmpDir, "secret.txt")
+	if err := os.WriteFile(secretFile, []byte("confidential"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	subject := &ast.ResourceRef{Path: secretFile, ResourceType: "file"}
+
+	// Encrypt with the old key and its ID
+	result := h.Enforce(ctx, subject, "encrypted", map[string]string{"key": "old-passphrase", "key_id": "key-1"})
+	if !result.Success {
+		t.Fatalf("Expected enforce to succeed: %v", result.Error)
+	}
+
+	// Current key ID passes, a newer expected ID reports a violation
+	result = h.Check(ctx, subject, "encrypted", map[string]string{"key_id": "key-1"})
+	if !result.Success {
+		t.Errorf("Expected check with matching key id to pass: %s", result.Message)
+	}
+	result = h.Check(ctx, subject, "encrypted", map[string]string{"key_id": "key-2"})
+	if result.Success {
+		t.Error("Expected check with newer key id to fail")
+	}
+
+	// Rotate to the new key
+	args := map[string]string{"old_key": "old-passphrase", "key": "new-passphrase", "key_id": "key-2"}
+	result = h.Enforce(ctx, subject, "rotate_key", args)
+	if !result.Success {
+		t.Fatalf("Expected rotation to succeed: %v", result.Error)
+	}
+
+	result = h.Check(ctx, subject, "encrypted", map[string]string{"key_id": "key-2"})
+	if !result.Success {
+		t.Errorf("Expected check after rotation to pass: %s", result.Message)
+	}
+
+	// Content round-trips through the new key
+	data, err := os.ReadFile(secretFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyID, ciphertext, encrypted := parseHeader(data)
+	if !encrypted || keyID != "key-2" {
+		t.Fatalf("Expected V2 header with key-2, got %q (encrypted=%v)", keyID, encrypted)
+	}
+	newKey, err := resolveKey("new-passphrase")
+	if err != nil {
+		t.Fatal(err)
+	}
+	plaintext, err := Decrypt(ciphertext, newKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(plaintext) != "confidential" {
+		t.Errorf("Expected original content after rotation, got %q", plaintext)
+	}
+
+	// Mode preserved
+	info, err := os.Stat(secretFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("Expected mode 0600 to be preserved, got %o", info.Mode().Perm())
+	}
+
+	// Rotating with the wrong old key fails
+	if err := Rotate(secretFile, "wrong", "other", "key-3"); err == nil {
+		t.Error("Expected rotation with wrong old key to fail")
+	}
+}
+
+func TestEncryptDecryptFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	secretFile := filepath.Join(tmpDir, "secret.txt")
+	if err := os.WriteFile(secretFile, []byte("round trip"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := EncryptFile(secretFile, "passphrase", "key-1"); err != nil {
+		t.Fatalf("EncryptFile failed: %v", err)
+	}
+
+	// Double encryption is refused
+	if err := EncryptFile(secretFile, "passphrase", "key-1"); err == nil {
+		t.Error("Expected error encrypting an already-encrypted file")
+	}
+
+	plaintext, err := DecryptFile(secretFile, "passphrase")
+	if err != nil {
+		t.Fatalf("DecryptFile failed: %v", err)
+	}
+	if string(plaintext) != "round trip" {
+		t.Errorf("Expected original content, got %q", plaintext)
+	}
+
+	if _, err := DecryptFile(secretFile, "wrong"); err == nil {
+		t.Error("Expected error decrypting with the wrong key")
+	}
+}
@@ -0,0 +1,151 @@
+// Package age provides age-based encryption handling for EnsuraScript.
+package age
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/ensurascript/ensura/pkg/ast"
+	"github.com/ensurascript/ensura/pkg/runtime"
+)
+
+// MagicHeader is the format line every age file starts with.
+var MagicHeader = []byte("age-encryption.org/v1")
+
+// Handler implements the encrypted condition using the age tool.
+type Handler struct{}
+
+// New creates a new age handler.
+func New() *Handler {
+	return &Handler{}
+}
+
+// Name returns the handler name.
+func (h *Handler) Name() string {
+	return "age"
+}
+
+// SupportedConditions lists the conditions this handler understands.
+func (h *Handler) SupportedConditions() []string {
+	return []string{"encrypted"}
+}
+
+// Check verifies the file is age-encrypted by its magic header.
+func (h *Handler) Check(ctx context.Context, subject *ast.ResourceRef, condition string, args map[string]string) runtime.HandlerResult {
+	if subject == nil {
+		return runtime.HandlerResult{
+			Success: false,
+			Error:   fmt.Errorf("no subject specified"),
+		}
+	}
+
+	if condition != "encrypted" {
+		return runtime.HandlerResult{
+			Success: false,
+			Error:   fmt.Errorf("unknown condition: %s", condition),
+		}
+	}
+
+	f, err := os.Open(subject.Path)
+	if err != nil {
+		return runtime.HandlerResult{
+			Success: false,
+			Error:   err,
+		}
+	}
+	defer f.Close()
+
+	header := make([]byte, len(MagicHeader))
+	n, _ := f.Read(header)
+	if n == len(MagicHeader) && bytes.Equal(header, MagicHeader) {
+		return runtime.HandlerResult{
+			Success: true,
+			Message: fmt.Sprintf("%s is age-encrypted", subject.Path),
+		}
+	}
+
+	return runtime.HandlerResult{
+		Success: false,
+		Message: fmt.Sprintf("%s is not age-encrypted", subject.Path),
+	}
+}
+
+// Enforce encrypts the file in place with the age tool. The recipients arg
+// holds one or more age public keys, comma separated.
+func (h *Handler) Enforce(ctx context.Context, subject *ast.ResourceRef, condition string, args map[string]string) runtime.HandlerResult {
+	if subject == nil {
+		return runtime.HandlerResult{
+			Success: false,
+			Error:   fmt.Errorf("no subject specified"),
+		}
+	}
+
+	if condition != "encrypted" {
+		return runtime.HandlerResult{
+			Success: false,
+			Error:   fmt.Errorf("cannot enforce condition: %s", condition),
+		}
+	}
+
+	if result := h.Check(ctx, subject, condition, args); result.Success {
+		return runtime.HandlerResult{
+			Success: true,
+			Message: fmt.Sprintf("%s is already encrypted", subject.Path),
+		}
+	}
+
+	recipients := args["recipients"]
+	if recipients == "" {
+		return runtime.HandlerResult{
+			Success: false,
+			Error:   fmt.Errorf("recipients argument required"),
+		}
+	}
+
+	if _, err := exec.LookPath("age"); err != nil {
+		return runtime.HandlerResult{
+			Success: false,
+			Error:   fmt.Errorf("age tool not found in PATH"),
+		}
+	}
+
+	path := subject.Path
+	tmp := filepath.Join(filepath.Dir(path), "."+filepath.Base(path)+".age")
+	cmdArgs := []string{}
+	for _, recipient := range strings.Split(recipients, ",") {
+		cmdArgs = append(cmdArgs, "-r", strings.TrimSpace(recipient))
+	}
+	cmdArgs = append(cmdArgs, "-o", tmp, path)
+
+	cmd := exec.CommandContext(ctx, "age", cmdArgs...)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		os.Remove(tmp)
+		return runtime.HandlerResult{
+			Success: false,
+			Message: strings.TrimSpace(string(output)),
+			Error:   fmt.Errorf("age encryption failed: %w", err),
+		}
+	}
+
+	if info, err := os.Stat(path); err == nil {
+		os.Chmod(tmp, info.Mode().Perm())
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return runtime.HandlerResult{
+			Success: false,
+			Error:   err,
+		}
+	}
+
+	return runtime.HandlerResult{
+		Success: true,
+		Message: fmt.Sprintf("encrypted %s with age", path),
+	}
+}
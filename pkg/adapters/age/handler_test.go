@@ -0,0 +1,54 @@
+package age
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ensurascript/ensura/pkg/ast"
+)
+
+func TestCheckEncrypted(t *testing.T) {
+	h := New()
+	ctx := context.Background()
+
+	tmpDir := t.TempDir()
+	ageFile := filepath.Join(tmpDir, "secret.age")
+	if err := os.WriteFile(ageFile, []byte("age-encryption.org/v1\n-> X25519 ...\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	subject := &ast.ResourceRef{Path: ageFile, ResourceType: "file"}
+	result := h.Check(ctx, subject, "encrypted", nil)
+	if !result.Success {
+		t.Errorf("Expected age file to pass check: %s", result.Message)
+	}
+
+	plainFile := filepath.Join(tmpDir, "plain.txt")
+	if err := os.WriteFile(plainFile, []byte("plain text"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	subject = &ast.ResourceRef{Path: plainFile, ResourceType: "file"}
+	result = h.Check(ctx, subject, "encrypted", nil)
+	if result.Success {
+		t.Error("Expected plain file to fail check")
+	}
+}
+
+func TestEnforceRequiresRecipients(t *testing.T) {
+	h := New()
+	ctx := context.Background()
+
+	tmpDir := t.TempDir()
+	plainFile := filepath.Join(tmpDir, "plain.txt")
+	if err := os.WriteFile(plainFile, []byte("plain text"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	subject := &ast.ResourceRef{Path: plainFile, ResourceType: "file"}
+	result := h.Enforce(ctx, subject, "encrypted", nil)
+	if result.Error == nil {
+		t.Error("Expected error for missing recipients argument")
+	}
+}
@@ -0,0 +1,219 @@
+// Package cert provides the certificate file handler for EnsuraScript.
+package cert
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/ensurascript/ensura/pkg/ast"
+	"github.com/ensurascript/ensura/pkg/runtime"
+)
+
+// Handler inspects on-disk PEM certificates.
+type Handler struct{}
+
+// New creates a new certificate handler.
+func New() *Handler {
+	return &Handler{}
+}
+
+// Name returns the handler name.
+func (h *Handler) Name() string {
+	return "cert.native"
+}
+
+// SupportedConditions lists the conditions this handler understands.
+func (h *Handler) SupportedConditions() []string {
+	return []string{"valid", "expires_after", "issued_by", "san_contains"}
+}
+
+// Check verifies a certificate condition.
+func (h *Handler) Check(ctx context.Context, subject *ast.ResourceRef, condition string, args map[string]string) runtime.HandlerResult {
+	if subject == nil {
+		return runtime.HandlerResult{
+			Success: false,
+			Error:   fmt.Errorf("no subject specified"),
+		}
+	}
+
+	cert, err := loadCertificate(subject.Path)
+	if err != nil {
+		return runtime.HandlerResult{
+			Success: false,
+			Error:   err,
+		}
+	}
+
+	switch condition {
+	case "valid":
+		return h.checkValid(subject.Path, cert)
+	case "expires_after":
+		return h.checkExpiresAfter(subject.Path, cert, args["duration"])
+	case "issued_by":
+		return h.checkIssuedBy(subject.Path, cert, args["issuer"])
+	case "san_contains":
+		return h.checkSANContains(subject.Path, cert, args["name"])
+	default:
+		return runtime.HandlerResult{
+			Success: false,
+			Error:   fmt.Errorf("unknown condition: %s", condition),
+		}
+	}
+}
+
+// Enforce runs the renewal command supplied via the renew argument. The
+// handler cannot mint certificates itself; renewal is delegated to tooling
+// like certbot or an internal CA client.
+func (h *Handler) Enforce(ctx context.Context, subject *ast.ResourceRef, condition string, args map[string]string) runtime.HandlerResult {
+	if subject == nil {
+		return runtime.HandlerResult{
+			Success: false,
+			Error:   fmt.Errorf("no subject specified"),
+		}
+	}
+
+	command := args["renew"]
+	if command == "" {
+		return runtime.HandlerResult{
+			Success: false,
+			Error:   fmt.Errorf("cannot enforce %s: no renew command specified", condition),
+		}
+	}
+
+	cmd := exec.CommandContext(ctx, "/bin/sh", "-c", command)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return runtime.HandlerResult{
+			Success: false,
+			Message: strings.TrimSpace(string(output)),
+			Error:   fmt.Errorf("renew command failed: %w", err),
+		}
+	}
+
+	return h.Check(ctx, subject, condition, args)
+}
+
+func (h *Handler) checkValid(path string, cert *x509.Certificate) runtime.HandlerResult {
+	now := time.Now()
+	if now.Before(cert.NotBefore) {
+		return runtime.HandlerResult{
+			Success: false,
+			Message: fmt.Sprintf("%s is not valid until %s", path, cert.NotBefore.Format(time.RFC3339)),
+		}
+	}
+	if now.After(cert.NotAfter) {
+		return runtime.HandlerResult{
+			Success: false,
+			Message: fmt.Sprintf("%s expired at %s", path, cert.NotAfter.Format(time.RFC3339)),
+		}
+	}
+	return runtime.HandlerResult{
+		Success: true,
+		Message: fmt.Sprintf("%s is valid until %s", path, cert.NotAfter.Format(time.RFC3339)),
+	}
+}
+
+func (h *Handler) checkExpiresAfter(path string, cert *x509.Certificate, duration string) runtime.HandlerResult {
+	if duration == "" {
+		return runtime.HandlerResult{
+			Success: false,
+			Error:   fmt.Errorf("duration argument not specified"),
+		}
+	}
+	minRemaining, err := time.ParseDuration(duration)
+	if err != nil {
+		return runtime.HandlerResult{
+			Success: false,
+			Error:   fmt.Errorf("invalid duration %q: %w", duration, err),
+		}
+	}
+
+	remaining := time.Until(cert.NotAfter)
+	if remaining > minRemaining {
+		return runtime.HandlerResult{
+			Success: true,
+			Message: fmt.Sprintf("%s expires in %s", path, remaining.Round(time.Hour)),
+		}
+	}
+	return runtime.HandlerResult{
+		Success: false,
+		Message: fmt.Sprintf("%s expires in %s, expected more than %s", path, remaining.Round(time.Hour), minRemaining),
+	}
+}
+
+func (h *Handler) checkIssuedBy(path string, cert *x509.Certificate, issuer string) runtime.HandlerResult {
+	if issuer == "" {
+		return runtime.HandlerResult{
+			Success: false,
+			Error:   fmt.Errorf("issuer argument not specified"),
+		}
+	}
+
+	if cert.Issuer.CommonName == issuer {
+		return runtime.HandlerResult{
+			Success: true,
+			Message: fmt.Sprintf("%s issued by %s", path, issuer),
+		}
+	}
+	return runtime.HandlerResult{
+		Success: false,
+		Message: fmt.Sprintf("%s issued by %q, expected %q", path, cert.Issuer.CommonName, issuer),
+	}
+}
+
+func (h *Handler) checkSANContains(path string, cert *x509.Certificate, name string) runtime.HandlerResult {
+	if name == "" {
+		return runtime.HandlerResult{
+			Success: false,
+			Error:   fmt.Errorf("name argument not specified"),
+		}
+	}
+
+	for _, dns := range cert.DNSNames {
+		if dns == name {
+			return runtime.HandlerResult{
+				Success: true,
+				Message: fmt.Sprintf("%s covers %s", path, name),
+			}
+		}
+	}
+	for _, ip := range cert.IPAddresses {
+		if ip.String() == name {
+			return runtime.HandlerResult{
+				Success: true,
+				Message: fmt.Sprintf("%s covers %s", path, name),
+			}
+		}
+	}
+	return runtime.HandlerResult{
+		Success: false,
+		Message: fmt.Sprintf("%s does not cover %s (SANs: %s)", path, name, strings.Join(cert.DNSNames, ", ")),
+	}
+}
+
+// loadCertificate reads a PEM file and parses its first CERTIFICATE block.
+func loadCertificate(path string) (*x509.Certificate, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	for len(data) > 0 {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		return x509.ParseCertificate(block.Bytes)
+	}
+	return nil, fmt.Errorf("no certificate found in %s", path)
+}
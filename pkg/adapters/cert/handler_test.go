@@ -0,0 +1,139 @@
+package cert
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ensurascript/ensura/pkg/ast"
+)
+
+// writeTestCert generates a self-signed certificate valid for the given
+// duration and writes it as PEM.
+func writeTestCert(t *testing.T, path string, validFor time.Duration) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "test.example"},
+		Issuer:       pkix.Name{CommonName: "Test CA"},
+		DNSNames:     []string{"test.example", "alt.example"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(validFor),
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer out.Close()
+	if err := pem.Encode(out, &pem.Block{Type: "CERTIFICATE", Bytes: der}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCheckValid(t *testing.T) {
+	h := New()
+	ctx := context.Background()
+
+	tmpDir := t.TempDir()
+	certFile := filepath.Join(tmpDir, "cert.pem")
+	writeTestCert(t, certFile, 24*time.Hour)
+
+	subject := &ast.ResourceRef{Path: certFile, ResourceType: "cert"}
+	result := h.Check(ctx, subject, "valid", nil)
+	if !result.Success {
+		t.Errorf("Expected valid to pass: %s", result.Message)
+	}
+
+	expired := filepath.Join(tmpDir, "expired.pem")
+	writeTestCert(t, expired, -time.Minute)
+	subject = &ast.ResourceRef{Path: expired, ResourceType: "cert"}
+	result = h.Check(ctx, subject, "valid", nil)
+	if result.Success {
+		t.Error("Expected valid to fail for expired certificate")
+	}
+}
+
+func TestCheckExpiresAfter(t *testing.T) {
+	h := New()
+	ctx := context.Background()
+
+	tmpDir := t.TempDir()
+	certFile := filepath.Join(tmpDir, "cert.pem")
+	writeTestCert(t, certFile, 48*time.Hour)
+
+	subject := &ast.ResourceRef{Path: certFile, ResourceType: "cert"}
+	result := h.Check(ctx, subject, "expires_after", map[string]string{"duration": "24h"})
+	if !result.Success {
+		t.Errorf("Expected expires_after 24h to pass: %s", result.Message)
+	}
+	result = h.Check(ctx, subject, "expires_after", map[string]string{"duration": "720h"})
+	if result.Success {
+		t.Error("Expected expires_after 720h to fail for 48h certificate")
+	}
+	result = h.Check(ctx, subject, "expires_after", nil)
+	if result.Error == nil {
+		t.Error("Expected error for missing duration argument")
+	}
+}
+
+func TestCheckSANContains(t *testing.T) {
+	h := New()
+	ctx := context.Background()
+
+	tmpDir := t.TempDir()
+	certFile := filepath.Join(tmpDir, "cert.pem")
+	writeTestCert(t, certFile, 24*time.Hour)
+
+	subject := &ast.ResourceRef{Path: certFile, ResourceType: "cert"}
+	result := h.Check(ctx, subject, "san_contains", map[string]string{"name": "alt.example"})
+	if !result.Success {
+		t.Errorf("Expected san_contains to pass: %s", result.Message)
+	}
+	result = h.Check(ctx, subject, "san_contains", map[string]string{"name": "other.example"})
+	if result.Success {
+		t.Error("Expected san_contains to fail for uncovered name")
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	h := New()
+	ctx := context.Background()
+
+	subject := &ast.ResourceRef{Path: "/nonexistent/cert.pem", ResourceType: "cert"}
+	result := h.Check(ctx, subject, "valid", nil)
+	if result.Error == nil {
+		t.Error("Expected error for missing file")
+	}
+
+	tmpDir := t.TempDir()
+	notPEM := filepath.Join(tmpDir, "not.pem")
+	if err := os.WriteFile(notPEM, []byte("not a certificate"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	subject = &ast.ResourceRef{Path: notPEM, ResourceType: "cert"}
+	result = h.Check(ctx, subject, "valid", nil)
+	if result.Error == nil {
+		t.Error("Expected error for non-PEM file")
+	}
+}
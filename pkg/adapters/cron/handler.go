@@ -4,12 +4,9 @@ package cron
 import (
 	"context"
 	"fmt"
-	"os"
-	"os/exec"
-	"runtime"
-	"strings"
 
 	"github.com/ensurascript/ensura/pkg/ast"
+	"github.com/ensurascript/ensura/pkg/cronexpr"
 	pkgruntime "github.com/ensurascript/ensura/pkg/runtime"
 )
 
@@ -26,6 +23,11 @@ func (h *Handler) Name() string {
 	return "cron.native"
 }
 
+// SupportedConditions lists the conditions this handler understands.
+func (h *Handler) SupportedConditions() []string {
+	return []string{"scheduled"}
+}
+
 // Check verifies a cron scheduling condition.
 func (h *Handler) Check(ctx context.Context, subject *ast.ResourceRef, condition string, args map[string]string) pkgruntime.HandlerResult {
 	if subject == nil {
@@ -49,11 +51,17 @@ func (h *Handler) Check(ctx context.Context, subject *ast.ResourceRef, condition
 			Error:   fmt.Errorf("schedule argument required"),
 		}
 	}
+	if err := cronexpr.Validate(schedule); err != nil {
+		return pkgruntime.HandlerResult{
+			Success: false,
+			Error:   fmt.Errorf("invalid schedule %q: %w", schedule, err),
+		}
+	}
 
 	jobName := subject.Path
 
-	// Check if cron job exists based on platform
-	exists, err := h.cronJobExists(jobName)
+	// Check if the job exists using the platform scheduler
+	exists, err := jobExists(jobName, args["user"])
 	if err != nil {
 		return pkgruntime.HandlerResult{
 			Success: false,
@@ -97,6 +105,12 @@ func (h *Handler) Enforce(ctx context.Context, subject *ast.ResourceRef, conditi
 			Error:   fmt.Errorf("schedule argument required"),
 		}
 	}
+	if err := cronexpr.Validate(schedule); err != nil {
+		return pkgruntime.HandlerResult{
+			Success: false,
+			Error:   fmt.Errorf("invalid schedule %q: %w", schedule, err),
+		}
+	}
 
 	jobName := subject.Path
 	command := args["command"]
@@ -107,8 +121,8 @@ func (h *Handler) Enforce(ctx context.Context, subject *ast.ResourceRef, conditi
 		}
 	}
 
-	// Add/update cron job based on platform
-	if err := h.addCronJob(jobName, schedule, command); err != nil {
+	// Add/update the job using the platform scheduler
+	if err := installJob(jobName, schedule, command, args["user"]); err != nil {
 		return pkgruntime.HandlerResult{
 			Success: false,
 			Error:   fmt.Errorf("failed to add cron job: %w", err),
@@ -120,96 +134,3 @@ func (h *Handler) Enforce(ctx context.Context, subject *ast.ResourceRef, conditi
 		Message: fmt.Sprintf("scheduled cron job %s: %s", jobName, schedule),
 	}
 }
-
-// cronJobExists checks if a cron job with the given identifier exists.
-func (h *Handler) cronJobExists(jobName string) (bool, error) {
-	switch runtime.GOOS {
-	case "darwin", "linux":
-		// Use crontab -l to list current user's cron jobs
-		cmd := exec.Command("crontab", "-l")
-		output, err := cmd.CombinedOutput()
-		if err != nil {
-			// Exit status 1 typically means no crontab
-			if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 {
-				return false, nil
-			}
-			return false, err
-		}
-
-		// Look for a comment marker that identifies this job
-		marker := fmt.Sprintf("# EnsuraScript: %s", jobName)
-		return strings.Contains(string(output), marker), nil
-
-	default:
-		return false, fmt.Errorf("cron scheduling not supported on %s", runtime.GOOS)
-	}
-}
-
-// addCronJob adds or updates a cron job entry.
-func (h *Handler) addCronJob(jobName, schedule, command string) error {
-	switch runtime.GOOS {
-	case "darwin", "linux":
-		// Get existing crontab
-		cmd := exec.Command("crontab", "-l")
-		output, err := cmd.CombinedOutput()
-		var existingCrontab string
-		if err != nil {
-			// Exit status 1 typically means no crontab exists yet
-			if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 {
-				existingCrontab = ""
-			} else {
-				return err
-			}
-		} else {
-			existingCrontab = string(output)
-		}
-
-		// Remove existing entry with this job name
-		marker := fmt.Sprintf("# EnsuraScript: %s", jobName)
-		lines := strings.Split(existingCrontab, "\n")
-		var newLines []string
-		skipNext := false
-		for _, line := range lines {
-			if strings.Contains(line, marker) {
-				skipNext = true
-				continue
-			}
-			if skipNext {
-				skipNext = false
-				continue
-			}
-			if line != "" {
-				newLines = append(newLines, line)
-			}
-		}
-
-		// Add new entry
-		newEntry := fmt.Sprintf("%s\n%s %s", marker, schedule, command)
-		newLines = append(newLines, newEntry)
-
-		// Write new crontab
-		newCrontab := strings.Join(newLines, "\n") + "\n"
-		tmpFile, err := os.CreateTemp("", "ensura-crontab-*")
-		if err != nil {
-			return err
-		}
-		defer os.Remove(tmpFile.Name())
-
-		if _, err := tmpFile.WriteString(newCrontab); err != nil {
-			tmpFile.Close()
-			return err
-		}
-		tmpFile.Close()
-
-		// Install new crontab
-		installCmd := exec.Command("crontab", tmpFile.Name())
-		if output, err := installCmd.CombinedOutput(); err != nil {
-			return fmt.Errorf("failed to install crontab: %w, output: %s", err, string(output))
-		}
-
-		return nil
-
-	default:
-		return fmt.Errorf("cron scheduling not supported on %s", runtime.GOOS)
-	}
-}
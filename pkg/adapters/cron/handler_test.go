@@ -48,6 +48,47 @@ func TestHandler_Check_NoSchedule(t *testing.T) {
 	}
 }
 
+func TestHandler_Check_InvalidSchedule(t *testing.T) {
+	h := New()
+	ctx := context.Background()
+	subject := &ast.ResourceRef{
+		Position:     lexer.Position{},
+		ResourceType: "cron",
+		Path:         "test_job",
+	}
+
+	result := h.Check(ctx, subject, "scheduled", map[string]string{"schedule": "61 * * * *"})
+
+	if result.Success {
+		t.Error("Check() should fail with an invalid schedule")
+	}
+	if result.Error == nil {
+		t.Error("Check() should return an error with an invalid schedule")
+	}
+}
+
+func TestHandler_Enforce_InvalidSchedule(t *testing.T) {
+	h := New()
+	ctx := context.Background()
+	subject := &ast.ResourceRef{
+		Position:     lexer.Position{},
+		ResourceType: "cron",
+		Path:         "test_job",
+	}
+
+	result := h.Enforce(ctx, subject, "scheduled", map[string]string{
+		"schedule": "not a schedule",
+		"command":  "echo test",
+	})
+
+	if result.Success {
+		t.Error("Enforce() should fail with an invalid schedule")
+	}
+	if result.Error == nil {
+		t.Error("Enforce() should return an error with an invalid schedule")
+	}
+}
+
 func TestHandler_Check_UnknownCondition(t *testing.T) {
 	h := New()
 	ctx := context.Background()
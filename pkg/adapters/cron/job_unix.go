@@ -0,0 +1,101 @@
+//go:build !windows
+
+package cron
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// crontabArgs prepends -u when a user is given, targeting that user's
+// crontab instead of the invoking user's (requires root).
+func crontabArgs(user string, args ...string) []string {
+	if user != "" {
+		return append([]string{"-u", user}, args...)
+	}
+	return args
+}
+
+// jobExists checks if a cron job with the given identifier exists in the
+// target user's crontab.
+func jobExists(jobName, user string) (bool, error) {
+	// Use crontab -l to list cron jobs
+	cmd := exec.Command("crontab", crontabArgs(user, "-l")...)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		// Exit status 1 typically means no crontab
+		if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 {
+			return false, nil
+		}
+		return false, err
+	}
+
+	// Look for a comment marker that identifies this job
+	marker := fmt.Sprintf("# EnsuraScript: %s", jobName)
+	return strings.Contains(string(output), marker), nil
+}
+
+// installJob adds or updates a cron job entry in the target user's crontab.
+func installJob(jobName, schedule, command, user string) error {
+	// Get existing crontab
+	cmd := exec.Command("crontab", crontabArgs(user, "-l")...)
+	output, err := cmd.CombinedOutput()
+	var existingCrontab string
+	if err != nil {
+		// Exit status 1 typically means no crontab exists yet
+		if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 {
+			existingCrontab = ""
+		} else {
+			return err
+		}
+	} else {
+		existingCrontab = string(output)
+	}
+
+	// Remove existing entry with this job name
+	marker := fmt.Sprintf("# EnsuraScript: %s", jobName)
+	lines := strings.Split(existingCrontab, "\n")
+	var newLines []string
+	skipNext := false
+	for _, line := range lines {
+		if strings.Contains(line, marker) {
+			skipNext = true
+			continue
+		}
+		if skipNext {
+			skipNext = false
+			continue
+		}
+		if line != "" {
+			newLines = append(newLines, line)
+		}
+	}
+
+	// Add new entry
+	newEntry := fmt.Sprintf("%s\n%s %s", marker, schedule, command)
+	newLines = append(newLines, newEntry)
+
+	// Write new crontab
+	newCrontab := strings.Join(newLines, "\n") + "\n"
+	tmpFile, err := os.CreateTemp("", "ensura-crontab-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpFile.Name())
+
+	if _, err := tmpFile.WriteString(newCrontab); err != nil {
+		tmpFile.Close()
+		return err
+	}
+	tmpFile.Close()
+
+	// Install new crontab
+	installCmd := exec.Command("crontab", crontabArgs(user, tmpFile.Name())...)
+	if output, err := installCmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to install crontab: %w, output: %s", err, string(output))
+	}
+
+	return nil
+}
@@ -0,0 +1,46 @@
+//go:build windows
+
+package cron
+
+import (
+	"fmt"
+	"os/exec"
+)
+
+// jobExists checks if a Task Scheduler task with the given identifier exists.
+func jobExists(jobName, user string) (bool, error) {
+	cmd := exec.Command("schtasks", "/Query", "/TN", taskName(jobName))
+	if err := cmd.Run(); err != nil {
+		// schtasks exits non-zero when the task does not exist
+		if _, ok := err.(*exec.ExitError); ok {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// installJob creates or replaces a Task Scheduler task for the job. When a
+// user is given the task runs as that account via /RU.
+func installJob(jobName, schedule, command, user string) error {
+	trigger, err := taskTrigger(schedule)
+	if err != nil {
+		return err
+	}
+
+	args := append([]string{"/Create", "/F", "/TN", taskName(jobName), "/TR", command}, trigger...)
+	if user != "" {
+		args = append(args, "/RU", user)
+	}
+	cmd := exec.Command("schtasks", args...)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to create scheduled task: %w, output: %s", err, string(output))
+	}
+	return nil
+}
+
+// taskName prefixes the job identifier so EnsuraScript tasks are
+// recognizable in the Task Scheduler library.
+func taskName(jobName string) string {
+	return "EnsuraScript-" + jobName
+}
@@ -0,0 +1,97 @@
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// dayNames maps cron day-of-week numbers (0 and 7 both mean Sunday) to the
+// abbreviations schtasks expects for /D.
+var dayNames = map[int]string{
+	0: "SUN",
+	1: "MON",
+	2: "TUE",
+	3: "WED",
+	4: "THU",
+	5: "FRI",
+	6: "SAT",
+	7: "SUN",
+}
+
+// taskTrigger translates a cron schedule into schtasks trigger arguments
+// (/SC plus /ST and /D where applicable). Only schedules that map onto a
+// single Task Scheduler trigger are supported: the @hourly-style shortcuts
+// and five-field expressions with fixed minute/hour/day values.
+func taskTrigger(schedule string) ([]string, error) {
+	switch schedule {
+	case "@hourly":
+		return []string{"/SC", "HOURLY"}, nil
+	case "@daily", "@midnight":
+		return []string{"/SC", "DAILY", "/ST", "00:00"}, nil
+	case "@weekly":
+		return []string{"/SC", "WEEKLY", "/D", "SUN", "/ST", "00:00"}, nil
+	case "@monthly":
+		return []string{"/SC", "MONTHLY", "/D", "1", "/ST", "00:00"}, nil
+	}
+
+	fields := strings.Fields(schedule)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("invalid cron schedule %q: expected 5 fields", schedule)
+	}
+	minute, hour, dom, month, dow := fields[0], fields[1], fields[2], fields[3], fields[4]
+
+	if month != "*" {
+		return nil, fmt.Errorf("cannot express cron schedule %q as a Task Scheduler trigger", schedule)
+	}
+
+	// Every minute
+	if minute == "*" && hour == "*" && dom == "*" && dow == "*" {
+		return []string{"/SC", "MINUTE"}, nil
+	}
+
+	m, err := strconv.Atoi(minute)
+	if err != nil || m < 0 || m > 59 {
+		return nil, fmt.Errorf("cannot express cron schedule %q as a Task Scheduler trigger", schedule)
+	}
+
+	// Hourly at a fixed minute
+	if hour == "*" && dom == "*" && dow == "*" {
+		return []string{"/SC", "HOURLY", "/ST", fmt.Sprintf("00:%02d", m)}, nil
+	}
+
+	h, err := strconv.Atoi(hour)
+	if err != nil || h < 0 || h > 23 {
+		return nil, fmt.Errorf("cannot express cron schedule %q as a Task Scheduler trigger", schedule)
+	}
+	start := fmt.Sprintf("%02d:%02d", h, m)
+
+	// Daily at a fixed time
+	if dom == "*" && dow == "*" {
+		return []string{"/SC", "DAILY", "/ST", start}, nil
+	}
+
+	// Weekly on a fixed day
+	if dom == "*" {
+		d, err := strconv.Atoi(dow)
+		if err != nil {
+			return nil, fmt.Errorf("cannot express cron schedule %q as a Task Scheduler trigger", schedule)
+		}
+		name, ok := dayNames[d]
+		if !ok {
+			return nil, fmt.Errorf("invalid day of week %q in cron schedule %q", dow, schedule)
+		}
+		return []string{"/SC", "WEEKLY", "/D", name, "/ST", start}, nil
+	}
+
+	// Monthly on a fixed day of month
+	if dow == "*" {
+		d, err := strconv.Atoi(dom)
+		if err != nil || d < 1 || d > 31 {
+			return nil, fmt.Errorf("invalid day of month %q in cron schedule %q", dom, schedule)
+		}
+		return []string{"/SC", "MONTHLY", "/D", strconv.Itoa(d), "/ST", start}, nil
+	}
+
+	return nil, fmt.Errorf("cannot express cron schedule %q as a Task Scheduler trigger", schedule)
+}
@@ -0,0 +1,56 @@
+package cron
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTaskTrigger(t *testing.T) {
+	tests := []struct {
+		schedule string
+		want     string
+	}{
+		{"@hourly", "/SC HOURLY"},
+		{"@daily", "/SC DAILY /ST 00:00"},
+		{"@midnight", "/SC DAILY /ST 00:00"},
+		{"@weekly", "/SC WEEKLY /D SUN /ST 00:00"},
+		{"@monthly", "/SC MONTHLY /D 1 /ST 00:00"},
+		{"* * * * *", "/SC MINUTE"},
+		{"30 * * * *", "/SC HOURLY /ST 00:30"},
+		{"0 2 * * *", "/SC DAILY /ST 02:00"},
+		{"15 4 * * 1", "/SC WEEKLY /D MON /ST 04:15"},
+		{"15 4 * * 7", "/SC WEEKLY /D SUN /ST 04:15"},
+		{"0 3 5 * *", "/SC MONTHLY /D 5 /ST 03:00"},
+	}
+
+	for _, tt := range tests {
+		args, err := taskTrigger(tt.schedule)
+		if err != nil {
+			t.Errorf("taskTrigger(%q) error: %v", tt.schedule, err)
+			continue
+		}
+		if got := strings.Join(args, " "); got != tt.want {
+			t.Errorf("taskTrigger(%q) = %q, want %q", tt.schedule, got, tt.want)
+		}
+	}
+}
+
+func TestTaskTrigger_Unsupported(t *testing.T) {
+	tests := []string{
+		"",
+		"0 2 * *",
+		"*/5 * * * *",
+		"0 2 1 1 *",
+		"0 2 1 * 1",
+		"60 * * * *",
+		"0 24 * * *",
+		"0 2 * * 8",
+		"0 2 32 * *",
+	}
+
+	for _, schedule := range tests {
+		if _, err := taskTrigger(schedule); err == nil {
+			t.Errorf("taskTrigger(%q) should fail", schedule)
+		}
+	}
+}
@@ -0,0 +1,200 @@
+// Package datastore provides lightweight reachability handlers for common
+// datastores. Subjects are DSN-style paths — redis://host:port,
+// memcached://host:port, or tcp://host:port — and each scheme speaks just
+// enough of the wire protocol to prove the service is really answering,
+// not merely accepting connections.
+package datastore
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/ensurascript/ensura/pkg/ast"
+	"github.com/ensurascript/ensura/pkg/runtime"
+)
+
+// defaultPorts maps DSN schemes to their conventional ports.
+var defaultPorts = map[string]string{
+	"redis":     "6379",
+	"memcached": "11211",
+}
+
+// Handler implements datastore reachability operations.
+type Handler struct{}
+
+// New creates a new datastore handler.
+func New() *Handler {
+	return &Handler{}
+}
+
+// Name returns the handler name.
+func (h *Handler) Name() string {
+	return "datastore.native"
+}
+
+// SupportedConditions lists the conditions this handler understands.
+func (h *Handler) SupportedConditions() []string {
+	return []string{"reachable"}
+}
+
+// Check verifies a datastore is reachable and answering its protocol.
+func (h *Handler) Check(ctx context.Context, subject *ast.ResourceRef, condition string, args map[string]string) runtime.HandlerResult {
+	if subject == nil {
+		return runtime.HandlerResult{
+			Success: false,
+			Error:   fmt.Errorf("no subject specified"),
+		}
+	}
+
+	if condition != "reachable" {
+		return runtime.HandlerResult{
+			Success: false,
+			Error:   fmt.Errorf("unknown condition: %s", condition),
+		}
+	}
+
+	scheme, addr, err := parseDSN(subject.Path)
+	if err != nil {
+		return runtime.HandlerResult{
+			Success: false,
+			Error:   err,
+		}
+	}
+
+	timeout := 5 * time.Second
+	if t := args["timeout"]; t != "" {
+		timeout, err = time.ParseDuration(t)
+		if err != nil {
+			return runtime.HandlerResult{
+				Success: false,
+				Error:   fmt.Errorf("invalid timeout %q: %w", t, err),
+			}
+		}
+	}
+
+	conn, err := net.DialTimeout("tcp", addr, timeout)
+	if err != nil {
+		return runtime.HandlerResult{
+			Success: false,
+			Message: fmt.Sprintf("%s is not reachable: %v", subject.Path, err),
+		}
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(timeout))
+
+	switch scheme {
+	case "redis":
+		err = pingRedis(conn, args["password"])
+	case "memcached":
+		err = pingMemcached(conn)
+	case "tcp":
+		err = expectBanner(conn, args["banner"])
+	default:
+		return runtime.HandlerResult{
+			Success: false,
+			Error:   fmt.Errorf("unknown datastore scheme %q", scheme),
+		}
+	}
+	if err != nil {
+		return runtime.HandlerResult{
+			Success: false,
+			Message: fmt.Sprintf("%s answered incorrectly: %v", subject.Path, err),
+		}
+	}
+
+	return runtime.HandlerResult{
+		Success: true,
+		Message: fmt.Sprintf("%s is reachable", subject.Path),
+	}
+}
+
+// Enforce cannot start a datastore; reachability is check-only.
+func (h *Handler) Enforce(ctx context.Context, subject *ast.ResourceRef, condition string, args map[string]string) runtime.HandlerResult {
+	return runtime.HandlerResult{
+		Success: false,
+		Error:   fmt.Errorf("cannot enforce condition: %s", condition),
+	}
+}
+
+// parseDSN splits scheme://host:port, filling in the scheme's default port
+// when none is given.
+func parseDSN(dsn string) (scheme, addr string, err error) {
+	scheme, rest, ok := strings.Cut(dsn, "://")
+	if !ok || scheme == "" || rest == "" {
+		return "", "", fmt.Errorf("invalid datastore path %q: expected scheme://host:port", dsn)
+	}
+	if _, _, err := net.SplitHostPort(rest); err != nil {
+		port, ok := defaultPorts[scheme]
+		if !ok {
+			return "", "", fmt.Errorf("invalid datastore path %q: missing port", dsn)
+		}
+		rest = net.JoinHostPort(rest, port)
+	}
+	return scheme, rest, nil
+}
+
+// pingRedis sends PING (after AUTH when a password is given) and expects
+// +PONG back.
+func pingRedis(conn net.Conn, password string) error {
+	reader := bufio.NewReader(conn)
+
+	if password != "" {
+		if _, err := fmt.Fprintf(conn, "AUTH %s\r\n", password); err != nil {
+			return err
+		}
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return err
+		}
+		if !strings.HasPrefix(line, "+OK") {
+			return fmt.Errorf("AUTH failed: %s", strings.TrimSpace(line))
+		}
+	}
+
+	if _, err := fmt.Fprint(conn, "PING\r\n"); err != nil {
+		return err
+	}
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return err
+	}
+	if !strings.HasPrefix(line, "+PONG") {
+		return fmt.Errorf("unexpected PING reply: %s", strings.TrimSpace(line))
+	}
+	return nil
+}
+
+// pingMemcached sends the version command and expects a VERSION reply.
+func pingMemcached(conn net.Conn) error {
+	if _, err := fmt.Fprint(conn, "version\r\n"); err != nil {
+		return err
+	}
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		return err
+	}
+	if !strings.HasPrefix(line, "VERSION ") {
+		return fmt.Errorf("unexpected version reply: %s", strings.TrimSpace(line))
+	}
+	return nil
+}
+
+// expectBanner reads the server's greeting line and checks it starts with
+// the expected banner. With no banner the connection itself suffices.
+func expectBanner(conn net.Conn, banner string) error {
+	if banner == "" {
+		return nil
+	}
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		return fmt.Errorf("reading banner: %w", err)
+	}
+	if !strings.HasPrefix(line, banner) {
+		return fmt.Errorf("banner %q does not start with %q", strings.TrimSpace(line), banner)
+	}
+	return nil
+}
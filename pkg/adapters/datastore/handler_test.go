@@ -0,0 +1,178 @@
+package datastore
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/ensurascript/ensura/pkg/ast"
+)
+
+// fakeServer accepts one connection and answers each line via reply.
+func fakeServer(t *testing.T, reply func(line string) string) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		reader := bufio.NewReader(conn)
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			fmt.Fprint(conn, reply(strings.TrimSpace(line)))
+		}
+	}()
+
+	return ln.Addr().String()
+}
+
+func TestParseDSN(t *testing.T) {
+	scheme, addr, err := parseDSN("redis://cache.internal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if scheme != "redis" || addr != "cache.internal:6379" {
+		t.Errorf("parseDSN() = %s, %s", scheme, addr)
+	}
+
+	scheme, addr, err = parseDSN("memcached://10.0.0.5:11300")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if scheme != "memcached" || addr != "10.0.0.5:11300" {
+		t.Errorf("parseDSN() = %s, %s", scheme, addr)
+	}
+
+	invalid := []string{"", "redis://", "cache.internal:6379", "tcp://host"}
+	for _, dsn := range invalid {
+		if _, _, err := parseDSN(dsn); err == nil {
+			t.Errorf("parseDSN(%q) should fail", dsn)
+		}
+	}
+}
+
+func TestCheckRedis(t *testing.T) {
+	addr := fakeServer(t, func(line string) string {
+		if line == "PING" {
+			return "+PONG\r\n"
+		}
+		return "-ERR unknown command\r\n"
+	})
+
+	h := New()
+	ctx := context.Background()
+	subject := &ast.ResourceRef{ResourceType: "datastore", Path: "redis://" + addr}
+
+	result := h.Check(ctx, subject, "reachable", map[string]string{})
+	if !result.Success {
+		t.Errorf("Check() should pass against a PONGing server: %v %v", result.Message, result.Error)
+	}
+}
+
+func TestCheckRedisAuth(t *testing.T) {
+	authed := false
+	addr := fakeServer(t, func(line string) string {
+		switch {
+		case line == "AUTH hunter2":
+			authed = true
+			return "+OK\r\n"
+		case line == "PING" && authed:
+			return "+PONG\r\n"
+		default:
+			return "-NOAUTH Authentication required\r\n"
+		}
+	})
+
+	h := New()
+	ctx := context.Background()
+	subject := &ast.ResourceRef{ResourceType: "datastore", Path: "redis://" + addr}
+
+	result := h.Check(ctx, subject, "reachable", map[string]string{"password": "hunter2"})
+	if !result.Success {
+		t.Errorf("Check() should pass with correct password: %v %v", result.Message, result.Error)
+	}
+}
+
+func TestCheckMemcached(t *testing.T) {
+	addr := fakeServer(t, func(line string) string {
+		if line == "version" {
+			return "VERSION 1.6.21\r\n"
+		}
+		return "ERROR\r\n"
+	})
+
+	h := New()
+	ctx := context.Background()
+	subject := &ast.ResourceRef{ResourceType: "datastore", Path: "memcached://" + addr}
+
+	result := h.Check(ctx, subject, "reachable", map[string]string{})
+	if !result.Success {
+		t.Errorf("Check() should pass against a memcached server: %v %v", result.Message, result.Error)
+	}
+}
+
+func TestCheckBanner(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			fmt.Fprint(conn, "220 smtp.example ESMTP ready\r\n")
+			conn.Close()
+		}
+	}()
+
+	h := New()
+	ctx := context.Background()
+	subject := &ast.ResourceRef{ResourceType: "datastore", Path: "tcp://" + ln.Addr().String()}
+
+	result := h.Check(ctx, subject, "reachable", map[string]string{"banner": "220"})
+	if !result.Success {
+		t.Errorf("Check() should pass for matching banner: %v %v", result.Message, result.Error)
+	}
+
+	result = h.Check(ctx, subject, "reachable", map[string]string{"banner": "250"})
+	if result.Success {
+		t.Error("Check() should fail for a different banner")
+	}
+}
+
+func TestCheckUnreachable(t *testing.T) {
+	h := New()
+	ctx := context.Background()
+	// Port 1 on localhost is almost certainly closed
+	subject := &ast.ResourceRef{ResourceType: "datastore", Path: "redis://127.0.0.1:1"}
+
+	result := h.Check(ctx, subject, "reachable", map[string]string{"timeout": "500ms"})
+	if result.Success {
+		t.Error("Check() should fail for a closed port")
+	}
+}
+
+func TestEnforce(t *testing.T) {
+	h := New()
+	subject := &ast.ResourceRef{ResourceType: "datastore", Path: "redis://127.0.0.1:1"}
+	result := h.Enforce(context.Background(), subject, "reachable", map[string]string{})
+	if result.Success || result.Error == nil {
+		t.Error("Enforce() should refuse to enforce reachable")
+	}
+}
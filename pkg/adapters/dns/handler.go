@@ -0,0 +1,201 @@
+// Package dns provides the DNS resolution handler for EnsuraScript.
+//
+// It backs the `resolves` and `resolves_to` conditions on `dns` resources:
+//
+//	ensure resolves on dns "example.com"
+//	ensure resolves_to on dns "example.com" with dns.native a "93.184.216.34"
+//
+// A custom nameserver and lookup timeout can be configured via args.
+package dns
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/ensurascript/ensura/pkg/ast"
+	pkgruntime "github.com/ensurascript/ensura/pkg/runtime"
+)
+
+// DefaultLookupTimeout is used when no timeout argument is given.
+const DefaultLookupTimeout = 5 * time.Second
+
+// Handler implements DNS resolution checks.
+type Handler struct{}
+
+// New creates a new dns handler.
+func New() *Handler {
+	return &Handler{}
+}
+
+// Name returns the handler name.
+func (h *Handler) Name() string {
+	return "dns.native"
+}
+
+// SupportedConditions lists the conditions this handler understands.
+func (h *Handler) SupportedConditions() []string {
+	return []string{"resolves", "resolves_to"}
+}
+
+// Check verifies a DNS condition.
+func (h *Handler) Check(ctx context.Context, subject *ast.ResourceRef, condition string, args map[string]string) pkgruntime.HandlerResult {
+	if subject == nil || subject.Path == "" {
+		return pkgruntime.HandlerResult{
+			Success: false,
+			Error:   fmt.Errorf("no subject specified"),
+		}
+	}
+
+	resolver, timeout, err := h.resolver(args)
+	if err != nil {
+		return pkgruntime.HandlerResult{Success: false, Error: err}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	name := subject.Path
+
+	switch condition {
+	case "resolves":
+		addrs, err := resolver.LookupHost(ctx, name)
+		if err != nil || len(addrs) == 0 {
+			return pkgruntime.HandlerResult{
+				Success: false,
+				Message: fmt.Sprintf("%s does not resolve: %v", name, err),
+			}
+		}
+		return pkgruntime.HandlerResult{
+			Success: true,
+			Message: fmt.Sprintf("%s resolves to %s", name, strings.Join(addrs, ", ")),
+		}
+
+	case "resolves_to":
+		return h.checkExpected(ctx, resolver, name, args)
+
+	default:
+		return pkgruntime.HandlerResult{
+			Success: false,
+			Error:   fmt.Errorf("unknown condition: %s", condition),
+		}
+	}
+}
+
+// Enforce is not supported: DNS records are managed outside the host.
+func (h *Handler) Enforce(ctx context.Context, subject *ast.ResourceRef, condition string, args map[string]string) pkgruntime.HandlerResult {
+	return pkgruntime.HandlerResult{
+		Success: false,
+		Error:   fmt.Errorf("cannot enforce condition %s: DNS records must be changed at the authoritative server", condition),
+	}
+}
+
+// checkExpected compares resolved records against the expected values given
+// in the a, aaaa and cname args (comma-separated for multiple values).
+func (h *Handler) checkExpected(ctx context.Context, resolver *net.Resolver, name string, args map[string]string) pkgruntime.HandlerResult {
+	checked := false
+
+	if expected := args["a"]; expected != "" {
+		checked = true
+		addrs, err := resolver.LookupIP(ctx, "ip4", name)
+		if result, ok := matchAddrs(name, "A", expected, addrs, err); !ok {
+			return result
+		}
+	}
+
+	if expected := args["aaaa"]; expected != "" {
+		checked = true
+		addrs, err := resolver.LookupIP(ctx, "ip6", name)
+		if result, ok := matchAddrs(name, "AAAA", expected, addrs, err); !ok {
+			return result
+		}
+	}
+
+	if expected := args["cname"]; expected != "" {
+		checked = true
+		cname, err := resolver.LookupCNAME(ctx, name)
+		if err != nil {
+			return pkgruntime.HandlerResult{
+				Success: false,
+				Message: fmt.Sprintf("%s CNAME lookup failed: %v", name, err),
+			}
+		}
+		if strings.TrimSuffix(cname, ".") != strings.TrimSuffix(expected, ".") {
+			return pkgruntime.HandlerResult{
+				Success: false,
+				Message: fmt.Sprintf("%s CNAME is %s, expected %s", name, cname, expected),
+			}
+		}
+	}
+
+	if !checked {
+		return pkgruntime.HandlerResult{
+			Success: false,
+			Error:   fmt.Errorf("resolves_to requires an a, aaaa or cname argument"),
+		}
+	}
+
+	return pkgruntime.HandlerResult{
+		Success: true,
+		Message: fmt.Sprintf("%s resolves to the expected records", name),
+	}
+}
+
+// matchAddrs checks that every expected address appears in the lookup result.
+func matchAddrs(name, recordType, expected string, addrs []net.IP, err error) (pkgruntime.HandlerResult, bool) {
+	if err != nil {
+		return pkgruntime.HandlerResult{
+			Success: false,
+			Message: fmt.Sprintf("%s %s lookup failed: %v", name, recordType, err),
+		}, false
+	}
+
+	got := make(map[string]bool, len(addrs))
+	for _, addr := range addrs {
+		got[addr.String()] = true
+	}
+
+	for _, want := range strings.Split(expected, ",") {
+		want = strings.TrimSpace(want)
+		if want != "" && !got[want] {
+			return pkgruntime.HandlerResult{
+				Success: false,
+				Message: fmt.Sprintf("%s has no %s record %s", name, recordType, want),
+			}, false
+		}
+	}
+
+	return pkgruntime.HandlerResult{}, true
+}
+
+// resolver builds a net.Resolver honoring the optional nameserver and
+// timeout args.
+func (h *Handler) resolver(args map[string]string) (*net.Resolver, time.Duration, error) {
+	timeout := DefaultLookupTimeout
+	if raw := args["timeout"]; raw != "" {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return nil, 0, fmt.Errorf("invalid timeout %q: %w", raw, err)
+		}
+		timeout = d
+	}
+
+	nameserver := args["nameserver"]
+	if nameserver == "" {
+		return net.DefaultResolver, timeout, nil
+	}
+	if !strings.Contains(nameserver, ":") {
+		nameserver += ":53"
+	}
+
+	resolver := &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+			d := net.Dialer{Timeout: timeout}
+			return d.DialContext(ctx, network, nameserver)
+		},
+	}
+	return resolver, timeout, nil
+}
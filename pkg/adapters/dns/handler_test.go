@@ -0,0 +1,104 @@
+package dns
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/ensurascript/ensura/pkg/ast"
+)
+
+func TestCheckErrors(t *testing.T) {
+	h := New()
+	ctx := context.Background()
+
+	result := h.Check(ctx, nil, "resolves", nil)
+	if result.Error == nil {
+		t.Error("Expected error for missing subject")
+	}
+
+	subject := &ast.ResourceRef{ResourceType: "dns", Path: "example.com"}
+	result = h.Check(ctx, subject, "resolves", map[string]string{"timeout": "soon"})
+	if result.Error == nil {
+		t.Error("Expected error for invalid timeout")
+	}
+
+	result = h.Check(ctx, subject, "bogus", nil)
+	if result.Error == nil {
+		t.Error("Expected error for unknown condition")
+	}
+
+	// resolves_to needs at least one expected record; no lookup happens
+	result = h.Check(ctx, subject, "resolves_to", nil)
+	if result.Error == nil {
+		t.Error("Expected error for resolves_to without expected records")
+	}
+}
+
+func TestMatchAddrs(t *testing.T) {
+	addrs := []net.IP{net.ParseIP("192.0.2.1"), net.ParseIP("192.0.2.2")}
+
+	if _, ok := matchAddrs("example.com", "A", "192.0.2.1", addrs, nil); !ok {
+		t.Error("Expected single expected address to match")
+	}
+	if _, ok := matchAddrs("example.com", "A", "192.0.2.2, 192.0.2.1", addrs, nil); !ok {
+		t.Error("Expected comma-separated addresses to match in any order")
+	}
+
+	result, ok := matchAddrs("example.com", "A", "192.0.2.9", addrs, nil)
+	if ok {
+		t.Error("Expected missing record to fail")
+	}
+	if result.Message != "example.com has no A record 192.0.2.9" {
+		t.Errorf("Unexpected message: %q", result.Message)
+	}
+
+	result, ok = matchAddrs("example.com", "AAAA", "2001:db8::1", nil, fmt.Errorf("no such host"))
+	if ok {
+		t.Error("Expected lookup error to fail")
+	}
+	if result.Message != "example.com AAAA lookup failed: no such host" {
+		t.Errorf("Unexpected message: %q", result.Message)
+	}
+}
+
+func TestEnforceNotSupported(t *testing.T) {
+	h := New()
+	ctx := context.Background()
+
+	subject := &ast.ResourceRef{ResourceType: "dns", Path: "example.com"}
+	result := h.Enforce(ctx, subject, "resolves", nil)
+	if result.Error == nil {
+		t.Error("Expected error: DNS records cannot be enforced")
+	}
+}
+
+func TestResolverArgs(t *testing.T) {
+	h := New()
+
+	resolver, timeout, err := h.resolver(nil)
+	if err != nil {
+		t.Fatalf("resolver failed: %v", err)
+	}
+	if resolver != net.DefaultResolver {
+		t.Error("Expected default resolver when no nameserver is given")
+	}
+	if timeout != DefaultLookupTimeout {
+		t.Errorf("Expected default timeout, got %v", timeout)
+	}
+
+	resolver, timeout, err = h.resolver(map[string]string{
+		"nameserver": "192.0.2.53",
+		"timeout":    "1s",
+	})
+	if err != nil {
+		t.Fatalf("resolver failed: %v", err)
+	}
+	if resolver == net.DefaultResolver {
+		t.Error("Expected custom resolver for explicit nameserver")
+	}
+	if timeout.String() != "1s" {
+		t.Errorf("Expected timeout 1s, got %v", timeout)
+	}
+}
@@ -0,0 +1,166 @@
+// Package env provides the environment variable handler for EnsuraScript.
+package env
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+
+	"github.com/ensurascript/ensura/pkg/ast"
+	"github.com/ensurascript/ensura/pkg/runtime"
+)
+
+// Handler implements environment variable operations.
+type Handler struct{}
+
+// New creates a new env handler.
+func New() *Handler {
+	return &Handler{}
+}
+
+// Name returns the handler name.
+func (h *Handler) Name() string {
+	return "env.native"
+}
+
+// SupportedConditions lists the conditions this handler understands.
+func (h *Handler) SupportedConditions() []string {
+	return []string{"set", "equals", "matches"}
+}
+
+// Check verifies an environment variable condition.
+func (h *Handler) Check(ctx context.Context, subject *ast.ResourceRef, condition string, args map[string]string) runtime.HandlerResult {
+	if subject == nil {
+		return runtime.HandlerResult{
+			Success: false,
+			Error:   fmt.Errorf("no subject specified"),
+		}
+	}
+
+	name := subject.Path
+	value, present := os.LookupEnv(name)
+
+	switch condition {
+	case "set":
+		if !present {
+			return runtime.HandlerResult{
+				Success: false,
+				Message: fmt.Sprintf("%s is not set", name),
+			}
+		}
+		return runtime.HandlerResult{
+			Success: true,
+			Message: fmt.Sprintf("%s is set", name),
+		}
+
+	case "equals":
+		expected, ok := args["expected"]
+		if !ok {
+			return runtime.HandlerResult{
+				Success: false,
+				Error:   fmt.Errorf("expected argument required"),
+			}
+		}
+		if !present {
+			return runtime.HandlerResult{
+				Success: false,
+				Message: fmt.Sprintf("%s is not set", name),
+			}
+		}
+		if value != expected {
+			return runtime.HandlerResult{
+				Success: false,
+				Message: fmt.Sprintf("%s = %q, expected %q", name, value, expected),
+			}
+		}
+		return runtime.HandlerResult{
+			Success: true,
+			Message: fmt.Sprintf("%s = %q", name, value),
+		}
+
+	case "matches":
+		pattern := args["pattern"]
+		if pattern == "" {
+			return runtime.HandlerResult{
+				Success: false,
+				Error:   fmt.Errorf("pattern argument required"),
+			}
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return runtime.HandlerResult{
+				Success: false,
+				Error:   fmt.Errorf("invalid pattern %q: %w", pattern, err),
+			}
+		}
+		if !present {
+			return runtime.HandlerResult{
+				Success: false,
+				Message: fmt.Sprintf("%s is not set", name),
+			}
+		}
+		if !re.MatchString(value) {
+			return runtime.HandlerResult{
+				Success: false,
+				Message: fmt.Sprintf("%s does not match pattern %s", name, pattern),
+			}
+		}
+		return runtime.HandlerResult{
+			Success: true,
+			Message: fmt.Sprintf("%s matches pattern %s", name, pattern),
+		}
+
+	default:
+		return runtime.HandlerResult{
+			Success: false,
+			Error:   fmt.Errorf("unknown condition: %s", condition),
+		}
+	}
+}
+
+// Enforce sets the variable in the running process, where later steps
+// (exec handlers, key resolution) will see it. The matches condition cannot
+// be enforced because a pattern does not determine a value.
+func (h *Handler) Enforce(ctx context.Context, subject *ast.ResourceRef, condition string, args map[string]string) runtime.HandlerResult {
+	if subject == nil {
+		return runtime.HandlerResult{
+			Success: false,
+			Error:   fmt.Errorf("no subject specified"),
+		}
+	}
+
+	name := subject.Path
+
+	switch condition {
+	case "set", "equals":
+		value, ok := args["value"]
+		if !ok {
+			if condition == "equals" {
+				value, ok = args["expected"]
+			}
+			if !ok {
+				return runtime.HandlerResult{
+					Success: false,
+					Error:   fmt.Errorf("value argument required for enforcement"),
+				}
+			}
+		}
+		if err := os.Setenv(name, value); err != nil {
+			return runtime.HandlerResult{
+				Success: false,
+				Error:   err,
+			}
+		}
+		return runtime.HandlerResult{
+			Success: true,
+			Message: fmt.Sprintf("set %s", name),
+		}
+
+	default:
+		return runtime.HandlerResult{
+			Success: false,
+			Error:   fmt.Errorf("cannot enforce condition: %s", condition),
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package env
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/ensurascript/ensura/pkg/ast"
+)
+
+func subject(name string) *ast.ResourceRef {
+	return &ast.ResourceRef{ResourceType: "env", Path: name}
+}
+
+func TestCheckSet(t *testing.T) {
+	h := New()
+	ctx := context.Background()
+	t.Setenv("ENSURA_TEST_SET", "1")
+
+	result := h.Check(ctx, subject("ENSURA_TEST_SET"), "set", map[string]string{})
+	if !result.Success {
+		t.Errorf("Check() should pass for a set variable: %v", result.Message)
+	}
+
+	result = h.Check(ctx, subject("ENSURA_TEST_UNSET"), "set", map[string]string{})
+	if result.Success {
+		t.Error("Check() should fail for an unset variable")
+	}
+}
+
+func TestCheckEquals(t *testing.T) {
+	h := New()
+	ctx := context.Background()
+	t.Setenv("ENSURA_TEST_EQ", "production")
+
+	result := h.Check(ctx, subject("ENSURA_TEST_EQ"), "equals", map[string]string{"expected": "production"})
+	if !result.Success {
+		t.Errorf("Check() should pass for matching value: %v", result.Message)
+	}
+
+	result = h.Check(ctx, subject("ENSURA_TEST_EQ"), "equals", map[string]string{"expected": "staging"})
+	if result.Success {
+		t.Error("Check() should fail for a different value")
+	}
+
+	result = h.Check(ctx, subject("ENSURA_TEST_EQ"), "equals", map[string]string{})
+	if result.Error == nil {
+		t.Error("Check() should error without expected argument")
+	}
+}
+
+func TestCheckMatches(t *testing.T) {
+	h := New()
+	ctx := context.Background()
+	t.Setenv("ENSURA_TEST_RE", "v1.2.3")
+
+	result := h.Check(ctx, subject("ENSURA_TEST_RE"), "matches", map[string]string{"pattern": `^v\d+\.\d+\.\d+$`})
+	if !result.Success {
+		t.Errorf("Check() should pass for matching pattern: %v", result.Message)
+	}
+
+	result = h.Check(ctx, subject("ENSURA_TEST_RE"), "matches", map[string]string{"pattern": `^release-`})
+	if result.Success {
+		t.Error("Check() should fail for a non-matching pattern")
+	}
+
+	result = h.Check(ctx, subject("ENSURA_TEST_RE"), "matches", map[string]string{"pattern": `([`})
+	if result.Error == nil {
+		t.Error("Check() should error on an invalid pattern")
+	}
+}
+
+func TestEnforceSet(t *testing.T) {
+	h := New()
+	ctx := context.Background()
+	t.Setenv("ENSURA_TEST_ENFORCE", "old")
+
+	result := h.Enforce(ctx, subject("ENSURA_TEST_ENFORCE"), "equals", map[string]string{"expected": "new"})
+	if !result.Success {
+		t.Fatalf("Enforce() failed: %v %v", result.Message, result.Error)
+	}
+	if os.Getenv("ENSURA_TEST_ENFORCE") != "new" {
+		t.Error("Enforce() should have updated the variable")
+	}
+
+	result = h.Enforce(ctx, subject("ENSURA_TEST_ENFORCE"), "matches", map[string]string{"pattern": ".*"})
+	if result.Error == nil {
+		t.Error("Enforce() should refuse to enforce matches")
+	}
+
+	result = h.Enforce(ctx, subject("ENSURA_TEST_ENFORCE"), "set", map[string]string{})
+	if result.Error == nil {
+		t.Error("Enforce() should error without a value argument")
+	}
+}
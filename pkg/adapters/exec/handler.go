@@ -0,0 +1,121 @@
+// Package exec provides the shell command handler for EnsuraScript.
+//
+// It lets users express custom guarantees with their own commands:
+//
+//	ensure custom with exec check "test -S /var/run/app.sock" repair "systemctl restart app"
+//
+// Exit code 0 means the condition is satisfied; any output is captured into
+// the result message with sensitive argument values redacted.
+package exec
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/ensurascript/ensura/pkg/ast"
+	pkgruntime "github.com/ensurascript/ensura/pkg/runtime"
+)
+
+// sensitiveArgNames lists argument names whose values are masked in
+// captured command output.
+var sensitiveArgNames = map[string]bool{
+	"key":      true,
+	"password": true,
+	"secret":   true,
+	"token":    true,
+}
+
+// Handler implements shell command checks and repairs.
+type Handler struct{}
+
+// New creates a new exec handler.
+func New() *Handler {
+	return &Handler{}
+}
+
+// Name returns the handler name.
+func (h *Handler) Name() string {
+	return "exec"
+}
+
+// SupportedConditions returns nil: the exec handler runs user-supplied
+// commands and accepts any condition.
+func (h *Handler) SupportedConditions() []string {
+	return nil
+}
+
+// Check runs the check command; exit code 0 means satisfied.
+func (h *Handler) Check(ctx context.Context, subject *ast.ResourceRef, condition string, args map[string]string) pkgruntime.HandlerResult {
+	command := args["check"]
+	if command == "" {
+		return pkgruntime.HandlerResult{
+			Success: false,
+			Error:   fmt.Errorf("check argument required"),
+		}
+	}
+	return h.run(ctx, command, args)
+}
+
+// Enforce runs the repair command; exit code 0 means the repair succeeded.
+func (h *Handler) Enforce(ctx context.Context, subject *ast.ResourceRef, condition string, args map[string]string) pkgruntime.HandlerResult {
+	command := args["repair"]
+	if command == "" {
+		return pkgruntime.HandlerResult{
+			Success: false,
+			Error:   fmt.Errorf("repair argument required for enforcement"),
+		}
+	}
+	return h.run(ctx, command, args)
+}
+
+func (h *Handler) run(ctx context.Context, command string, args map[string]string) pkgruntime.HandlerResult {
+	if runtime.GOOS == "windows" {
+		return pkgruntime.HandlerResult{
+			Success: false,
+			Error:   fmt.Errorf("exec handler not supported on %s", runtime.GOOS),
+		}
+	}
+
+	var output bytes.Buffer
+	cmd := exec.CommandContext(ctx, "/bin/sh", "-c", command)
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+
+	err := cmd.Run()
+	message := redactOutput(strings.TrimSpace(output.String()), args)
+
+	if err != nil {
+		if _, ok := err.(*exec.ExitError); ok {
+			return pkgruntime.HandlerResult{
+				Success: false,
+				Message: message,
+			}
+		}
+		return pkgruntime.HandlerResult{
+			Success: false,
+			Message: message,
+			Error:   fmt.Errorf("failed to run command: %w", err),
+		}
+	}
+
+	return pkgruntime.HandlerResult{
+		Success: true,
+		Message: message,
+	}
+}
+
+// redactOutput masks occurrences of sensitive argument values in command
+// output so secrets passed to commands do not leak into logs.
+func redactOutput(output string, args map[string]string) string {
+	for name, value := range args {
+		if value == "" || !sensitiveArgNames[strings.ToLower(name)] {
+			continue
+		}
+		output = strings.ReplaceAll(output, value, "[REDACTED]")
+	}
+	return output
+}
@@ -0,0 +1,91 @@
+package exec
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestCheckExitCode(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("exec handler not supported on windows")
+	}
+
+	h := New()
+	ctx := context.Background()
+
+	result := h.Check(ctx, nil, "custom", map[string]string{"check": "true"})
+	if !result.Success {
+		t.Errorf("Expected success for exit 0, got failure: %v", result.Error)
+	}
+
+	result = h.Check(ctx, nil, "custom", map[string]string{"check": "false"})
+	if result.Success {
+		t.Error("Expected failure for non-zero exit")
+	}
+	if result.Error != nil {
+		t.Errorf("Non-zero exit should not be an error, got %v", result.Error)
+	}
+}
+
+func TestCheckCapturesOutput(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("exec handler not supported on windows")
+	}
+
+	h := New()
+	ctx := context.Background()
+
+	result := h.Check(ctx, nil, "custom", map[string]string{"check": "echo hello; echo oops >&2"})
+	if !strings.Contains(result.Message, "hello") || !strings.Contains(result.Message, "oops") {
+		t.Errorf("Expected stdout and stderr in message, got %q", result.Message)
+	}
+}
+
+func TestEnforceRepair(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("exec handler not supported on windows")
+	}
+
+	h := New()
+	ctx := context.Background()
+
+	marker := filepath.Join(t.TempDir(), "repaired")
+	result := h.Enforce(ctx, nil, "custom", map[string]string{"repair": "touch " + marker})
+	if !result.Success {
+		t.Errorf("Expected repair to succeed: %v", result.Error)
+	}
+	if _, err := os.Stat(marker); err != nil {
+		t.Error("Repair command should have created the marker file")
+	}
+
+	// Missing repair command
+	result = h.Enforce(ctx, nil, "custom", nil)
+	if result.Error == nil {
+		t.Error("Expected error for missing repair argument")
+	}
+}
+
+func TestRedactOutput(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("exec handler not supported on windows")
+	}
+
+	h := New()
+	ctx := context.Background()
+
+	args := map[string]string{
+		"check": "echo the password is hunter2",
+		"token": "hunter2",
+	}
+	result := h.Check(ctx, nil, "custom", args)
+	if strings.Contains(result.Message, "hunter2") {
+		t.Errorf("Expected secret redacted from output, got %q", result.Message)
+	}
+	if !strings.Contains(result.Message, "[REDACTED]") {
+		t.Errorf("Expected redaction marker in output, got %q", result.Message)
+	}
+}
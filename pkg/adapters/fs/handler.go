@@ -5,12 +5,18 @@ import (
 	"context"
 	"crypto/sha256"
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/ensurascript/ensura/pkg/ast"
+	"github.com/ensurascript/ensura/pkg/atomicfile"
 	"github.com/ensurascript/ensura/pkg/runtime"
 )
 
@@ -27,6 +33,17 @@ func (h *Handler) Name() string {
 	return "fs.native"
 }
 
+// SupportedConditions lists the conditions this handler understands.
+func (h *Handler) SupportedConditions() []string {
+	return []string{
+		"exists", "readable", "writable", "checksum", "content",
+		"size_below", "size_above", "newer_than", "is_symlink",
+		"empty", "not_empty",
+		"file_count_below", "contains", "no_world_writable",
+		"matches", "json_path",
+	}
+}
+
 // Check verifies a filesystem condition.
 func (h *Handler) Check(ctx context.Context, subject *ast.ResourceRef, condition string, args map[string]string) runtime.HandlerResult {
 	if subject == nil {
@@ -49,6 +66,35 @@ func (h *Handler) Check(ctx context.Context, subject *ast.ResourceRef, condition
 		return h.checkChecksum(path, args["expected"])
 	case "content":
 		return h.checkContent(path, args["expected"])
+	case "size_below":
+		return h.checkSize(path, args["bytes"], true)
+	case "size_above":
+		return h.checkSize(path, args["bytes"], false)
+	case "newer_than":
+		age := args["age"]
+		if age == "" {
+			age = args["within"]
+		}
+		return h.checkNewerThan(path, age)
+	case "is_symlink":
+		return h.checkSymlink(path, args["target"])
+	case "empty":
+		return h.checkEmpty(path, true)
+	case "not_empty":
+		return h.checkEmpty(path, false)
+	case "file_count_below":
+		return h.checkFileCount(path, args["count"])
+	case "contains":
+		if args["substring"] != "" {
+			return h.checkContainsText(path, args["substring"])
+		}
+		return h.checkContains(path, args["name"])
+	case "matches":
+		return h.checkMatches(path, args["pattern"])
+	case "json_path":
+		return h.checkJSONPath(path, args["path"], args["expected"])
+	case "no_world_writable":
+		return h.checkNoWorldWritable(path)
 	default:
 		return runtime.HandlerResult{
 			Success: false,
@@ -72,7 +118,9 @@ func (h *Handler) Enforce(ctx context.Context, subject *ast.ResourceRef, conditi
 	case "exists":
 		return h.enforceExists(path, subject.ResourceType)
 	case "content":
-		return h.enforceContent(path, args["content"])
+		return h.enforceContent(path, args["content"], args["backup"] == "true")
+	case "no_world_writable":
+		return h.enforceNoWorldWritable(path)
 	default:
 		return runtime.HandlerResult{
 			Success: false,
@@ -194,6 +242,421 @@ func (h *Handler) checkContent(path, expected string) runtime.HandlerResult {
 	}
 }
 
+func (h *Handler) checkSize(path, limit string, below bool) runtime.HandlerResult {
+	if limit == "" {
+		return runtime.HandlerResult{
+			Success: false,
+			Error:   fmt.Errorf("bytes argument not specified"),
+		}
+	}
+	max, err := strconv.ParseInt(limit, 10, 64)
+	if err != nil {
+		return runtime.HandlerResult{
+			Success: false,
+			Error:   fmt.Errorf("invalid bytes value %q: %w", limit, err),
+		}
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return runtime.HandlerResult{
+			Success: false,
+			Error:   err,
+		}
+	}
+
+	size := info.Size()
+	if below {
+		if size < max {
+			return runtime.HandlerResult{
+				Success: true,
+				Message: fmt.Sprintf("%s is %d bytes (below %d)", path, size, max),
+			}
+		}
+		return runtime.HandlerResult{
+			Success: false,
+			Message: fmt.Sprintf("%s is %d bytes, expected below %d", path, size, max),
+		}
+	}
+	if size > max {
+		return runtime.HandlerResult{
+			Success: true,
+			Message: fmt.Sprintf("%s is %d bytes (above %d)", path, size, max),
+		}
+	}
+	return runtime.HandlerResult{
+		Success: false,
+		Message: fmt.Sprintf("%s is %d bytes, expected above %d", path, size, max),
+	}
+}
+
+func (h *Handler) checkNewerThan(path, age string) runtime.HandlerResult {
+	if age == "" {
+		return runtime.HandlerResult{
+			Success: false,
+			Error:   fmt.Errorf("age argument not specified"),
+		}
+	}
+	maxAge, err := time.ParseDuration(age)
+	if err != nil {
+		return runtime.HandlerResult{
+			Success: false,
+			Error:   fmt.Errorf("invalid age %q: %w", age, err),
+		}
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return runtime.HandlerResult{
+			Success: false,
+			Error:   err,
+		}
+	}
+
+	elapsed := time.Since(info.ModTime())
+	if elapsed <= maxAge {
+		return runtime.HandlerResult{
+			Success: true,
+			Message: fmt.Sprintf("%s was modified %s ago", path, elapsed.Round(time.Second)),
+		}
+	}
+	return runtime.HandlerResult{
+		Success: false,
+		Message: fmt.Sprintf("%s was modified %s ago, expected within %s", path, elapsed.Round(time.Second), maxAge),
+	}
+}
+
+func (h *Handler) checkSymlink(path, target string) runtime.HandlerResult {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return runtime.HandlerResult{
+			Success: false,
+			Error:   err,
+		}
+	}
+	if info.Mode()&os.ModeSymlink == 0 {
+		return runtime.HandlerResult{
+			Success: false,
+			Message: fmt.Sprintf("%s is not a symlink", path),
+		}
+	}
+
+	if target != "" {
+		actual, err := os.Readlink(path)
+		if err != nil {
+			return runtime.HandlerResult{
+				Success: false,
+				Error:   err,
+			}
+		}
+		if actual != target {
+			return runtime.HandlerResult{
+				Success: false,
+				Message: fmt.Sprintf("%s points to %s, expected %s", path, actual, target),
+			}
+		}
+	}
+
+	return runtime.HandlerResult{
+		Success: true,
+		Message: fmt.Sprintf("%s is a symlink", path),
+	}
+}
+
+func (h *Handler) checkEmpty(path string, wantEmpty bool) runtime.HandlerResult {
+	info, err := os.Stat(path)
+	if err != nil {
+		return runtime.HandlerResult{
+			Success: false,
+			Error:   err,
+		}
+	}
+
+	var empty bool
+	if info.IsDir() {
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return runtime.HandlerResult{
+				Success: false,
+				Error:   err,
+			}
+		}
+		empty = len(entries) == 0
+	} else {
+		empty = info.Size() == 0
+	}
+
+	if empty == wantEmpty {
+		state := "empty"
+		if !empty {
+			state = "not empty"
+		}
+		return runtime.HandlerResult{
+			Success: true,
+			Message: fmt.Sprintf("%s is %s", path, state),
+		}
+	}
+	if wantEmpty {
+		return runtime.HandlerResult{
+			Success: false,
+			Message: fmt.Sprintf("%s is not empty", path),
+		}
+	}
+	return runtime.HandlerResult{
+		Success: false,
+		Message: fmt.Sprintf("%s is empty", path),
+	}
+}
+
+func (h *Handler) checkContainsText(path, substring string) runtime.HandlerResult {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return runtime.HandlerResult{
+			Success: false,
+			Error:   err,
+		}
+	}
+
+	if strings.Contains(string(data), substring) {
+		return runtime.HandlerResult{
+			Success: true,
+			Message: fmt.Sprintf("%s contains %q", path, substring),
+		}
+	}
+	return runtime.HandlerResult{
+		Success: false,
+		Message: fmt.Sprintf("%s does not contain %q", path, substring),
+	}
+}
+
+func (h *Handler) checkMatches(path, pattern string) runtime.HandlerResult {
+	if pattern == "" {
+		return runtime.HandlerResult{
+			Success: false,
+			Error:   fmt.Errorf("pattern argument not specified"),
+		}
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return runtime.HandlerResult{
+			Success: false,
+			Error:   fmt.Errorf("invalid pattern %q: %w", pattern, err),
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return runtime.HandlerResult{
+			Success: false,
+			Error:   err,
+		}
+	}
+
+	if re.Match(data) {
+		return runtime.HandlerResult{
+			Success: true,
+			Message: fmt.Sprintf("%s matches %q", path, pattern),
+		}
+	}
+	return runtime.HandlerResult{
+		Success: false,
+		Message: fmt.Sprintf("%s does not match %q", path, pattern),
+	}
+}
+
+func (h *Handler) checkJSONPath(path, jsonPath, expected string) runtime.HandlerResult {
+	if jsonPath == "" {
+		return runtime.HandlerResult{
+			Success: false,
+			Error:   fmt.Errorf("path argument not specified"),
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return runtime.HandlerResult{
+			Success: false,
+			Error:   err,
+		}
+	}
+
+	var doc interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return runtime.HandlerResult{
+			Success: false,
+			Error:   fmt.Errorf("invalid JSON in %s: %w", path, err),
+		}
+	}
+
+	value, err := lookupJSONPath(doc, jsonPath)
+	if err != nil {
+		return runtime.HandlerResult{
+			Success: false,
+			Message: fmt.Sprintf("%s: %v", path, err),
+		}
+	}
+
+	actual := fmt.Sprintf("%v", value)
+	if actual == expected {
+		return runtime.HandlerResult{
+			Success: true,
+			Message: fmt.Sprintf("%s: %s is %s", path, jsonPath, actual),
+		}
+	}
+	return runtime.HandlerResult{
+		Success: false,
+		Message: fmt.Sprintf("%s: %s is %s, expected %s", path, jsonPath, actual, expected),
+	}
+}
+
+// lookupJSONPath walks a dot-separated path through decoded JSON. Segments
+// index objects by key and arrays by decimal position (e.g. "servers.0.host").
+func lookupJSONPath(doc interface{}, path string) (interface{}, error) {
+	current := doc
+	for _, segment := range strings.Split(path, ".") {
+		switch node := current.(type) {
+		case map[string]interface{}:
+			value, ok := node[segment]
+			if !ok {
+				return nil, fmt.Errorf("key %q not found", segment)
+			}
+			current = value
+		case []interface{}:
+			index, err := strconv.Atoi(segment)
+			if err != nil {
+				return nil, fmt.Errorf("array index expected, got %q", segment)
+			}
+			if index < 0 || index >= len(node) {
+				return nil, fmt.Errorf("array index %d out of range", index)
+			}
+			current = node[index]
+		default:
+			return nil, fmt.Errorf("cannot descend into %T at %q", current, segment)
+		}
+	}
+	return current, nil
+}
+
+func (h *Handler) checkFileCount(path, limit string) runtime.HandlerResult {
+	if limit == "" {
+		return runtime.HandlerResult{
+			Success: false,
+			Error:   fmt.Errorf("count argument not specified"),
+		}
+	}
+	max, err := strconv.Atoi(limit)
+	if err != nil {
+		return runtime.HandlerResult{
+			Success: false,
+			Error:   fmt.Errorf("invalid count %q: %w", limit, err),
+		}
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return runtime.HandlerResult{
+			Success: false,
+			Error:   err,
+		}
+	}
+
+	if len(entries) < max {
+		return runtime.HandlerResult{
+			Success: true,
+			Message: fmt.Sprintf("%s has %d entries (below %d)", path, len(entries), max),
+		}
+	}
+	return runtime.HandlerResult{
+		Success: false,
+		Message: fmt.Sprintf("%s has %d entries, expected below %d", path, len(entries), max),
+	}
+}
+
+func (h *Handler) checkContains(path, name string) runtime.HandlerResult {
+	if name == "" {
+		return runtime.HandlerResult{
+			Success: false,
+			Error:   fmt.Errorf("name argument not specified"),
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(path, name)); err != nil {
+		if os.IsNotExist(err) {
+			return runtime.HandlerResult{
+				Success: false,
+				Message: fmt.Sprintf("%s does not contain %s", path, name),
+			}
+		}
+		return runtime.HandlerResult{
+			Success: false,
+			Error:   err,
+		}
+	}
+	return runtime.HandlerResult{
+		Success: true,
+		Message: fmt.Sprintf("%s contains %s", path, name),
+	}
+}
+
+func (h *Handler) checkNoWorldWritable(path string) runtime.HandlerResult {
+	var offenders []string
+	err := filepath.Walk(path, func(entry string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.Mode().Perm()&0002 != 0 {
+			offenders = append(offenders, entry)
+		}
+		return nil
+	})
+	if err != nil {
+		return runtime.HandlerResult{
+			Success: false,
+			Error:   err,
+		}
+	}
+
+	if len(offenders) == 0 {
+		return runtime.HandlerResult{
+			Success: true,
+			Message: fmt.Sprintf("no world-writable entries under %s", path),
+		}
+	}
+	return runtime.HandlerResult{
+		Success: false,
+		Message: fmt.Sprintf("world-writable entries under %s: %s", path, strings.Join(offenders, ", ")),
+	}
+}
+
+func (h *Handler) enforceNoWorldWritable(path string) runtime.HandlerResult {
+	var fixed int
+	err := filepath.Walk(path, func(entry string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		mode := info.Mode()
+		if mode.Perm()&0002 == 0 {
+			return nil
+		}
+		if err := os.Chmod(entry, mode.Perm()&^0002); err != nil {
+			return err
+		}
+		fixed++
+		return nil
+	})
+	if err != nil {
+		return runtime.HandlerResult{
+			Success: false,
+			Error:   err,
+		}
+	}
+
+	return runtime.HandlerResult{
+		Success: true,
+		Message: fmt.Sprintf("removed world-writable bit from %d entries under %s", fixed, path),
+	}
+}
+
 func (h *Handler) enforceExists(path, resourceType string) runtime.HandlerResult {
 	if resourceType == "directory" {
 		err := os.MkdirAll(path, 0755)
@@ -240,8 +703,13 @@ func (h *Handler) enforceExists(path, resourceType string) runtime.HandlerResult
 	}
 }
 
-func (h *Handler) enforceContent(path, content string) runtime.HandlerResult {
-	err := os.WriteFile(path, []byte(content), 0644)
+func (h *Handler) enforceContent(path, content string, backup bool) runtime.HandlerResult {
+	var err error
+	if backup {
+		err = atomicfile.WriteWithBackup(path, []byte(content), 0644)
+	} else {
+		err = atomicfile.Write(path, []byte(content), 0644)
+	}
 	if err != nil {
 		return runtime.HandlerResult{
 			Success: false,
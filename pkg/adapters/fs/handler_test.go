@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/ensurascript/ensura/pkg/ast"
 )
@@ -175,3 +176,283 @@ func TestNilSubject(t *testing.T) {
 		t.Error("Expected error for nil subject")
 	}
 }
+
+func TestCheckSize(t *testing.T) {
+	h := New()
+	ctx := context.Background()
+
+	tmpDir := t.TempDir()
+	tmpFile := filepath.Join(tmpDir, "sized.txt")
+	if err := os.WriteFile(tmpFile, []byte("0123456789"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	subject := &ast.ResourceRef{Path: tmpFile, ResourceType: "file"}
+	result := h.Check(ctx, subject, "size_below", map[string]string{"bytes": "100"})
+	if !result.Success {
+		t.Errorf("Expected size_below 100 to pass for 10-byte file: %s", result.Message)
+	}
+	result = h.Check(ctx, subject, "size_below", map[string]string{"bytes": "5"})
+	if result.Success {
+		t.Error("Expected size_below 5 to fail for 10-byte file")
+	}
+	result = h.Check(ctx, subject, "size_above", map[string]string{"bytes": "5"})
+	if !result.Success {
+		t.Errorf("Expected size_above 5 to pass for 10-byte file: %s", result.Message)
+	}
+	result = h.Check(ctx, subject, "size_below", map[string]string{"bytes": "many"})
+	if result.Error == nil {
+		t.Error("Expected error for invalid bytes value")
+	}
+}
+
+func TestCheckNewerThan(t *testing.T) {
+	h := New()
+	ctx := context.Background()
+
+	tmpDir := t.TempDir()
+	tmpFile := filepath.Join(tmpDir, "fresh.txt")
+	if err := os.WriteFile(tmpFile, []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	subject := &ast.ResourceRef{Path: tmpFile, ResourceType: "file"}
+	result := h.Check(ctx, subject, "newer_than", map[string]string{"age": "1h"})
+	if !result.Success {
+		t.Errorf("Expected newer_than 1h to pass for fresh file: %s", result.Message)
+	}
+
+	old := time.Now().Add(-2 * time.Hour)
+	if err := os.Chtimes(tmpFile, old, old); err != nil {
+		t.Fatal(err)
+	}
+	result = h.Check(ctx, subject, "newer_than", map[string]string{"age": "1h"})
+	if result.Success {
+		t.Error("Expected newer_than 1h to fail for 2-hour-old file")
+	}
+}
+
+func TestCheckSymlink(t *testing.T) {
+	h := New()
+	ctx := context.Background()
+
+	tmpDir := t.TempDir()
+	target := filepath.Join(tmpDir, "target.txt")
+	link := filepath.Join(tmpDir, "link")
+	if err := os.WriteFile(target, []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(target, link); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+
+	subject := &ast.ResourceRef{Path: link, ResourceType: "file"}
+	result := h.Check(ctx, subject, "is_symlink", nil)
+	if !result.Success {
+		t.Errorf("Expected is_symlink to pass for symlink: %s", result.Message)
+	}
+	result = h.Check(ctx, subject, "is_symlink", map[string]string{"target": target})
+	if !result.Success {
+		t.Errorf("Expected is_symlink with matching target to pass: %s", result.Message)
+	}
+	result = h.Check(ctx, subject, "is_symlink", map[string]string{"target": "/elsewhere"})
+	if result.Success {
+		t.Error("Expected is_symlink with wrong target to fail")
+	}
+
+	subject = &ast.ResourceRef{Path: target, ResourceType: "file"}
+	result = h.Check(ctx, subject, "is_symlink", nil)
+	if result.Success {
+		t.Error("Expected is_symlink to fail for regular file")
+	}
+}
+
+func TestCheckEmpty(t *testing.T) {
+	h := New()
+	ctx := context.Background()
+
+	tmpDir := t.TempDir()
+	emptyFile := filepath.Join(tmpDir, "empty.txt")
+	if err := os.WriteFile(emptyFile, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	fullFile := filepath.Join(tmpDir, "full.txt")
+	if err := os.WriteFile(fullFile, []byte("data"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	subject := &ast.ResourceRef{Path: emptyFile, ResourceType: "file"}
+	if result := h.Check(ctx, subject, "empty", nil); !result.Success {
+		t.Errorf("Expected empty to pass for empty file: %s", result.Message)
+	}
+	if result := h.Check(ctx, subject, "not_empty", nil); result.Success {
+		t.Error("Expected not_empty to fail for empty file")
+	}
+
+	subject = &ast.ResourceRef{Path: fullFile, ResourceType: "file"}
+	if result := h.Check(ctx, subject, "not_empty", nil); !result.Success {
+		t.Errorf("Expected not_empty to pass for non-empty file: %s", result.Message)
+	}
+
+	// Directory with one entry is not empty
+	subject = &ast.ResourceRef{Path: tmpDir, ResourceType: "directory"}
+	if result := h.Check(ctx, subject, "empty", nil); result.Success {
+		t.Error("Expected empty to fail for populated directory")
+	}
+}
+
+func TestCheckFileCount(t *testing.T) {
+	h := New()
+	ctx := context.Background()
+
+	tmpDir := t.TempDir()
+	for _, name := range []string{"a", "b", "c"} {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	subject := &ast.ResourceRef{Path: tmpDir, ResourceType: "directory"}
+	result := h.Check(ctx, subject, "file_count_below", map[string]string{"count": "5"})
+	if !result.Success {
+		t.Errorf("Expected file_count_below 5 to pass for 3 entries: %s", result.Message)
+	}
+	result = h.Check(ctx, subject, "file_count_below", map[string]string{"count": "3"})
+	if result.Success {
+		t.Error("Expected file_count_below 3 to fail for 3 entries")
+	}
+	result = h.Check(ctx, subject, "file_count_below", nil)
+	if result.Error == nil {
+		t.Error("Expected error for missing count argument")
+	}
+}
+
+func TestCheckContains(t *testing.T) {
+	h := New()
+	ctx := context.Background()
+
+	tmpDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tmpDir, "present.txt"), []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	subject := &ast.ResourceRef{Path: tmpDir, ResourceType: "directory"}
+	result := h.Check(ctx, subject, "contains", map[string]string{"name": "present.txt"})
+	if !result.Success {
+		t.Errorf("Expected contains to pass for existing entry: %s", result.Message)
+	}
+	result = h.Check(ctx, subject, "contains", map[string]string{"name": "missing.txt"})
+	if result.Success {
+		t.Error("Expected contains to fail for missing entry")
+	}
+}
+
+func TestCheckContainsText(t *testing.T) {
+	h := New()
+	ctx := context.Background()
+
+	tmpDir := t.TempDir()
+	tmpFile := filepath.Join(tmpDir, "config.ini")
+	if err := os.WriteFile(tmpFile, []byte("mode = production\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	subject := &ast.ResourceRef{Path: tmpFile, ResourceType: "file"}
+	result := h.Check(ctx, subject, "contains", map[string]string{"substring": "production"})
+	if !result.Success {
+		t.Errorf("Expected contains to pass for present substring: %s", result.Message)
+	}
+	result = h.Check(ctx, subject, "contains", map[string]string{"substring": "debug"})
+	if result.Success {
+		t.Error("Expected contains to fail for absent substring")
+	}
+}
+
+func TestCheckMatches(t *testing.T) {
+	h := New()
+	ctx := context.Background()
+
+	tmpDir := t.TempDir()
+	tmpFile := filepath.Join(tmpDir, "config.ini")
+	if err := os.WriteFile(tmpFile, []byte("port = 8080\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	subject := &ast.ResourceRef{Path: tmpFile, ResourceType: "file"}
+	result := h.Check(ctx, subject, "matches", map[string]string{"pattern": `port\s*=\s*\d+`})
+	if !result.Success {
+		t.Errorf("Expected matches to pass: %s", result.Message)
+	}
+	result = h.Check(ctx, subject, "matches", map[string]string{"pattern": `host\s*=`})
+	if result.Success {
+		t.Error("Expected matches to fail for absent pattern")
+	}
+	result = h.Check(ctx, subject, "matches", map[string]string{"pattern": "["})
+	if result.Error == nil {
+		t.Error("Expected error for invalid pattern")
+	}
+}
+
+func TestCheckJSONPath(t *testing.T) {
+	h := New()
+	ctx := context.Background()
+
+	tmpDir := t.TempDir()
+	tmpFile := filepath.Join(tmpDir, "config.json")
+	content := `{"server": {"port": 8080, "hosts": ["a.example", "b.example"]}}`
+	if err := os.WriteFile(tmpFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	subject := &ast.ResourceRef{Path: tmpFile, ResourceType: "file"}
+	result := h.Check(ctx, subject, "json_path", map[string]string{"path": "server.port", "expected": "8080"})
+	if !result.Success {
+		t.Errorf("Expected json_path on server.port to pass: %s", result.Message)
+	}
+	result = h.Check(ctx, subject, "json_path", map[string]string{"path": "server.hosts.1", "expected": "b.example"})
+	if !result.Success {
+		t.Errorf("Expected json_path with array index to pass: %s", result.Message)
+	}
+	result = h.Check(ctx, subject, "json_path", map[string]string{"path": "server.port", "expected": "9090"})
+	if result.Success {
+		t.Error("Expected json_path to fail on wrong value")
+	}
+	result = h.Check(ctx, subject, "json_path", map[string]string{"path": "server.missing", "expected": "x"})
+	if result.Success {
+		t.Error("Expected json_path to fail on missing key")
+	}
+}
+
+func TestNoWorldWritable(t *testing.T) {
+	h := New()
+	ctx := context.Background()
+
+	tmpDir := t.TempDir()
+	sub := filepath.Join(tmpDir, "sub")
+	if err := os.Mkdir(sub, 0755); err != nil {
+		t.Fatal(err)
+	}
+	loose := filepath.Join(sub, "loose.txt")
+	if err := os.WriteFile(loose, []byte("x"), 0646); err != nil {
+		t.Fatal(err)
+	}
+	if info, err := os.Stat(loose); err != nil || info.Mode().Perm()&0002 == 0 {
+		t.Skip("umask prevents creating world-writable files")
+	}
+
+	subject := &ast.ResourceRef{Path: tmpDir, ResourceType: "directory"}
+	result := h.Check(ctx, subject, "no_world_writable", nil)
+	if result.Success {
+		t.Error("Expected no_world_writable to fail with a world-writable file")
+	}
+
+	result = h.Enforce(ctx, subject, "no_world_writable", nil)
+	if !result.Success {
+		t.Errorf("Expected enforce to succeed: %v", result.Error)
+	}
+
+	result = h.Check(ctx, subject, "no_world_writable", nil)
+	if !result.Success {
+		t.Errorf("Expected no_world_writable to pass after enforce: %s", result.Message)
+	}
+}
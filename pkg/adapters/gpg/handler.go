@@ -0,0 +1,170 @@
+// Package gpg provides OpenPGP encryption handling for EnsuraScript.
+package gpg
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/ensurascript/ensura/pkg/ast"
+	"github.com/ensurascript/ensura/pkg/runtime"
+)
+
+// armorHeader is the first line of an ASCII-armored OpenPGP message.
+var armorHeader = []byte("-----BEGIN PGP MESSAGE-----")
+
+// Handler implements the encrypted condition using the gpg tool.
+type Handler struct{}
+
+// New creates a new gpg handler.
+func New() *Handler {
+	return &Handler{}
+}
+
+// Name returns the handler name.
+func (h *Handler) Name() string {
+	return "gpg"
+}
+
+// SupportedConditions lists the conditions this handler understands.
+func (h *Handler) SupportedConditions() []string {
+	return []string{"encrypted"}
+}
+
+// Check verifies the file is OpenPGP-encrypted, either ASCII armored or a
+// binary message starting with an encrypted session key packet.
+func (h *Handler) Check(ctx context.Context, subject *ast.ResourceRef, condition string, args map[string]string) runtime.HandlerResult {
+	if subject == nil {
+		return runtime.HandlerResult{
+			Success: false,
+			Error:   fmt.Errorf("no subject specified"),
+		}
+	}
+
+	if condition != "encrypted" {
+		return runtime.HandlerResult{
+			Success: false,
+			Error:   fmt.Errorf("unknown condition: %s", condition),
+		}
+	}
+
+	f, err := os.Open(subject.Path)
+	if err != nil {
+		return runtime.HandlerResult{
+			Success: false,
+			Error:   err,
+		}
+	}
+	defer f.Close()
+
+	header := make([]byte, len(armorHeader))
+	n, _ := f.Read(header)
+	if isPGPMessage(header[:n]) {
+		return runtime.HandlerResult{
+			Success: true,
+			Message: fmt.Sprintf("%s is gpg-encrypted", subject.Path),
+		}
+	}
+
+	return runtime.HandlerResult{
+		Success: false,
+		Message: fmt.Sprintf("%s is not gpg-encrypted", subject.Path),
+	}
+}
+
+// isPGPMessage recognizes armored messages and binary OpenPGP packets whose
+// leading tag is an encrypted session key (public-key tag 1, symmetric tag 3).
+func isPGPMessage(data []byte) bool {
+	if bytes.HasPrefix(data, armorHeader) {
+		return true
+	}
+	if len(data) == 0 || data[0]&0x80 == 0 {
+		return false
+	}
+	var tag byte
+	if data[0]&0x40 != 0 {
+		tag = data[0] & 0x3F // new packet format
+	} else {
+		tag = (data[0] >> 2) & 0x0F // old packet format
+	}
+	return tag == 1 || tag == 3
+}
+
+// Enforce encrypts the file in place with the gpg tool. The recipient arg
+// selects the public key; armor "true" produces ASCII output.
+func (h *Handler) Enforce(ctx context.Context, subject *ast.ResourceRef, condition string, args map[string]string) runtime.HandlerResult {
+	if subject == nil {
+		return runtime.HandlerResult{
+			Success: false,
+			Error:   fmt.Errorf("no subject specified"),
+		}
+	}
+
+	if condition != "encrypted" {
+		return runtime.HandlerResult{
+			Success: false,
+			Error:   fmt.Errorf("cannot enforce condition: %s", condition),
+		}
+	}
+
+	if result := h.Check(ctx, subject, condition, args); result.Success {
+		return runtime.HandlerResult{
+			Success: true,
+			Message: fmt.Sprintf("%s is already encrypted", subject.Path),
+		}
+	}
+
+	recipient := args["recipient"]
+	if recipient == "" {
+		return runtime.HandlerResult{
+			Success: false,
+			Error:   fmt.Errorf("recipient argument required"),
+		}
+	}
+
+	if _, err := exec.LookPath("gpg"); err != nil {
+		return runtime.HandlerResult{
+			Success: false,
+			Error:   fmt.Errorf("gpg tool not found in PATH"),
+		}
+	}
+
+	path := subject.Path
+	tmp := filepath.Join(filepath.Dir(path), "."+filepath.Base(path)+".gpg")
+	cmdArgs := []string{"--batch", "--yes", "--recipient", recipient, "--output", tmp}
+	if args["armor"] == "true" {
+		cmdArgs = append(cmdArgs, "--armor")
+	}
+	cmdArgs = append(cmdArgs, "--encrypt", path)
+
+	cmd := exec.CommandContext(ctx, "gpg", cmdArgs...)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		os.Remove(tmp)
+		return runtime.HandlerResult{
+			Success: false,
+			Message: strings.TrimSpace(string(output)),
+			Error:   fmt.Errorf("gpg encryption failed: %w", err),
+		}
+	}
+
+	if info, err := os.Stat(path); err == nil {
+		os.Chmod(tmp, info.Mode().Perm())
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return runtime.HandlerResult{
+			Success: false,
+			Error:   err,
+		}
+	}
+
+	return runtime.HandlerResult{
+		Success: true,
+		Message: fmt.Sprintf("encrypted %s with gpg", path),
+	}
+}
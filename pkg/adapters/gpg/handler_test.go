@@ -0,0 +1,84 @@
+package gpg
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ensurascript/ensura/pkg/ast"
+)
+
+func TestCheckEncrypted(t *testing.T) {
+	h := New()
+	ctx := context.Background()
+
+	tmpDir := t.TempDir()
+	armored := filepath.Join(tmpDir, "secret.asc")
+	if err := os.WriteFile(armored, []byte("-----BEGIN PGP MESSAGE-----\n...\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	subject := &ast.ResourceRef{Path: armored, ResourceType: "file"}
+	result := h.Check(ctx, subject, "encrypted", nil)
+	if !result.Success {
+		t.Errorf("Expected armored message to pass check: %s", result.Message)
+	}
+
+	// Binary message: old-format public-key encrypted session key packet
+	binary := filepath.Join(tmpDir, "secret.gpg")
+	if err := os.WriteFile(binary, []byte{0x84, 0x8c, 0x03, 0x00}, 0600); err != nil {
+		t.Fatal(err)
+	}
+	subject = &ast.ResourceRef{Path: binary, ResourceType: "file"}
+	result = h.Check(ctx, subject, "encrypted", nil)
+	if !result.Success {
+		t.Errorf("Expected binary message to pass check: %s", result.Message)
+	}
+
+	plainFile := filepath.Join(tmpDir, "plain.txt")
+	if err := os.WriteFile(plainFile, []byte("plain text"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	subject = &ast.ResourceRef{Path: plainFile, ResourceType: "file"}
+	result = h.Check(ctx, subject, "encrypted", nil)
+	if result.Success {
+		t.Error("Expected plain file to fail check")
+	}
+}
+
+func TestIsPGPMessage(t *testing.T) {
+	cases := []struct {
+		name string
+		data []byte
+		want bool
+	}{
+		{"empty", nil, false},
+		{"old format tag 1", []byte{0x84}, true},
+		{"new format tag 3", []byte{0xC3}, true},
+		{"old format tag 6 (public key)", []byte{0x98}, false},
+		{"ascii text", []byte("hello"), false},
+	}
+	for _, tc := range cases {
+		if got := isPGPMessage(tc.data); got != tc.want {
+			t.Errorf("%s: isPGPMessage = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestEnforceRequiresRecipient(t *testing.T) {
+	h := New()
+	ctx := context.Background()
+
+	tmpDir := t.TempDir()
+	plainFile := filepath.Join(tmpDir, "plain.txt")
+	if err := os.WriteFile(plainFile, []byte("plain text"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	subject := &ast.ResourceRef{Path: plainFile, ResourceType: "file"}
+	result := h.Enforce(ctx, subject, "encrypted", nil)
+	if result.Error == nil {
+		t.Error("Expected error for missing recipient argument")
+	}
+}
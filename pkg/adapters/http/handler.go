@@ -4,9 +4,14 @@ package http
 import (
 	"context"
 	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
+	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/ensurascript/ensura/pkg/ast"
@@ -32,6 +37,14 @@ func (h *Handler) Name() string {
 	return "http.get"
 }
 
+// SupportedConditions lists the conditions this handler understands.
+func (h *Handler) SupportedConditions() []string {
+	return []string{
+		"reachable", "status_code", "tls", "cert_expires_after", "cert_issuer",
+		"body_contains", "json_path", "latency_below",
+	}
+}
+
 // Check verifies an HTTP endpoint condition.
 func (h *Handler) Check(ctx context.Context, subject *ast.ResourceRef, condition string, args map[string]string) runtime.HandlerResult {
 	if subject == nil {
@@ -45,11 +58,21 @@ func (h *Handler) Check(ctx context.Context, subject *ast.ResourceRef, condition
 
 	switch condition {
 	case "reachable":
-		return h.checkReachable(ctx, url)
+		return h.checkReachable(ctx, url, args)
 	case "status_code":
-		return h.checkStatusCode(ctx, url, args["expected_status"])
+		return h.checkStatusCode(ctx, url, args)
 	case "tls":
 		return h.checkTLS(ctx, url)
+	case "cert_expires_after":
+		return h.checkCertExpiresAfter(ctx, url, args["duration"])
+	case "cert_issuer":
+		return h.checkCertIssuer(ctx, url, args["issuer"])
+	case "body_contains":
+		return h.checkBodyContains(ctx, url, args)
+	case "json_path":
+		return h.checkJSONPath(ctx, url, args)
+	case "latency_below":
+		return h.checkLatencyBelow(ctx, url, args)
 	default:
 		return runtime.HandlerResult{
 			Success: false,
@@ -66,16 +89,8 @@ func (h *Handler) Enforce(ctx context.Context, subject *ast.ResourceRef, conditi
 	}
 }
 
-func (h *Handler) checkReachable(ctx context.Context, url string) runtime.HandlerResult {
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
-	if err != nil {
-		return runtime.HandlerResult{
-			Success: false,
-			Error:   err,
-		}
-	}
-
-	resp, err := h.client.Do(req)
+func (h *Handler) checkReachable(ctx context.Context, url string, args map[string]string) runtime.HandlerResult {
+	resp, err := h.do(ctx, url, args)
 	if err != nil {
 		return runtime.HandlerResult{
 			Success: false,
@@ -99,7 +114,8 @@ func (h *Handler) checkReachable(ctx context.Context, url string) runtime.Handle
 	}
 }
 
-func (h *Handler) checkStatusCode(ctx context.Context, url, expectedStatus string) runtime.HandlerResult {
+func (h *Handler) checkStatusCode(ctx context.Context, url string, args map[string]string) runtime.HandlerResult {
+	expectedStatus := args["expected_status"]
 	if expectedStatus == "" {
 		expectedStatus = "200"
 	}
@@ -112,15 +128,7 @@ func (h *Handler) checkStatusCode(ctx context.Context, url, expectedStatus strin
 		}
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
-	if err != nil {
-		return runtime.HandlerResult{
-			Success: false,
-			Error:   err,
-		}
-	}
-
-	resp, err := h.client.Do(req)
+	resp, err := h.do(ctx, url, args)
 	if err != nil {
 		return runtime.HandlerResult{
 			Success: false,
@@ -185,6 +193,402 @@ func (h *Handler) checkTLS(ctx context.Context, url string) runtime.HandlerResul
 	}
 }
 
+func (h *Handler) checkCertExpiresAfter(ctx context.Context, url, duration string) runtime.HandlerResult {
+	if duration == "" {
+		return runtime.HandlerResult{
+			Success: false,
+			Error:   fmt.Errorf("duration argument not specified"),
+		}
+	}
+	minRemaining, err := time.ParseDuration(duration)
+	if err != nil {
+		return runtime.HandlerResult{
+			Success: false,
+			Error:   fmt.Errorf("invalid duration %q: %w", duration, err),
+		}
+	}
+
+	cert, result := h.peerCertificate(ctx, url)
+	if cert == nil {
+		return result
+	}
+
+	remaining := time.Until(cert.NotAfter)
+	if remaining > minRemaining {
+		return runtime.HandlerResult{
+			Success: true,
+			Message: fmt.Sprintf("%s certificate expires in %s", url, remaining.Round(time.Hour)),
+		}
+	}
+	return runtime.HandlerResult{
+		Success: false,
+		Message: fmt.Sprintf("%s certificate expires in %s, expected more than %s", url, remaining.Round(time.Hour), minRemaining),
+	}
+}
+
+func (h *Handler) checkCertIssuer(ctx context.Context, url, issuer string) runtime.HandlerResult {
+	if issuer == "" {
+		return runtime.HandlerResult{
+			Success: false,
+			Error:   fmt.Errorf("issuer argument not specified"),
+		}
+	}
+
+	cert, result := h.peerCertificate(ctx, url)
+	if cert == nil {
+		return result
+	}
+
+	if cert.Issuer.CommonName == issuer {
+		return runtime.HandlerResult{
+			Success: true,
+			Message: fmt.Sprintf("%s certificate issued by %s", url, issuer),
+		}
+	}
+	return runtime.HandlerResult{
+		Success: false,
+		Message: fmt.Sprintf("%s certificate issued by %q, expected %q", url, cert.Issuer.CommonName, issuer),
+	}
+}
+
+func (h *Handler) checkBodyContains(ctx context.Context, url string, args map[string]string) runtime.HandlerResult {
+	substring := args["substring"]
+	if substring == "" {
+		return runtime.HandlerResult{
+			Success: false,
+			Error:   fmt.Errorf("substring argument not specified"),
+		}
+	}
+
+	body, result := h.fetchBody(ctx, url, args)
+	if body == nil {
+		return result
+	}
+
+	if strings.Contains(string(body), substring) {
+		return runtime.HandlerResult{
+			Success: true,
+			Message: fmt.Sprintf("%s response contains %q", url, substring),
+		}
+	}
+	return runtime.HandlerResult{
+		Success: false,
+		Message: fmt.Sprintf("%s response does not contain %q", url, substring),
+	}
+}
+
+func (h *Handler) checkJSONPath(ctx context.Context, url string, args map[string]string) runtime.HandlerResult {
+	jsonPath := args["path"]
+	if jsonPath == "" {
+		return runtime.HandlerResult{
+			Success: false,
+			Error:   fmt.Errorf("path argument not specified"),
+		}
+	}
+
+	body, result := h.fetchBody(ctx, url, args)
+	if body == nil {
+		return result
+	}
+
+	var doc interface{}
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return runtime.HandlerResult{
+			Success: false,
+			Error:   fmt.Errorf("invalid JSON from %s: %w", url, err),
+		}
+	}
+
+	value, err := lookupJSONPath(doc, jsonPath)
+	if err != nil {
+		return runtime.HandlerResult{
+			Success: false,
+			Message: fmt.Sprintf("%s: %v", url, err),
+		}
+	}
+
+	actual := fmt.Sprintf("%v", value)
+	expected := args["expected"]
+	if actual == expected {
+		return runtime.HandlerResult{
+			Success: true,
+			Message: fmt.Sprintf("%s: %s is %s", url, jsonPath, actual),
+		}
+	}
+	return runtime.HandlerResult{
+		Success: false,
+		Message: fmt.Sprintf("%s: %s is %s, expected %s", url, jsonPath, actual, expected),
+	}
+}
+
+// checkLatencyBelow measures response time (request start to headers
+// received) over a configurable number of samples and compares the average
+// against the duration argument.
+func (h *Handler) checkLatencyBelow(ctx context.Context, url string, args map[string]string) runtime.HandlerResult {
+	duration := args["duration"]
+	if duration == "" {
+		return runtime.HandlerResult{
+			Success: false,
+			Error:   fmt.Errorf("duration argument not specified"),
+		}
+	}
+	maxLatency, err := time.ParseDuration(duration)
+	if err != nil {
+		return runtime.HandlerResult{
+			Success: false,
+			Error:   fmt.Errorf("invalid duration %q: %w", duration, err),
+		}
+	}
+
+	samples := 1
+	if args["samples"] != "" {
+		samples, err = strconv.Atoi(args["samples"])
+		if err != nil || samples < 1 {
+			return runtime.HandlerResult{
+				Success: false,
+				Error:   fmt.Errorf("invalid samples value %q", args["samples"]),
+			}
+		}
+	}
+
+	client := h.clientFor(args)
+	var total time.Duration
+	for i := 0; i < samples; i++ {
+		req, err := h.buildRequest(ctx, url, args)
+		if err != nil {
+			return runtime.HandlerResult{
+				Success: false,
+				Error:   err,
+			}
+		}
+
+		start := time.Now()
+		resp, err := client.Do(req)
+		if err != nil {
+			return runtime.HandlerResult{
+				Success: false,
+				Message: fmt.Sprintf("%s is not reachable", url),
+				Error:   err,
+			}
+		}
+		resp.Body.Close()
+		total += time.Since(start)
+	}
+
+	average := total / time.Duration(samples)
+	if average < maxLatency {
+		return runtime.HandlerResult{
+			Success: true,
+			Message: fmt.Sprintf("%s responded in %s on average over %d samples", url, average.Round(time.Millisecond), samples),
+		}
+	}
+	return runtime.HandlerResult{
+		Success: false,
+		Message: fmt.Sprintf("%s responded in %s on average, expected below %s", url, average.Round(time.Millisecond), maxLatency),
+	}
+}
+
+// fetchBody performs the request and reads the full response body. On
+// failure the body is nil and the returned result carries the error.
+func (h *Handler) fetchBody(ctx context.Context, url string, args map[string]string) ([]byte, runtime.HandlerResult) {
+	resp, err := h.do(ctx, url, args)
+	if err != nil {
+		return nil, runtime.HandlerResult{
+			Success: false,
+			Message: fmt.Sprintf("%s is not reachable", url),
+			Error:   err,
+		}
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, runtime.HandlerResult{
+			Success: false,
+			Error:   err,
+		}
+	}
+	return body, runtime.HandlerResult{}
+}
+
+// buildRequest constructs the request from handler args: method (default
+// GET), body, header_* args (header_content_type becomes Content-Type), and
+// auth_env, which names an environment variable whose value is sent as the
+// Authorization header so secrets stay out of scripts.
+func (h *Handler) buildRequest(ctx context.Context, url string, args map[string]string) (*http.Request, error) {
+	method := args["method"]
+	if method == "" {
+		method = http.MethodGet
+	} else {
+		method = strings.ToUpper(method)
+	}
+
+	var body io.Reader
+	if args["body"] != "" {
+		body = strings.NewReader(args["body"])
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return nil, err
+	}
+
+	for key, value := range args {
+		if !strings.HasPrefix(key, "header_") {
+			continue
+		}
+		name := strings.ReplaceAll(strings.TrimPrefix(key, "header_"), "_", "-")
+		req.Header.Set(name, value)
+	}
+
+	if envVar := args["auth_env"]; envVar != "" {
+		value := os.Getenv(envVar)
+		if value == "" {
+			return nil, fmt.Errorf("auth_env variable %s is not set", envVar)
+		}
+		req.Header.Set("Authorization", value)
+	}
+
+	return req, nil
+}
+
+// clientFor returns the shared client, or a copy configured from handler
+// args: timeout (duration), follow_redirects ("false" stops at the first
+// response), and insecure_skip_verify ("true" accepts any certificate, for
+// internal endpoints with private CAs).
+func (h *Handler) clientFor(args map[string]string) *http.Client {
+	timeout := h.client.Timeout
+	if args["timeout"] != "" {
+		if parsed, err := time.ParseDuration(args["timeout"]); err == nil {
+			timeout = parsed
+		}
+	}
+
+	skipVerify := args["insecure_skip_verify"] == "true"
+	noRedirects := args["follow_redirects"] == "false"
+
+	if timeout == h.client.Timeout && !skipVerify && !noRedirects {
+		return h.client
+	}
+
+	client := &http.Client{Timeout: timeout}
+	if noRedirects {
+		client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		}
+	}
+	if skipVerify {
+		client.Transport = &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		}
+	}
+	return client
+}
+
+// do builds and performs the request, retrying failed attempts when the
+// retries arg is set. retry_interval controls the pause between attempts
+// (default 1s).
+func (h *Handler) do(ctx context.Context, url string, args map[string]string) (*http.Response, error) {
+	retries := 0
+	if args["retries"] != "" {
+		parsed, err := strconv.Atoi(args["retries"])
+		if err != nil || parsed < 0 {
+			return nil, fmt.Errorf("invalid retries value %q", args["retries"])
+		}
+		retries = parsed
+	}
+
+	interval := time.Second
+	if args["retry_interval"] != "" {
+		parsed, err := time.ParseDuration(args["retry_interval"])
+		if err != nil {
+			return nil, fmt.Errorf("invalid retry_interval %q: %w", args["retry_interval"], err)
+		}
+		interval = parsed
+	}
+
+	client := h.clientFor(args)
+	var lastErr error
+	for attempt := 0; attempt <= retries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(interval):
+			}
+		}
+
+		req, err := h.buildRequest(ctx, url, args)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := client.Do(req)
+		if err == nil {
+			return resp, nil
+		}
+		lastErr = err
+	}
+	return nil, lastErr
+}
+
+// lookupJSONPath walks a dot-separated path through decoded JSON. Segments
+// index objects by key and arrays by decimal position (e.g. "checks.0.name").
+func lookupJSONPath(doc interface{}, path string) (interface{}, error) {
+	current := doc
+	for _, segment := range strings.Split(path, ".") {
+		switch node := current.(type) {
+		case map[string]interface{}:
+			value, ok := node[segment]
+			if !ok {
+				return nil, fmt.Errorf("key %q not found", segment)
+			}
+			current = value
+		case []interface{}:
+			index, err := strconv.Atoi(segment)
+			if err != nil {
+				return nil, fmt.Errorf("array index expected, got %q", segment)
+			}
+			if index < 0 || index >= len(node) {
+				return nil, fmt.Errorf("array index %d out of range", index)
+			}
+			current = node[index]
+		default:
+			return nil, fmt.Errorf("cannot descend into %T at %q", current, segment)
+		}
+	}
+	return current, nil
+}
+
+// peerCertificate fetches the endpoint's leaf certificate. On failure the
+// certificate is nil and the returned result carries the error.
+func (h *Handler) peerCertificate(ctx context.Context, url string) (*x509.Certificate, runtime.HandlerResult) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+	if err != nil {
+		return nil, runtime.HandlerResult{
+			Success: false,
+			Error:   err,
+		}
+	}
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return nil, runtime.HandlerResult{
+			Success: false,
+			Message: fmt.Sprintf("%s is not reachable", url),
+			Error:   err,
+		}
+	}
+	defer resp.Body.Close()
+
+	if resp.TLS == nil || len(resp.TLS.PeerCertificates) == 0 {
+		return nil, runtime.HandlerResult{
+			Success: false,
+			Message: fmt.Sprintf("%s is not using TLS", url),
+		}
+	}
+	return resp.TLS.PeerCertificates[0], runtime.HandlerResult{}
+}
+
 func tlsVersionString(version uint16) string {
 	switch version {
 	case tls.VersionTLS10:
@@ -0,0 +1,449 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ensurascript/ensura/pkg/ast"
+)
+
+// testHandler returns a handler whose client trusts the test server's
+// certificate.
+func testHandler(srv *httptest.Server) *Handler {
+	return &Handler{client: srv.Client()}
+}
+
+func okServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+}
+
+func subjectFor(srv *httptest.Server) *ast.ResourceRef {
+	return &ast.ResourceRef{ResourceType: "http", Path: srv.URL}
+}
+
+func TestCheckErrors(t *testing.T) {
+	h := New()
+	ctx := context.Background()
+
+	result := h.Check(ctx, nil, "reachable", nil)
+	if result.Error == nil {
+		t.Error("Expected error for missing subject")
+	}
+
+	subject := &ast.ResourceRef{ResourceType: "http", Path: "http://127.0.0.1:1"}
+	result = h.Check(ctx, subject, "bogus", nil)
+	if result.Error == nil {
+		t.Error("Expected error for unknown condition")
+	}
+}
+
+func TestEnforceNotSupported(t *testing.T) {
+	h := New()
+	ctx := context.Background()
+
+	subject := &ast.ResourceRef{ResourceType: "http", Path: "http://127.0.0.1:1"}
+	result := h.Enforce(ctx, subject, "reachable", nil)
+	if result.Error == nil {
+		t.Error("Expected error: HTTP endpoints cannot be enforced")
+	}
+}
+
+func TestCertExpiresAfter(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	h := testHandler(srv)
+	ctx := context.Background()
+	subject := subjectFor(srv)
+
+	// The httptest certificate is valid for decades
+	result := h.Check(ctx, subject, "cert_expires_after", map[string]string{"duration": "1h"})
+	if !result.Success {
+		t.Errorf("Expected cert to outlive 1h: %s (%v)", result.Message, result.Error)
+	}
+
+	result = h.Check(ctx, subject, "cert_expires_after", map[string]string{"duration": "1000000h"})
+	if result.Success {
+		t.Error("Expected cert not to outlive 1000000h")
+	}
+
+	result = h.Check(ctx, subject, "cert_expires_after", nil)
+	if result.Error == nil {
+		t.Error("Expected error for missing duration")
+	}
+	result = h.Check(ctx, subject, "cert_expires_after", map[string]string{"duration": "soon"})
+	if result.Error == nil {
+		t.Error("Expected error for invalid duration")
+	}
+}
+
+func TestCertIssuer(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	h := testHandler(srv)
+	ctx := context.Background()
+	subject := subjectFor(srv)
+
+	result := h.Check(ctx, subject, "cert_issuer", map[string]string{"issuer": "Example CA"})
+	if result.Success {
+		t.Error("Expected issuer mismatch for the httptest certificate")
+	}
+	if !strings.Contains(result.Message, "expected") {
+		t.Errorf("Expected mismatch message, got %q", result.Message)
+	}
+
+	result = h.Check(ctx, subject, "cert_issuer", nil)
+	if result.Error == nil {
+		t.Error("Expected error for missing issuer")
+	}
+}
+
+func TestCertChecksRequireTLS(t *testing.T) {
+	srv := okServer()
+	defer srv.Close()
+
+	h := testHandler(srv)
+	ctx := context.Background()
+
+	result := h.Check(ctx, subjectFor(srv), "cert_expires_after", map[string]string{"duration": "1h"})
+	if result.Success {
+		t.Error("Expected failure for plain HTTP endpoint")
+	}
+	if !strings.Contains(result.Message, "not using TLS") {
+		t.Errorf("Expected not-using-TLS message, got %q", result.Message)
+	}
+}
+
+func TestCheckStatusCode(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	h := testHandler(srv)
+	ctx := context.Background()
+	subject := subjectFor(srv)
+
+	result := h.Check(ctx, subject, "status_code", map[string]string{"expected_status": "404"})
+	if !result.Success {
+		t.Errorf("Expected matching status to succeed: %s", result.Message)
+	}
+
+	result = h.Check(ctx, subject, "status_code", map[string]string{"expected_status": "200"})
+	if result.Success {
+		t.Error("Expected mismatched status to fail")
+	}
+
+	result = h.Check(ctx, subject, "status_code", map[string]string{"expected_status": "teapot"})
+	if result.Error == nil {
+		t.Error("Expected error for invalid expected status")
+	}
+}
+
+func TestRequestConstruction(t *testing.T) {
+	var gotMethod, gotAuth, gotContentType, gotBody string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotMethod = r.Method
+		gotAuth = r.Header.Get("Authorization")
+		gotContentType = r.Header.Get("Content-Type")
+		body, _ := io.ReadAll(r.Body)
+		gotBody = string(body)
+	}))
+	defer srv.Close()
+
+	h := testHandler(srv)
+	ctx := context.Background()
+	t.Setenv("ENSURA_TEST_TOKEN", "Bearer token123")
+
+	result := h.Check(ctx, subjectFor(srv), "reachable", map[string]string{
+		"method":              "post",
+		"body":                `{"ping":true}`,
+		"header_content_type": "application/json",
+		"auth_env":            "ENSURA_TEST_TOKEN",
+	})
+	if !result.Success {
+		t.Fatalf("Expected request to succeed: %s (%v)", result.Message, result.Error)
+	}
+
+	if gotMethod != "POST" {
+		t.Errorf("Expected method POST, got %q", gotMethod)
+	}
+	if gotAuth != "Bearer token123" {
+		t.Errorf("Expected Authorization from auth_env, got %q", gotAuth)
+	}
+	if gotContentType != "application/json" {
+		t.Errorf("Expected Content-Type header, got %q", gotContentType)
+	}
+	if gotBody != `{"ping":true}` {
+		t.Errorf("Expected request body sent, got %q", gotBody)
+	}
+
+	// An unset auth_env variable must not send an empty header
+	result = h.Check(ctx, subjectFor(srv), "reachable", map[string]string{"auth_env": "ENSURA_TEST_UNSET"})
+	if result.Error == nil {
+		t.Error("Expected error for unset auth_env variable")
+	}
+}
+
+func TestBodyContains(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "service healthy, all systems go")
+	}))
+	defer srv.Close()
+
+	h := testHandler(srv)
+	ctx := context.Background()
+	subject := subjectFor(srv)
+
+	result := h.Check(ctx, subject, "body_contains", map[string]string{"substring": "healthy"})
+	if !result.Success {
+		t.Errorf("Expected substring to match: %s", result.Message)
+	}
+
+	result = h.Check(ctx, subject, "body_contains", map[string]string{"substring": "degraded"})
+	if result.Success {
+		t.Error("Expected missing substring to fail")
+	}
+
+	result = h.Check(ctx, subject, "body_contains", nil)
+	if result.Error == nil {
+		t.Error("Expected error for missing substring argument")
+	}
+}
+
+func TestJSONPath(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"status": "ok", "checks": [{"name": "db", "up": true}]}`)
+	}))
+	defer srv.Close()
+
+	h := testHandler(srv)
+	ctx := context.Background()
+	subject := subjectFor(srv)
+
+	result := h.Check(ctx, subject, "json_path", map[string]string{"path": "status", "expected": "ok"})
+	if !result.Success {
+		t.Errorf("Expected status path to match: %s", result.Message)
+	}
+
+	result = h.Check(ctx, subject, "json_path", map[string]string{"path": "checks.0.name", "expected": "db"})
+	if !result.Success {
+		t.Errorf("Expected array path to match: %s", result.Message)
+	}
+
+	result = h.Check(ctx, subject, "json_path", map[string]string{"path": "status", "expected": "down"})
+	if result.Success {
+		t.Error("Expected value mismatch to fail")
+	}
+
+	result = h.Check(ctx, subject, "json_path", map[string]string{"path": "missing", "expected": "x"})
+	if result.Success {
+		t.Error("Expected missing key to fail")
+	}
+
+	result = h.Check(ctx, subject, "json_path", nil)
+	if result.Error == nil {
+		t.Error("Expected error for missing path argument")
+	}
+}
+
+func TestLookupJSONPath(t *testing.T) {
+	var doc interface{}
+	if err := json.Unmarshal([]byte(`{"items": [1, 2, 3]}`), &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	value, err := lookupJSONPath(doc, "items.2")
+	if err != nil || fmt.Sprintf("%v", value) != "3" {
+		t.Errorf("Expected items.2 == 3, got %v (%v)", value, err)
+	}
+
+	if _, err := lookupJSONPath(doc, "items.9"); err == nil {
+		t.Error("Expected error for out-of-range index")
+	}
+	if _, err := lookupJSONPath(doc, "items.first"); err == nil {
+		t.Error("Expected error for non-numeric array index")
+	}
+	if _, err := lookupJSONPath(doc, "items.0.deeper"); err == nil {
+		t.Error("Expected error when descending into a scalar")
+	}
+}
+
+func TestLatencyBelow(t *testing.T) {
+	var requests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+	}))
+	defer srv.Close()
+
+	h := testHandler(srv)
+	ctx := context.Background()
+	subject := subjectFor(srv)
+
+	result := h.Check(ctx, subject, "latency_below", map[string]string{"duration": "5s"})
+	if !result.Success {
+		t.Errorf("Expected local server to respond within 5s: %s", result.Message)
+	}
+
+	atomic.StoreInt32(&requests, 0)
+	result = h.Check(ctx, subject, "latency_below", map[string]string{"duration": "5s", "samples": "3"})
+	if !result.Success {
+		t.Errorf("Expected sampled check to succeed: %s", result.Message)
+	}
+	if got := atomic.LoadInt32(&requests); got != 3 {
+		t.Errorf("Expected 3 samples, got %d requests", got)
+	}
+
+	result = h.Check(ctx, subject, "latency_below", nil)
+	if result.Error == nil {
+		t.Error("Expected error for missing duration")
+	}
+	result = h.Check(ctx, subject, "latency_below", map[string]string{"duration": "fast"})
+	if result.Error == nil {
+		t.Error("Expected error for invalid duration")
+	}
+	result = h.Check(ctx, subject, "latency_below", map[string]string{"duration": "1s", "samples": "0"})
+	if result.Error == nil {
+		t.Error("Expected error for invalid samples value")
+	}
+}
+
+func TestLatencyBelowExceeded(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(30 * time.Millisecond)
+	}))
+	defer srv.Close()
+
+	h := testHandler(srv)
+	result := h.Check(context.Background(), subjectFor(srv), "latency_below",
+		map[string]string{"duration": "1ms"})
+	if result.Success {
+		t.Error("Expected slow endpoint to exceed the latency bound")
+	}
+	if !strings.Contains(result.Message, "expected below") {
+		t.Errorf("Expected latency message, got %q", result.Message)
+	}
+}
+
+func TestRetries(t *testing.T) {
+	// The first attempt gets its connection closed mid-request; the
+	// retry succeeds.
+	var attempts int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			conn, _, err := w.(http.Hijacker).Hijack()
+			if err == nil {
+				conn.Close()
+			}
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	h := testHandler(srv)
+	ctx := context.Background()
+	subject := subjectFor(srv)
+
+	result := h.Check(ctx, subject, "reachable", map[string]string{
+		"retries":        "2",
+		"retry_interval": "10ms",
+	})
+	if !result.Success {
+		t.Errorf("Expected retry to recover: %s (%v)", result.Message, result.Error)
+	}
+	if got := atomic.LoadInt32(&attempts); got != 2 {
+		t.Errorf("Expected 2 attempts, got %d", got)
+	}
+
+	result = h.Check(ctx, subject, "reachable", map[string]string{"retries": "-1"})
+	if result.Error == nil {
+		t.Error("Expected error for invalid retries value")
+	}
+	result = h.Check(ctx, subject, "reachable", map[string]string{"retries": "1", "retry_interval": "soon"})
+	if result.Error == nil {
+		t.Error("Expected error for invalid retry_interval")
+	}
+}
+
+func TestTimeoutOption(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer srv.Close()
+
+	h := testHandler(srv)
+	ctx := context.Background()
+	subject := subjectFor(srv)
+
+	result := h.Check(ctx, subject, "reachable", map[string]string{"timeout": "20ms"})
+	if result.Success {
+		t.Error("Expected slow endpoint to exceed the per-check timeout")
+	}
+
+	result = h.Check(ctx, subject, "reachable", map[string]string{"timeout": "2s"})
+	if !result.Success {
+		t.Errorf("Expected endpoint within the timeout: %s (%v)", result.Message, result.Error)
+	}
+}
+
+func TestFollowRedirects(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/ok", http.StatusFound)
+	})
+	mux.HandleFunc("/ok", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	h := testHandler(srv)
+	ctx := context.Background()
+	subject := subjectFor(srv)
+
+	// Redirects are followed by default
+	result := h.Check(ctx, subject, "status_code", map[string]string{"expected_status": "200"})
+	if !result.Success {
+		t.Errorf("Expected redirect to be followed: %s", result.Message)
+	}
+
+	result = h.Check(ctx, subject, "status_code", map[string]string{
+		"expected_status":  "302",
+		"follow_redirects": "false",
+	})
+	if !result.Success {
+		t.Errorf("Expected first response when redirects are off: %s", result.Message)
+	}
+}
+
+func TestInsecureSkipVerify(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	// The default client does not trust the self-signed test certificate
+	h := New()
+	ctx := context.Background()
+	subject := subjectFor(srv)
+
+	result := h.Check(ctx, subject, "reachable", nil)
+	if result.Success {
+		t.Error("Expected untrusted certificate to fail verification")
+	}
+
+	result = h.Check(ctx, subject, "reachable", map[string]string{"insecure_skip_verify": "true"})
+	if !result.Success {
+		t.Errorf("Expected insecure_skip_verify to accept the certificate: %s (%v)", result.Message, result.Error)
+	}
+}
@@ -0,0 +1,217 @@
+// Package mount provides the filesystem mount handler for EnsuraScript.
+package mount
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/ensurascript/ensura/pkg/ast"
+	"github.com/ensurascript/ensura/pkg/runtime"
+)
+
+// Handler implements mount point and disk space operations.
+type Handler struct{}
+
+// New creates a new mount handler.
+func New() *Handler {
+	return &Handler{}
+}
+
+// Name returns the handler name.
+func (h *Handler) Name() string {
+	return "mount.native"
+}
+
+// SupportedConditions lists the conditions this handler understands.
+func (h *Handler) SupportedConditions() []string {
+	return []string{"mounted", "free_space_above"}
+}
+
+// Check verifies a mount condition.
+func (h *Handler) Check(ctx context.Context, subject *ast.ResourceRef, condition string, args map[string]string) runtime.HandlerResult {
+	if subject == nil {
+		return runtime.HandlerResult{
+			Success: false,
+			Error:   fmt.Errorf("no subject specified"),
+		}
+	}
+
+	switch condition {
+	case "mounted":
+		return h.checkMounted(subject.Path, args["device"], args["options"])
+	case "free_space_above":
+		return h.checkFreeSpace(subject.Path, args["bytes"], args["percent"])
+	default:
+		return runtime.HandlerResult{
+			Success: false,
+			Error:   fmt.Errorf("unknown condition: %s", condition),
+		}
+	}
+}
+
+// Enforce ensures a mount condition is met. Only mounted can be enforced;
+// free space cannot be reclaimed automatically.
+func (h *Handler) Enforce(ctx context.Context, subject *ast.ResourceRef, condition string, args map[string]string) runtime.HandlerResult {
+	if subject == nil {
+		return runtime.HandlerResult{
+			Success: false,
+			Error:   fmt.Errorf("no subject specified"),
+		}
+	}
+
+	switch condition {
+	case "mounted":
+		return h.enforceMounted(ctx, subject.Path, args["device"], args["options"])
+	default:
+		return runtime.HandlerResult{
+			Success: false,
+			Error:   fmt.Errorf("cannot enforce condition: %s", condition),
+		}
+	}
+}
+
+func (h *Handler) checkMounted(mountpoint, device, options string) runtime.HandlerResult {
+	entry, found, err := mountEntry(mountpoint)
+	if err != nil {
+		return runtime.HandlerResult{
+			Success: false,
+			Error:   fmt.Errorf("failed to read mount table: %w", err),
+		}
+	}
+	if !found {
+		return runtime.HandlerResult{
+			Success: false,
+			Message: fmt.Sprintf("%s is not mounted", mountpoint),
+		}
+	}
+
+	if device != "" && entry.device != device {
+		return runtime.HandlerResult{
+			Success: false,
+			Message: fmt.Sprintf("%s is mounted from %s, expected %s", mountpoint, entry.device, device),
+		}
+	}
+
+	if missing := missingOptions(entry.options, options); len(missing) > 0 {
+		return runtime.HandlerResult{
+			Success: false,
+			Message: fmt.Sprintf("%s is mounted without options: %s", mountpoint, strings.Join(missing, ", ")),
+		}
+	}
+
+	return runtime.HandlerResult{
+		Success: true,
+		Message: fmt.Sprintf("%s is mounted from %s", mountpoint, entry.device),
+	}
+}
+
+func (h *Handler) enforceMounted(ctx context.Context, mountpoint, device, options string) runtime.HandlerResult {
+	if device == "" {
+		return runtime.HandlerResult{
+			Success: false,
+			Error:   fmt.Errorf("device argument required for enforcement"),
+		}
+	}
+
+	args := []string{}
+	if options != "" {
+		args = append(args, "-o", options)
+	}
+	args = append(args, device, mountpoint)
+
+	cmd := exec.CommandContext(ctx, "mount", args...)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return runtime.HandlerResult{
+			Success: false,
+			Error:   fmt.Errorf("mount failed: %w, output: %s", err, string(output)),
+		}
+	}
+
+	return runtime.HandlerResult{
+		Success: true,
+		Message: fmt.Sprintf("mounted %s at %s", device, mountpoint),
+	}
+}
+
+func (h *Handler) checkFreeSpace(mountpoint, bytesArg, percentArg string) runtime.HandlerResult {
+	if bytesArg == "" && percentArg == "" {
+		return runtime.HandlerResult{
+			Success: false,
+			Error:   fmt.Errorf("bytes or percent argument required"),
+		}
+	}
+
+	free, total, err := freeSpace(mountpoint)
+	if err != nil {
+		return runtime.HandlerResult{
+			Success: false,
+			Error:   fmt.Errorf("failed to stat filesystem: %w", err),
+		}
+	}
+
+	if bytesArg != "" {
+		min, err := strconv.ParseUint(bytesArg, 10, 64)
+		if err != nil {
+			return runtime.HandlerResult{
+				Success: false,
+				Error:   fmt.Errorf("invalid bytes value %q: %w", bytesArg, err),
+			}
+		}
+		if free <= min {
+			return runtime.HandlerResult{
+				Success: false,
+				Message: fmt.Sprintf("%s has %d bytes free, need more than %d", mountpoint, free, min),
+			}
+		}
+	}
+
+	if percentArg != "" {
+		min, err := strconv.ParseFloat(strings.TrimSuffix(percentArg, "%"), 64)
+		if err != nil {
+			return runtime.HandlerResult{
+				Success: false,
+				Error:   fmt.Errorf("invalid percent value %q: %w", percentArg, err),
+			}
+		}
+		if total == 0 {
+			return runtime.HandlerResult{
+				Success: false,
+				Error:   fmt.Errorf("filesystem at %s reports zero size", mountpoint),
+			}
+		}
+		freePct := float64(free) / float64(total) * 100
+		if freePct <= min {
+			return runtime.HandlerResult{
+				Success: false,
+				Message: fmt.Sprintf("%s has %.1f%% free, need more than %.1f%%", mountpoint, freePct, min),
+			}
+		}
+	}
+
+	return runtime.HandlerResult{
+		Success: true,
+		Message: fmt.Sprintf("%s has %d bytes free", mountpoint, free),
+	}
+}
+
+// missingOptions returns the requested mount options (comma-separated) that
+// are not present in the actual options.
+func missingOptions(actual, requested string) []string {
+	if requested == "" {
+		return nil
+	}
+	have := make(map[string]bool)
+	for _, opt := range strings.Split(actual, ",") {
+		have[opt] = true
+	}
+	var missing []string
+	for _, opt := range strings.Split(requested, ",") {
+		if opt != "" && !have[opt] {
+			missing = append(missing, opt)
+		}
+	}
+	return missing
+}
@@ -0,0 +1,92 @@
+package mount
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ensurascript/ensura/pkg/ast"
+)
+
+const sampleMounts = `sysfs /sys sysfs rw,nosuid,nodev,noexec,relatime 0 0
+/dev/sda1 / ext4 rw,relatime,errors=remount-ro 0 0
+/dev/sdb1 /data xfs rw,noatime,nodiratime 0 0
+tmpfs /tmp tmpfs rw,nosuid,nodev 0 0
+`
+
+func TestParseMounts(t *testing.T) {
+	e, found := parseMounts([]byte(sampleMounts), "/data")
+	if !found {
+		t.Fatal("Expected /data to be found")
+	}
+	if e.device != "/dev/sdb1" {
+		t.Errorf("Expected device /dev/sdb1, got %s", e.device)
+	}
+	if e.fstype != "xfs" {
+		t.Errorf("Expected fstype xfs, got %s", e.fstype)
+	}
+	if e.options != "rw,noatime,nodiratime" {
+		t.Errorf("Expected options rw,noatime,nodiratime, got %s", e.options)
+	}
+
+	if _, found := parseMounts([]byte(sampleMounts), "/missing"); found {
+		t.Error("Expected /missing not to be found")
+	}
+}
+
+func TestMissingOptions(t *testing.T) {
+	missing := missingOptions("rw,noatime,nodiratime", "noatime,ro")
+	if len(missing) != 1 || missing[0] != "ro" {
+		t.Errorf("Expected [ro], got %v", missing)
+	}
+
+	if missing := missingOptions("rw,noatime", ""); missing != nil {
+		t.Errorf("Expected nil for no requested options, got %v", missing)
+	}
+}
+
+func TestCheckFreeSpace(t *testing.T) {
+	h := New()
+	ctx := context.Background()
+	subject := &ast.ResourceRef{ResourceType: "mount", Path: t.TempDir()}
+
+	// Any writable temp dir has more than one byte free
+	result := h.Check(ctx, subject, "free_space_above", map[string]string{"bytes": "1"})
+	if !result.Success {
+		t.Errorf("Check() should pass for 1 byte threshold: %v %v", result.Message, result.Error)
+	}
+
+	// And less than 100% free
+	result = h.Check(ctx, subject, "free_space_above", map[string]string{"percent": "100"})
+	if result.Success {
+		t.Error("Check() should fail for 100% threshold")
+	}
+
+	// Threshold argument is required
+	result = h.Check(ctx, subject, "free_space_above", map[string]string{})
+	if result.Error == nil {
+		t.Error("Check() should error without bytes or percent")
+	}
+
+	// Malformed thresholds are errors
+	result = h.Check(ctx, subject, "free_space_above", map[string]string{"bytes": "lots"})
+	if result.Error == nil {
+		t.Error("Check() should error on invalid bytes value")
+	}
+}
+
+func TestCheckNoSubject(t *testing.T) {
+	h := New()
+	result := h.Check(context.Background(), nil, "mounted", map[string]string{})
+	if result.Success || result.Error == nil {
+		t.Error("Check() should fail with no subject")
+	}
+}
+
+func TestEnforceFreeSpace(t *testing.T) {
+	h := New()
+	subject := &ast.ResourceRef{ResourceType: "mount", Path: "/"}
+	result := h.Enforce(context.Background(), subject, "free_space_above", map[string]string{"bytes": "1"})
+	if result.Success || result.Error == nil {
+		t.Error("Enforce() should refuse to enforce free_space_above")
+	}
+}
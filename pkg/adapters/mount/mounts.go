@@ -0,0 +1,32 @@
+package mount
+
+import "strings"
+
+// entry is one line of the mount table.
+type entry struct {
+	device     string
+	mountpoint string
+	fstype     string
+	options    string
+}
+
+// parseMounts parses /proc/mounts content and returns the entry for the
+// given mount point. Octal escapes in paths (\040 for space) are left as-is;
+// callers matching such paths must use the escaped form.
+func parseMounts(data []byte, mountpoint string) (entry, bool) {
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 4 {
+			continue
+		}
+		if fields[1] == mountpoint {
+			return entry{
+				device:     fields[0],
+				mountpoint: fields[1],
+				fstype:     fields[2],
+				options:    fields[3],
+			}, true
+		}
+	}
+	return entry{}, false
+}
@@ -0,0 +1,15 @@
+//go:build linux
+
+package mount
+
+import "os"
+
+// mountEntry looks up the mount table entry for a mount point in /proc/mounts.
+func mountEntry(mountpoint string) (entry, bool, error) {
+	data, err := os.ReadFile("/proc/mounts")
+	if err != nil {
+		return entry{}, false, err
+	}
+	e, found := parseMounts(data, mountpoint)
+	return e, found, nil
+}
@@ -0,0 +1,13 @@
+//go:build !linux
+
+package mount
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// mountEntry is only implemented where a mount table is available.
+func mountEntry(mountpoint string) (entry, bool, error) {
+	return entry{}, false, fmt.Errorf("mount table inspection not supported on %s", runtime.GOOS)
+}
@@ -0,0 +1,11 @@
+//go:build windows
+
+package mount
+
+import "fmt"
+
+// freeSpace is not implemented on Windows, which has no statfs equivalent
+// in the standard library.
+func freeSpace(path string) (free, total uint64, err error) {
+	return 0, 0, fmt.Errorf("disk space inspection not supported on windows")
+}
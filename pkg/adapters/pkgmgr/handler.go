@@ -0,0 +1,241 @@
+// Package pkgmgr provides the installed-software handler for EnsuraScript.
+//
+// It backs the `installed` condition on `package` resources:
+//
+//	ensure installed on package "curl"
+//	ensure installed on package "nginx" with pkg.native version "1.24"
+//
+// The handler detects the system package manager (apt, dnf, brew or apk)
+// and enforces by installing through it when run with sufficient privileges.
+package pkgmgr
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/ensurascript/ensura/pkg/ast"
+	pkgruntime "github.com/ensurascript/ensura/pkg/runtime"
+)
+
+// Handler implements package installation checks.
+type Handler struct{}
+
+// New creates a new package handler.
+func New() *Handler {
+	return &Handler{}
+}
+
+// Name returns the handler name.
+func (h *Handler) Name() string {
+	return "pkg.native"
+}
+
+// SupportedConditions lists the conditions this handler understands.
+func (h *Handler) SupportedConditions() []string {
+	return []string{"installed"}
+}
+
+// Check verifies that a package is installed, optionally at a version.
+func (h *Handler) Check(ctx context.Context, subject *ast.ResourceRef, condition string, args map[string]string) pkgruntime.HandlerResult {
+	if subject == nil || subject.Path == "" {
+		return pkgruntime.HandlerResult{
+			Success: false,
+			Error:   fmt.Errorf("no subject specified"),
+		}
+	}
+
+	if condition != "installed" {
+		return pkgruntime.HandlerResult{
+			Success: false,
+			Error:   fmt.Errorf("unknown condition: %s", condition),
+		}
+	}
+
+	mgr, err := detectManager()
+	if err != nil {
+		return pkgruntime.HandlerResult{Success: false, Error: err}
+	}
+
+	name := subject.Path
+	installed, version := mgr.query(ctx, name)
+	if !installed {
+		return pkgruntime.HandlerResult{
+			Success: false,
+			Message: fmt.Sprintf("package %s is not installed (%s)", name, mgr.name),
+		}
+	}
+
+	if want := args["version"]; want != "" && !strings.HasPrefix(version, want) {
+		return pkgruntime.HandlerResult{
+			Success: false,
+			Message: fmt.Sprintf("package %s is at version %s, expected %s", name, version, want),
+		}
+	}
+
+	if version != "" {
+		return pkgruntime.HandlerResult{
+			Success: true,
+			Message: fmt.Sprintf("package %s %s is installed", name, version),
+		}
+	}
+	return pkgruntime.HandlerResult{
+		Success: true,
+		Message: fmt.Sprintf("package %s is installed", name),
+	}
+}
+
+// Enforce installs the package through the detected package manager.
+func (h *Handler) Enforce(ctx context.Context, subject *ast.ResourceRef, condition string, args map[string]string) pkgruntime.HandlerResult {
+	if subject == nil || subject.Path == "" {
+		return pkgruntime.HandlerResult{
+			Success: false,
+			Error:   fmt.Errorf("no subject specified"),
+		}
+	}
+
+	if condition != "installed" {
+		return pkgruntime.HandlerResult{
+			Success: false,
+			Error:   fmt.Errorf("cannot enforce condition: %s", condition),
+		}
+	}
+
+	mgr, err := detectManager()
+	if err != nil {
+		return pkgruntime.HandlerResult{Success: false, Error: err}
+	}
+
+	name := subject.Path
+	cmdline := mgr.install(name, args["version"])
+	cmd := exec.CommandContext(ctx, cmdline[0], cmdline[1:]...)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return pkgruntime.HandlerResult{
+			Success: false,
+			Message: strings.TrimSpace(string(output)),
+			Error:   fmt.Errorf("failed to install %s via %s: %w", name, mgr.name, err),
+		}
+	}
+
+	return pkgruntime.HandlerResult{
+		Success: true,
+		Message: fmt.Sprintf("installed package %s via %s", name, mgr.name),
+	}
+}
+
+// manager describes one supported package manager.
+type manager struct {
+	name string
+	// query reports whether the package is installed and, when available,
+	// the installed version.
+	query func(ctx context.Context, pkg string) (bool, string)
+	// install returns the command line that installs the package.
+	install func(pkg, version string) []string
+}
+
+// detectManager picks the first package manager available on this system.
+func detectManager() (*manager, error) {
+	switch {
+	case commandExists("dpkg-query"):
+		return &manager{
+			name: "apt",
+			query: func(ctx context.Context, pkg string) (bool, string) {
+				out, err := exec.CommandContext(ctx, "dpkg-query", "-W", "-f", "${Status}\t${Version}", pkg).Output()
+				if err != nil {
+					return false, ""
+				}
+				fields := strings.SplitN(strings.TrimSpace(string(out)), "\t", 2)
+				if !strings.Contains(fields[0], "install ok installed") {
+					return false, ""
+				}
+				if len(fields) == 2 {
+					return true, fields[1]
+				}
+				return true, ""
+			},
+			install: func(pkg, version string) []string {
+				if version != "" {
+					pkg = pkg + "=" + version
+				}
+				return []string{"apt-get", "install", "-y", pkg}
+			},
+		}, nil
+
+	case commandExists("rpm"):
+		installer := "dnf"
+		if !commandExists("dnf") {
+			installer = "yum"
+		}
+		return &manager{
+			name: installer,
+			query: func(ctx context.Context, pkg string) (bool, string) {
+				out, err := exec.CommandContext(ctx, "rpm", "-q", "--qf", "%{VERSION}", pkg).Output()
+				if err != nil {
+					return false, ""
+				}
+				return true, strings.TrimSpace(string(out))
+			},
+			install: func(pkg, version string) []string {
+				if version != "" {
+					pkg = pkg + "-" + version
+				}
+				return []string{installer, "install", "-y", pkg}
+			},
+		}, nil
+
+	case commandExists("brew"):
+		return &manager{
+			name: "brew",
+			query: func(ctx context.Context, pkg string) (bool, string) {
+				out, err := exec.CommandContext(ctx, "brew", "list", "--versions", pkg).Output()
+				if err != nil {
+					return false, ""
+				}
+				fields := strings.Fields(strings.TrimSpace(string(out)))
+				if len(fields) >= 2 {
+					return true, fields[1]
+				}
+				return len(fields) > 0, ""
+			},
+			install: func(pkg, version string) []string {
+				return []string{"brew", "install", pkg}
+			},
+		}, nil
+
+	case commandExists("apk"):
+		return &manager{
+			name: "apk",
+			query: func(ctx context.Context, pkg string) (bool, string) {
+				out, err := exec.CommandContext(ctx, "apk", "list", "--installed", pkg).Output()
+				if err != nil {
+					return false, ""
+				}
+				line := strings.TrimSpace(string(out))
+				if line == "" {
+					return false, ""
+				}
+				// Output looks like: name-1.2.3-r0 x86_64 {...}
+				first := strings.Fields(line)[0]
+				version := strings.TrimPrefix(first, pkg+"-")
+				if version == first {
+					version = ""
+				}
+				return true, version
+			},
+			install: func(pkg, version string) []string {
+				if version != "" {
+					pkg = pkg + "=" + version
+				}
+				return []string{"apk", "add", pkg}
+			},
+		}, nil
+	}
+
+	return nil, fmt.Errorf("no supported package manager found (apt, dnf, brew or apk)")
+}
+
+func commandExists(name string) bool {
+	_, err := exec.LookPath(name)
+	return err == nil
+}
@@ -0,0 +1,43 @@
+package pkgmgr
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ensurascript/ensura/pkg/ast"
+)
+
+func TestCheckErrors(t *testing.T) {
+	h := New()
+	ctx := context.Background()
+
+	result := h.Check(ctx, nil, "installed", nil)
+	if result.Error == nil {
+		t.Error("Expected error for missing subject")
+	}
+
+	subject := &ast.ResourceRef{ResourceType: "package", Path: "curl"}
+	result = h.Check(ctx, subject, "bogus", nil)
+	if result.Error == nil {
+		t.Error("Expected error for unknown condition")
+	}
+}
+
+func TestCheckInstalled(t *testing.T) {
+	if _, err := detectManager(); err != nil {
+		t.Skipf("no package manager available: %v", err)
+	}
+
+	h := New()
+	ctx := context.Background()
+
+	// A package this unlikely to exist should report not installed, not error.
+	subject := &ast.ResourceRef{ResourceType: "package", Path: "ensura-no-such-package-zz"}
+	result := h.Check(ctx, subject, "installed", nil)
+	if result.Success {
+		t.Error("Expected check to fail for nonexistent package")
+	}
+	if result.Error != nil {
+		t.Errorf("Missing package should not be an error, got %v", result.Error)
+	}
+}
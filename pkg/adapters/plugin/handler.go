@@ -0,0 +1,170 @@
+// Package plugin provides the exec-based plugin handler for EnsuraScript.
+//
+// A plugin is an executable speaking a small JSON protocol: it receives one
+// request object on stdin and writes one response object to stdout. This
+// lets users add custom resource types without forking the adapters package.
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/ensurascript/ensura/pkg/ast"
+	pkgruntime "github.com/ensurascript/ensura/pkg/runtime"
+)
+
+// PluginPathEnv is the environment variable holding a list of directories
+// searched for plugin executables, in os.PathListSeparator-separated form.
+const PluginPathEnv = "ENSURA_PLUGIN_PATH"
+
+// request is the JSON message sent to a plugin on stdin.
+type request struct {
+	Action    string            `json:"action"` // "check" or "enforce"
+	Condition string            `json:"condition"`
+	Subject   *subjectRef       `json:"subject,omitempty"`
+	Args      map[string]string `json:"args,omitempty"`
+}
+
+type subjectRef struct {
+	Type string `json:"type,omitempty"`
+	Path string `json:"path,omitempty"`
+}
+
+// response is the JSON message a plugin writes to stdout.
+type response struct {
+	Success bool   `json:"success"`
+	Message string `json:"message,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// Handler implements plugin dispatch.
+type Handler struct{}
+
+// New creates a new plugin handler.
+func New() *Handler {
+	return &Handler{}
+}
+
+// Name returns the handler name.
+func (h *Handler) Name() string {
+	return "plugin"
+}
+
+// SupportedConditions returns nil: what a plugin supports is up to the
+// external executable, so any condition is accepted.
+func (h *Handler) SupportedConditions() []string {
+	return nil
+}
+
+// Check verifies a condition by invoking the plugin executable.
+func (h *Handler) Check(ctx context.Context, subject *ast.ResourceRef, condition string, args map[string]string) pkgruntime.HandlerResult {
+	return h.invoke(ctx, "check", subject, condition, args)
+}
+
+// Enforce repairs a condition by invoking the plugin executable.
+func (h *Handler) Enforce(ctx context.Context, subject *ast.ResourceRef, condition string, args map[string]string) pkgruntime.HandlerResult {
+	return h.invoke(ctx, "enforce", subject, condition, args)
+}
+
+func (h *Handler) invoke(ctx context.Context, action string, subject *ast.ResourceRef, condition string, args map[string]string) pkgruntime.HandlerResult {
+	name := args["name"]
+	if name == "" {
+		return pkgruntime.HandlerResult{
+			Success: false,
+			Error:   fmt.Errorf("plugin name required (with plugin %q)", "<name>"),
+		}
+	}
+
+	path, err := findPlugin(name)
+	if err != nil {
+		return pkgruntime.HandlerResult{
+			Success: false,
+			Error:   fmt.Errorf("plugin %s not found: %w", name, err),
+		}
+	}
+
+	req := request{
+		Action:    action,
+		Condition: condition,
+	}
+	if subject != nil {
+		req.Subject = &subjectRef{Type: subject.ResourceType, Path: subject.Path}
+	}
+	for k, v := range args {
+		if k == "name" {
+			continue
+		}
+		if req.Args == nil {
+			req.Args = make(map[string]string)
+		}
+		req.Args[k] = v
+	}
+
+	input, err := json.Marshal(req)
+	if err != nil {
+		return pkgruntime.HandlerResult{
+			Success: false,
+			Error:   fmt.Errorf("failed to encode plugin request: %w", err),
+		}
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, path)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg != "" {
+			return pkgruntime.HandlerResult{
+				Success: false,
+				Message: msg,
+				Error:   fmt.Errorf("plugin %s failed: %w", name, err),
+			}
+		}
+		return pkgruntime.HandlerResult{
+			Success: false,
+			Error:   fmt.Errorf("plugin %s failed: %w", name, err),
+		}
+	}
+
+	var resp response
+	if err := json.Unmarshal(stdout.Bytes(), &resp); err != nil {
+		return pkgruntime.HandlerResult{
+			Success: false,
+			Error:   fmt.Errorf("plugin %s returned invalid response: %w", name, err),
+		}
+	}
+
+	result := pkgruntime.HandlerResult{
+		Success: resp.Success,
+		Message: resp.Message,
+	}
+	if resp.Error != "" {
+		result.Error = fmt.Errorf("plugin %s: %s", name, resp.Error)
+	}
+	return result
+}
+
+// findPlugin locates the executable for a plugin name, searching the
+// directories in ENSURA_PLUGIN_PATH before falling back to the regular PATH.
+func findPlugin(name string) (string, error) {
+	for _, dir := range filepath.SplitList(os.Getenv(PluginPathEnv)) {
+		if dir == "" {
+			continue
+		}
+		candidate := filepath.Join(dir, name)
+		info, err := os.Stat(candidate)
+		if err == nil && !info.IsDir() && info.Mode()&0111 != 0 {
+			return candidate, nil
+		}
+	}
+	return exec.LookPath(name)
+}
@@ -0,0 +1,86 @@
+package plugin
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/ensurascript/ensura/pkg/ast"
+)
+
+// writePlugin creates an executable plugin script in dir and returns its name.
+func writePlugin(t *testing.T, dir, name, script string) {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+script+"\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPluginCheck(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell plugins not supported on windows")
+	}
+
+	tmpDir := t.TempDir()
+	writePlugin(t, tmpDir, "always-ok", `echo '{"success":true,"message":"all good"}'`)
+	writePlugin(t, tmpDir, "always-bad", `echo '{"success":false,"message":"broken"}'`)
+	t.Setenv(PluginPathEnv, tmpDir)
+
+	h := New()
+	ctx := context.Background()
+	subject := &ast.ResourceRef{ResourceType: "file", Path: "/tmp/x"}
+
+	result := h.Check(ctx, subject, "custom", map[string]string{"name": "always-ok"})
+	if !result.Success {
+		t.Errorf("Expected success, got failure: %v", result.Error)
+	}
+	if result.Message != "all good" {
+		t.Errorf("Expected message 'all good', got %q", result.Message)
+	}
+
+	result = h.Check(ctx, subject, "custom", map[string]string{"name": "always-bad"})
+	if result.Success {
+		t.Error("Expected failure for always-bad plugin")
+	}
+}
+
+func TestPluginErrors(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell plugins not supported on windows")
+	}
+
+	tmpDir := t.TempDir()
+	writePlugin(t, tmpDir, "garbage", `echo 'not json'`)
+	writePlugin(t, tmpDir, "erroring", `echo '{"success":false,"error":"exploded"}'`)
+	t.Setenv(PluginPathEnv, tmpDir)
+
+	h := New()
+	ctx := context.Background()
+
+	// Missing plugin name
+	result := h.Check(ctx, nil, "custom", nil)
+	if result.Error == nil {
+		t.Error("Expected error for missing plugin name")
+	}
+
+	// Unknown plugin
+	result = h.Check(ctx, nil, "custom", map[string]string{"name": "no-such-plugin"})
+	if result.Error == nil {
+		t.Error("Expected error for unknown plugin")
+	}
+
+	// Invalid JSON response
+	result = h.Check(ctx, nil, "custom", map[string]string{"name": "garbage"})
+	if result.Error == nil {
+		t.Error("Expected error for invalid plugin output")
+	}
+
+	// Error reported by the plugin
+	result = h.Check(ctx, nil, "custom", map[string]string{"name": "erroring"})
+	if result.Success || result.Error == nil {
+		t.Error("Expected plugin-reported error to surface")
+	}
+}
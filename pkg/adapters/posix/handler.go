@@ -24,6 +24,11 @@ func (h *Handler) Name() string {
 	return "posix"
 }
 
+// SupportedConditions lists the conditions this handler understands.
+func (h *Handler) SupportedConditions() []string {
+	return []string{"permissions"}
+}
+
 // Check verifies POSIX permissions.
 func (h *Handler) Check(ctx context.Context, subject *ast.ResourceRef, condition string, args map[string]string) runtime.HandlerResult {
 	if subject == nil {
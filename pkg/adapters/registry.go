@@ -2,11 +2,26 @@
 package adapters
 
 import (
+	"github.com/ensurascript/ensura/pkg/adapters/account"
 	"github.com/ensurascript/ensura/pkg/adapters/aes"
+	"github.com/ensurascript/ensura/pkg/adapters/age"
+	"github.com/ensurascript/ensura/pkg/adapters/cert"
 	"github.com/ensurascript/ensura/pkg/adapters/cron"
+	"github.com/ensurascript/ensura/pkg/adapters/datastore"
+	"github.com/ensurascript/ensura/pkg/adapters/dns"
+	"github.com/ensurascript/ensura/pkg/adapters/env"
+	"github.com/ensurascript/ensura/pkg/adapters/exec"
 	"github.com/ensurascript/ensura/pkg/adapters/fs"
+	"github.com/ensurascript/ensura/pkg/adapters/gpg"
 	"github.com/ensurascript/ensura/pkg/adapters/http"
+	"github.com/ensurascript/ensura/pkg/adapters/mount"
+	"github.com/ensurascript/ensura/pkg/adapters/pkgmgr"
+	"github.com/ensurascript/ensura/pkg/adapters/plugin"
 	"github.com/ensurascript/ensura/pkg/adapters/posix"
+	"github.com/ensurascript/ensura/pkg/adapters/s3"
+	"github.com/ensurascript/ensura/pkg/adapters/sysctl"
+	"github.com/ensurascript/ensura/pkg/adapters/tcp"
+	"github.com/ensurascript/ensura/pkg/adapters/template"
 	"github.com/ensurascript/ensura/pkg/runtime"
 )
 
@@ -23,11 +38,54 @@ func NewDefaultRegistry() *runtime.HandlerRegistry {
 	// Register AES encryption handler
 	registry.Register(aes.New())
 
+	// Register age and gpg encryption handlers
+	registry.Register(age.New())
+	registry.Register(gpg.New())
+
 	// Register HTTP handler
 	registry.Register(http.New())
 
+	// Register certificate file handler
+	registry.Register(cert.New())
+
 	// Register cron handler
 	registry.Register(cron.New())
 
+	// Register mount point handler
+	registry.Register(mount.New())
+
+	// Register kernel parameter handler
+	registry.Register(sysctl.New())
+
+	// Register object storage handler
+	registry.Register(s3.New())
+
+	// Register environment variable handler
+	registry.Register(env.New())
+
+	// Register datastore reachability handler
+	registry.Register(datastore.New())
+
+	// Register TCP port handler
+	registry.Register(tcp.New())
+
+	// Register DNS resolution handler
+	registry.Register(dns.New())
+
+	// Register package manager handler
+	registry.Register(pkgmgr.New())
+
+	// Register user and group handler
+	registry.Register(account.New())
+
+	// Register template rendering handler
+	registry.Register(template.New())
+
+	// Register shell command handler
+	registry.Register(exec.New())
+
+	// Register exec-based plugin handler
+	registry.Register(plugin.New())
+
 	return registry
 }
@@ -0,0 +1,322 @@
+// Package s3 provides the object storage handler for EnsuraScript.
+//
+// Objects are addressed as s3://bucket/key and accessed through the aws
+// CLI, which carries its own credential chain (instance profile, SSO,
+// environment). S3-compatible stores work via the endpoint arg.
+package s3
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/ensurascript/ensura/pkg/ast"
+	"github.com/ensurascript/ensura/pkg/runtime"
+)
+
+// Handler implements object storage operations via the aws CLI.
+type Handler struct{}
+
+// New creates a new s3 handler.
+func New() *Handler {
+	return &Handler{}
+}
+
+// Name returns the handler name.
+func (h *Handler) Name() string {
+	return "s3.aws"
+}
+
+// SupportedConditions lists the conditions this handler understands.
+func (h *Handler) SupportedConditions() []string {
+	return []string{"exists", "checksum", "max_age"}
+}
+
+// objectInfo is the subset of aws s3api head-object output we use.
+type objectInfo struct {
+	ETag         string `json:"ETag"`
+	LastModified string `json:"LastModified"`
+}
+
+// Check verifies an object storage condition.
+func (h *Handler) Check(ctx context.Context, subject *ast.ResourceRef, condition string, args map[string]string) runtime.HandlerResult {
+	if subject == nil {
+		return runtime.HandlerResult{
+			Success: false,
+			Error:   fmt.Errorf("no subject specified"),
+		}
+	}
+
+	bucket, key, err := parseObjectURL(subject.Path)
+	if err != nil {
+		return runtime.HandlerResult{
+			Success: false,
+			Error:   err,
+		}
+	}
+
+	switch condition {
+	case "exists":
+		return h.checkExists(ctx, bucket, key, args)
+	case "checksum":
+		return h.checkChecksum(ctx, bucket, key, args)
+	case "max_age":
+		return h.checkMaxAge(ctx, bucket, key, args)
+	default:
+		return runtime.HandlerResult{
+			Success: false,
+			Error:   fmt.Errorf("unknown condition: %s", condition),
+		}
+	}
+}
+
+// Enforce uploads the local source file to the object, satisfying all three
+// conditions at once.
+func (h *Handler) Enforce(ctx context.Context, subject *ast.ResourceRef, condition string, args map[string]string) runtime.HandlerResult {
+	if subject == nil {
+		return runtime.HandlerResult{
+			Success: false,
+			Error:   fmt.Errorf("no subject specified"),
+		}
+	}
+
+	switch condition {
+	case "exists", "checksum", "max_age":
+	default:
+		return runtime.HandlerResult{
+			Success: false,
+			Error:   fmt.Errorf("cannot enforce condition: %s", condition),
+		}
+	}
+
+	bucket, key, err := parseObjectURL(subject.Path)
+	if err != nil {
+		return runtime.HandlerResult{
+			Success: false,
+			Error:   err,
+		}
+	}
+
+	source := args["source"]
+	if source == "" {
+		return runtime.HandlerResult{
+			Success: false,
+			Error:   fmt.Errorf("source argument required for enforcement"),
+		}
+	}
+	if _, err := os.Stat(source); err != nil {
+		return runtime.HandlerResult{
+			Success: false,
+			Error:   fmt.Errorf("source file: %w", err),
+		}
+	}
+
+	cliArgs := append([]string{"s3", "cp", source, fmt.Sprintf("s3://%s/%s", bucket, key)}, globalFlags(args)...)
+	cmd := exec.CommandContext(ctx, "aws", cliArgs...)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return runtime.HandlerResult{
+			Success: false,
+			Error:   fmt.Errorf("upload failed: %w, output: %s", err, string(output)),
+		}
+	}
+
+	return runtime.HandlerResult{
+		Success: true,
+		Message: fmt.Sprintf("uploaded %s to s3://%s/%s", source, bucket, key),
+	}
+}
+
+func (h *Handler) checkExists(ctx context.Context, bucket, key string, args map[string]string) runtime.HandlerResult {
+	_, found, err := h.headObject(ctx, bucket, key, args)
+	if err != nil {
+		return runtime.HandlerResult{
+			Success: false,
+			Error:   err,
+		}
+	}
+	if !found {
+		return runtime.HandlerResult{
+			Success: false,
+			Message: fmt.Sprintf("s3://%s/%s does not exist", bucket, key),
+		}
+	}
+	return runtime.HandlerResult{
+		Success: true,
+		Message: fmt.Sprintf("s3://%s/%s exists", bucket, key),
+	}
+}
+
+func (h *Handler) checkChecksum(ctx context.Context, bucket, key string, args map[string]string) runtime.HandlerResult {
+	expected := args["expected"]
+	if expected == "" && args["source"] != "" {
+		sum, err := fileMD5(args["source"])
+		if err != nil {
+			return runtime.HandlerResult{
+				Success: false,
+				Error:   fmt.Errorf("failed to hash source file: %w", err),
+			}
+		}
+		expected = sum
+	}
+	if expected == "" {
+		return runtime.HandlerResult{
+			Success: false,
+			Error:   fmt.Errorf("expected or source argument required"),
+		}
+	}
+
+	info, found, err := h.headObject(ctx, bucket, key, args)
+	if err != nil {
+		return runtime.HandlerResult{
+			Success: false,
+			Error:   err,
+		}
+	}
+	if !found {
+		return runtime.HandlerResult{
+			Success: false,
+			Message: fmt.Sprintf("s3://%s/%s does not exist", bucket, key),
+		}
+	}
+
+	actual := strings.Trim(info.ETag, `"`)
+	if actual == expected {
+		return runtime.HandlerResult{
+			Success: true,
+			Message: "checksum matches",
+		}
+	}
+	return runtime.HandlerResult{
+		Success: false,
+		Message: fmt.Sprintf("checksum mismatch: expected %s, got %s", expected, actual),
+	}
+}
+
+func (h *Handler) checkMaxAge(ctx context.Context, bucket, key string, args map[string]string) runtime.HandlerResult {
+	durArg := args["duration"]
+	if durArg == "" {
+		return runtime.HandlerResult{
+			Success: false,
+			Error:   fmt.Errorf("duration argument required"),
+		}
+	}
+	maxAge, err := time.ParseDuration(durArg)
+	if err != nil {
+		return runtime.HandlerResult{
+			Success: false,
+			Error:   fmt.Errorf("invalid duration %q: %w", durArg, err),
+		}
+	}
+
+	info, found, err := h.headObject(ctx, bucket, key, args)
+	if err != nil {
+		return runtime.HandlerResult{
+			Success: false,
+			Error:   err,
+		}
+	}
+	if !found {
+		return runtime.HandlerResult{
+			Success: false,
+			Message: fmt.Sprintf("s3://%s/%s does not exist", bucket, key),
+		}
+	}
+
+	modified, err := time.Parse(time.RFC3339, info.LastModified)
+	if err != nil {
+		return runtime.HandlerResult{
+			Success: false,
+			Error:   fmt.Errorf("cannot parse LastModified %q: %w", info.LastModified, err),
+		}
+	}
+
+	age := time.Since(modified)
+	if age <= maxAge {
+		return runtime.HandlerResult{
+			Success: true,
+			Message: fmt.Sprintf("s3://%s/%s is %s old", bucket, key, age.Round(time.Second)),
+		}
+	}
+	return runtime.HandlerResult{
+		Success: false,
+		Message: fmt.Sprintf("s3://%s/%s is %s old, max age %s", bucket, key, age.Round(time.Second), maxAge),
+	}
+}
+
+// headObject fetches object metadata. The found return distinguishes a
+// missing object (404 from head-object) from a real failure.
+func (h *Handler) headObject(ctx context.Context, bucket, key string, args map[string]string) (*objectInfo, bool, error) {
+	if _, err := exec.LookPath("aws"); err != nil {
+		return nil, false, fmt.Errorf("aws CLI not found in PATH")
+	}
+
+	cliArgs := append([]string{"s3api", "head-object", "--bucket", bucket, "--key", key}, globalFlags(args)...)
+	cmd := exec.CommandContext(ctx, "aws", cliArgs...)
+	output, err := cmd.Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			stderr := string(exitErr.Stderr)
+			if strings.Contains(stderr, "404") || strings.Contains(stderr, "Not Found") {
+				return nil, false, nil
+			}
+			return nil, false, fmt.Errorf("head-object failed: %s", strings.TrimSpace(stderr))
+		}
+		return nil, false, err
+	}
+
+	var info objectInfo
+	if err := json.Unmarshal(output, &info); err != nil {
+		return nil, false, fmt.Errorf("cannot parse head-object output: %w", err)
+	}
+	return &info, true, nil
+}
+
+// globalFlags maps handler args to aws CLI flags shared by all subcommands.
+// endpoint points the CLI at an S3-compatible store.
+func globalFlags(args map[string]string) []string {
+	var flags []string
+	if args["endpoint"] != "" {
+		flags = append(flags, "--endpoint-url", args["endpoint"])
+	}
+	if args["region"] != "" {
+		flags = append(flags, "--region", args["region"])
+	}
+	if args["profile"] != "" {
+		flags = append(flags, "--profile", args["profile"])
+	}
+	return flags
+}
+
+// parseObjectURL splits s3://bucket/key (the scheme is optional) into its
+// bucket and key parts.
+func parseObjectURL(path string) (bucket, key string, err error) {
+	path = strings.TrimPrefix(path, "s3://")
+	bucket, key, ok := strings.Cut(path, "/")
+	if !ok || bucket == "" || key == "" {
+		return "", "", fmt.Errorf("invalid object path %q: expected s3://bucket/key", path)
+	}
+	return bucket, key, nil
+}
+
+// fileMD5 computes the hex MD5 of a local file, matching the ETag S3
+// assigns to non-multipart uploads.
+func fileMD5(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	hash := md5.New()
+	if _, err := io.Copy(hash, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
@@ -0,0 +1,105 @@
+package s3
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ensurascript/ensura/pkg/ast"
+)
+
+func TestParseObjectURL(t *testing.T) {
+	tests := []struct {
+		path   string
+		bucket string
+		key    string
+	}{
+		{"s3://backups/db/latest.sql.gz", "backups", "db/latest.sql.gz"},
+		{"backups/latest.tar", "backups", "latest.tar"},
+	}
+	for _, tt := range tests {
+		bucket, key, err := parseObjectURL(tt.path)
+		if err != nil {
+			t.Errorf("parseObjectURL(%q) error: %v", tt.path, err)
+			continue
+		}
+		if bucket != tt.bucket || key != tt.key {
+			t.Errorf("parseObjectURL(%q) = %q, %q, want %q, %q", tt.path, bucket, key, tt.bucket, tt.key)
+		}
+	}
+
+	invalid := []string{"", "s3://", "s3://bucket", "bucket/", "/key"}
+	for _, path := range invalid {
+		if _, _, err := parseObjectURL(path); err == nil {
+			t.Errorf("parseObjectURL(%q) should fail", path)
+		}
+	}
+}
+
+func TestFileMD5(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.txt")
+	if err := os.WriteFile(path, []byte("hello world"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	sum, err := fileMD5(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sum != "5eb63bbbe01eeed093cb22bb8f5acdc3" {
+		t.Errorf("fileMD5() = %s", sum)
+	}
+}
+
+func TestGlobalFlags(t *testing.T) {
+	flags := globalFlags(map[string]string{"endpoint": "http://minio:9000", "region": "us-east-1"})
+	want := []string{"--endpoint-url", "http://minio:9000", "--region", "us-east-1"}
+	if len(flags) != len(want) {
+		t.Fatalf("globalFlags() = %v, want %v", flags, want)
+	}
+	for i := range want {
+		if flags[i] != want[i] {
+			t.Fatalf("globalFlags() = %v, want %v", flags, want)
+		}
+	}
+
+	if flags := globalFlags(map[string]string{}); flags != nil {
+		t.Errorf("globalFlags() = %v, want nil", flags)
+	}
+}
+
+func TestCheckErrors(t *testing.T) {
+	h := New()
+	ctx := context.Background()
+
+	if result := h.Check(ctx, nil, "exists", map[string]string{}); result.Error == nil {
+		t.Error("Check() should error with no subject")
+	}
+
+	subject := &ast.ResourceRef{ResourceType: "s3", Path: "not-an-object-url"}
+	if result := h.Check(ctx, subject, "exists", map[string]string{}); result.Error == nil {
+		t.Error("Check() should error on a malformed object path")
+	}
+
+	subject = &ast.ResourceRef{ResourceType: "s3", Path: "s3://bucket/key"}
+	if result := h.Check(ctx, subject, "unknown", map[string]string{}); result.Error == nil {
+		t.Error("Check() should error on unknown condition")
+	}
+	if result := h.Check(ctx, subject, "max_age", map[string]string{}); result.Error == nil {
+		t.Error("Check() should error without duration argument")
+	}
+}
+
+func TestEnforceErrors(t *testing.T) {
+	h := New()
+	ctx := context.Background()
+	subject := &ast.ResourceRef{ResourceType: "s3", Path: "s3://bucket/key"}
+
+	if result := h.Enforce(ctx, subject, "exists", map[string]string{}); result.Error == nil {
+		t.Error("Enforce() should error without source argument")
+	}
+	if result := h.Enforce(ctx, subject, "exists", map[string]string{"source": "/does/not/exist"}); result.Error == nil {
+		t.Error("Enforce() should error on missing source file")
+	}
+}
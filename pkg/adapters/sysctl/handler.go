@@ -0,0 +1,182 @@
+// Package sysctl provides the kernel parameter handler for EnsuraScript.
+package sysctl
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/ensurascript/ensura/pkg/ast"
+	"github.com/ensurascript/ensura/pkg/atomicfile"
+	"github.com/ensurascript/ensura/pkg/runtime"
+)
+
+// persistFile is where enforced values are recorded when persistence is
+// requested, so they survive a reboot.
+const persistFile = "/etc/sysctl.d/99-ensurascript.conf"
+
+// Handler implements kernel parameter operations via /proc/sys and sysctl.
+type Handler struct{}
+
+// New creates a new sysctl handler.
+func New() *Handler {
+	return &Handler{}
+}
+
+// Name returns the handler name.
+func (h *Handler) Name() string {
+	return "sysctl.native"
+}
+
+// SupportedConditions lists the conditions this handler understands.
+func (h *Handler) SupportedConditions() []string {
+	return []string{"value"}
+}
+
+// Check verifies a kernel parameter has the expected value.
+func (h *Handler) Check(ctx context.Context, subject *ast.ResourceRef, condition string, args map[string]string) runtime.HandlerResult {
+	if subject == nil {
+		return runtime.HandlerResult{
+			Success: false,
+			Error:   fmt.Errorf("no subject specified"),
+		}
+	}
+
+	if condition != "value" {
+		return runtime.HandlerResult{
+			Success: false,
+			Error:   fmt.Errorf("unknown condition: %s", condition),
+		}
+	}
+
+	expected := args["expected"]
+	if expected == "" {
+		return runtime.HandlerResult{
+			Success: false,
+			Error:   fmt.Errorf("expected argument required"),
+		}
+	}
+
+	key := subject.Path
+	data, err := os.ReadFile(procPath(key))
+	if err != nil {
+		return runtime.HandlerResult{
+			Success: false,
+			Error:   fmt.Errorf("failed to read sysctl %s: %w", key, err),
+		}
+	}
+
+	actual := normalize(string(data))
+	if actual == normalize(expected) {
+		return runtime.HandlerResult{
+			Success: true,
+			Message: fmt.Sprintf("%s = %s", key, actual),
+		}
+	}
+
+	return runtime.HandlerResult{
+		Success: false,
+		Message: fmt.Sprintf("%s = %s, expected %s", key, actual, expected),
+	}
+}
+
+// Enforce sets a kernel parameter with sysctl -w, and records it under
+// /etc/sysctl.d when args["persist"] is "true".
+func (h *Handler) Enforce(ctx context.Context, subject *ast.ResourceRef, condition string, args map[string]string) runtime.HandlerResult {
+	if subject == nil {
+		return runtime.HandlerResult{
+			Success: false,
+			Error:   fmt.Errorf("no subject specified"),
+		}
+	}
+
+	if condition != "value" {
+		return runtime.HandlerResult{
+			Success: false,
+			Error:   fmt.Errorf("cannot enforce condition: %s", condition),
+		}
+	}
+
+	expected := args["expected"]
+	if expected == "" {
+		return runtime.HandlerResult{
+			Success: false,
+			Error:   fmt.Errorf("expected argument required"),
+		}
+	}
+
+	key := subject.Path
+	cmd := exec.CommandContext(ctx, "sysctl", "-w", fmt.Sprintf("%s=%s", key, expected))
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return runtime.HandlerResult{
+			Success: false,
+			Error:   fmt.Errorf("sysctl -w failed: %w, output: %s", err, string(output)),
+		}
+	}
+
+	if args["persist"] == "true" {
+		if err := persist(key, expected); err != nil {
+			return runtime.HandlerResult{
+				Success: false,
+				Error:   fmt.Errorf("failed to persist sysctl: %w", err),
+			}
+		}
+		return runtime.HandlerResult{
+			Success: true,
+			Message: fmt.Sprintf("set %s = %s (persisted)", key, expected),
+		}
+	}
+
+	return runtime.HandlerResult{
+		Success: true,
+		Message: fmt.Sprintf("set %s = %s", key, expected),
+	}
+}
+
+// procPath maps a dotted sysctl key to its /proc/sys file.
+func procPath(key string) string {
+	return filepath.Join("/proc/sys", strings.ReplaceAll(key, ".", "/"))
+}
+
+// normalize collapses the whitespace in a sysctl value; multi-value
+// parameters are tab-separated in /proc/sys.
+func normalize(value string) string {
+	return strings.Join(strings.Fields(value), " ")
+}
+
+// persist records the key/value pair in the EnsuraScript sysctl.d file,
+// replacing any previous entry for the same key.
+func persist(key, value string) error {
+	existing, err := os.ReadFile(persistFile)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return atomicfile.Write(persistFile, upsertConf(existing, key, value), 0644)
+}
+
+// upsertConf updates the line for key in sysctl.conf content, or appends one.
+func upsertConf(existing []byte, key, value string) []byte {
+	entry := fmt.Sprintf("%s = %s", key, value)
+	var lines []string
+	replaced := false
+	for _, line := range strings.Split(string(existing), "\n") {
+		trimmed := strings.TrimSpace(line)
+		if k, _, ok := strings.Cut(trimmed, "="); ok && strings.TrimSpace(k) == key {
+			if !replaced {
+				lines = append(lines, entry)
+				replaced = true
+			}
+			continue
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if !replaced {
+		lines = append(lines, entry)
+	}
+	return []byte(strings.Join(lines, "\n") + "\n")
+}
@@ -0,0 +1,84 @@
+package sysctl
+
+import (
+	"context"
+	"os"
+	"runtime"
+	"testing"
+
+	"github.com/ensurascript/ensura/pkg/ast"
+)
+
+func TestProcPath(t *testing.T) {
+	if got := procPath("net.ipv4.ip_forward"); got != "/proc/sys/net/ipv4/ip_forward" {
+		t.Errorf("procPath() = %s, want /proc/sys/net/ipv4/ip_forward", got)
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	if got := normalize("4096\t87380\t6291456\n"); got != "4096 87380 6291456" {
+		t.Errorf("normalize() = %q", got)
+	}
+}
+
+func TestUpsertConf(t *testing.T) {
+	existing := []byte("# managed by EnsuraScript\nnet.ipv4.ip_forward = 0\nvm.swappiness = 10\n")
+
+	updated := string(upsertConf(existing, "net.ipv4.ip_forward", "1"))
+	want := "# managed by EnsuraScript\nnet.ipv4.ip_forward = 1\nvm.swappiness = 10\n"
+	if updated != want {
+		t.Errorf("upsertConf replace = %q, want %q", updated, want)
+	}
+
+	appended := string(upsertConf(existing, "kernel.panic", "10"))
+	want = "# managed by EnsuraScript\nnet.ipv4.ip_forward = 0\nvm.swappiness = 10\nkernel.panic = 10\n"
+	if appended != want {
+		t.Errorf("upsertConf append = %q, want %q", appended, want)
+	}
+
+	fresh := string(upsertConf(nil, "vm.swappiness", "1"))
+	if fresh != "vm.swappiness = 1\n" {
+		t.Errorf("upsertConf on empty = %q", fresh)
+	}
+}
+
+func TestCheckValue(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("requires /proc/sys")
+	}
+
+	h := New()
+	ctx := context.Background()
+	subject := &ast.ResourceRef{ResourceType: "sysctl", Path: "kernel.ostype"}
+
+	data, err := os.ReadFile("/proc/sys/kernel/ostype")
+	if err != nil {
+		t.Skip("cannot read /proc/sys/kernel/ostype")
+	}
+
+	result := h.Check(ctx, subject, "value", map[string]string{"expected": normalize(string(data))})
+	if !result.Success {
+		t.Errorf("Check() should pass for actual value: %v %v", result.Message, result.Error)
+	}
+
+	result = h.Check(ctx, subject, "value", map[string]string{"expected": "NotAnOS"})
+	if result.Success {
+		t.Error("Check() should fail for wrong value")
+	}
+}
+
+func TestCheckErrors(t *testing.T) {
+	h := New()
+	ctx := context.Background()
+	subject := &ast.ResourceRef{ResourceType: "sysctl", Path: "kernel.ostype"}
+
+	if result := h.Check(ctx, nil, "value", map[string]string{"expected": "x"}); result.Error == nil {
+		t.Error("Check() should error with no subject")
+	}
+	if result := h.Check(ctx, subject, "value", map[string]string{}); result.Error == nil {
+		t.Error("Check() should error without expected argument")
+	}
+	if result := h.Check(ctx, subject, "unknown", map[string]string{"expected": "x"}); result.Error == nil {
+		t.Error("Check() should error on unknown condition")
+	}
+}
@@ -0,0 +1,154 @@
+// Package tcp provides the TCP port handler for EnsuraScript.
+//
+// It backs the `open` and `closed` conditions on `port` resources:
+//
+//	ensure open on port "localhost:5432"
+//
+// Enforcement can optionally run a configured start (or stop) command.
+package tcp
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/ensurascript/ensura/pkg/ast"
+	pkgruntime "github.com/ensurascript/ensura/pkg/runtime"
+)
+
+// DefaultDialTimeout is used when no timeout argument is given.
+const DefaultDialTimeout = 5 * time.Second
+
+// Handler implements TCP port checks.
+type Handler struct{}
+
+// New creates a new tcp handler.
+func New() *Handler {
+	return &Handler{}
+}
+
+// Name returns the handler name.
+func (h *Handler) Name() string {
+	return "tcp.native"
+}
+
+// SupportedConditions lists the conditions this handler understands.
+func (h *Handler) SupportedConditions() []string {
+	return []string{"open", "closed"}
+}
+
+// Check verifies a port condition.
+func (h *Handler) Check(ctx context.Context, subject *ast.ResourceRef, condition string, args map[string]string) pkgruntime.HandlerResult {
+	addr, timeout, err := h.target(subject, args)
+	if err != nil {
+		return pkgruntime.HandlerResult{Success: false, Error: err}
+	}
+
+	conn, dialErr := net.DialTimeout("tcp", addr, timeout)
+	open := dialErr == nil
+	if conn != nil {
+		conn.Close()
+	}
+
+	switch condition {
+	case "open":
+		if open {
+			return pkgruntime.HandlerResult{
+				Success: true,
+				Message: fmt.Sprintf("port %s is open", addr),
+			}
+		}
+		return pkgruntime.HandlerResult{
+			Success: false,
+			Message: fmt.Sprintf("port %s is not open: %v", addr, dialErr),
+		}
+
+	case "closed":
+		if !open {
+			return pkgruntime.HandlerResult{
+				Success: true,
+				Message: fmt.Sprintf("port %s is closed", addr),
+			}
+		}
+		return pkgruntime.HandlerResult{
+			Success: false,
+			Message: fmt.Sprintf("port %s is unexpectedly open", addr),
+		}
+
+	default:
+		return pkgruntime.HandlerResult{
+			Success: false,
+			Error:   fmt.Errorf("unknown condition: %s", condition),
+		}
+	}
+}
+
+// Enforce attempts to bring a port into the desired state by running the
+// configured start (for open) or stop (for closed) command.
+func (h *Handler) Enforce(ctx context.Context, subject *ast.ResourceRef, condition string, args map[string]string) pkgruntime.HandlerResult {
+	addr, _, err := h.target(subject, args)
+	if err != nil {
+		return pkgruntime.HandlerResult{Success: false, Error: err}
+	}
+
+	var command string
+	switch condition {
+	case "open":
+		command = args["start"]
+	case "closed":
+		command = args["stop"]
+	default:
+		return pkgruntime.HandlerResult{
+			Success: false,
+			Error:   fmt.Errorf("cannot enforce condition: %s", condition),
+		}
+	}
+
+	if command == "" {
+		return pkgruntime.HandlerResult{
+			Success: false,
+			Error:   fmt.Errorf("no command configured to enforce %s on port %s", condition, addr),
+		}
+	}
+
+	cmd := exec.CommandContext(ctx, "/bin/sh", "-c", command)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return pkgruntime.HandlerResult{
+			Success: false,
+			Message: strings.TrimSpace(string(output)),
+			Error:   fmt.Errorf("enforce command failed: %w", err),
+		}
+	}
+
+	return pkgruntime.HandlerResult{
+		Success: true,
+		Message: fmt.Sprintf("ran %s command for port %s", condition, addr),
+	}
+}
+
+// target resolves the dial address and timeout for a port subject. A bare
+// port number defaults to localhost.
+func (h *Handler) target(subject *ast.ResourceRef, args map[string]string) (string, time.Duration, error) {
+	if subject == nil || subject.Path == "" {
+		return "", 0, fmt.Errorf("no subject specified")
+	}
+
+	addr := subject.Path
+	if !strings.Contains(addr, ":") {
+		addr = "127.0.0.1:" + addr
+	}
+
+	timeout := DefaultDialTimeout
+	if raw := args["timeout"]; raw != "" {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return "", 0, fmt.Errorf("invalid timeout %q: %w", raw, err)
+		}
+		timeout = d
+	}
+
+	return addr, timeout, nil
+}
@@ -0,0 +1,87 @@
+package tcp
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/ensurascript/ensura/pkg/ast"
+)
+
+func TestCheckOpen(t *testing.T) {
+	h := New()
+	ctx := context.Background()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	subject := &ast.ResourceRef{ResourceType: "port", Path: listener.Addr().String()}
+	result := h.Check(ctx, subject, "open", map[string]string{"timeout": "2s"})
+	if !result.Success {
+		t.Errorf("Expected open check to succeed for listening port: %s", result.Message)
+	}
+
+	result = h.Check(ctx, subject, "closed", nil)
+	if result.Success {
+		t.Error("Expected closed check to fail for listening port")
+	}
+}
+
+func TestCheckClosed(t *testing.T) {
+	h := New()
+	ctx := context.Background()
+
+	// Grab a free port, then close it so nothing is listening.
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+
+	subject := &ast.ResourceRef{ResourceType: "port", Path: addr}
+	result := h.Check(ctx, subject, "closed", map[string]string{"timeout": "500ms"})
+	if !result.Success {
+		t.Errorf("Expected closed check to succeed for free port: %s", result.Message)
+	}
+
+	result = h.Check(ctx, subject, "open", map[string]string{"timeout": "500ms"})
+	if result.Success {
+		t.Error("Expected open check to fail for free port")
+	}
+}
+
+func TestCheckErrors(t *testing.T) {
+	h := New()
+	ctx := context.Background()
+
+	result := h.Check(ctx, nil, "open", nil)
+	if result.Error == nil {
+		t.Error("Expected error for missing subject")
+	}
+
+	subject := &ast.ResourceRef{ResourceType: "port", Path: "127.0.0.1:1"}
+	result = h.Check(ctx, subject, "open", map[string]string{"timeout": "soon"})
+	if result.Error == nil {
+		t.Error("Expected error for invalid timeout")
+	}
+
+	result = h.Check(ctx, subject, "bogus", nil)
+	if result.Error == nil {
+		t.Error("Expected error for unknown condition")
+	}
+}
+
+func TestEnforceRequiresCommand(t *testing.T) {
+	h := New()
+	ctx := context.Background()
+
+	subject := &ast.ResourceRef{ResourceType: "port", Path: "127.0.0.1:1"}
+	result := h.Enforce(ctx, subject, "open", nil)
+	if result.Error == nil {
+		t.Error("Expected error when no start command is configured")
+	}
+}
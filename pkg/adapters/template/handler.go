@@ -0,0 +1,158 @@
+// Package template provides the templated file content handler for EnsuraScript.
+package template
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	texttemplate "text/template"
+
+	"github.com/ensurascript/ensura/pkg/ast"
+	"github.com/ensurascript/ensura/pkg/atomicfile"
+	"github.com/ensurascript/ensura/pkg/runtime"
+)
+
+// Handler renders template files and keeps targets in sync with the output.
+type Handler struct{}
+
+// New creates a new template handler.
+func New() *Handler {
+	return &Handler{}
+}
+
+// Name returns the handler name.
+func (h *Handler) Name() string {
+	return "template.native"
+}
+
+// SupportedConditions lists the conditions this handler understands.
+func (h *Handler) SupportedConditions() []string {
+	return []string{"rendered"}
+}
+
+// Check verifies that the target matches the rendered template output.
+func (h *Handler) Check(ctx context.Context, subject *ast.ResourceRef, condition string, args map[string]string) runtime.HandlerResult {
+	if subject == nil {
+		return runtime.HandlerResult{
+			Success: false,
+			Error:   fmt.Errorf("no subject specified"),
+		}
+	}
+
+	if condition != "rendered" {
+		return runtime.HandlerResult{
+			Success: false,
+			Error:   fmt.Errorf("unknown condition: %s", condition),
+		}
+	}
+
+	rendered, err := h.render(args)
+	if err != nil {
+		return runtime.HandlerResult{
+			Success: false,
+			Error:   err,
+		}
+	}
+
+	actual, err := os.ReadFile(subject.Path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return runtime.HandlerResult{
+				Success: false,
+				Message: fmt.Sprintf("%s does not exist", subject.Path),
+			}
+		}
+		return runtime.HandlerResult{
+			Success: false,
+			Error:   err,
+		}
+	}
+
+	if bytes.Equal(actual, rendered) {
+		return runtime.HandlerResult{
+			Success: true,
+			Message: fmt.Sprintf("%s matches rendered template", subject.Path),
+		}
+	}
+	return runtime.HandlerResult{
+		Success: false,
+		Message: fmt.Sprintf("%s does not match rendered template", subject.Path),
+	}
+}
+
+// Enforce writes the rendered template to the target atomically, preserving
+// the target's mode when it already exists.
+func (h *Handler) Enforce(ctx context.Context, subject *ast.ResourceRef, condition string, args map[string]string) runtime.HandlerResult {
+	if subject == nil {
+		return runtime.HandlerResult{
+			Success: false,
+			Error:   fmt.Errorf("no subject specified"),
+		}
+	}
+
+	if condition != "rendered" {
+		return runtime.HandlerResult{
+			Success: false,
+			Error:   fmt.Errorf("cannot enforce condition: %s", condition),
+		}
+	}
+
+	rendered, err := h.render(args)
+	if err != nil {
+		return runtime.HandlerResult{
+			Success: false,
+			Error:   err,
+		}
+	}
+
+	if err := atomicfile.Write(subject.Path, rendered, 0644); err != nil {
+		return runtime.HandlerResult{
+			Success: false,
+			Error:   err,
+		}
+	}
+
+	return runtime.HandlerResult{
+		Success: true,
+		Message: fmt.Sprintf("wrote rendered template to %s", subject.Path),
+	}
+}
+
+// render parses the template named by args["template"] and executes it.
+// All other args become template variables; the env function exposes
+// environment variables to the template.
+func (h *Handler) render(args map[string]string) ([]byte, error) {
+	templatePath := args["template"]
+	if templatePath == "" {
+		return nil, fmt.Errorf("template argument required")
+	}
+
+	source, err := os.ReadFile(templatePath)
+	if err != nil {
+		return nil, err
+	}
+
+	vars := make(map[string]string, len(args))
+	for k, v := range args {
+		if k == "template" {
+			continue
+		}
+		vars[k] = v
+	}
+
+	tmpl, err := texttemplate.New(filepath.Base(templatePath)).
+		Funcs(texttemplate.FuncMap{"env": os.Getenv}).
+		Option("missingkey=error").
+		Parse(string(source))
+	if err != nil {
+		return nil, fmt.Errorf("invalid template %s: %w", templatePath, err)
+	}
+
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, vars); err != nil {
+		return nil, fmt.Errorf("rendering %s: %w", templatePath, err)
+	}
+	return out.Bytes(), nil
+}
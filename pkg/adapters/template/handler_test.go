@@ -0,0 +1,98 @@
+package template
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ensurascript/ensura/pkg/ast"
+)
+
+func TestCheckRendered(t *testing.T) {
+	h := New()
+	ctx := context.Background()
+
+	tmpDir := t.TempDir()
+	tmplFile := filepath.Join(tmpDir, "motd.tmpl")
+	if err := os.WriteFile(tmplFile, []byte("Welcome to {{.host}}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	target := filepath.Join(tmpDir, "motd")
+	if err := os.WriteFile(target, []byte("Welcome to web01\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	subject := &ast.ResourceRef{Path: target, ResourceType: "file"}
+	args := map[string]string{"template": tmplFile, "host": "web01"}
+	result := h.Check(ctx, subject, "rendered", args)
+	if !result.Success {
+		t.Errorf("Expected rendered check to pass: %s", result.Message)
+	}
+
+	args["host"] = "web02"
+	result = h.Check(ctx, subject, "rendered", args)
+	if result.Success {
+		t.Error("Expected rendered check to fail for different variables")
+	}
+}
+
+func TestEnforceRendered(t *testing.T) {
+	h := New()
+	ctx := context.Background()
+
+	tmpDir := t.TempDir()
+	tmplFile := filepath.Join(tmpDir, "conf.tmpl")
+	if err := os.WriteFile(tmplFile, []byte("port={{.port}}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	target := filepath.Join(tmpDir, "app.conf")
+	if err := os.WriteFile(target, []byte("stale\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	subject := &ast.ResourceRef{Path: target, ResourceType: "file"}
+	args := map[string]string{"template": tmplFile, "port": "8080"}
+	result := h.Enforce(ctx, subject, "rendered", args)
+	if !result.Success {
+		t.Errorf("Expected enforce to succeed: %v", result.Error)
+	}
+
+	data, err := os.ReadFile(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "port=8080\n" {
+		t.Errorf("Expected rendered content, got %q", string(data))
+	}
+
+	// Mode of the existing target is preserved
+	info, err := os.Stat(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("Expected mode 0600 to be preserved, got %o", info.Mode().Perm())
+	}
+}
+
+func TestRenderErrors(t *testing.T) {
+	h := New()
+	ctx := context.Background()
+
+	subject := &ast.ResourceRef{Path: "/tmp/out", ResourceType: "file"}
+	result := h.Check(ctx, subject, "rendered", nil)
+	if result.Error == nil {
+		t.Error("Expected error for missing template argument")
+	}
+
+	tmpDir := t.TempDir()
+	tmplFile := filepath.Join(tmpDir, "bad.tmpl")
+	if err := os.WriteFile(tmplFile, []byte("{{.missing}}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	result = h.Check(ctx, subject, "rendered", map[string]string{"template": tmplFile})
+	if result.Error == nil {
+		t.Error("Expected error for missing template variable")
+	}
+}
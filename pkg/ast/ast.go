@@ -3,14 +3,19 @@ package ast
 
 import (
 	"fmt"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/ensurascript/ensura/pkg/lexer"
 )
 
-// Node represents any node in the AST.
+// Node represents any node in the AST. Pos and End span the node's
+// source text; End is the position just past its last token, recorded by
+// the parser for editor integrations.
 type Node interface {
 	Pos() lexer.Position
+	End() lexer.Position
 	String() string
 }
 
@@ -30,9 +35,11 @@ type Expression interface {
 type Program struct {
 	Statements []Statement
 	Position   lexer.Position
+	EndPos     lexer.Position
 }
 
 func (p *Program) Pos() lexer.Position { return p.Position }
+func (p *Program) End() lexer.Position { return p.EndPos }
 func (p *Program) String() string {
 	var out strings.Builder
 	for _, s := range p.Statements {
@@ -46,13 +53,16 @@ func (p *Program) String() string {
 // Example: resource file "secrets.db" as secrets_db
 type ResourceDecl struct {
 	Position     lexer.Position
+	EndPos       lexer.Position
 	ResourceType string // file, directory, http, database, etc.
 	Path         string // the resource path/identifier
 	Alias        string // optional alias (from "as")
+	DocComment   string // leading comment block, if any
 }
 
-func (r *ResourceDecl) statementNode()        {}
-func (r *ResourceDecl) Pos() lexer.Position   { return r.Position }
+func (r *ResourceDecl) statementNode()      {}
+func (r *ResourceDecl) Pos() lexer.Position { return r.Position }
+func (r *ResourceDecl) End() lexer.Position { return r.EndPos }
 func (r *ResourceDecl) String() string {
 	if r.Alias != "" {
 		return fmt.Sprintf("resource %s %q as %s", r.ResourceType, r.Path, r.Alias)
@@ -63,14 +73,22 @@ func (r *ResourceDecl) String() string {
 // ResourceRef references a resource (inline or by alias).
 type ResourceRef struct {
 	Position     lexer.Position
+	EndPos       lexer.Position
 	ResourceType string // file, directory, http, etc.
 	Path         string // the resource path (if inline)
 	Alias        string // the alias (if referencing by alias)
 	IsIterator   bool   // true if this is a for-each iterator placeholder
+
+	// Loop context for iterator placeholders, so the runtime can expand
+	// them over the container's current entries.
+	Container *ResourceRef // the for-each container
+	Matching  []string     // keep only entries matching one of these globs
+	Excluding []string     // drop entries matching any of these globs
 }
 
-func (r *ResourceRef) expressionNode()       {}
-func (r *ResourceRef) Pos() lexer.Position   { return r.Position }
+func (r *ResourceRef) expressionNode()     {}
+func (r *ResourceRef) Pos() lexer.Position { return r.Position }
+func (r *ResourceRef) End() lexer.Position { return r.EndPos }
 func (r *ResourceRef) String() string {
 	if r.Alias != "" {
 		return r.Alias
@@ -78,20 +96,35 @@ func (r *ResourceRef) String() string {
 	return fmt.Sprintf("%s %q", r.ResourceType, r.Path)
 }
 
+// Literal kinds recorded in HandlerSpec.ArgTypes for arguments written
+// as typed literals rather than quoted strings.
+const (
+	ArgDuration = "duration"
+	ArgSize     = "size"
+	ArgBool     = "bool"
+)
+
 // HandlerSpec represents a handler specification with its arguments.
 // Example: AES:256 key "env:SECRET_KEY"
 type HandlerSpec struct {
 	Position lexer.Position
+	EndPos   lexer.Position
 	Name     string            // e.g., "AES:256", "posix", "http.get"
 	Args     map[string]string // key-value arguments
+	ArgTypes map[string]string // literal kind per typed argument (absent = string)
 }
 
-func (h *HandlerSpec) expressionNode()       {}
-func (h *HandlerSpec) Pos() lexer.Position   { return h.Position }
+func (h *HandlerSpec) expressionNode()     {}
+func (h *HandlerSpec) Pos() lexer.Position { return h.Position }
+func (h *HandlerSpec) End() lexer.Position { return h.EndPos }
 func (h *HandlerSpec) String() string {
 	var args []string
 	for k, v := range h.Args {
-		args = append(args, fmt.Sprintf("%s %q", k, v))
+		if h.ArgTypes[k] != "" {
+			args = append(args, fmt.Sprintf("%s %s", k, v))
+		} else {
+			args = append(args, fmt.Sprintf("%s %q", k, v))
+		}
 	}
 	if len(args) > 0 {
 		return fmt.Sprintf("%s %s", h.Name, strings.Join(args, " "))
@@ -99,22 +132,112 @@ func (h *HandlerSpec) String() string {
 	return h.Name
 }
 
+// Duration returns the parsed value of a duration-typed argument.
+func (h *HandlerSpec) Duration(key string) (time.Duration, bool) {
+	if h.ArgTypes[key] != ArgDuration {
+		return 0, false
+	}
+	d, err := time.ParseDuration(h.Args[key])
+	if err != nil {
+		return 0, false
+	}
+	return d, true
+}
+
+// Size returns the value of a size-typed argument in bytes.
+func (h *HandlerSpec) Size(key string) (int64, bool) {
+	if h.ArgTypes[key] != ArgSize {
+		return 0, false
+	}
+	n, err := lexer.ParseSize(h.Args[key])
+	if err != nil {
+		return 0, false
+	}
+	return n, true
+}
+
+// Bool returns the value of a boolean-typed argument.
+func (h *HandlerSpec) Bool(key string) (bool, bool) {
+	if h.ArgTypes[key] != ArgBool {
+		return false, false
+	}
+	return h.Args[key] == "true", true
+}
+
+// Severity levels a guarantee may carry, from most to least urgent.
+const (
+	SeverityCritical = "critical"
+	SeverityHigh     = "high"
+	SeverityWarning  = "warning"
+	SeverityInfo     = "info"
+)
+
+// ValidSeverity reports whether s names a recognized severity level.
+func ValidSeverity(s string) bool {
+	switch s {
+	case SeverityCritical, SeverityHigh, SeverityWarning, SeverityInfo:
+		return true
+	}
+	return false
+}
+
+// SeverityRank orders severity levels for comparison; higher is more
+// urgent. Statements without a severity clause default to high, so files
+// that never mention severity keep their original check semantics.
+func SeverityRank(s string) int {
+	switch s {
+	case SeverityCritical:
+		return 4
+	case SeverityHigh, "":
+		return 3
+	case SeverityWarning:
+		return 2
+	case SeverityInfo:
+		return 1
+	}
+	return 0
+}
+
+// NotifyRule pairs a notification target with an escalation threshold.
+// Example: notify "security" after 10 failures severity high
+type NotifyRule struct {
+	Target        string
+	AfterFailures int    // consecutive failures before notifying (1 = immediately)
+	MinSeverity   string // only notify for violations at or above this level ("" = always)
+}
+
 // ViolationHandler represents violation handling configuration.
 type ViolationHandler struct {
-	Position lexer.Position
-	Retry    int      // number of retries
-	Notify   []string // notification targets
+	Position    lexer.Position
+	EndPos      lexer.Position
+	Retry       int          // number of retries
+	Notify      []string     // notification targets
+	NotifyRules []NotifyRule // targets with escalation thresholds
 }
 
-func (v *ViolationHandler) expressionNode()       {}
-func (v *ViolationHandler) Pos() lexer.Position   { return v.Position }
+func (v *ViolationHandler) expressionNode()     {}
+func (v *ViolationHandler) Pos() lexer.Position { return v.Position }
+func (v *ViolationHandler) End() lexer.Position { return v.EndPos }
 func (v *ViolationHandler) String() string {
 	var parts []string
 	if v.Retry > 0 {
 		parts = append(parts, fmt.Sprintf("retry %d", v.Retry))
 	}
-	for _, n := range v.Notify {
-		parts = append(parts, fmt.Sprintf("notify %q", n))
+	if len(v.NotifyRules) > 0 {
+		for _, r := range v.NotifyRules {
+			part := fmt.Sprintf("notify %q", r.Target)
+			if r.AfterFailures > 1 {
+				part += fmt.Sprintf(" after %d failures", r.AfterFailures)
+			}
+			if r.MinSeverity != "" {
+				part += " severity " + r.MinSeverity
+			}
+			parts = append(parts, part)
+		}
+	} else {
+		for _, n := range v.Notify {
+			parts = append(parts, fmt.Sprintf("notify %q", n))
+		}
 	}
 	return strings.Join(parts, "\n  ")
 }
@@ -123,13 +246,15 @@ func (v *ViolationHandler) String() string {
 // Example: environment == "prod"
 type GuardExpr struct {
 	Position lexer.Position
+	EndPos   lexer.Position
 	Left     string // e.g., "environment"
 	Operator string // "==" or "!="
 	Right    string // e.g., "prod"
 }
 
-func (g *GuardExpr) expressionNode()       {}
-func (g *GuardExpr) Pos() lexer.Position   { return g.Position }
+func (g *GuardExpr) expressionNode()     {}
+func (g *GuardExpr) Pos() lexer.Position { return g.Position }
+func (g *GuardExpr) End() lexer.Position { return g.EndPos }
 func (g *GuardExpr) String() string {
 	return fmt.Sprintf("%s %s %q", g.Left, g.Operator, g.Right)
 }
@@ -138,19 +263,29 @@ func (g *GuardExpr) String() string {
 // Example: ensure encrypted on file "secrets.db" with AES:256 key "env:SECRET_KEY"
 type EnsureStmt struct {
 	Position         lexer.Position
-	Condition        string            // exists, encrypted, permissions, etc.
-	Subject          *ResourceRef      // the resource (may be nil if inherited)
-	Handler          *HandlerSpec      // optional handler specification
-	Guard            *GuardExpr        // optional when clause
-	Requires         []string          // required conditions
-	RequiresResource []*ResourceRef    // required resources with conditions
-	After            []*ResourceRef    // ordering: after these
-	Before           []*ResourceRef    // ordering: before these
-	ViolationHandler *ViolationHandler // per-ensure violation handling
-}
-
-func (e *EnsureStmt) statementNode()        {}
-func (e *EnsureStmt) Pos() lexer.Position   { return e.Position }
+	EndPos           lexer.Position
+	Condition        string               // exists, encrypted, permissions, etc.
+	Subject          *ResourceRef         // the resource (may be nil if inherited)
+	Handler          *HandlerSpec         // optional handler specification
+	Guard            *GuardExpr           // optional when clause
+	Requires         []string             // required conditions
+	RequiresResource []*ResourceCondition // required conditions on other resources
+	After            []*ResourceRef       // ordering: after these
+	Before           []*ResourceRef       // ordering: before these
+	ViolationHandler *ViolationHandler    // per-ensure violation handling
+	Timeout          time.Duration        // per-step timeout (0 = use runtime default)
+	Severity         string               // critical, high, warning, or info ("" = high)
+	Tags             []string             // labels for run filtering
+	During           string               // maintenance window for repairs ("" = always)
+	Within           string               // freshness duration passed to the handler ("" = none)
+	DocComment       string               // leading comment block, if any
+	IsImplied        bool                 // synthesized by implication expansion
+	ImpliedBy        string               // condition of the statement that implied this one
+}
+
+func (e *EnsureStmt) statementNode()      {}
+func (e *EnsureStmt) Pos() lexer.Position { return e.Position }
+func (e *EnsureStmt) End() lexer.Position { return e.EndPos }
 func (e *EnsureStmt) String() string {
 	var out strings.Builder
 	out.WriteString("ensure ")
@@ -167,26 +302,73 @@ func (e *EnsureStmt) String() string {
 		out.WriteString(" when ")
 		out.WriteString(e.Guard.String())
 	}
+	if e.Timeout > 0 {
+		out.WriteString(fmt.Sprintf(" timeout %q", e.Timeout.String()))
+	}
+	if e.Severity != "" {
+		out.WriteString(" severity ")
+		out.WriteString(e.Severity)
+	}
+	if len(e.Tags) > 0 {
+		out.WriteString(" tags")
+		for _, tag := range e.Tags {
+			out.WriteString(fmt.Sprintf(" %q", tag))
+		}
+	}
+	if e.During != "" {
+		out.WriteString(fmt.Sprintf(" during %q", e.During))
+	}
+	if e.Within != "" {
+		out.WriteString(fmt.Sprintf(" within %q", e.Within))
+	}
 	for _, r := range e.Requires {
 		out.WriteString(" requires ")
 		out.WriteString(r)
 	}
+	for _, rc := range e.RequiresResource {
+		out.WriteString(" requires ")
+		out.WriteString(rc.String())
+	}
 	return out.String()
 }
 
+// ResourceCondition names a condition required on another resource.
+// Example: requires file "config.yml" exists
+type ResourceCondition struct {
+	Ref       *ResourceRef
+	Condition string // empty means every guarantee on the resource
+}
+
+func (rc *ResourceCondition) String() string {
+	if rc.Condition == "" {
+		return rc.Ref.String()
+	}
+	return fmt.Sprintf("%s %s", rc.Ref.String(), rc.Condition)
+}
+
 // OnBlock represents an "on resource { ... }" block.
 type OnBlock struct {
 	Position   lexer.Position
+	EndPos     lexer.Position
 	Subject    *ResourceRef
+	Tags       []string // labels inherited by every statement in the block
+	DocComment string   // leading comment block, if any
 	Statements []Statement
 }
 
-func (o *OnBlock) statementNode()        {}
-func (o *OnBlock) Pos() lexer.Position   { return o.Position }
+func (o *OnBlock) statementNode()      {}
+func (o *OnBlock) Pos() lexer.Position { return o.Position }
+func (o *OnBlock) End() lexer.Position { return o.EndPos }
 func (o *OnBlock) String() string {
 	var out strings.Builder
 	out.WriteString("on ")
 	out.WriteString(o.Subject.String())
+	if len(o.Tags) > 0 {
+		out.WriteString(" tags")
+		for _, tag := range o.Tags {
+			out.WriteString(fmt.Sprintf(" %q", tag))
+		}
+	}
 	out.WriteString(" {\n")
 	for _, s := range o.Statements {
 		out.WriteString("  ")
@@ -197,21 +379,27 @@ func (o *OnBlock) String() string {
 	return out.String()
 }
 
-// PolicyParam represents a policy parameter.
+// PolicyParam represents a policy parameter, optionally with a default
+// value. Example: policy secure_file(key_ref, mode = "0600")
 type PolicyParam struct {
-	Name string
+	Name       string
+	Default    string
+	HasDefault bool
 }
 
 // PolicyDecl represents a policy declaration.
 type PolicyDecl struct {
 	Position   lexer.Position
+	EndPos     lexer.Position
 	Name       string
 	Params     []PolicyParam
+	DocComment string // leading comment block, if any
 	Statements []Statement
 }
 
-func (p *PolicyDecl) statementNode()        {}
-func (p *PolicyDecl) Pos() lexer.Position   { return p.Position }
+func (p *PolicyDecl) statementNode()      {}
+func (p *PolicyDecl) Pos() lexer.Position { return p.Position }
+func (p *PolicyDecl) End() lexer.Position { return p.EndPos }
 func (p *PolicyDecl) String() string {
 	var out strings.Builder
 	out.WriteString("policy ")
@@ -223,6 +411,9 @@ func (p *PolicyDecl) String() string {
 				out.WriteString(", ")
 			}
 			out.WriteString(param.Name)
+			if param.HasDefault {
+				out.WriteString(fmt.Sprintf(" = %q", param.Default))
+			}
 		}
 		out.WriteString(")")
 	}
@@ -240,15 +431,25 @@ func (p *PolicyDecl) String() string {
 // Example: apply secure_file("env:SECRET_KEY")
 type ApplyStmt struct {
 	Position   lexer.Position
+	EndPos     lexer.Position
 	PolicyName string
-	Args       []string
+	Args       []string          // positional arguments
+	NamedArgs  map[string]string // named arguments (param: "value")
 }
 
-func (a *ApplyStmt) statementNode()        {}
-func (a *ApplyStmt) Pos() lexer.Position   { return a.Position }
+func (a *ApplyStmt) statementNode()      {}
+func (a *ApplyStmt) Pos() lexer.Position { return a.Position }
+func (a *ApplyStmt) End() lexer.Position { return a.EndPos }
 func (a *ApplyStmt) String() string {
-	if len(a.Args) > 0 {
-		return fmt.Sprintf("apply %s(%s)", a.PolicyName, strings.Join(a.Args, ", "))
+	args := append([]string{}, a.Args...)
+	named := make([]string, 0, len(a.NamedArgs))
+	for name, value := range a.NamedArgs {
+		named = append(named, fmt.Sprintf("%s: %q", name, value))
+	}
+	sort.Strings(named)
+	args = append(args, named...)
+	if len(args) > 0 {
+		return fmt.Sprintf("apply %s(%s)", a.PolicyName, strings.Join(args, ", "))
 	}
 	return fmt.Sprintf("apply %s", a.PolicyName)
 }
@@ -257,20 +458,36 @@ func (a *ApplyStmt) String() string {
 // Example: for each file in directory "/secrets" { ... }
 type ForEachStmt struct {
 	Position   lexer.Position
-	ItemType   string      // file, etc.
-	ItemVar    string      // implicit variable name
+	EndPos     lexer.Position
+	ItemType   string       // file, etc.
+	ItemVar    string       // implicit variable name
 	Container  *ResourceRef // directory, etc.
+	Matching   []string     // keep only entries matching one of these globs
+	Excluding  []string     // drop entries matching any of these globs
 	Statements []Statement
 }
 
-func (f *ForEachStmt) statementNode()        {}
-func (f *ForEachStmt) Pos() lexer.Position   { return f.Position }
+func (f *ForEachStmt) statementNode()      {}
+func (f *ForEachStmt) Pos() lexer.Position { return f.Position }
+func (f *ForEachStmt) End() lexer.Position { return f.EndPos }
 func (f *ForEachStmt) String() string {
 	var out strings.Builder
 	out.WriteString("for each ")
 	out.WriteString(f.ItemType)
 	out.WriteString(" in ")
 	out.WriteString(f.Container.String())
+	if len(f.Matching) > 0 {
+		out.WriteString(" matching")
+		for _, pat := range f.Matching {
+			out.WriteString(fmt.Sprintf(" %q", pat))
+		}
+	}
+	if len(f.Excluding) > 0 {
+		out.WriteString(" excluding")
+		for _, pat := range f.Excluding {
+			out.WriteString(fmt.Sprintf(" %q", pat))
+		}
+	}
 	out.WriteString(" {\n")
 	for _, s := range f.Statements {
 		out.WriteString("  ")
@@ -284,11 +501,14 @@ func (f *ForEachStmt) String() string {
 // InvariantBlock represents an invariant block.
 type InvariantBlock struct {
 	Position   lexer.Position
+	EndPos     lexer.Position
+	DocComment string // leading comment block, if any
 	Statements []Statement
 }
 
-func (i *InvariantBlock) statementNode()        {}
-func (i *InvariantBlock) Pos() lexer.Position   { return i.Position }
+func (i *InvariantBlock) statementNode()      {}
+func (i *InvariantBlock) Pos() lexer.Position { return i.Position }
+func (i *InvariantBlock) End() lexer.Position { return i.EndPos }
 func (i *InvariantBlock) String() string {
 	var out strings.Builder
 	out.WriteString("invariant {\n")
@@ -304,25 +524,49 @@ func (i *InvariantBlock) String() string {
 // OnViolationBlock represents a global violation handler.
 type OnViolationBlock struct {
 	Position lexer.Position
+	EndPos   lexer.Position
 	Handler  *ViolationHandler
 }
 
-func (o *OnViolationBlock) statementNode()        {}
-func (o *OnViolationBlock) Pos() lexer.Position   { return o.Position }
+func (o *OnViolationBlock) statementNode()      {}
+func (o *OnViolationBlock) Pos() lexer.Position { return o.Position }
+func (o *OnViolationBlock) End() lexer.Position { return o.EndPos }
 func (o *OnViolationBlock) String() string {
 	return fmt.Sprintf("on violation {\n  %s\n}", o.Handler.String())
 }
 
+// ImportStmt imports policy declarations from an external module.
+// Example: import policy "github.com/org/ensura-policies//hardening" sha256 "abc..."
+type ImportStmt struct {
+	Position lexer.Position
+	EndPos   lexer.Position
+	Kind     string // what is imported; currently only "policy"
+	Path     string // module reference: repo host/path with optional //subdir
+	SHA256   string // optional integrity pin over the module's source files
+}
+
+func (i *ImportStmt) statementNode()      {}
+func (i *ImportStmt) Pos() lexer.Position { return i.Position }
+func (i *ImportStmt) End() lexer.Position { return i.EndPos }
+func (i *ImportStmt) String() string {
+	if i.SHA256 != "" {
+		return fmt.Sprintf("import %s %q sha256 %q", i.Kind, i.Path, i.SHA256)
+	}
+	return fmt.Sprintf("import %s %q", i.Kind, i.Path)
+}
+
 // AssumeStmt represents an assumption.
 // Example: assume environment == "dev"
 type AssumeStmt struct {
 	Position lexer.Position
+	EndPos   lexer.Position
 	Guard    *GuardExpr
 	Simple   string // for simple assumptions like "filesystem reliable"
 }
 
-func (a *AssumeStmt) statementNode()        {}
-func (a *AssumeStmt) Pos() lexer.Position   { return a.Position }
+func (a *AssumeStmt) statementNode()      {}
+func (a *AssumeStmt) Pos() lexer.Position { return a.Position }
+func (a *AssumeStmt) End() lexer.Position { return a.EndPos }
 func (a *AssumeStmt) String() string {
 	if a.Guard != nil {
 		return fmt.Sprintf("assume %s", a.Guard.String())
@@ -333,11 +577,13 @@ func (a *AssumeStmt) String() string {
 // ParallelBlock represents a parallel execution block (v2 feature).
 type ParallelBlock struct {
 	Position   lexer.Position
+	EndPos     lexer.Position
 	Statements []Statement
 }
 
-func (p *ParallelBlock) statementNode()        {}
-func (p *ParallelBlock) Pos() lexer.Position   { return p.Position }
+func (p *ParallelBlock) statementNode()      {}
+func (p *ParallelBlock) Pos() lexer.Position { return p.Position }
+func (p *ParallelBlock) End() lexer.Position { return p.EndPos }
 func (p *ParallelBlock) String() string {
 	var out strings.Builder
 	out.WriteString("parallel {\n")
@@ -349,3 +595,12 @@ func (p *ParallelBlock) String() string {
 	out.WriteString("}")
 	return out.String()
 }
+
+// Diag is a structured diagnostic carrying the source range of the
+// offending construct. Compile stages record them alongside their
+// formatted error strings so editors can show precise ranges.
+type Diag struct {
+	Pos lexer.Position
+	End lexer.Position
+	Msg string
+}
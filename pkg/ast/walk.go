@@ -0,0 +1,128 @@
+package ast
+
+// A Visitor's Visit method is invoked for each node encountered by Walk.
+// If the result visitor w is not nil, Walk visits each of the children
+// of node with the visitor w, followed by a call of w.Visit(nil).
+type Visitor interface {
+	Visit(node Node) (w Visitor)
+}
+
+// Walk traverses an AST in depth-first order: it starts by calling
+// v.Visit(node); node must not be nil. If the visitor w returned by
+// v.Visit(node) is not nil, Walk is invoked recursively with visitor w
+// for each of the non-nil children of node, followed by a call of
+// w.Visit(nil).
+func Walk(v Visitor, node Node) {
+	if v = v.Visit(node); v == nil {
+		return
+	}
+
+	switch n := node.(type) {
+	case *Program:
+		walkStatements(v, n.Statements)
+
+	case *ResourceDecl:
+		// no children
+
+	case *ResourceRef:
+		if n.Container != nil {
+			Walk(v, n.Container)
+		}
+
+	case *HandlerSpec:
+		// no children
+
+	case *ViolationHandler:
+		// no children
+
+	case *GuardExpr:
+		// no children
+
+	case *EnsureStmt:
+		if n.Subject != nil {
+			Walk(v, n.Subject)
+		}
+		if n.Handler != nil {
+			Walk(v, n.Handler)
+		}
+		if n.Guard != nil {
+			Walk(v, n.Guard)
+		}
+		for _, rc := range n.RequiresResource {
+			if rc.Ref != nil {
+				Walk(v, rc.Ref)
+			}
+		}
+		for _, ref := range n.After {
+			Walk(v, ref)
+		}
+		for _, ref := range n.Before {
+			Walk(v, ref)
+		}
+		if n.ViolationHandler != nil {
+			Walk(v, n.ViolationHandler)
+		}
+
+	case *OnBlock:
+		if n.Subject != nil {
+			Walk(v, n.Subject)
+		}
+		walkStatements(v, n.Statements)
+
+	case *PolicyDecl:
+		walkStatements(v, n.Statements)
+
+	case *ApplyStmt:
+		// no children
+
+	case *ForEachStmt:
+		if n.Container != nil {
+			Walk(v, n.Container)
+		}
+		walkStatements(v, n.Statements)
+
+	case *InvariantBlock:
+		walkStatements(v, n.Statements)
+
+	case *OnViolationBlock:
+		if n.Handler != nil {
+			Walk(v, n.Handler)
+		}
+
+	case *ImportStmt:
+		// no children
+
+	case *AssumeStmt:
+		if n.Guard != nil {
+			Walk(v, n.Guard)
+		}
+
+	case *ParallelBlock:
+		walkStatements(v, n.Statements)
+	}
+
+	v.Visit(nil)
+}
+
+func walkStatements(v Visitor, stmts []Statement) {
+	for _, stmt := range stmts {
+		Walk(v, stmt)
+	}
+}
+
+type inspector func(Node) bool
+
+func (f inspector) Visit(node Node) Visitor {
+	if f(node) {
+		return f
+	}
+	return nil
+}
+
+// Inspect traverses an AST in depth-first order: it starts by calling
+// f(node); node must not be nil. If f returns true, Inspect invokes f
+// recursively for each of the non-nil children of node, followed by a
+// call of f(nil).
+func Inspect(node Node, f func(Node) bool) {
+	Walk(inspector(f), node)
+}
@@ -0,0 +1,89 @@
+package ast
+
+import "testing"
+
+func sampleProgram() *Program {
+	return &Program{
+		Statements: []Statement{
+			&ResourceDecl{ResourceType: "file", Path: "/etc/app.conf", Alias: "conf"},
+			&OnBlock{
+				Subject: &ResourceRef{Alias: "conf"},
+				Statements: []Statement{
+					&EnsureStmt{Condition: "exists"},
+					&EnsureStmt{
+						Condition: "encrypted",
+						Handler:   &HandlerSpec{Name: "AES:256", Args: map[string]string{"key": "env:KEY"}},
+					},
+				},
+			},
+			&InvariantBlock{
+				Statements: []Statement{
+					&EnsureStmt{Condition: "running", Subject: &ResourceRef{ResourceType: "service", Path: "sshd"}},
+				},
+			},
+		},
+	}
+}
+
+func TestInspect(t *testing.T) {
+	var ensures, refs int
+	Inspect(sampleProgram(), func(node Node) bool {
+		switch node.(type) {
+		case *EnsureStmt:
+			ensures++
+		case *ResourceRef:
+			refs++
+		}
+		return true
+	})
+
+	if ensures != 3 {
+		t.Errorf("Expected 3 ensure statements, got %d", ensures)
+	}
+	if refs != 2 {
+		t.Errorf("Expected 2 resource refs, got %d", refs)
+	}
+}
+
+func TestInspectPrunes(t *testing.T) {
+	var ensures int
+	Inspect(sampleProgram(), func(node Node) bool {
+		switch node.(type) {
+		case *InvariantBlock:
+			return false
+		case *EnsureStmt:
+			ensures++
+		}
+		return true
+	})
+
+	if ensures != 2 {
+		t.Errorf("Expected pruned walk to see 2 ensure statements, got %d", ensures)
+	}
+}
+
+func TestWalkVisitsHandlerAndGuard(t *testing.T) {
+	stmt := &EnsureStmt{
+		Condition: "exists",
+		Handler:   &HandlerSpec{Name: "fs.native"},
+		Guard:     &GuardExpr{Left: "environment", Operator: "==", Right: "prod"},
+	}
+
+	var sawHandler, sawGuard bool
+	Inspect(stmt, func(node Node) bool {
+		switch node.(type) {
+		case *HandlerSpec:
+			sawHandler = true
+		case *GuardExpr:
+			sawGuard = true
+		}
+		return true
+	})
+
+	if !sawHandler {
+		t.Error("Expected walk to visit the handler spec")
+	}
+	if !sawGuard {
+		t.Error("Expected walk to visit the guard expression")
+	}
+}
@@ -0,0 +1,63 @@
+// Package atomicfile replaces file contents atomically so that a crash
+// mid-write never leaves a partially written file behind. Handlers that
+// mutate managed files (fs, aes, template) write through this package.
+package atomicfile
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// Write replaces the file at path with data via a temp file in the same
+// directory: write, fsync, then rename over the target. When the target
+// already exists its mode and owner are preserved; otherwise mode is used
+// for the new file.
+func Write(path string, data []byte, mode os.FileMode) error {
+	var existing os.FileInfo
+	if info, err := os.Stat(path); err == nil {
+		existing = info
+		mode = info.Mode().Perm()
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".ensura-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(mode); err != nil {
+		tmp.Close()
+		return err
+	}
+	if existing != nil {
+		preserveOwner(existing, tmp)
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// WriteWithBackup is Write, but first retains the current content of the
+// target as path.bak so an enforcement that overwrote the wrong thing can
+// be recovered by hand.
+func WriteWithBackup(path string, data []byte, mode os.FileMode) error {
+	if current, err := os.ReadFile(path); err == nil {
+		backupMode := mode
+		if info, err := os.Stat(path); err == nil {
+			backupMode = info.Mode().Perm()
+		}
+		if err := os.WriteFile(path+".bak", current, backupMode); err != nil {
+			return err
+		}
+	}
+	return Write(path, data, mode)
+}
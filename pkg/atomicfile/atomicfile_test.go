@@ -0,0 +1,98 @@
+package atomicfile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteNewFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	target := filepath.Join(tmpDir, "new.txt")
+
+	if err := Write(target, []byte("content"), 0640); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "content" {
+		t.Errorf("Expected written content, got %q", data)
+	}
+
+	info, err := os.Stat(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0640 {
+		t.Errorf("Expected mode 0640, got %o", info.Mode().Perm())
+	}
+}
+
+func TestWritePreservesMode(t *testing.T) {
+	tmpDir := t.TempDir()
+	target := filepath.Join(tmpDir, "existing.txt")
+	if err := os.WriteFile(target, []byte("old"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Write(target, []byte("new"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := os.Stat(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("Expected existing mode 0600 to be preserved, got %o", info.Mode().Perm())
+	}
+
+	// No temp files left behind
+	entries, err := os.ReadDir(tmpDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("Expected only the target file, found %d entries", len(entries))
+	}
+}
+
+func TestWriteWithBackup(t *testing.T) {
+	tmpDir := t.TempDir()
+	target := filepath.Join(tmpDir, "config.txt")
+	if err := os.WriteFile(target, []byte("original"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := WriteWithBackup(target, []byte("replaced"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	backup, err := os.ReadFile(target + ".bak")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(backup) != "original" {
+		t.Errorf("Expected backup to hold original content, got %q", backup)
+	}
+
+	current, err := os.ReadFile(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(current) != "replaced" {
+		t.Errorf("Expected replaced content, got %q", current)
+	}
+
+	// No backup is created for a previously missing target
+	fresh := filepath.Join(tmpDir, "fresh.txt")
+	if err := WriteWithBackup(fresh, []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(fresh + ".bak"); !os.IsNotExist(err) {
+		t.Error("Expected no backup for a new file")
+	}
+}
@@ -0,0 +1,16 @@
+//go:build !windows
+
+package atomicfile
+
+import (
+	"os"
+	"syscall"
+)
+
+// preserveOwner copies the existing file's uid/gid onto the temp file.
+// Best effort: only root may change ownership, so errors are ignored.
+func preserveOwner(info os.FileInfo, f *os.File) {
+	if st, ok := info.Sys().(*syscall.Stat_t); ok {
+		f.Chown(int(st.Uid), int(st.Gid))
+	}
+}
@@ -0,0 +1,9 @@
+//go:build windows
+
+package atomicfile
+
+import "os"
+
+// preserveOwner is a no-op on Windows, where file ownership does not map
+// onto uid/gid.
+func preserveOwner(info os.FileInfo, f *os.File) {}
@@ -3,8 +3,13 @@ package binder
 
 import (
 	"fmt"
+	"path/filepath"
+	"strings"
 
 	"github.com/ensurascript/ensura/pkg/ast"
+	"github.com/ensurascript/ensura/pkg/lexer"
+	"github.com/ensurascript/ensura/pkg/modules"
+	"github.com/ensurascript/ensura/pkg/stdlib"
 )
 
 // ResourceTable holds declared resources and their aliases.
@@ -82,6 +87,7 @@ type Binder struct {
 	resources *ResourceTable
 	policies  *PolicyTable
 	errors    []string
+	diags     []ast.Diag
 }
 
 // New creates a new Binder.
@@ -97,8 +103,19 @@ func (b *Binder) Errors() []string {
 	return b.errors
 }
 
+// Diagnostics returns all binding errors with their source ranges.
+func (b *Binder) Diagnostics() []ast.Diag {
+	return b.diags
+}
+
 func (b *Binder) addError(pos interface{}, msg string) {
 	b.errors = append(b.errors, fmt.Sprintf("%v: %s", pos, msg))
+	diag := ast.Diag{Msg: msg}
+	if p, ok := pos.(lexer.Position); ok {
+		diag.Pos = p
+		diag.End = p
+	}
+	b.diags = append(b.diags, diag)
 }
 
 // Bind processes the AST and resolves implicit subjects.
@@ -114,6 +131,8 @@ func (b *Binder) Bind(program *ast.Program) *ast.Program {
 			if err := b.policies.Add(s); err != nil {
 				b.addError(s.Position, err.Error())
 			}
+		case *ast.ImportStmt:
+			b.importPolicies(s)
 		}
 	}
 
@@ -161,6 +180,9 @@ func (b *Binder) bindStatement(stmt ast.Statement, lastSubject **ast.ResourceRef
 	case *ast.AssumeStmt:
 		return s
 
+	case *ast.ImportStmt:
+		return s
+
 	case *ast.ParallelBlock:
 		return b.bindParallelBlock(s)
 
@@ -183,6 +205,18 @@ func (b *Binder) bindEnsureStmt(stmt *ast.EnsureStmt, lastSubject **ast.Resource
 		*lastSubject = stmt.Subject
 	}
 
+	// Resolve ordering and dependency references so aliases match
+	// guarantees declared with inline subjects
+	for _, ref := range stmt.After {
+		b.validateResourceRef(ref)
+	}
+	for _, ref := range stmt.Before {
+		b.validateResourceRef(ref)
+	}
+	for _, rc := range stmt.RequiresResource {
+		b.validateResourceRef(rc.Ref)
+	}
+
 	// Validate handler if specified
 	if stmt.Handler != nil {
 		b.validateHandler(stmt.Handler, stmt.Condition)
@@ -206,6 +240,7 @@ func (b *Binder) bindOnBlock(block *ast.OnBlock, lastSubject **ast.ResourceRef)
 			if s.Subject == nil {
 				s.Subject = blockSubject
 			}
+			s.Tags = mergeTags(block.Tags, s.Tags)
 			boundStmt := b.bindEnsureStmt(s, &blockSubject)
 			if boundStmt != nil {
 				boundStatements = append(boundStatements, boundStmt)
@@ -229,21 +264,101 @@ func (b *Binder) bindPolicyDecl(decl *ast.PolicyDecl) *ast.PolicyDecl {
 	return decl
 }
 
+// importPolicies loads policy declarations from an external module into the
+// policy table, verifying the integrity pin when one is declared.
+func (b *Binder) importPolicies(stmt *ast.ImportStmt) {
+	if stmt.Kind != "policy" {
+		b.addError(stmt.Position, fmt.Sprintf("unsupported import kind: %s", stmt.Kind))
+		return
+	}
+
+	dir, err := modules.Resolve(stmt.Path)
+	if err != nil {
+		b.addError(stmt.Position, err.Error())
+		return
+	}
+
+	if stmt.SHA256 != "" {
+		sum, err := modules.Checksum(dir)
+		if err != nil {
+			b.addError(stmt.Position, err.Error())
+			return
+		}
+		if sum != stmt.SHA256 {
+			b.addError(stmt.Position, fmt.Sprintf("module %s checksum mismatch: got %s, want %s",
+				stmt.Path, sum, stmt.SHA256))
+			return
+		}
+	}
+
+	policies, err := modules.LoadPolicies(dir)
+	if err != nil {
+		b.addError(stmt.Position, err.Error())
+		return
+	}
+	for _, decl := range policies {
+		if err := b.policies.Add(decl); err != nil {
+			b.addError(stmt.Position, err.Error())
+		}
+	}
+}
+
+// lookupPolicy resolves a policy by name, falling back to the built-in
+// library for std.-prefixed names.
+func (b *Binder) lookupPolicy(name string) (*ast.PolicyDecl, bool) {
+	if decl, ok := b.policies.Lookup(name); ok {
+		return decl, true
+	}
+	if short, ok := strings.CutPrefix(name, "std."); ok {
+		return stdlib.Policy(short)
+	}
+	return nil, false
+}
+
 func (b *Binder) bindApplyStmt(stmt *ast.ApplyStmt, currentSubject *ast.ResourceRef) *ast.ApplyStmt {
 	// Validate policy exists
-	policy, ok := b.policies.Lookup(stmt.PolicyName)
+	policy, ok := b.lookupPolicy(stmt.PolicyName)
 	if !ok {
 		b.addError(stmt.Position, fmt.Sprintf("undefined policy: %s", stmt.PolicyName))
 		return nil
 	}
 
-	// Validate argument count
-	if len(stmt.Args) != len(policy.Params) {
-		b.addError(stmt.Position, fmt.Sprintf("policy %s expects %d arguments, got %d",
+	// Validate arguments: no more positionals than parameters, named
+	// arguments must name real parameters, and every parameter without a
+	// default must receive a value
+	if len(stmt.Args) > len(policy.Params) {
+		b.addError(stmt.Position, fmt.Sprintf("policy %s expects at most %d arguments, got %d",
 			stmt.PolicyName, len(policy.Params), len(stmt.Args)))
 		return nil
 	}
 
+	byName := make(map[string]int)
+	for i, param := range policy.Params {
+		byName[param.Name] = i
+	}
+	for name := range stmt.NamedArgs {
+		i, ok := byName[name]
+		if !ok {
+			b.addError(stmt.Position, fmt.Sprintf("policy %s has no parameter %q", stmt.PolicyName, name))
+			return nil
+		}
+		if i < len(stmt.Args) {
+			b.addError(stmt.Position, fmt.Sprintf("policy %s parameter %q given both positionally and by name",
+				stmt.PolicyName, name))
+			return nil
+		}
+	}
+	for i, param := range policy.Params {
+		if i < len(stmt.Args) || param.HasDefault {
+			continue
+		}
+		if _, ok := stmt.NamedArgs[param.Name]; !ok {
+			b.addError(stmt.Position, fmt.Sprintf("policy %s missing argument for parameter %q",
+				stmt.PolicyName, param.Name))
+			return nil
+		}
+	}
+
 	return stmt
 }
 
@@ -251,6 +366,15 @@ func (b *Binder) bindForEachStmt(stmt *ast.ForEachStmt) *ast.ForEachStmt {
 	// Validate container reference
 	b.validateResourceRef(stmt.Container)
 
+	// A glob container's path is a pattern; reject malformed ones here
+	// rather than on every runtime expansion.
+	if stmt.Container != nil && stmt.Container.ResourceType == "glob" {
+		if _, err := filepath.Match(stmt.Container.Path, ""); err != nil {
+			b.addError(stmt.Container.Position,
+				fmt.Sprintf("invalid glob pattern %q", stmt.Container.Path))
+		}
+	}
+
 	// Create an implicit subject representing the iterator item
 	// This is a placeholder that will be resolved at runtime when iterating
 	iteratorSubject := &ast.ResourceRef{
@@ -258,6 +382,9 @@ func (b *Binder) bindForEachStmt(stmt *ast.ForEachStmt) *ast.ForEachStmt {
 		ResourceType: stmt.ItemType,
 		Path:         "__foreach_item__",
 		IsIterator:   true,
+		Container:    stmt.Container,
+		Matching:     stmt.Matching,
+		Excluding:    stmt.Excluding,
 	}
 
 	// Bind statements with the iterator as the implicit subject
@@ -316,11 +443,16 @@ func (b *Binder) validateResourceRef(ref *ast.ResourceRef) {
 		return
 	}
 
-	// If it's an alias, look it up
+	// If it's an alias, look it up and fill in the declared type and path so
+	// later phases can match the reference against inline declarations
 	if ref.Alias != "" {
-		if _, ok := b.resources.Lookup(ref); !ok {
+		decl, ok := b.resources.Lookup(ref)
+		if !ok {
 			b.addError(ref.Position, fmt.Sprintf("undefined resource alias: %s", ref.Alias))
+			return
 		}
+		ref.ResourceType = decl.ResourceType
+		ref.Path = decl.Path
 	}
 	// Inline references don't need to be declared (they're implicit declarations)
 }
@@ -366,52 +498,207 @@ func (b *Binder) expandOnBlock(block *ast.OnBlock) *ast.OnBlock {
 }
 
 func (b *Binder) expandApply(apply *ast.ApplyStmt, subject *ast.ResourceRef) []ast.Statement {
-	policy, ok := b.policies.Lookup(apply.PolicyName)
+	return b.expandApplyRec(apply, subject, nil, make(map[string]bool))
+}
+
+// expandApplyRec expands one apply statement. outerParams holds the
+// parameter bindings of the enclosing policy (nil at the top level) so that
+// nested apply arguments referring to parameters are substituted before
+// recursing. The expanding set detects policies that apply themselves,
+// directly or through another policy.
+func (b *Binder) expandApplyRec(apply *ast.ApplyStmt, subject *ast.ResourceRef, outerParams map[string]string, expanding map[string]bool) []ast.Statement {
+	policy, ok := b.lookupPolicy(apply.PolicyName)
 	if !ok {
 		return nil
 	}
 
-	// Build parameter substitution map
+	if expanding[apply.PolicyName] {
+		b.addError(apply.Position, fmt.Sprintf("policy expansion cycle involving %q", apply.PolicyName))
+		return nil
+	}
+	expanding[apply.PolicyName] = true
+	defer delete(expanding, apply.PolicyName)
+
+	// Build parameter substitution map: defaults first, then positional
+	// and named arguments, resolving each argument through the enclosing
+	// policy's parameters
+	resolve := func(arg string) string {
+		if subst, ok := outerParams[arg]; ok {
+			return subst
+		}
+		return arg
+	}
 	params := make(map[string]string)
 	for i, param := range policy.Params {
+		if param.HasDefault {
+			params[param.Name] = param.Default
+		}
 		if i < len(apply.Args) {
-			params[param.Name] = apply.Args[i]
+			params[param.Name] = resolve(apply.Args[i])
 		}
 	}
+	for name, value := range apply.NamedArgs {
+		params[name] = resolve(value)
+	}
 
-	// Expand policy statements
+	return b.expandPolicyStatements(policy.Statements, subject, params, expanding)
+}
+
+// expandPolicyStatements clones a policy's body with parameters substituted,
+// recursing into nested applies and statement blocks.
+func (b *Binder) expandPolicyStatements(statements []ast.Statement, subject *ast.ResourceRef, params map[string]string, expanding map[string]bool) []ast.Statement {
 	var expanded []ast.Statement
-	for _, stmt := range policy.Statements {
-		if ensure, ok := stmt.(*ast.EnsureStmt); ok {
-			// Clone the ensure statement and substitute parameters
-			newEnsure := &ast.EnsureStmt{
-				Position:  apply.Position,
-				Condition: ensure.Condition,
-				Subject:   subject,
-				Guard:     ensure.Guard,
-				Requires:  ensure.Requires,
+	for _, stmt := range statements {
+		switch s := stmt.(type) {
+		case *ast.EnsureStmt:
+			expanded = append(expanded, b.expandPolicyEnsure(s, subject, params))
+		case *ast.ApplyStmt:
+			expanded = append(expanded, b.expandApplyRec(s, subject, params, expanding)...)
+		case *ast.ForEachStmt:
+			clone := &ast.ForEachStmt{
+				Position:  s.Position,
+				ItemType:  s.ItemType,
+				ItemVar:   s.ItemVar,
+				Container: s.Container,
+				Matching:  s.Matching,
+				Excluding: s.Excluding,
 			}
+			clone.Statements = b.expandPolicyStatements(s.Statements, nil, params, expanding)
+			expanded = append(expanded, clone)
+		default:
+			expanded = append(expanded, stmt)
+		}
+	}
+	return expanded
+}
+
+// mergeTags combines a block's tags with a statement's own, preserving
+// order and dropping duplicates.
+func mergeTags(block, own []string) []string {
+	if len(block) == 0 {
+		return own
+	}
+	merged := append([]string(nil), block...)
+	seen := make(map[string]bool, len(block))
+	for _, tag := range block {
+		seen[tag] = true
+	}
+	for _, tag := range own {
+		if !seen[tag] {
+			merged = append(merged, tag)
+			seen[tag] = true
+		}
+	}
+	return merged
+}
 
-			// Substitute handler parameters
-			if ensure.Handler != nil {
-				newHandler := &ast.HandlerSpec{
-					Position: ensure.Handler.Position,
-					Name:     ensure.Handler.Name,
-					Args:     make(map[string]string),
-				}
-				for k, v := range ensure.Handler.Args {
-					if subst, ok := params[v]; ok {
-						newHandler.Args[k] = subst
-					} else {
-						newHandler.Args[k] = v
-					}
-				}
-				newEnsure.Handler = newHandler
+// substParam replaces a value that names a policy parameter with the
+// parameter's bound value; anything else passes through unchanged.
+func substParam(value string, params map[string]string) string {
+	if subst, ok := params[value]; ok {
+		return subst
+	}
+	return value
+}
+
+// substParamRefs clones a resource ref list, substituting policy
+// parameters in each path.
+func substParamRefs(refs []*ast.ResourceRef, params map[string]string) []*ast.ResourceRef {
+	if len(refs) == 0 {
+		return nil
+	}
+	out := make([]*ast.ResourceRef, len(refs))
+	for i, ref := range refs {
+		clone := *ref
+		clone.Path = substParam(clone.Path, params)
+		out[i] = &clone
+	}
+	return out
+}
+
+func (b *Binder) expandPolicyEnsure(ensure *ast.EnsureStmt, subject *ast.ResourceRef, params map[string]string) *ast.EnsureStmt {
+	// Clone the ensure statement and substitute parameters
+	newEnsure := &ast.EnsureStmt{
+		Position:   ensure.Position,
+		Condition:  ensure.Condition,
+		Subject:    subject,
+		Requires:   ensure.Requires,
+		Timeout:    ensure.Timeout,
+		Severity:   ensure.Severity,
+		Tags:       ensure.Tags,
+		During:     ensure.During,
+		Within:     ensure.Within,
+		DocComment: ensure.DocComment,
+	}
+
+	// Ordering clauses and cross-resource requirements may name a
+	// parameter in their paths
+	newEnsure.After = substParamRefs(ensure.After, params)
+	newEnsure.Before = substParamRefs(ensure.Before, params)
+	if len(ensure.RequiresResource) > 0 {
+		newEnsure.RequiresResource = make([]*ast.ResourceCondition, len(ensure.RequiresResource))
+		for i, rc := range ensure.RequiresResource {
+			clone := *rc
+			if rc.Ref != nil {
+				ref := *rc.Ref
+				ref.Path = substParam(ref.Path, params)
+				clone.Ref = &ref
 			}
+			newEnsure.RequiresResource[i] = &clone
+		}
+	}
+
+	// A subject declared in the policy body wins over the apply site; its
+	// path may name a parameter
+	if ensure.Subject != nil {
+		sub := *ensure.Subject
+		sub.Path = substParam(sub.Path, params)
+		newEnsure.Subject = &sub
+	}
 
-			expanded = append(expanded, newEnsure)
+	// Guard right-hand sides may name a parameter
+	if ensure.Guard != nil {
+		guard := *ensure.Guard
+		guard.Right = substParam(guard.Right, params)
+		newEnsure.Guard = &guard
+	}
+
+	// Notify targets may name a parameter
+	if ensure.ViolationHandler != nil {
+		vh := *ensure.ViolationHandler
+		vh.Notify = append([]string(nil), ensure.ViolationHandler.Notify...)
+		for i := range vh.Notify {
+			vh.Notify[i] = substParam(vh.Notify[i], params)
+		}
+		vh.NotifyRules = append([]ast.NotifyRule(nil), ensure.ViolationHandler.NotifyRules...)
+		for i := range vh.NotifyRules {
+			vh.NotifyRules[i].Target = substParam(vh.NotifyRules[i].Target, params)
 		}
+		newEnsure.ViolationHandler = &vh
 	}
 
-	return expanded
+	// Substitute handler parameters
+	if ensure.Handler != nil {
+		newHandler := &ast.HandlerSpec{
+			Position: ensure.Handler.Position,
+			Name:     ensure.Handler.Name,
+			Args:     make(map[string]string),
+		}
+		for k, v := range ensure.Handler.Args {
+			if subst, ok := params[v]; ok {
+				newHandler.Args[k] = subst
+			} else {
+				newHandler.Args[k] = v
+			}
+		}
+		if len(ensure.Handler.ArgTypes) > 0 {
+			newHandler.ArgTypes = make(map[string]string, len(ensure.Handler.ArgTypes))
+			for k, v := range ensure.Handler.ArgTypes {
+				newHandler.ArgTypes[k] = v
+			}
+		}
+		newEnsure.Handler = newHandler
+	}
+
+	return newEnsure
 }
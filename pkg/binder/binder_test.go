@@ -1,7 +1,9 @@
 package binder
 
 import (
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/ensurascript/ensura/pkg/ast"
 	"github.com/ensurascript/ensura/pkg/parser"
@@ -173,6 +175,228 @@ on file "secrets.db" {
 	}
 }
 
+func TestPolicyParamDefaultsAndNamedArgs(t *testing.T) {
+	input := `policy secure_file(key_ref, mode = "0600") {
+  ensure encrypted with AES:256 key key_ref
+  ensure permissions with posix mode mode
+}
+
+on file "secrets.db" {
+  apply secure_file(key_ref: "env:KEY")
+}`
+
+	program, errors := parser.ParseString(input)
+	if len(errors) > 0 {
+		t.Fatalf("Parse errors: %v", errors)
+	}
+
+	b := New()
+	program = b.Bind(program)
+	program = b.ExpandPolicies(program)
+
+	if len(b.Errors()) > 0 {
+		t.Fatalf("Binding errors: %v", b.Errors())
+	}
+
+	block, ok := program.Statements[1].(*ast.OnBlock)
+	if !ok {
+		t.Fatal("Expected OnBlock")
+	}
+	for _, stmt := range block.Statements {
+		ensure, ok := stmt.(*ast.EnsureStmt)
+		if !ok {
+			continue
+		}
+		switch ensure.Condition {
+		case "encrypted":
+			if ensure.Handler.Args["key"] != "env:KEY" {
+				t.Errorf("Expected named argument substituted, got %q", ensure.Handler.Args["key"])
+			}
+		case "permissions":
+			if ensure.Handler.Args["mode"] != "0600" {
+				t.Errorf("Expected default mode substituted, got %q", ensure.Handler.Args["mode"])
+			}
+		}
+	}
+}
+
+func TestPolicyMissingRequiredArg(t *testing.T) {
+	input := `policy secure_file(key_ref, mode = "0600") {
+  ensure encrypted with AES:256 key key_ref
+}
+
+on file "secrets.db" {
+  apply secure_file()
+}`
+
+	program, errors := parser.ParseString(input)
+	if len(errors) > 0 {
+		t.Fatalf("Parse errors: %v", errors)
+	}
+
+	b := New()
+	b.Bind(program)
+
+	found := false
+	for _, e := range b.Errors() {
+		if strings.Contains(e, "missing argument") {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Expected missing argument error, got %v", b.Errors())
+	}
+}
+
+func TestApplyStdPolicy(t *testing.T) {
+	input := `on file "secrets.db" {
+  apply std.secure_file("env:KEY")
+}`
+
+	program, errors := parser.ParseString(input)
+	if len(errors) > 0 {
+		t.Fatalf("Parse errors: %v", errors)
+	}
+
+	b := New()
+	program = b.Bind(program)
+	program = b.ExpandPolicies(program)
+
+	if len(b.Errors()) > 0 {
+		t.Fatalf("Binding errors: %v", b.Errors())
+	}
+
+	block, ok := program.Statements[0].(*ast.OnBlock)
+	if !ok {
+		t.Fatal("Expected OnBlock")
+	}
+	if len(block.Statements) != 3 {
+		t.Fatalf("Expected 3 statements from std.secure_file, got %d", len(block.Statements))
+	}
+	encrypted, ok := block.Statements[1].(*ast.EnsureStmt)
+	if !ok || encrypted.Condition != "encrypted" {
+		t.Fatalf("Expected encrypted statement, got %v", block.Statements[1])
+	}
+	if encrypted.Handler.Args["key"] != "env:KEY" {
+		t.Errorf("Expected key substituted, got %q", encrypted.Handler.Args["key"])
+	}
+}
+
+func TestPolicyParamInPathAndGuard(t *testing.T) {
+	input := `policy managed_dir(path, env) {
+  ensure exists on directory path when environment == env
+}
+
+on file "secrets.db" {
+  apply managed_dir("/var/data", "prod")
+}`
+
+	program, errors := parser.ParseString(input)
+	if len(errors) > 0 {
+		t.Fatalf("Parse errors: %v", errors)
+	}
+
+	b := New()
+	program = b.Bind(program)
+	program = b.ExpandPolicies(program)
+
+	if len(b.Errors()) > 0 {
+		t.Fatalf("Binding errors: %v", b.Errors())
+	}
+
+	block, ok := program.Statements[1].(*ast.OnBlock)
+	if !ok {
+		t.Fatal("Expected OnBlock")
+	}
+	ensure, ok := block.Statements[0].(*ast.EnsureStmt)
+	if !ok {
+		t.Fatalf("Expected EnsureStmt, got %T", block.Statements[0])
+	}
+	if ensure.Subject == nil || ensure.Subject.ResourceType != "directory" || ensure.Subject.Path != "/var/data" {
+		t.Errorf("Expected substituted directory subject, got %v", ensure.Subject)
+	}
+	if ensure.Guard == nil || ensure.Guard.Right != "prod" {
+		t.Errorf("Expected substituted guard value, got %v", ensure.Guard)
+	}
+}
+
+func TestExpandNestedPolicies(t *testing.T) {
+	input := `policy base_file(key_ref) {
+  ensure encrypted with AES:256 key key_ref
+}
+
+policy secure_file(key_ref) {
+  apply base_file(key_ref)
+  ensure permissions with posix mode "0600"
+}
+
+on file "secrets.db" {
+  apply secure_file("env:KEY")
+}`
+
+	program, errors := parser.ParseString(input)
+	if len(errors) > 0 {
+		t.Fatalf("Parse errors: %v", errors)
+	}
+
+	b := New()
+	program = b.Bind(program)
+	program = b.ExpandPolicies(program)
+
+	if len(b.Errors()) > 0 {
+		t.Fatalf("Binding errors: %v", b.Errors())
+	}
+
+	block, ok := program.Statements[2].(*ast.OnBlock)
+	if !ok {
+		t.Fatal("Expected OnBlock")
+	}
+	if len(block.Statements) != 2 {
+		t.Fatalf("Expected 2 statements after nested expansion, got %d", len(block.Statements))
+	}
+
+	encrypted, ok := block.Statements[0].(*ast.EnsureStmt)
+	if !ok || encrypted.Condition != "encrypted" {
+		t.Fatalf("Expected encrypted from nested policy, got %v", block.Statements[0])
+	}
+	if encrypted.Handler.Args["key"] != "env:KEY" {
+		t.Errorf("Expected key argument forwarded through nested apply, got %q", encrypted.Handler.Args["key"])
+	}
+}
+
+func TestPolicyExpansionCycle(t *testing.T) {
+	input := `policy a(x) {
+  apply b(x)
+}
+
+policy b(x) {
+  apply a(x)
+}
+
+on file "secrets.db" {
+  apply a("env:KEY")
+}`
+
+	program, errors := parser.ParseString(input)
+	if len(errors) > 0 {
+		t.Fatalf("Parse errors: %v", errors)
+	}
+
+	b := New()
+	program = b.Bind(program)
+	b.ExpandPolicies(program)
+
+	found := false
+	for _, e := range b.Errors() {
+		if strings.Contains(e, "cycle") {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Expected policy cycle error, got %v", b.Errors())
+	}
+}
+
 func TestMissingImplicitSubject(t *testing.T) {
 	input := `ensure encrypted with AES:256 key "env:KEY"`
 
@@ -228,3 +452,101 @@ on file "secrets.db" {
 		t.Error("Expected error for wrong argument count")
 	}
 }
+
+func TestGlobContainer(t *testing.T) {
+	input := `for each file in glob "/etc/nginx/conf.d/*.conf" {
+  ensure exists
+}`
+
+	program, errors := parser.ParseString(input)
+	if len(errors) > 0 {
+		t.Fatalf("Parse errors: %v", errors)
+	}
+
+	b := New()
+	program = b.Bind(program)
+
+	if len(b.Errors()) > 0 {
+		t.Fatalf("Binding errors: %v", b.Errors())
+	}
+
+	forEach, ok := program.Statements[0].(*ast.ForEachStmt)
+	if !ok {
+		t.Fatalf("Expected ForEachStmt, got %T", program.Statements[0])
+	}
+	ensure, ok := forEach.Statements[0].(*ast.EnsureStmt)
+	if !ok {
+		t.Fatalf("Expected EnsureStmt, got %T", forEach.Statements[0])
+	}
+	if ensure.Subject == nil || !ensure.Subject.IsIterator {
+		t.Fatal("Expected iterator subject")
+	}
+	if ensure.Subject.Container.ResourceType != "glob" {
+		t.Errorf("Expected glob container, got %q", ensure.Subject.Container.ResourceType)
+	}
+}
+
+func TestMalformedGlobContainer(t *testing.T) {
+	input := `for each file in glob "/etc/[" {
+  ensure exists
+}`
+
+	program, errors := parser.ParseString(input)
+	if len(errors) > 0 {
+		t.Fatalf("Parse errors: %v", errors)
+	}
+
+	b := New()
+	b.Bind(program)
+
+	if len(b.Errors()) == 0 {
+		t.Error("Expected error for malformed glob pattern")
+	}
+}
+
+func TestPolicyPreservesClausesOnExpansion(t *testing.T) {
+	input := `policy ordered_file(dep) {
+  ensure exists timeout "45s" after file dep before file "/tmp/final" requires file "/tmp/base" exists
+}
+
+on file "/tmp/data" {
+  apply ordered_file("/tmp/dep.conf")
+}`
+
+	program, errors := parser.ParseString(input)
+	if len(errors) > 0 {
+		t.Fatalf("Parse errors: %v", errors)
+	}
+
+	b := New()
+	program = b.Bind(program)
+	program = b.ExpandPolicies(program)
+
+	if len(b.Errors()) > 0 {
+		t.Fatalf("Binding errors: %v", b.Errors())
+	}
+
+	block, ok := program.Statements[1].(*ast.OnBlock)
+	if !ok {
+		t.Fatal("Expected OnBlock")
+	}
+	ensure, ok := block.Statements[0].(*ast.EnsureStmt)
+	if !ok {
+		t.Fatalf("Expected EnsureStmt, got %T", block.Statements[0])
+	}
+
+	if ensure.Timeout != 45*time.Second {
+		t.Errorf("Expected timeout 45s, got %v", ensure.Timeout)
+	}
+	if len(ensure.After) != 1 || ensure.After[0].Path != "/tmp/dep.conf" {
+		t.Errorf("Expected after [/tmp/dep.conf] with parameter substituted, got %v", ensure.After)
+	}
+	if len(ensure.Before) != 1 || ensure.Before[0].Path != "/tmp/final" {
+		t.Errorf("Expected before [/tmp/final], got %v", ensure.Before)
+	}
+	if len(ensure.RequiresResource) != 1 ||
+		ensure.RequiresResource[0].Ref.Path != "/tmp/base" ||
+		ensure.RequiresResource[0].Condition != "exists" {
+		t.Errorf("Expected requires file /tmp/base exists, got %v", ensure.RequiresResource)
+	}
+}
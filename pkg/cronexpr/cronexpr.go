@@ -0,0 +1,138 @@
+// Package cronexpr validates cron schedule expressions. It is shared by the
+// planner, which rejects invalid schedules at compile time, and the cron
+// adapter, which refuses to install them.
+package cronexpr
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// shortcuts are the @-prefixed schedule aliases understood by Vixie cron.
+var shortcuts = map[string]bool{
+	"@reboot":   true,
+	"@yearly":   true,
+	"@annually": true,
+	"@monthly":  true,
+	"@weekly":   true,
+	"@daily":    true,
+	"@midnight": true,
+	"@hourly":   true,
+}
+
+// field describes the allowed values of one cron field.
+type field struct {
+	name  string
+	min   int
+	max   int
+	names map[string]bool
+}
+
+var monthNames = map[string]bool{
+	"jan": true, "feb": true, "mar": true, "apr": true, "may": true, "jun": true,
+	"jul": true, "aug": true, "sep": true, "oct": true, "nov": true, "dec": true,
+}
+
+var dayOfWeekNames = map[string]bool{
+	"sun": true, "mon": true, "tue": true, "wed": true, "thu": true, "fri": true, "sat": true,
+}
+
+var fields = []field{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12, names: monthNames},
+	// 0 and 7 both mean Sunday
+	{name: "day of week", min: 0, max: 7, names: dayOfWeekNames},
+}
+
+// Validate reports whether schedule is a well-formed cron expression:
+// either an @shortcut or five whitespace-separated fields of values,
+// ranges, steps, and lists within each field's allowed bounds.
+func Validate(schedule string) error {
+	schedule = strings.TrimSpace(schedule)
+	if schedule == "" {
+		return fmt.Errorf("schedule is empty")
+	}
+
+	if strings.HasPrefix(schedule, "@") {
+		if !shortcuts[schedule] {
+			return fmt.Errorf("unknown schedule shortcut %q", schedule)
+		}
+		return nil
+	}
+
+	parts := strings.Fields(schedule)
+	if len(parts) != 5 {
+		return fmt.Errorf("expected 5 fields, got %d", len(parts))
+	}
+
+	for i, part := range parts {
+		if err := validateField(part, fields[i]); err != nil {
+			return fmt.Errorf("invalid %s field %q: %w", fields[i].name, part, err)
+		}
+	}
+	return nil
+}
+
+// validateField checks one comma-separated list of values, ranges, and steps.
+func validateField(spec string, f field) error {
+	for _, item := range strings.Split(spec, ",") {
+		if item == "" {
+			return fmt.Errorf("empty list item")
+		}
+
+		// Split off an optional step suffix
+		base := item
+		if idx := strings.Index(item, "/"); idx >= 0 {
+			base = item[:idx]
+			step := item[idx+1:]
+			n, err := strconv.Atoi(step)
+			if err != nil || n < 1 {
+				return fmt.Errorf("step %q must be a positive number", step)
+			}
+		}
+
+		if base == "*" {
+			continue
+		}
+
+		// A range, or a single value
+		if idx := strings.Index(base, "-"); idx >= 0 {
+			lo, err := parseValue(base[:idx], f)
+			if err != nil {
+				return err
+			}
+			hi, err := parseValue(base[idx+1:], f)
+			if err != nil {
+				return err
+			}
+			if lo > hi {
+				return fmt.Errorf("range %q is inverted", base)
+			}
+			continue
+		}
+
+		if _, err := parseValue(base, f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseValue accepts a number within the field's bounds, or one of the
+// field's named values (month and day-of-week abbreviations).
+func parseValue(s string, f field) (int, error) {
+	if f.names != nil && f.names[strings.ToLower(s)] {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("value %q is not a number", s)
+	}
+	if n < f.min || n > f.max {
+		return 0, fmt.Errorf("value %d out of range %d-%d", n, f.min, f.max)
+	}
+	return n, nil
+}
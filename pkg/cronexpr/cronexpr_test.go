@@ -0,0 +1,58 @@
+package cronexpr
+
+import "testing"
+
+func TestValidate(t *testing.T) {
+	valid := []string{
+		"* * * * *",
+		"0 2 * * *",
+		"*/5 * * * *",
+		"0 0 1 1 *",
+		"15 4 * * 1",
+		"0 2 * * 7",
+		"0 9-17 * * mon-fri",
+		"0 0 1 jan,jul *",
+		"0,15,30,45 * * * *",
+		"10-50/10 * * * *",
+		"@daily",
+		"@hourly",
+		"@midnight",
+		"@weekly",
+		"@monthly",
+		"@yearly",
+		"@annually",
+		"@reboot",
+		"  0 2 * * *  ",
+	}
+	for _, schedule := range valid {
+		if err := Validate(schedule); err != nil {
+			t.Errorf("Validate(%q) = %v, want nil", schedule, err)
+		}
+	}
+}
+
+func TestValidate_Invalid(t *testing.T) {
+	invalid := []string{
+		"",
+		"@fortnightly",
+		"0 2 * *",
+		"0 2 * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * 32 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"* * * * blursday",
+		"*/0 * * * *",
+		"*/x * * * *",
+		"5-1 * * * *",
+		"1,,2 * * * *",
+		"not a cron line",
+	}
+	for _, schedule := range invalid {
+		if err := Validate(schedule); err == nil {
+			t.Errorf("Validate(%q) = nil, want error", schedule)
+		}
+	}
+}
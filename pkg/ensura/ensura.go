@@ -0,0 +1,182 @@
+// Package ensura is the public facade for embedding EnsuraScript in
+// other Go programs. It wires the compile pipeline (parse, bind, imply,
+// graph, plan) and the runtime behind three calls, so enforcement can be
+// embedded without copying the wiring from cmd/ensura:
+//
+//	plan, diags := ensura.Compile(source, nil)
+//	if len(diags) > 0 { ... }
+//	result, err := ensura.Check(ctx, plan, nil)
+package ensura
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/ensurascript/ensura/pkg/adapters"
+	"github.com/ensurascript/ensura/pkg/ast"
+	"github.com/ensurascript/ensura/pkg/binder"
+	"github.com/ensurascript/ensura/pkg/graph"
+	"github.com/ensurascript/ensura/pkg/imply"
+	"github.com/ensurascript/ensura/pkg/lexer"
+	"github.com/ensurascript/ensura/pkg/parser"
+	"github.com/ensurascript/ensura/pkg/planner"
+	"github.com/ensurascript/ensura/pkg/runtime"
+)
+
+// Diagnostic is a compile finding with the stage that produced it and,
+// when the stage records one, the source range of the offending
+// construct.
+type Diagnostic struct {
+	Stage   string // parse, bind, imply, conflict, graph, or plan
+	Pos     lexer.Position
+	End     lexer.Position
+	Message string
+}
+
+func (d Diagnostic) String() string {
+	if d.Pos.Line > 0 {
+		return fmt.Sprintf("%s: %s: %s", d.Stage, d.Pos, d.Message)
+	}
+	return fmt.Sprintf("%s: %s", d.Stage, d.Message)
+}
+
+// CompileOptions control compilation. The zero value compiles plain
+// source with the built-in conditions.
+type CompileOptions struct {
+	// Filename appears in diagnostic positions.
+	Filename string
+	// ConditionsFile optionally loads user-defined conditions from a
+	// JSON file before expansion.
+	ConditionsFile string
+}
+
+// RunOptions control Check and Run. The zero value checks with the
+// default adapters and discards log output.
+type RunOptions struct {
+	// Handlers supplies the handler registry; nil uses the default
+	// adapter set.
+	Handlers *runtime.HandlerRegistry
+	// Config supplies the runtime configuration; nil uses check-safe
+	// defaults with logging discarded.
+	Config *runtime.Config
+}
+
+// Compile turns EnsuraScript source into an execution plan. A non-empty
+// diagnostic slice means compilation failed and the plan is nil.
+func Compile(source string, opts *CompileOptions) (*planner.Plan, []Diagnostic) {
+	if opts == nil {
+		opts = &CompileOptions{}
+	}
+
+	l := lexer.NewWithFilename(source, opts.Filename)
+	p := parser.New(l)
+	program := p.Parse()
+	if diags := stageDiags("parse", p.Diagnostics()); len(diags) > 0 {
+		return nil, diags
+	}
+
+	b := binder.New()
+	program = b.Bind(program)
+	if diags := stageDiags("bind", b.Diagnostics()); len(diags) > 0 {
+		return nil, diags
+	}
+	program = b.ExpandPolicies(program)
+
+	expander := imply.NewExpander()
+	if opts.ConditionsFile != "" {
+		if _, err := expander.Registry().LoadConditionsFile(opts.ConditionsFile); err != nil {
+			return nil, []Diagnostic{{Stage: "imply", Message: err.Error()}}
+		}
+	}
+	program = expander.Expand(program)
+	if diags := stageDiags("imply", expander.Diagnostics()); len(diags) > 0 {
+		return nil, diags
+	}
+	if conflicts := expander.CheckConflicts(program); len(conflicts) > 0 {
+		if diags := stageDiags("conflict", expander.Diagnostics()); len(diags) > 0 {
+			return nil, diags
+		}
+		diags := make([]Diagnostic, len(conflicts))
+		for i, c := range conflicts {
+			diags[i] = Diagnostic{Stage: "conflict", Message: c}
+		}
+		return nil, diags
+	}
+
+	g := graph.Build(program)
+	if diags := stageDiags("graph", g.Diagnostics()); len(diags) > 0 {
+		return nil, diags
+	}
+
+	pl := planner.New()
+	pl.SetHandlerCapabilities(adapters.NewDefaultRegistry().Capabilities())
+	plan, err := pl.CreatePlan(g, program)
+	if err != nil {
+		return nil, []Diagnostic{{Stage: "plan", Message: err.Error()}}
+	}
+	if errs := pl.Errors(); len(errs) > 0 {
+		diags := make([]Diagnostic, len(errs))
+		for i, e := range errs {
+			diags[i] = Diagnostic{Stage: "plan", Message: e}
+		}
+		return nil, diags
+	}
+
+	return plan, nil
+}
+
+// Check runs a single check-only pass over the plan without repairing
+// anything.
+func Check(ctx context.Context, plan *planner.Plan, opts *RunOptions) (*runtime.RunResult, error) {
+	if plan == nil {
+		return nil, fmt.Errorf("nil plan")
+	}
+	registry, config := runSetup(opts)
+	config.DryRun = true
+	config.CheckOnly = true
+	rt := runtime.New(plan, registry, config)
+	return rt.Check(ctx), nil
+}
+
+// Run starts the continuous enforcement loop and blocks until ctx is
+// cancelled.
+func Run(ctx context.Context, plan *planner.Plan, opts *RunOptions) error {
+	if plan == nil {
+		return fmt.Errorf("nil plan")
+	}
+	registry, config := runSetup(opts)
+	rt := runtime.New(plan, registry, config)
+	return rt.Run(ctx)
+}
+
+// runSetup resolves the handler registry and runtime configuration from
+// the options, filling in defaults.
+func runSetup(opts *RunOptions) (*runtime.HandlerRegistry, *runtime.Config) {
+	if opts == nil {
+		opts = &RunOptions{}
+	}
+	registry := opts.Handlers
+	if registry == nil {
+		registry = adapters.NewDefaultRegistry()
+	}
+	var config runtime.Config
+	if opts.Config != nil {
+		config = *opts.Config
+	}
+	if config.Logger == nil {
+		config.Logger = io.Discard
+	}
+	return registry, &config
+}
+
+func stageDiags(stage string, diags []ast.Diag) []Diagnostic {
+	if len(diags) == 0 {
+		return nil
+	}
+	out := make([]Diagnostic, len(diags))
+	for i, d := range diags {
+		out[i] = Diagnostic{Stage: stage, Pos: d.Pos, End: d.End, Message: d.Msg}
+	}
+	return out
+}
@@ -0,0 +1,86 @@
+package ensura
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCompile(t *testing.T) {
+	input := `on file "secrets.db" {
+  ensure exists
+  ensure encrypted with AES:256 key "env:KEY"
+}`
+
+	plan, diags := Compile(input, nil)
+	if len(diags) > 0 {
+		t.Fatalf("Expected no diagnostics, got %v", diags)
+	}
+	if plan == nil || len(plan.Steps) == 0 {
+		t.Fatal("Expected a plan with steps")
+	}
+}
+
+func TestCompileParseError(t *testing.T) {
+	plan, diags := Compile(`ensure {`, nil)
+	if plan != nil {
+		t.Error("Expected nil plan on parse error")
+	}
+	if len(diags) == 0 {
+		t.Fatal("Expected diagnostics")
+	}
+	if diags[0].Stage != "parse" {
+		t.Errorf("Expected parse stage, got %q", diags[0].Stage)
+	}
+	if diags[0].Pos.Line == 0 {
+		t.Error("Expected a source position on the diagnostic")
+	}
+}
+
+func TestCompileConflict(t *testing.T) {
+	input := `on file "app.log" {
+  ensure encrypted with AES:256 key "env:KEY"
+  ensure unencrypted
+}`
+
+	plan, diags := Compile(input, nil)
+	if plan != nil {
+		t.Error("Expected nil plan on conflict")
+	}
+	found := false
+	for _, d := range diags {
+		if d.Stage == "conflict" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Expected a conflict diagnostic, got %v", diags)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "present.txt")
+	if err := os.WriteFile(path, []byte("data"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	input := fmt.Sprintf(`on file %q {
+  ensure exists
+}`, path)
+
+	plan, diags := Compile(input, nil)
+	if len(diags) > 0 {
+		t.Fatalf("Expected no diagnostics, got %v", diags)
+	}
+
+	result, err := Check(context.Background(), plan, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.AllSatisfied {
+		t.Errorf("Expected all guarantees satisfied, got %d failures", result.TotalFailures)
+	}
+}
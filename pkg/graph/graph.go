@@ -2,8 +2,11 @@
 package graph
 
 import (
+	"container/heap"
+	"crypto/sha256"
 	"fmt"
 	"sort"
+	"strings"
 
 	"github.com/ensurascript/ensura/pkg/ast"
 )
@@ -29,6 +32,7 @@ type Graph struct {
 	Edges      []Edge
 	Invariants map[string]bool // set of guarantee IDs from invariant blocks
 	errors     []string
+	diags      []ast.Diag
 }
 
 // NewGraph creates a new empty graph.
@@ -44,14 +48,53 @@ func (g *Graph) Errors() []string {
 	return g.errors
 }
 
+// Diagnostics returns all graph building errors with their source ranges.
+func (g *Graph) Diagnostics() []ast.Diag {
+	return g.diags
+}
+
 // Build constructs the dependency graph from the AST.
 func Build(program *ast.Program) *Graph {
 	g := NewGraph()
 	g.buildFromStatements(program.Statements, false, 0)
+	g.buildResourceRequirementEdges()
 	g.buildImplicitEdges()
 	return g
 }
 
+// buildResourceRequirementEdges adds edges for requires clauses that name a
+// condition on a different resource. It runs after every guarantee has been
+// added so forward references resolve, and errors when the required
+// guarantee is not declared anywhere.
+func (g *Graph) buildResourceRequirementEdges() {
+	for id, guarantee := range g.Nodes {
+		stmt := guarantee.Statement
+		for _, rc := range stmt.RequiresResource {
+			var reqIDs []string
+			if rc.Condition != "" {
+				if reqID := g.findGuaranteeByCondition(rc.Condition, rc.Ref); reqID != "" {
+					reqIDs = append(reqIDs, reqID)
+				}
+			} else {
+				reqIDs = g.findGuaranteesByResource(rc.Ref)
+			}
+			if len(reqIDs) == 0 {
+				g.errors = append(g.errors, fmt.Sprintf("%s: requires %s, but no such guarantee is declared",
+					stmt.Position, rc.String()))
+				g.diags = append(g.diags, ast.Diag{
+					Pos: stmt.Position,
+					End: stmt.EndPos,
+					Msg: fmt.Sprintf("requires %s, but no such guarantee is declared", rc.String()),
+				})
+				continue
+			}
+			for _, reqID := range reqIDs {
+				g.Edges = append(g.Edges, Edge{From: reqID, To: id, Type: "requires"})
+			}
+		}
+	}
+}
+
 func (g *Graph) buildFromStatements(statements []ast.Statement, isInvariant bool, basePriority int) {
 	for _, stmt := range statements {
 		g.processStatement(stmt, isInvariant, basePriority)
@@ -83,6 +126,7 @@ func (g *Graph) addGuarantee(stmt *ast.EnsureStmt, isInvariant bool, priority in
 		ID:        id,
 		Statement: stmt,
 		Priority:  priority,
+		IsImplied: stmt.IsImplied,
 	}
 
 	g.Nodes[id] = guarantee
@@ -117,27 +161,61 @@ func (g *Graph) addGuarantee(stmt *ast.EnsureStmt, isInvariant bool, priority in
 	}
 }
 
+// generateID derives a stable, human-readable ID from the statement's
+// content instead of its source position, so IDs survive reformatting and
+// moved lines and state can be correlated across runs. The trailing hash
+// covers the handler spec; identical statements are disambiguated with a
+// #n suffix.
 func (g *Graph) generateID(stmt *ast.EnsureStmt) string {
 	subject := ""
 	if stmt.Subject != nil {
 		subject = stmt.Subject.String()
 	}
-	return fmt.Sprintf("%s:%s@%s", stmt.Condition, subject, stmt.Position)
+
+	fingerprint := ""
+	if stmt.Handler != nil {
+		fingerprint = stmt.Handler.Name
+		args := make([]string, 0, len(stmt.Handler.Args))
+		for k, v := range stmt.Handler.Args {
+			args = append(args, k+"="+v)
+		}
+		sort.Strings(args)
+		fingerprint += "|" + strings.Join(args, "|")
+	}
+	hash := sha256.Sum256([]byte(fingerprint))
+
+	base := fmt.Sprintf("%s:%s@%x", stmt.Condition, subject, hash[:4])
+	id := base
+	for n := 2; ; n++ {
+		existing, ok := g.Nodes[id]
+		// Textually identical statements are the same guarantee and share
+		// an ID; only differing content (e.g. another guard) is suffixed.
+		if !ok || existing.Statement.String() == stmt.String() {
+			return id
+		}
+		id = fmt.Sprintf("%s#%d", base, n)
+	}
 }
 
-func (g *Graph) findGuaranteeByCondition(condition string, subject *ast.ResourceRef) string {
-	subjectStr := ""
-	if subject != nil {
-		subjectStr = subject.String()
+// refKey returns a canonical comparison key for a resource reference. It
+// prefers the resolved type and path over the display alias, so a reference
+// by alias matches guarantees declared with an inline subject.
+func refKey(ref *ast.ResourceRef) string {
+	if ref == nil {
+		return ""
 	}
+	if ref.Path != "" {
+		return fmt.Sprintf("%s %q", ref.ResourceType, ref.Path)
+	}
+	return ref.String()
+}
+
+func (g *Graph) findGuaranteeByCondition(condition string, subject *ast.ResourceRef) string {
+	subjectKey := refKey(subject)
 
 	for id, guarantee := range g.Nodes {
 		if guarantee.Statement.Condition == condition {
-			guardSubject := ""
-			if guarantee.Statement.Subject != nil {
-				guardSubject = guarantee.Statement.Subject.String()
-			}
-			if guardSubject == subjectStr {
+			if refKey(guarantee.Statement.Subject) == subjectKey {
 				return id
 			}
 		}
@@ -147,10 +225,10 @@ func (g *Graph) findGuaranteeByCondition(condition string, subject *ast.Resource
 
 func (g *Graph) findGuaranteesByResource(ref *ast.ResourceRef) []string {
 	var ids []string
-	refStr := ref.String()
+	key := refKey(ref)
 
 	for id, guarantee := range g.Nodes {
-		if guarantee.Statement.Subject != nil && guarantee.Statement.Subject.String() == refStr {
+		if guarantee.Statement.Subject != nil && refKey(guarantee.Statement.Subject) == key {
 			ids = append(ids, id)
 		}
 	}
@@ -163,10 +241,7 @@ func (g *Graph) buildImplicitEdges() {
 	// Group guarantees by subject
 	bySubject := make(map[string][]*Guarantee)
 	for _, guarantee := range g.Nodes {
-		subject := ""
-		if guarantee.Statement.Subject != nil {
-			subject = guarantee.Statement.Subject.String()
-		}
+		subject := refKey(guarantee.Statement.Subject)
 		bySubject[subject] = append(bySubject[subject], guarantee)
 	}
 
@@ -227,38 +302,27 @@ func (g *Graph) TopoSort() ([]*Guarantee, error) {
 		inDegree[edge.To]++
 	}
 
-	// Kahn's algorithm with priority-based tie-breaking
-	var queue []*Guarantee
+	// Kahn's algorithm with priority-based tie-breaking. Ready nodes live
+	// in a heap keyed by (priority, ID) so large graphs avoid re-sorting
+	// the whole queue on every insertion.
+	queue := &guaranteeHeap{}
 	for id, degree := range inDegree {
 		if degree == 0 {
-			queue = append(queue, g.Nodes[id])
+			heap.Push(queue, g.Nodes[id])
 		}
 	}
 
-	// Sort initial queue by priority (descending) then ID (for stability)
-	sortQueue := func(q []*Guarantee) {
-		sort.Slice(q, func(i, j int) bool {
-			if q[i].Priority != q[j].Priority {
-				return q[i].Priority > q[j].Priority
-			}
-			return q[i].ID < q[j].ID
-		})
-	}
-	sortQueue(queue)
-
 	var result []*Guarantee
-	for len(queue) > 0 {
+	for queue.Len() > 0 {
 		// Take highest priority node
-		node := queue[0]
-		queue = queue[1:]
+		node := heap.Pop(queue).(*Guarantee)
 		result = append(result, node)
 
 		// Decrease in-degree of neighbors
 		for _, neighbor := range adj[node.ID] {
 			inDegree[neighbor]--
 			if inDegree[neighbor] == 0 {
-				queue = append(queue, g.Nodes[neighbor])
-				sortQueue(queue)
+				heap.Push(queue, g.Nodes[neighbor])
 			}
 		}
 	}
@@ -271,6 +335,27 @@ func (g *Graph) TopoSort() ([]*Guarantee, error) {
 	return result, nil
 }
 
+// guaranteeHeap orders ready guarantees by priority (descending), breaking
+// ties by ID for stable output.
+type guaranteeHeap []*Guarantee
+
+func (h guaranteeHeap) Len() int { return len(h) }
+func (h guaranteeHeap) Less(i, j int) bool {
+	if h[i].Priority != h[j].Priority {
+		return h[i].Priority > h[j].Priority
+	}
+	return h[i].ID < h[j].ID
+}
+func (h guaranteeHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *guaranteeHeap) Push(x interface{}) { *h = append(*h, x.(*Guarantee)) }
+func (h *guaranteeHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	*h = old[:n-1]
+	return item
+}
+
 // FindCycle finds a cycle in the graph if one exists.
 func (g *Graph) FindCycle() []string {
 	// Build adjacency list
@@ -322,22 +407,258 @@ func (g *Graph) FindCycle() []string {
 	return nil
 }
 
+// DescribeCycle renders a cycle as a human-readable chain. Each statement
+// is shown with its condition, subject and source position, and consecutive
+// statements are joined by the edge type (requires/after/before/implies)
+// that links them, so the offending declarations can be located and fixed.
+func (g *Graph) DescribeCycle(cycle []string) string {
+	var b strings.Builder
+	for i, id := range cycle {
+		node, ok := g.Nodes[id]
+		if !ok {
+			b.WriteString(id)
+		} else {
+			stmt := node.Statement
+			fmt.Fprintf(&b, "'%s'", stmt.Condition)
+			if stmt.Subject != nil {
+				fmt.Fprintf(&b, " on %s", stmt.Subject.String())
+			}
+			fmt.Fprintf(&b, " (%s)", stmt.Position.String())
+		}
+		if i < len(cycle)-1 {
+			fmt.Fprintf(&b, "\n  --%s--> ", g.edgeType(id, cycle[i+1]))
+		}
+	}
+	return b.String()
+}
+
+// edgeType returns the type of the edge from one node to another.
+func (g *Graph) edgeType(from, to string) string {
+	for _, edge := range g.Edges {
+		if edge.From == from && edge.To == to {
+			return edge.Type
+		}
+	}
+	return "depends"
+}
+
 // Visualize returns a DOT graph representation for debugging.
-func (g *Graph) Visualize() string {
-	var out string
-	out += "digraph G {\n"
-	out += "  rankdir=TB;\n"
+// ToJSON returns a JSON-compatible structure describing the graph's nodes
+// and edges, for embedding in docs and web UIs.
+func (g *Graph) ToJSON() map[string]interface{} {
+	nodes := make([]map[string]interface{}, 0, len(g.Nodes))
+	for _, id := range g.sortedNodeIDs() {
+		node := g.Nodes[id]
+		n := map[string]interface{}{
+			"id":        id,
+			"condition": node.Statement.Condition,
+			"position":  node.Statement.Position.String(),
+		}
+		if node.Statement.Subject != nil {
+			n["subject"] = node.Statement.Subject.String()
+		}
+		if node.IsImplied {
+			n["implied"] = true
+		}
+		if g.Invariants[id] {
+			n["invariant"] = true
+		}
+		nodes = append(nodes, n)
+	}
 
-	for id, node := range g.Nodes {
+	edges := make([]map[string]interface{}, 0, len(g.Edges))
+	for _, edge := range g.Edges {
+		edges = append(edges, map[string]interface{}{
+			"from": edge.From,
+			"to":   edge.To,
+			"type": edge.Type,
+		})
+	}
+
+	return map[string]interface{}{
+		"nodes": nodes,
+		"edges": edges,
+	}
+}
+
+// VisualizeMermaid returns the graph as a Mermaid flowchart. Node IDs are
+// replaced with short aliases since Mermaid identifiers cannot carry the
+// punctuation guarantee IDs contain.
+func (g *Graph) VisualizeMermaid() string {
+	var out strings.Builder
+	out.WriteString("flowchart TB\n")
+
+	alias := make(map[string]string, len(g.Nodes))
+	for i, id := range g.sortedNodeIDs() {
+		alias[id] = fmt.Sprintf("n%d", i)
+		node := g.Nodes[id]
 		label := node.Statement.Condition
 		if node.Statement.Subject != nil {
-			label += "\\n" + node.Statement.Subject.String()
+			label += "<br>" + node.Statement.Subject.String()
 		}
-		shape := "box"
-		if g.Invariants[id] {
-			shape = "doublebox"
+		label = strings.ReplaceAll(label, `"`, "#quot;")
+		out.WriteString(fmt.Sprintf("  %s[\"%s\"]\n", alias[id], label))
+	}
+
+	for _, edge := range g.Edges {
+		arrow := "-->"
+		if edge.Type == "implies" {
+			arrow = "-.->"
+		}
+		out.WriteString(fmt.Sprintf("  %s %s|%s| %s\n", alias[edge.From], arrow, edge.Type, alias[edge.To]))
+	}
+
+	return out.String()
+}
+
+// sortedNodeIDs returns the node IDs in a stable order.
+func (g *Graph) sortedNodeIDs() []string {
+	ids := make([]string, 0, len(g.Nodes))
+	for id := range g.Nodes {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// DependenciesOf returns the IDs of every guarantee that must hold before
+// the given one, following edges transitively. The result is sorted and
+// excludes the starting ID.
+func (g *Graph) DependenciesOf(id string) []string {
+	return g.closure(id, func(edge Edge, cur string) (string, bool) {
+		if edge.To == cur {
+			return edge.From, true
+		}
+		return "", false
+	})
+}
+
+// DependentsOf returns the IDs of every guarantee that transitively depends
+// on the given one — what breaks when it is violated. The result is sorted
+// and excludes the starting ID.
+func (g *Graph) DependentsOf(id string) []string {
+	return g.closure(id, func(edge Edge, cur string) (string, bool) {
+		if edge.From == cur {
+			return edge.To, true
+		}
+		return "", false
+	})
+}
+
+// ImpactOf returns the IDs of every guarantee declared on the subject
+// (matched against its canonical string, e.g. `file "secrets.db"`) together
+// with everything that transitively depends on them.
+func (g *Graph) ImpactOf(subject string) []string {
+	impacted := make(map[string]bool)
+	for id, node := range g.Nodes {
+		if node.Statement.Subject != nil && node.Statement.Subject.String() == subject {
+			impacted[id] = true
+			for _, dep := range g.DependentsOf(id) {
+				impacted[dep] = true
+			}
+		}
+	}
+
+	ids := make([]string, 0, len(impacted))
+	for id := range impacted {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// closure walks edges from id using next to pick each edge's far end, and
+// returns the visited IDs sorted.
+func (g *Graph) closure(id string, next func(edge Edge, cur string) (string, bool)) []string {
+	visited := make(map[string]bool)
+	queue := []string{id}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		for _, edge := range g.Edges {
+			if other, ok := next(edge, cur); ok && !visited[other] && other != id {
+				visited[other] = true
+				queue = append(queue, other)
+			}
+		}
+	}
+
+	ids := make([]string, 0, len(visited))
+	for v := range visited {
+		ids = append(ids, v)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// statusColors maps step statuses (StepStatus.String() values) to node
+// fill colors.
+var statusColors = map[string]string{
+	"satisfied":  "palegreen",
+	"repaired":   "lightskyblue",
+	"violated":   "khaki",
+	"failed":     "lightcoral",
+	"suppressed": "lightgray",
+}
+
+// Visualize returns the graph in DOT format, with one cluster per subject,
+// dashed borders on implied guarantees, and a legend.
+func (g *Graph) Visualize() string {
+	return g.VisualizeStatus(nil)
+}
+
+// VisualizeStatus is Visualize with nodes colored by run status. The map
+// keys are guarantee IDs and the values StepStatus strings (satisfied,
+// repaired, failed, ...); IDs absent from the map render uncolored. A nil
+// map omits the status part of the legend.
+func (g *Graph) VisualizeStatus(status map[string]string) string {
+	var out strings.Builder
+	out.WriteString("digraph G {\n")
+	out.WriteString("  rankdir=TB;\n")
+	out.WriteString("  node [shape=box, style=filled, fillcolor=white];\n")
+
+	// Group nodes by subject so each resource becomes a cluster
+	bySubject := make(map[string][]string)
+	for _, id := range g.sortedNodeIDs() {
+		subject := ""
+		if s := g.Nodes[id].Statement.Subject; s != nil {
+			subject = s.String()
+		}
+		bySubject[subject] = append(bySubject[subject], id)
+	}
+	subjects := make([]string, 0, len(bySubject))
+	for subject := range bySubject {
+		subjects = append(subjects, subject)
+	}
+	sort.Strings(subjects)
+
+	cluster := 0
+	for _, subject := range subjects {
+		indent := "  "
+		if subject != "" {
+			fmt.Fprintf(&out, "  subgraph cluster_%d {\n    label=%q;\n", cluster, subject)
+			cluster++
+			indent = "    "
+		}
+		for _, id := range bySubject[subject] {
+			node := g.Nodes[id]
+			attrs := []string{fmt.Sprintf("label=%q", node.Statement.Condition)}
+			if g.Invariants[id] {
+				attrs = append(attrs, "shape=doubleoctagon")
+			}
+			style := "filled"
+			if node.IsImplied {
+				style = "filled,dashed"
+			}
+			attrs = append(attrs, fmt.Sprintf("style=%q", style))
+			if color, ok := statusColors[status[id]]; ok {
+				attrs = append(attrs, fmt.Sprintf("fillcolor=%s", color))
+			}
+			fmt.Fprintf(&out, "%s%q [%s];\n", indent, id, strings.Join(attrs, ", "))
+		}
+		if subject != "" {
+			out.WriteString("  }\n")
 		}
-		out += fmt.Sprintf("  %q [label=%q, shape=%s];\n", id, label, shape)
 	}
 
 	for _, edge := range g.Edges {
@@ -345,9 +666,27 @@ func (g *Graph) Visualize() string {
 		if edge.Type == "implies" {
 			style = "dashed"
 		}
-		out += fmt.Sprintf("  %q -> %q [style=%s, label=%q];\n", edge.From, edge.To, style, edge.Type)
+		fmt.Fprintf(&out, "  %q -> %q [style=%s, label=%q];\n", edge.From, edge.To, style, edge.Type)
 	}
 
-	out += "}\n"
-	return out
+	g.writeLegend(&out, status != nil)
+
+	out.WriteString("}\n")
+	return out.String()
+}
+
+// writeLegend appends a legend cluster explaining node styles, and the
+// status colors when a run result was supplied.
+func (g *Graph) writeLegend(out *strings.Builder, withStatus bool) {
+	out.WriteString("  subgraph cluster_legend {\n")
+	out.WriteString("    label=\"Legend\";\n")
+	out.WriteString("    legend_explicit [label=\"explicit\", style=\"filled\"];\n")
+	out.WriteString("    legend_implied [label=\"implied\", style=\"filled,dashed\"];\n")
+	out.WriteString("    legend_invariant [label=\"invariant\", shape=doubleoctagon, style=\"filled\"];\n")
+	if withStatus {
+		for _, s := range []string{"satisfied", "repaired", "violated", "failed", "suppressed"} {
+			fmt.Fprintf(out, "    legend_%s [label=%q, fillcolor=%s];\n", s, s, statusColors[s])
+		}
+	}
+	out.WriteString("  }\n")
 }
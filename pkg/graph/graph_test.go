@@ -1,10 +1,14 @@
 package graph
 
 import (
+	"fmt"
+	"strings"
 	"testing"
 
+	"github.com/ensurascript/ensura/pkg/ast"
 	"github.com/ensurascript/ensura/pkg/binder"
 	"github.com/ensurascript/ensura/pkg/imply"
+	"github.com/ensurascript/ensura/pkg/lexer"
 	"github.com/ensurascript/ensura/pkg/parser"
 )
 
@@ -114,6 +118,336 @@ func TestVisualize(t *testing.T) {
 	}
 }
 
+func TestStableIDs(t *testing.T) {
+	input := `ensure exists on file "a.txt"
+ensure encrypted on file "a.txt" with AES:256 key "env:KEY"`
+
+	// Same statements on different lines must produce the same IDs
+	moved := "\n\n" + input
+
+	ids := func(g *Graph) map[string]bool {
+		out := make(map[string]bool)
+		for id := range g.Nodes {
+			out[id] = true
+		}
+		return out
+	}
+
+	a := ids(compile(input))
+	b := ids(compile(moved))
+	if len(a) != len(b) {
+		t.Fatalf("Expected same node count, got %d and %d", len(a), len(b))
+	}
+	for id := range a {
+		if !b[id] {
+			t.Errorf("ID %q changed when source lines moved", id)
+		}
+	}
+}
+
+func TestDuplicateIDDisambiguation(t *testing.T) {
+	g := NewGraph()
+	stmt := func(guard *ast.GuardExpr) *ast.EnsureStmt {
+		return &ast.EnsureStmt{
+			Condition: "exists",
+			Subject:   &ast.ResourceRef{ResourceType: "file", Path: "a.txt"},
+			Guard:     guard,
+		}
+	}
+
+	// Identical statements collapse into one guarantee
+	g.addGuarantee(stmt(nil), false, 0)
+	g.addGuarantee(stmt(nil), false, 0)
+	if len(g.Nodes) != 1 {
+		t.Fatalf("Expected identical statements to share an ID, got %d nodes", len(g.Nodes))
+	}
+
+	// The same condition and subject with differing content gets a suffix
+	g.addGuarantee(stmt(&ast.GuardExpr{Left: "environment", Operator: "==", Right: "prod"}), false, 0)
+	if len(g.Nodes) != 2 {
+		t.Fatalf("Expected a second node for the guarded variant, got %d", len(g.Nodes))
+	}
+	suffixed := 0
+	for id := range g.Nodes {
+		if strings.Contains(id, "#") {
+			suffixed++
+		}
+	}
+	if suffixed != 1 {
+		t.Errorf("Expected exactly one disambiguated ID, got %d", suffixed)
+	}
+}
+
+func TestDependencyQueries(t *testing.T) {
+	input := `on file "secrets.db" {
+  ensure exists
+  ensure encrypted with AES:256 key "env:KEY"
+}`
+
+	g := compile(input)
+
+	var encryptedID, existsID string
+	for id, node := range g.Nodes {
+		switch node.Statement.Condition {
+		case "encrypted":
+			encryptedID = id
+		case "exists":
+			existsID = id
+		}
+	}
+	if encryptedID == "" || existsID == "" {
+		t.Fatal("Expected encrypted and exists nodes")
+	}
+
+	deps := g.DependenciesOf(encryptedID)
+	if !contains(deps, existsID) {
+		t.Errorf("encrypted should depend on exists, got %v", deps)
+	}
+
+	dependents := g.DependentsOf(existsID)
+	if !contains(dependents, encryptedID) {
+		t.Errorf("encrypted should be a dependent of exists, got %v", dependents)
+	}
+
+	impacted := g.ImpactOf(`file "secrets.db"`)
+	if !contains(impacted, encryptedID) || !contains(impacted, existsID) {
+		t.Errorf("ImpactOf should include every guarantee on the file, got %v", impacted)
+	}
+	if impacted := g.ImpactOf(`file "other.db"`); len(impacted) != 0 {
+		t.Errorf("ImpactOf unknown resource should be empty, got %v", impacted)
+	}
+}
+
+func contains(ids []string, id string) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
+
+func TestVisualizeClustersAndLegend(t *testing.T) {
+	input := `on file "secrets.db" {
+  ensure exists
+  ensure encrypted with AES:256 key "env:KEY"
+}`
+
+	g := compile(input)
+	dot := g.Visualize()
+
+	if !strings.Contains(dot, `label="file \"secrets.db\""`) {
+		t.Error("Expected a cluster labeled with the subject")
+	}
+	if !strings.Contains(dot, "cluster_legend") {
+		t.Error("Expected a legend cluster")
+	}
+	// Implied guarantees render dashed
+	if !strings.Contains(dot, `style="filled,dashed"`) {
+		t.Error("Expected dashed style on implied nodes")
+	}
+}
+
+func TestVisualizeStatus(t *testing.T) {
+	input := `ensure exists on file "a.txt"`
+
+	g := compile(input)
+	var id string
+	for nodeID := range g.Nodes {
+		id = nodeID
+	}
+
+	dot := g.VisualizeStatus(map[string]string{id: "failed"})
+	if !strings.Contains(dot, "fillcolor=lightcoral") {
+		t.Error("Expected failed node to be colored")
+	}
+	if !strings.Contains(dot, "legend_satisfied") {
+		t.Error("Expected status legend entries when a status map is given")
+	}
+}
+
+func TestToJSON(t *testing.T) {
+	input := `on file "secrets.db" {
+  ensure exists
+  ensure encrypted with AES:256 key "env:KEY"
+}`
+
+	g := compile(input)
+	out := g.ToJSON()
+
+	nodes, ok := out["nodes"].([]map[string]interface{})
+	if !ok || len(nodes) != len(g.Nodes) {
+		t.Fatalf("Expected %d nodes in JSON output", len(g.Nodes))
+	}
+	for _, n := range nodes {
+		if n["id"] == "" || n["condition"] == "" {
+			t.Errorf("Node missing id or condition: %v", n)
+		}
+	}
+
+	edges, ok := out["edges"].([]map[string]interface{})
+	if !ok || len(edges) != len(g.Edges) {
+		t.Fatalf("Expected %d edges in JSON output", len(g.Edges))
+	}
+}
+
+func TestVisualizeMermaid(t *testing.T) {
+	input := `on file "secrets.db" {
+  ensure exists
+  ensure encrypted with AES:256 key "env:KEY"
+}`
+
+	g := compile(input)
+	mermaid := g.VisualizeMermaid()
+
+	if !strings.HasPrefix(mermaid, "flowchart TB\n") {
+		t.Error("Expected flowchart header")
+	}
+	if !strings.Contains(mermaid, "encrypted") {
+		t.Error("Expected condition labels in output")
+	}
+	// Implied edges render dashed
+	if !strings.Contains(mermaid, "-.->|implies|") {
+		t.Error("Expected dashed implies edges")
+	}
+}
+
+func TestAliasOrderingEdges(t *testing.T) {
+	input := `resource file "secrets.db" as secrets_db
+
+ensure exists on file "secrets.db"
+ensure running on service "app" after secrets_db`
+
+	g := compile(input)
+
+	found := false
+	for _, edge := range g.Edges {
+		if edge.Type == "after" {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("Expected after edge from alias reference to inline declaration")
+	}
+}
+
+func TestRequiresResourceEdges(t *testing.T) {
+	input := `ensure exists on file "config.yml"
+ensure healthy on service "app" requires file "config.yml" exists`
+
+	g := compile(input)
+
+	if len(g.Errors()) > 0 {
+		t.Fatalf("Unexpected errors: %v", g.Errors())
+	}
+
+	var healthyID, existsID string
+	for id, node := range g.Nodes {
+		switch node.Statement.Condition {
+		case "healthy":
+			healthyID = id
+		case "exists":
+			existsID = id
+		}
+	}
+
+	found := false
+	for _, edge := range g.Edges {
+		if edge.Type == "requires" && edge.From == existsID && edge.To == healthyID {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("Expected requires edge from exists on config.yml to healthy on app")
+	}
+}
+
+func TestRequiresResourceMissingTarget(t *testing.T) {
+	input := `ensure healthy on service "app" requires file "config.yml" exists`
+
+	g := compile(input)
+
+	if len(g.Errors()) == 0 {
+		t.Error("Expected error for requires on an undeclared guarantee")
+	}
+}
+
+func benchmarkGraph(nodes int) *Graph {
+	g := NewGraph()
+	ids := make([]string, 0, nodes)
+	for i := 0; i < nodes; i++ {
+		stmt := &ast.EnsureStmt{
+			Condition: "exists",
+			Subject:   &ast.ResourceRef{ResourceType: "file", Path: fmt.Sprintf("f%d.txt", i)},
+		}
+		g.addGuarantee(stmt, false, i%10)
+		ids = append(ids, g.generateID(stmt))
+	}
+	// Chain every tenth node so the ready queue stays large
+	for i := 10; i < nodes; i++ {
+		g.Edges = append(g.Edges, Edge{From: ids[i-10], To: ids[i], Type: "requires"})
+	}
+	return g
+}
+
+func BenchmarkTopoSort10k(b *testing.B) {
+	g := benchmarkGraph(10000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := g.TopoSort(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkTopoSort50k(b *testing.B) {
+	g := benchmarkGraph(50000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := g.TopoSort(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestDescribeCycle(t *testing.T) {
+	g := NewGraph()
+	stmt := func(condition string, line int) *ast.EnsureStmt {
+		return &ast.EnsureStmt{
+			Condition: condition,
+			Subject:   &ast.ResourceRef{ResourceType: "file", Path: "a.txt"},
+			Position:  lexer.Position{Line: line, Column: 1},
+		}
+	}
+	g.addGuarantee(stmt("exists", 1), false, 0)
+	g.addGuarantee(stmt("backed_up", 2), false, 0)
+
+	var a, b string
+	for id, node := range g.Nodes {
+		if node.Statement.Condition == "exists" {
+			a = id
+		} else {
+			b = id
+		}
+	}
+	g.Edges = append(g.Edges,
+		Edge{From: a, To: b, Type: "requires"},
+		Edge{From: b, To: a, Type: "after"},
+	)
+
+	cycle := g.FindCycle()
+	if cycle == nil {
+		t.Fatal("Expected a cycle")
+	}
+
+	desc := g.DescribeCycle(cycle)
+	for _, want := range []string{"'exists'", "'backed_up'", `file "a.txt"`, "--requires-->", "--after-->", "1:1"} {
+		if !strings.Contains(desc, want) {
+			t.Errorf("Cycle description missing %q:\n%s", want, desc)
+		}
+	}
+}
+
 func TestDependencyEdges(t *testing.T) {
 	input := `ensure exists on file "secrets.db"
 ensure backed_up on file "secrets.db" requires exists`
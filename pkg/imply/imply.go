@@ -2,17 +2,18 @@
 package imply
 
 import (
+	"sort"
+
 	"github.com/ensurascript/ensura/pkg/ast"
-	"github.com/ensurascript/ensura/pkg/lexer"
 )
 
 // ConditionMeta defines metadata for a condition.
 type ConditionMeta struct {
 	Name            string
-	ApplicableTypes []string          // resource types this condition applies to
-	Implies         []string          // conditions this implies
-	Conflicts       []string          // conditions this conflicts with
-	DefaultHandler  string            // default handler if none specified
+	ApplicableTypes []string // resource types this condition applies to
+	Implies         []string // conditions this implies
+	Conflicts       []string // conditions this conflicts with
+	DefaultHandler  string   // default handler if none specified
 }
 
 // Registry holds all known conditions and their metadata.
@@ -33,7 +34,7 @@ func (r *Registry) registerBuiltins() {
 	// Filesystem conditions
 	r.Register(&ConditionMeta{
 		Name:            "exists",
-		ApplicableTypes: []string{"file", "directory"},
+		ApplicableTypes: []string{"file", "directory", "user", "group", "s3"},
 		Implies:         nil,
 		Conflicts:       nil,
 		DefaultHandler:  "fs.native",
@@ -81,7 +82,7 @@ func (r *Registry) registerBuiltins() {
 
 	r.Register(&ConditionMeta{
 		Name:            "checksum",
-		ApplicableTypes: []string{"file"},
+		ApplicableTypes: []string{"file", "s3"},
 		Implies:         []string{"exists", "readable"},
 		Conflicts:       nil,
 		DefaultHandler:  "fs.native",
@@ -95,6 +96,175 @@ func (r *Registry) registerBuiltins() {
 		DefaultHandler:  "fs.native",
 	})
 
+	r.Register(&ConditionMeta{
+		Name:            "size_below",
+		ApplicableTypes: []string{"file"},
+		Implies:         []string{"exists"},
+		Conflicts:       nil,
+		DefaultHandler:  "fs.native",
+	})
+
+	r.Register(&ConditionMeta{
+		Name:            "size_above",
+		ApplicableTypes: []string{"file"},
+		Implies:         []string{"exists"},
+		Conflicts:       nil,
+		DefaultHandler:  "fs.native",
+	})
+
+	r.Register(&ConditionMeta{
+		Name:            "newer_than",
+		ApplicableTypes: []string{"file"},
+		Implies:         []string{"exists"},
+		Conflicts:       nil,
+		DefaultHandler:  "fs.native",
+	})
+
+	r.Register(&ConditionMeta{
+		Name:            "is_symlink",
+		ApplicableTypes: []string{"file"},
+		Implies:         []string{"exists"},
+		Conflicts:       nil,
+		DefaultHandler:  "fs.native",
+	})
+
+	r.Register(&ConditionMeta{
+		Name:            "empty",
+		ApplicableTypes: []string{"file", "directory"},
+		Implies:         []string{"exists"},
+		Conflicts:       []string{"not_empty"},
+		DefaultHandler:  "fs.native",
+	})
+
+	r.Register(&ConditionMeta{
+		Name:            "not_empty",
+		ApplicableTypes: []string{"file", "directory"},
+		Implies:         []string{"exists"},
+		Conflicts:       []string{"empty"},
+		DefaultHandler:  "fs.native",
+	})
+
+	r.Register(&ConditionMeta{
+		Name:            "file_count_below",
+		ApplicableTypes: []string{"directory"},
+		Implies:         []string{"exists"},
+		Conflicts:       nil,
+		DefaultHandler:  "fs.native",
+	})
+
+	r.Register(&ConditionMeta{
+		Name:            "contains",
+		ApplicableTypes: []string{"file", "directory"},
+		Implies:         []string{"exists"},
+		Conflicts:       nil,
+		DefaultHandler:  "fs.native",
+	})
+
+	r.Register(&ConditionMeta{
+		Name:            "matches",
+		ApplicableTypes: []string{"file", "env"},
+		Implies:         []string{"exists"},
+		Conflicts:       nil,
+		DefaultHandler:  "fs.native",
+	})
+
+	r.Register(&ConditionMeta{
+		Name:            "json_path",
+		ApplicableTypes: []string{"file"},
+		Implies:         []string{"exists"},
+		Conflicts:       nil,
+		DefaultHandler:  "fs.native",
+	})
+
+	r.Register(&ConditionMeta{
+		Name:            "rotate_key",
+		ApplicableTypes: []string{"file"},
+		Implies:         []string{"encrypted"},
+		Conflicts:       nil,
+		DefaultHandler:  "AES:256",
+	})
+
+	// Certificate conditions
+	r.Register(&ConditionMeta{
+		Name:            "valid",
+		ApplicableTypes: []string{"cert", "certificate"},
+		Implies:         nil,
+		Conflicts:       nil,
+		DefaultHandler:  "cert.native",
+	})
+
+	r.Register(&ConditionMeta{
+		Name:            "expires_after",
+		ApplicableTypes: []string{"cert", "certificate"},
+		Implies:         []string{"valid"},
+		Conflicts:       nil,
+		DefaultHandler:  "cert.native",
+	})
+
+	r.Register(&ConditionMeta{
+		Name:            "issued_by",
+		ApplicableTypes: []string{"cert", "certificate"},
+		Implies:         nil,
+		Conflicts:       nil,
+		DefaultHandler:  "cert.native",
+	})
+
+	r.Register(&ConditionMeta{
+		Name:            "san_contains",
+		ApplicableTypes: []string{"cert", "certificate"},
+		Implies:         nil,
+		Conflicts:       nil,
+		DefaultHandler:  "cert.native",
+	})
+
+	r.Register(&ConditionMeta{
+		Name:            "latency_below",
+		ApplicableTypes: []string{"endpoint", "service"},
+		Implies:         []string{"reachable"},
+		Conflicts:       nil,
+		DefaultHandler:  "http.get",
+	})
+
+	r.Register(&ConditionMeta{
+		Name:            "body_contains",
+		ApplicableTypes: []string{"endpoint", "service"},
+		Implies:         []string{"reachable"},
+		Conflicts:       nil,
+		DefaultHandler:  "http.get",
+	})
+
+	r.Register(&ConditionMeta{
+		Name:            "cert_expires_after",
+		ApplicableTypes: []string{"endpoint", "service"},
+		Implies:         []string{"tls"},
+		Conflicts:       nil,
+		DefaultHandler:  "http.get",
+	})
+
+	r.Register(&ConditionMeta{
+		Name:            "cert_issuer",
+		ApplicableTypes: []string{"endpoint", "service"},
+		Implies:         []string{"tls"},
+		Conflicts:       nil,
+		DefaultHandler:  "http.get",
+	})
+
+	r.Register(&ConditionMeta{
+		Name:            "rendered",
+		ApplicableTypes: []string{"file"},
+		Implies:         nil,
+		Conflicts:       nil,
+		DefaultHandler:  "template.native",
+	})
+
+	r.Register(&ConditionMeta{
+		Name:            "no_world_writable",
+		ApplicableTypes: []string{"directory"},
+		Implies:         []string{"exists"},
+		Conflicts:       nil,
+		DefaultHandler:  "fs.native",
+	})
+
 	// Process/Service conditions
 	r.Register(&ConditionMeta{
 		Name:            "running",
@@ -131,7 +301,7 @@ func (r *Registry) registerBuiltins() {
 	// HTTP conditions
 	r.Register(&ConditionMeta{
 		Name:            "reachable",
-		ApplicableTypes: []string{"http"},
+		ApplicableTypes: []string{"http", "datastore"},
 		Implies:         nil,
 		Conflicts:       nil,
 		DefaultHandler:  "http.get",
@@ -153,6 +323,90 @@ func (r *Registry) registerBuiltins() {
 		DefaultHandler:  "http.get",
 	})
 
+	// Port conditions
+	r.Register(&ConditionMeta{
+		Name:            "open",
+		ApplicableTypes: []string{"port"},
+		Implies:         nil,
+		Conflicts:       []string{"closed"},
+		DefaultHandler:  "tcp.native",
+	})
+
+	r.Register(&ConditionMeta{
+		Name:            "closed",
+		ApplicableTypes: []string{"port"},
+		Implies:         nil,
+		Conflicts:       []string{"open"},
+		DefaultHandler:  "tcp.native",
+	})
+
+	// DNS conditions
+	r.Register(&ConditionMeta{
+		Name:            "resolves",
+		ApplicableTypes: []string{"dns"},
+		Implies:         nil,
+		Conflicts:       nil,
+		DefaultHandler:  "dns.native",
+	})
+
+	r.Register(&ConditionMeta{
+		Name:            "resolves_to",
+		ApplicableTypes: []string{"dns"},
+		Implies:         []string{"resolves"},
+		Conflicts:       nil,
+		DefaultHandler:  "dns.native",
+	})
+
+	// Account conditions
+	r.Register(&ConditionMeta{
+		Name:            "uid",
+		ApplicableTypes: []string{"user"},
+		Implies:         []string{"exists"},
+		Conflicts:       nil,
+		DefaultHandler:  "account.native",
+	})
+
+	r.Register(&ConditionMeta{
+		Name:            "gid",
+		ApplicableTypes: []string{"user", "group"},
+		Implies:         []string{"exists"},
+		Conflicts:       nil,
+		DefaultHandler:  "account.native",
+	})
+
+	r.Register(&ConditionMeta{
+		Name:            "member_of",
+		ApplicableTypes: []string{"user"},
+		Implies:         []string{"exists"},
+		Conflicts:       nil,
+		DefaultHandler:  "account.native",
+	})
+
+	r.Register(&ConditionMeta{
+		Name:            "shell",
+		ApplicableTypes: []string{"user"},
+		Implies:         []string{"exists"},
+		Conflicts:       nil,
+		DefaultHandler:  "account.native",
+	})
+
+	r.Register(&ConditionMeta{
+		Name:            "home",
+		ApplicableTypes: []string{"user"},
+		Implies:         []string{"exists"},
+		Conflicts:       nil,
+		DefaultHandler:  "account.native",
+	})
+
+	// Package conditions
+	r.Register(&ConditionMeta{
+		Name:            "installed",
+		ApplicableTypes: []string{"package"},
+		Implies:         nil,
+		Conflicts:       nil,
+		DefaultHandler:  "pkg.native",
+	})
+
 	// Scheduling conditions
 	r.Register(&ConditionMeta{
 		Name:            "scheduled",
@@ -162,6 +416,56 @@ func (r *Registry) registerBuiltins() {
 		DefaultHandler:  "cron.native",
 	})
 
+	// Mount conditions
+	r.Register(&ConditionMeta{
+		Name:            "mounted",
+		ApplicableTypes: []string{"mount"},
+		Implies:         nil,
+		Conflicts:       nil,
+		DefaultHandler:  "mount.native",
+	})
+	r.Register(&ConditionMeta{
+		Name:            "free_space_above",
+		ApplicableTypes: []string{"mount"},
+		Implies:         nil,
+		Conflicts:       nil,
+		DefaultHandler:  "mount.native",
+	})
+
+	// Environment variable conditions
+	r.Register(&ConditionMeta{
+		Name:            "set",
+		ApplicableTypes: []string{"env"},
+		Implies:         nil,
+		Conflicts:       nil,
+		DefaultHandler:  "env.native",
+	})
+	r.Register(&ConditionMeta{
+		Name:            "equals",
+		ApplicableTypes: []string{"env"},
+		Implies:         []string{"set"},
+		Conflicts:       nil,
+		DefaultHandler:  "env.native",
+	})
+
+	// Object storage conditions
+	r.Register(&ConditionMeta{
+		Name:            "max_age",
+		ApplicableTypes: []string{"s3"},
+		Implies:         []string{"exists"},
+		Conflicts:       nil,
+		DefaultHandler:  "s3.aws",
+	})
+
+	// Kernel parameter conditions
+	r.Register(&ConditionMeta{
+		Name:            "value",
+		ApplicableTypes: []string{"sysctl"},
+		Implies:         nil,
+		Conflicts:       nil,
+		DefaultHandler:  "sysctl.native",
+	})
+
 	// Backup conditions
 	r.Register(&ConditionMeta{
 		Name:            "backed_up",
@@ -194,8 +498,10 @@ func (r *Registry) Get(name string) (*ConditionMeta, bool) {
 
 // Expander handles implication expansion.
 type Expander struct {
-	registry *Registry
-	errors   []string
+	registry    *Registry
+	errors      []string
+	diags       []ast.Diag
+	assumptions []*ast.AssumeStmt
 }
 
 // NewExpander creates a new implication expander.
@@ -210,10 +516,71 @@ func (e *Expander) Errors() []string {
 	return e.errors
 }
 
+// Diagnostics returns all expansion and conflict errors with their
+// source ranges. Conflicts appear after CheckConflicts has run.
+func (e *Expander) Diagnostics() []ast.Diag {
+	return e.diags
+}
+
+// Assumptions returns the assume statements collected during expansion.
+func (e *Expander) Assumptions() []*ast.AssumeStmt {
+	return e.assumptions
+}
+
+// SetVariables fixes guard variables to the given values before
+// expansion, as if the program declared a matching assume statement for
+// each. Callers use this to inject variables from outside the program,
+// e.g. from CLI flags or var files.
+func (e *Expander) SetVariables(vars map[string]string) {
+	names := make([]string, 0, len(vars))
+	for name := range vars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		e.assumptions = append(e.assumptions, &ast.AssumeStmt{
+			Guard: &ast.GuardExpr{Left: name, Operator: "==", Right: vars[name]},
+		})
+	}
+}
+
+// guardActive reports whether a guard can still hold under the program's
+// declared assumptions. An assumption like `assume environment == "dev"`
+// fixes the variable, so guards requiring another value are inactive.
+func (e *Expander) guardActive(guard *ast.GuardExpr) bool {
+	if guard == nil {
+		return true
+	}
+	for _, assume := range e.assumptions {
+		if assume.Guard == nil {
+			continue
+		}
+		if !guardsCanOverlap(assume.Guard, guard) {
+			return false
+		}
+	}
+	return true
+}
+
+// Registry returns the expander's condition registry, so callers can add
+// user-defined conditions before expanding.
+func (e *Expander) Registry() *Registry {
+	return e.registry
+}
+
 // Expand expands all implied conditions in the program.
 func (e *Expander) Expand(program *ast.Program) *ast.Program {
 	var expandedStatements []ast.Statement
 
+	// Collect assumptions first: they fix guard variables for the whole
+	// program, so statements whose guard contradicts an assumption are
+	// pruned during expansion.
+	for _, stmt := range program.Statements {
+		if assume, ok := stmt.(*ast.AssumeStmt); ok {
+			e.assumptions = append(e.assumptions, assume)
+		}
+	}
+
 	for _, stmt := range program.Statements {
 		expanded := e.expandStatement(stmt)
 		expandedStatements = append(expandedStatements, expanded...)
@@ -242,6 +609,11 @@ func (e *Expander) expandStatement(stmt ast.Statement) []ast.Statement {
 }
 
 func (e *Expander) expandEnsure(stmt *ast.EnsureStmt) []ast.Statement {
+	// A guard that contradicts a declared assumption can never fire
+	if !e.guardActive(stmt.Guard) {
+		return nil
+	}
+
 	var result []ast.Statement
 
 	// Get the condition metadata
@@ -263,17 +635,38 @@ func (e *Expander) expandEnsure(stmt *ast.EnsureStmt) []ast.Statement {
 		if !valid {
 			e.errors = append(e.errors,
 				stmt.Position.String()+": condition '"+stmt.Condition+
-				"' is not applicable to resource type '"+stmt.Subject.ResourceType+"'")
+					"' is not applicable to resource type '"+stmt.Subject.ResourceType+"'")
+			e.diags = append(e.diags, ast.Diag{
+				Pos: stmt.Position,
+				End: stmt.EndPos,
+				Msg: "condition '" + stmt.Condition +
+					"' is not applicable to resource type '" + stmt.Subject.ResourceType + "'",
+			})
 		}
 	}
 
-	// Expand implied conditions first (they must be satisfied before this one)
+	// Expand implied conditions first (they must be satisfied before this one).
+	// Implications that do not apply to the subject's resource type are
+	// skipped: checksum implies readable for files, but not for s3 objects.
 	for _, implied := range meta.Implies {
+		if impliedMeta, ok := e.registry.Get(implied); ok && !applicable(impliedMeta, stmt.Subject) {
+			continue
+		}
+		// The derived statement inherits the guard, ordering clauses, and
+		// violation handler of its source, and records its provenance.
 		impliedStmt := &ast.EnsureStmt{
-			Position:  stmt.Position,
-			Condition: implied,
-			Subject:   stmt.Subject,
-			Guard:     stmt.Guard,
+			Position:         stmt.Position,
+			Condition:        implied,
+			Subject:          stmt.Subject,
+			Guard:            stmt.Guard,
+			After:            stmt.After,
+			Before:           stmt.Before,
+			ViolationHandler: stmt.ViolationHandler,
+			Severity:         stmt.Severity,
+			Tags:             stmt.Tags,
+			During:           stmt.During,
+			IsImplied:        true,
+			ImpliedBy:        stmt.Condition,
 		}
 		// Recursively expand implied conditions
 		result = append(result, e.expandEnsure(impliedStmt)...)
@@ -285,6 +678,20 @@ func (e *Expander) expandEnsure(stmt *ast.EnsureStmt) []ast.Statement {
 	return result
 }
 
+// applicable reports whether the condition applies to the subject's resource
+// type. Subjects without a resource type are not restricted.
+func applicable(meta *ConditionMeta, subject *ast.ResourceRef) bool {
+	if subject == nil || subject.ResourceType == "" {
+		return true
+	}
+	for _, t := range meta.ApplicableTypes {
+		if t == subject.ResourceType {
+			return true
+		}
+	}
+	return false
+}
+
 func (e *Expander) expandOnBlock(block *ast.OnBlock) *ast.OnBlock {
 	var expandedStatements []ast.Statement
 
@@ -333,18 +740,31 @@ func (e *Expander) expandParallelBlock(block *ast.ParallelBlock) *ast.ParallelBl
 	return block
 }
 
-// deduplicate removes duplicate guarantees.
+// deduplicate removes duplicate guarantees. When a dropped duplicate was
+// written explicitly and the kept one was synthesized, the kept statement
+// loses its implied provenance: the user declared it themselves.
 func (e *Expander) deduplicate(statements []ast.Statement) []ast.Statement {
-	seen := make(map[string]bool)
+	kept := make(map[string]*ast.EnsureStmt)
 	var result []ast.Statement
 
 	for _, stmt := range statements {
 		key := e.statementKey(stmt)
-		if key == "" || !seen[key] {
+		if key == "" {
 			result = append(result, stmt)
-			if key != "" {
-				seen[key] = true
+			continue
+		}
+		if prior, seen := kept[key]; seen {
+			if ensure, ok := stmt.(*ast.EnsureStmt); ok && prior != nil && prior.IsImplied && !ensure.IsImplied {
+				prior.IsImplied = false
+				prior.ImpliedBy = ""
 			}
+			continue
+		}
+		result = append(result, stmt)
+		if ensure, ok := stmt.(*ast.EnsureStmt); ok {
+			kept[key] = ensure
+		} else {
+			kept[key] = nil
 		}
 	}
 
@@ -371,9 +791,9 @@ func (e *Expander) CheckConflicts(program *ast.Program) []string {
 
 	// Check for conflicts within each subject
 	for subject, ensures := range bySubject {
-		conditions := make(map[string]lexer.Position)
+		seen := make(map[string][]*ast.EnsureStmt)
 		for _, ensure := range ensures {
-			conditions[ensure.Condition] = ensure.Position
+			seen[ensure.Condition] = append(seen[ensure.Condition], ensure)
 
 			// Check if this condition conflicts with any previously seen
 			meta, ok := e.registry.Get(ensure.Condition)
@@ -382,11 +802,22 @@ func (e *Expander) CheckConflicts(program *ast.Program) []string {
 			}
 
 			for _, conflict := range meta.Conflicts {
-				if pos, exists := conditions[conflict]; exists {
+				for _, prior := range seen[conflict] {
+					// Statements whose guards can never both apply do not
+					// actually conflict
+					if !guardsCanOverlap(ensure.Guard, prior.Guard) {
+						continue
+					}
 					conflicts = append(conflicts,
 						ensure.Position.String()+": '"+ensure.Condition+
-						"' conflicts with '"+conflict+"' on "+subject+
-						" (declared at "+pos.String()+")")
+							"' conflicts with '"+conflict+"' on "+subject+
+							" (declared at "+prior.Position.String()+")")
+					e.diags = append(e.diags, ast.Diag{
+						Pos: ensure.Position,
+						End: ensure.EndPos,
+						Msg: "'" + ensure.Condition + "' conflicts with '" + conflict +
+							"' on " + subject + " (declared at " + prior.Position.String() + ")",
+					})
 				}
 			}
 		}
@@ -395,23 +826,42 @@ func (e *Expander) CheckConflicts(program *ast.Program) []string {
 	return conflicts
 }
 
+// guardsCanOverlap reports whether two guard expressions can both be true
+// for some value of the guarded variable. An absent guard always applies.
+// Guards over different variables are conservatively assumed to overlap.
+func guardsCanOverlap(a, b *ast.GuardExpr) bool {
+	if a == nil || b == nil {
+		return true
+	}
+	if a.Left != b.Left {
+		return true
+	}
+
+	switch {
+	case a.Operator == "==" && b.Operator == "==":
+		// x == "dev" and x == "prod" are disjoint
+		return a.Right == b.Right
+	case a.Operator == "==" && b.Operator == "!=":
+		return a.Right != b.Right
+	case a.Operator == "!=" && b.Operator == "==":
+		return a.Right != b.Right
+	default:
+		// Two exclusions always leave common values
+		return true
+	}
+}
+
 func (e *Expander) collectEnsures(statements []ast.Statement, bySubject map[string][]*ast.EnsureStmt) {
 	for _, stmt := range statements {
-		switch s := stmt.(type) {
-		case *ast.EnsureStmt:
-			key := ""
-			if s.Subject != nil {
-				key = s.Subject.String()
+		ast.Inspect(stmt, func(node ast.Node) bool {
+			if s, ok := node.(*ast.EnsureStmt); ok {
+				key := ""
+				if s.Subject != nil {
+					key = s.Subject.String()
+				}
+				bySubject[key] = append(bySubject[key], s)
 			}
-			bySubject[key] = append(bySubject[key], s)
-		case *ast.OnBlock:
-			e.collectEnsures(s.Statements, bySubject)
-		case *ast.InvariantBlock:
-			e.collectEnsures(s.Statements, bySubject)
-		case *ast.ForEachStmt:
-			e.collectEnsures(s.Statements, bySubject)
-		case *ast.ParallelBlock:
-			e.collectEnsures(s.Statements, bySubject)
-		}
+			return true
+		})
 	}
 }
@@ -3,6 +3,7 @@ package imply
 import (
 	"testing"
 
+	"github.com/ensurascript/ensura/pkg/ast"
 	"github.com/ensurascript/ensura/pkg/parser"
 )
 
@@ -78,6 +79,184 @@ func TestConflictDetection(t *testing.T) {
 	}
 }
 
+func TestImpliedProvenance(t *testing.T) {
+	input := `ensure encrypted on file "secrets.db" with AES:256 key "env:KEY" when environment == "prod"`
+
+	program, errors := parser.ParseString(input)
+	if len(errors) > 0 {
+		t.Fatalf("Parse errors: %v", errors)
+	}
+
+	expander := NewExpander()
+	program = expander.Expand(program)
+
+	var implied, explicit int
+	for _, stmt := range program.Statements {
+		ensure, ok := stmt.(*ast.EnsureStmt)
+		if !ok {
+			continue
+		}
+		if ensure.Condition == "encrypted" {
+			explicit++
+			if ensure.IsImplied {
+				t.Error("Explicit statement should not be marked implied")
+			}
+			continue
+		}
+		implied++
+		if !ensure.IsImplied {
+			t.Errorf("Derived %q should be marked implied", ensure.Condition)
+		}
+		if ensure.ImpliedBy == "" {
+			t.Errorf("Derived %q should record what implied it", ensure.Condition)
+		}
+		if ensure.Guard == nil {
+			t.Errorf("Derived %q should inherit the guard", ensure.Condition)
+		}
+	}
+	if explicit != 1 || implied == 0 {
+		t.Errorf("Expected 1 explicit and some implied statements, got %d/%d", explicit, implied)
+	}
+}
+
+func TestExplicitDuplicateClearsImpliedFlag(t *testing.T) {
+	input := `on file "secrets.db" {
+  ensure encrypted with AES:256 key "env:KEY"
+  ensure readable
+}`
+
+	program, errors := parser.ParseString(input)
+	if len(errors) > 0 {
+		t.Fatalf("Parse errors: %v", errors)
+	}
+
+	expander := NewExpander()
+	program = expander.Expand(program)
+
+	for _, stmt := range program.Statements {
+		if ensure, ok := stmt.(*ast.EnsureStmt); ok && ensure.Condition == "readable" {
+			if ensure.IsImplied {
+				t.Error("readable was declared explicitly; implied flag should be cleared")
+			}
+		}
+	}
+}
+
+func TestConflictDetectionDisjointGuards(t *testing.T) {
+	input := `on file "test.txt" {
+  ensure encrypted with AES:256 key "env:KEY" when environment == "prod"
+  ensure unencrypted when environment == "dev"
+}`
+
+	program, errors := parser.ParseString(input)
+	if len(errors) > 0 {
+		t.Fatalf("Parse errors: %v", errors)
+	}
+
+	expander := NewExpander()
+	program = expander.Expand(program)
+
+	conflicts := expander.CheckConflicts(program)
+	if len(conflicts) != 0 {
+		t.Errorf("Expected no conflict for disjoint guards, got %v", conflicts)
+	}
+}
+
+func TestConflictDetectionOverlappingGuards(t *testing.T) {
+	input := `on file "test.txt" {
+  ensure encrypted with AES:256 key "env:KEY" when environment != "dev"
+  ensure unencrypted when environment != "prod"
+}`
+
+	program, errors := parser.ParseString(input)
+	if len(errors) > 0 {
+		t.Fatalf("Parse errors: %v", errors)
+	}
+
+	expander := NewExpander()
+	program = expander.Expand(program)
+
+	conflicts := expander.CheckConflicts(program)
+	if len(conflicts) == 0 {
+		t.Error("Expected conflict: both guards hold when environment is staging")
+	}
+}
+
+func TestGuardsCanOverlap(t *testing.T) {
+	guard := func(left, op, right string) *ast.GuardExpr {
+		return &ast.GuardExpr{Left: left, Operator: op, Right: right}
+	}
+
+	tests := []struct {
+		a, b    *ast.GuardExpr
+		overlap bool
+	}{
+		{nil, nil, true},
+		{guard("environment", "==", "prod"), nil, true},
+		{guard("environment", "==", "prod"), guard("environment", "==", "prod"), true},
+		{guard("environment", "==", "prod"), guard("environment", "==", "dev"), false},
+		{guard("environment", "==", "prod"), guard("environment", "!=", "prod"), false},
+		{guard("environment", "!=", "prod"), guard("environment", "==", "dev"), true},
+		{guard("environment", "!=", "prod"), guard("environment", "!=", "dev"), true},
+		{guard("environment", "==", "prod"), guard("region", "==", "eu"), true},
+	}
+
+	for _, tt := range tests {
+		if got := guardsCanOverlap(tt.a, tt.b); got != tt.overlap {
+			t.Errorf("guardsCanOverlap(%v, %v) = %v, want %v", tt.a, tt.b, got, tt.overlap)
+		}
+	}
+}
+
+func TestAssumptionPrunesGuardedStatements(t *testing.T) {
+	input := `assume environment == "dev"
+
+on file "test.txt" {
+  ensure exists
+  ensure encrypted with AES:256 key "env:KEY" when environment == "prod"
+}`
+
+	program, errors := parser.ParseString(input)
+	if len(errors) > 0 {
+		t.Fatalf("Parse errors: %v", errors)
+	}
+
+	expander := NewExpander()
+	program = expander.Expand(program)
+
+	for _, stmt := range program.Statements {
+		if ensure, ok := stmt.(*ast.EnsureStmt); ok && ensure.Condition == "encrypted" {
+			t.Error("encrypted is guarded on prod; assuming dev should prune it")
+		}
+	}
+
+	if len(expander.Assumptions()) != 1 {
+		t.Errorf("Expected 1 assumption, got %d", len(expander.Assumptions()))
+	}
+}
+
+func TestAssumptionPrunesConflict(t *testing.T) {
+	input := `assume environment == "dev"
+
+on file "test.txt" {
+  ensure encrypted with AES:256 key "env:KEY" when environment == "prod"
+  ensure unencrypted
+}`
+
+	program, errors := parser.ParseString(input)
+	if len(errors) > 0 {
+		t.Fatalf("Parse errors: %v", errors)
+	}
+
+	expander := NewExpander()
+	program = expander.Expand(program)
+
+	conflicts := expander.CheckConflicts(program)
+	if len(conflicts) != 0 {
+		t.Errorf("Expected no conflicts under the assumption, got %v", conflicts)
+	}
+}
+
 func TestResourceTypeValidation(t *testing.T) {
 	// encrypted is only applicable to files, not http
 	input := `ensure encrypted on http "https://example.com" with AES:256 key "env:KEY"`
@@ -0,0 +1,128 @@
+package imply
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// conditionsDocument is the on-disk format for user-defined conditions.
+type conditionsDocument struct {
+	Conditions []conditionEntry `json:"conditions"`
+}
+
+type conditionEntry struct {
+	Name            string   `json:"name"`
+	ApplicableTypes []string `json:"applicableTypes,omitempty"`
+	Implies         []string `json:"implies,omitempty"`
+	Conflicts       []string `json:"conflicts,omitempty"`
+	DefaultHandler  string   `json:"defaultHandler,omitempty"`
+}
+
+// LoadConditions parses user-defined condition metadata from a JSON
+// document and registers each entry, returning the loaded entries. Entries
+// may also redefine built-in conditions.
+func (r *Registry) LoadConditions(data []byte) ([]*ConditionMeta, error) {
+	var doc conditionsDocument
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("invalid conditions document: %w", err)
+	}
+
+	if len(doc.Conditions) == 0 {
+		return nil, fmt.Errorf("conditions document defines no conditions")
+	}
+
+	var loaded []*ConditionMeta
+	for i, entry := range doc.Conditions {
+		if entry.Name == "" {
+			return nil, fmt.Errorf("condition %d has no name", i+1)
+		}
+		loaded = append(loaded, &ConditionMeta{
+			Name:            entry.Name,
+			ApplicableTypes: entry.ApplicableTypes,
+			Implies:         entry.Implies,
+			Conflicts:       entry.Conflicts,
+			DefaultHandler:  entry.DefaultHandler,
+		})
+	}
+
+	// Expansion follows implies chains recursively, so a cycle through the
+	// loaded entries (possibly via redefined built-ins) would never
+	// terminate. Reject it here, before touching the registry.
+	implies := make(map[string][]string, len(r.conditions)+len(loaded))
+	for name, meta := range r.conditions {
+		implies[name] = meta.Implies
+	}
+	for _, meta := range loaded {
+		implies[meta.Name] = meta.Implies
+	}
+	if chain := findImplicationCycle(implies); chain != nil {
+		return nil, fmt.Errorf("implication cycle: %s", strings.Join(chain, " -> "))
+	}
+
+	for _, meta := range loaded {
+		r.Register(meta)
+	}
+
+	return loaded, nil
+}
+
+// findImplicationCycle returns a condition chain that implies itself, or
+// nil if the implication graph is acyclic.
+func findImplicationCycle(implies map[string][]string) []string {
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := make(map[string]int, len(implies))
+	var cycle []string
+
+	var visit func(name string, path []string) bool
+	visit = func(name string, path []string) bool {
+		switch state[name] {
+		case done:
+			return false
+		case visiting:
+			// Trim the path to the segment that loops back to name.
+			for i, n := range path {
+				if n == name {
+					cycle = append(append([]string(nil), path[i:]...), name)
+					return true
+				}
+			}
+			return false
+		}
+		state[name] = visiting
+		for _, implied := range implies[name] {
+			if visit(implied, append(path, name)) {
+				return true
+			}
+		}
+		state[name] = done
+		return false
+	}
+
+	names := make([]string, 0, len(implies))
+	for name := range implies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if visit(name, nil) {
+			return cycle
+		}
+	}
+	return nil
+}
+
+// LoadConditionsFile reads user-defined conditions from a file.
+func (r *Registry) LoadConditionsFile(path string) ([]*ConditionMeta, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read conditions file: %w", err)
+	}
+	return r.LoadConditions(data)
+}
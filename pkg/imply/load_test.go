@@ -0,0 +1,98 @@
+package imply
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLoadConditions(t *testing.T) {
+	doc := `{
+  "conditions": [
+    {
+      "name": "rotated",
+      "applicableTypes": ["file"],
+      "implies": ["exists"],
+      "defaultHandler": "exec"
+    },
+    {
+      "name": "replicated",
+      "applicableTypes": ["database"],
+      "conflicts": ["standalone"]
+    }
+  ]
+}`
+
+	r := NewRegistry()
+	loaded, err := r.LoadConditions([]byte(doc))
+	if err != nil {
+		t.Fatalf("LoadConditions failed: %v", err)
+	}
+	if len(loaded) != 2 {
+		t.Fatalf("Expected 2 conditions, got %d", len(loaded))
+	}
+
+	meta, ok := r.Get("rotated")
+	if !ok {
+		t.Fatal("Expected 'rotated' to be registered")
+	}
+	if meta.DefaultHandler != "exec" {
+		t.Errorf("Expected default handler 'exec', got %q", meta.DefaultHandler)
+	}
+	if len(meta.Implies) != 1 || meta.Implies[0] != "exists" {
+		t.Errorf("Expected implies [exists], got %v", meta.Implies)
+	}
+
+	meta, ok = r.Get("replicated")
+	if !ok {
+		t.Fatal("Expected 'replicated' to be registered")
+	}
+	if len(meta.Conflicts) != 1 || meta.Conflicts[0] != "standalone" {
+		t.Errorf("Expected conflicts [standalone], got %v", meta.Conflicts)
+	}
+
+	// Built-ins are still present
+	if _, ok := r.Get("exists"); !ok {
+		t.Error("Built-in conditions should survive loading")
+	}
+}
+
+func TestLoadConditionsErrors(t *testing.T) {
+	r := NewRegistry()
+
+	if _, err := r.LoadConditions([]byte("not json")); err == nil {
+		t.Error("Expected error for invalid JSON")
+	}
+	if _, err := r.LoadConditions([]byte(`{"conditions": []}`)); err == nil {
+		t.Error("Expected error for empty conditions list")
+	}
+	if _, err := r.LoadConditions([]byte(`{"conditions": [{"implies": ["exists"]}]}`)); err == nil {
+		t.Error("Expected error for condition without a name")
+	}
+}
+
+func TestLoadConditionsImplicationCycle(t *testing.T) {
+	r := NewRegistry()
+
+	doc := `{
+  "conditions": [
+    {"name": "a", "implies": ["b"]},
+    {"name": "b", "implies": ["a"]}
+  ]
+}`
+	_, err := r.LoadConditions([]byte(doc))
+	if err == nil {
+		t.Fatal("Expected error for cyclic implications")
+	}
+	if !strings.Contains(err.Error(), "implication cycle") {
+		t.Errorf("Expected implication cycle error, got %v", err)
+	}
+
+	// A rejected document must not leave partial entries behind
+	if _, ok := r.Get("a"); ok {
+		t.Error("Expected rejected conditions not to be registered")
+	}
+
+	if _, err := r.LoadConditions([]byte(`{"conditions": [{"name": "c", "implies": ["c"]}]}`)); err == nil {
+		t.Error("Expected error for self-implication")
+	}
+}
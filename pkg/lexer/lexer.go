@@ -1,6 +1,10 @@
 package lexer
 
 import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
 	"unicode"
 	"unicode/utf8"
 )
@@ -94,7 +98,7 @@ func (l *Lexer) NextToken() Token {
 			l.readChar()
 			tok = l.newToken(EQUALS, string(ch)+string(l.ch))
 		} else {
-			tok = l.newToken(ILLEGAL, string(l.ch))
+			tok = l.newToken(ASSIGN, string(l.ch))
 		}
 	case '!':
 		if l.peekChar() == '=' {
@@ -108,27 +112,44 @@ func (l *Lexer) NextToken() Token {
 		tok.Type = COMMENT
 		tok.Literal = l.readComment()
 		tok.Pos = pos
+		tok.End = l.currentPos()
 		return tok
 	case '"':
 		tok.Type = STRING
 		tok.Literal = l.readString()
 		tok.Pos = pos
+		tok.End = l.currentPos()
+		return tok
+	case '`':
+		tok.Type = STRING
+		tok.Literal = l.readRawString()
+		tok.Pos = pos
+		tok.End = l.currentPos()
 		return tok
 	case 0:
 		tok.Type = EOF
 		tok.Literal = ""
 		tok.Pos = pos
+		tok.End = pos
 		return tok
 	default:
 		if isLetter(l.ch) {
 			tok.Literal = l.readIdentifier()
 			tok.Type = LookupIdent(tok.Literal)
 			tok.Pos = pos
+			tok.End = l.currentPos()
 			return tok
 		} else if isDigit(l.ch) {
 			tok.Literal = l.readNumber()
 			tok.Type = NUMBER
+			// A unit suffix turns a bare number into a duration or size
+			// literal ("30s", "1h30m", "10MB")
+			if isLetter(l.ch) {
+				tok.Literal += l.readLiteralSuffix()
+				tok.Type = classifyNumericLiteral(tok.Literal)
+			}
 			tok.Pos = pos
+			tok.End = l.currentPos()
 			return tok
 		} else {
 			tok = l.newToken(ILLEGAL, string(l.ch))
@@ -136,6 +157,7 @@ func (l *Lexer) NextToken() Token {
 	}
 
 	l.readChar()
+	tok.End = l.currentPos()
 	return tok
 }
 
@@ -169,19 +191,106 @@ func (l *Lexer) readNumber() string {
 	return l.input[start:l.pos]
 }
 
+// readLiteralSuffix reads the unit tail of a numeric literal, including
+// any further digits so compound durations like "1h30m" stay one token.
+func (l *Lexer) readLiteralSuffix() string {
+	start := l.pos
+	for isLetter(l.ch) || isDigit(l.ch) {
+		l.readChar()
+	}
+	return l.input[start:l.pos]
+}
+
+// classifyNumericLiteral decides whether a number with a unit suffix is
+// a duration or a size. Anything else is malformed.
+func classifyNumericLiteral(lit string) TokenType {
+	if _, err := time.ParseDuration(lit); err == nil {
+		return DURATION
+	}
+	if _, err := ParseSize(lit); err == nil {
+		return SIZE
+	}
+	return ILLEGAL
+}
+
+// sizeUnits maps size literal suffixes to byte multipliers. The plain
+// units are decimal; the -ib forms are binary.
+var sizeUnits = map[string]int64{
+	"B":   1,
+	"KB":  1000,
+	"MB":  1000 * 1000,
+	"GB":  1000 * 1000 * 1000,
+	"TB":  1000 * 1000 * 1000 * 1000,
+	"KiB": 1024,
+	"MiB": 1024 * 1024,
+	"GiB": 1024 * 1024 * 1024,
+	"TiB": 1024 * 1024 * 1024 * 1024,
+}
+
+// ParseSize converts a size literal like "10MB" or "512KiB" to bytes.
+func ParseSize(s string) (int64, error) {
+	i := 0
+	for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+		i++
+	}
+	if i == 0 {
+		return 0, fmt.Errorf("invalid size literal %q", s)
+	}
+	n, err := strconv.ParseInt(s[:i], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid size literal %q: %v", s, err)
+	}
+	mult, ok := sizeUnits[s[i:]]
+	if !ok {
+		return 0, fmt.Errorf("unknown size unit %q in %q", s[i:], s)
+	}
+	return n * mult, nil
+}
+
 func (l *Lexer) readString() string {
 	l.readChar() // skip opening quote
-	start := l.pos
+	var out strings.Builder
 	for l.ch != '"' && l.ch != 0 {
 		if l.ch == '\\' {
-			l.readChar() // skip escape char
+			l.readChar()
+			switch l.ch {
+			case 'n':
+				out.WriteByte('\n')
+			case 't':
+				out.WriteByte('\t')
+			case '"':
+				out.WriteByte('"')
+			case '\\':
+				out.WriteByte('\\')
+			default:
+				// Unknown escape: keep the backslash so the literal is
+				// not silently altered.
+				out.WriteByte('\\')
+				out.WriteRune(l.ch)
+			}
+		} else {
+			out.WriteRune(l.ch)
 		}
 		l.readChar()
 	}
-	str := l.input[start:l.pos]
 	if l.ch == '"' {
 		l.readChar() // skip closing quote
 	}
+	return out.String()
+}
+
+// readRawString reads a backtick-delimited string. No escape processing
+// is done, so regexes and Windows paths can be written verbatim.
+func (l *Lexer) readRawString() string {
+	l.readChar() // skip opening backtick
+	start := l.pos
+	for l.ch != '`' && l.ch != 0 {
+		l.readChar()
+	}
+	str := l.input[start:l.pos]
+	if l.ch == '`' {
+		l.readChar() // skip closing backtick
+	}
 	return str
 }
 
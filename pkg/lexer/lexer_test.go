@@ -129,6 +129,13 @@ func TestStrings(t *testing.T) {
 		{`"path/to/file"`, "path/to/file"},
 		{`"env:SECRET_KEY"`, "env:SECRET_KEY"},
 		{`"https://example.com"`, "https://example.com"},
+		{`"line1\nline2"`, "line1\nline2"},
+		{`"col1\tcol2"`, "col1\tcol2"},
+		{`"say \"hi\""`, `say "hi"`},
+		{`"C:\\temp"`, `C:\temp`},
+		{`"unknown \q escape"`, `unknown \q escape`},
+		{"`raw \\n stays`", `raw \n stays`},
+		{"`C:\\Users\\admin`", `C:\Users\admin`},
 	}
 
 	for _, tt := range tests {
@@ -197,11 +204,92 @@ ensure exists`
 	}
 
 	// Skip to next line
-	l.NextToken() // file
-	l.NextToken() // "test.txt"
+	l.NextToken()       // file
+	l.NextToken()       // "test.txt"
 	tok = l.NextToken() // ensure
 
 	if tok.Pos.Line != 2 {
 		t.Errorf("Expected line 2, got %d", tok.Pos.Line)
 	}
 }
+
+func TestEndPosition(t *testing.T) {
+	input := `resource file "a.txt"`
+
+	l := New(input)
+
+	tok := l.NextToken() // resource
+	if tok.End.Column != tok.Pos.Column+len("resource") {
+		t.Errorf("Expected end column %d, got %d", tok.Pos.Column+len("resource"), tok.End.Column)
+	}
+	if tok.End.Offset != len("resource") {
+		t.Errorf("Expected end offset %d, got %d", len("resource"), tok.End.Offset)
+	}
+
+	l.NextToken()       // file
+	tok = l.NextToken() // "a.txt"
+	if tok.End.Offset != len(input) {
+		t.Errorf("Expected string end offset %d, got %d", len(input), tok.End.Offset)
+	}
+}
+
+func TestTypedLiterals(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected TokenType
+		literal  string
+	}{
+		{"30s", DURATION, "30s"},
+		{"24h", DURATION, "24h"},
+		{"1h30m", DURATION, "1h30m"},
+		{"10MB", SIZE, "10MB"},
+		{"512KiB", SIZE, "512KiB"},
+		{"100B", SIZE, "100B"},
+		{"true", BOOLEAN, "true"},
+		{"false", BOOLEAN, "false"},
+		{"0600", NUMBER, "0600"},
+		{"10XY", ILLEGAL, "10XY"},
+	}
+
+	for _, tt := range tests {
+		l := New(tt.input)
+		tok := l.NextToken()
+
+		if tok.Type != tt.expected {
+			t.Errorf("%s: expected %v, got %v", tt.input, tt.expected, tok.Type)
+		}
+		if tok.Literal != tt.literal {
+			t.Errorf("%s: expected literal %q, got %q", tt.input, tt.literal, tok.Literal)
+		}
+	}
+}
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"100B", 100},
+		{"10KB", 10000},
+		{"10MB", 10000000},
+		{"1KiB", 1024},
+		{"2MiB", 2 * 1024 * 1024},
+	}
+
+	for _, tt := range tests {
+		n, err := ParseSize(tt.input)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.input, err)
+			continue
+		}
+		if n != tt.expected {
+			t.Errorf("%s: expected %d bytes, got %d", tt.input, tt.expected, n)
+		}
+	}
+
+	for _, bad := range []string{"MB", "10", "10QB", ""} {
+		if _, err := ParseSize(bad); err == nil {
+			t.Errorf("%s: expected error, got none", bad)
+		}
+	}
+}
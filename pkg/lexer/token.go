@@ -13,9 +13,12 @@ const (
 	COMMENT
 
 	// Literals
-	IDENT  // identifiers like file, exists, etc.
-	STRING // "string literal"
-	NUMBER // 123, 0600
+	IDENT    // identifiers like file, exists, etc.
+	STRING   // "string literal"
+	NUMBER   // 123, 0600
+	DURATION // 30s, 24h, 1h30m
+	SIZE     // 10MB, 512KiB
+	BOOLEAN  // true, false
 
 	// Delimiters
 	LBRACE    // {
@@ -27,6 +30,7 @@ const (
 	NEWLINE   // \n (significant in some contexts)
 	EQUALS    // ==
 	NOTEQUALS // !=
+	ASSIGN    // = (policy parameter defaults)
 
 	// Keywords
 	RESOURCE
@@ -59,6 +63,12 @@ const (
 	CRON
 	ENVIRONMENT
 	PARALLEL
+	TIMEOUT
+	IMPORT
+	SEVERITY
+	TAGS
+	DURING
+	WITHIN
 )
 
 var tokenNames = map[TokenType]string{
@@ -68,6 +78,9 @@ var tokenNames = map[TokenType]string{
 	IDENT:        "IDENT",
 	STRING:       "STRING",
 	NUMBER:       "NUMBER",
+	DURATION:     "DURATION",
+	SIZE:         "SIZE",
+	BOOLEAN:      "BOOLEAN",
 	LBRACE:       "LBRACE",
 	RBRACE:       "RBRACE",
 	LPAREN:       "LPAREN",
@@ -77,6 +90,7 @@ var tokenNames = map[TokenType]string{
 	NEWLINE:      "NEWLINE",
 	EQUALS:       "EQUALS",
 	NOTEQUALS:    "NOTEQUALS",
+	ASSIGN:       "ASSIGN",
 	RESOURCE:     "RESOURCE",
 	ENSURE:       "ENSURE",
 	ON:           "ON",
@@ -107,6 +121,12 @@ var tokenNames = map[TokenType]string{
 	CRON:         "CRON",
 	ENVIRONMENT:  "ENVIRONMENT",
 	PARALLEL:     "PARALLEL",
+	TIMEOUT:      "TIMEOUT",
+	IMPORT:       "IMPORT",
+	SEVERITY:     "SEVERITY",
+	TAGS:         "TAGS",
+	DURING:       "DURING",
+	WITHIN:       "WITHIN",
 }
 
 func (t TokenType) String() string {
@@ -147,6 +167,14 @@ var keywords = map[string]TokenType{
 	"cron":        CRON,
 	"environment": ENVIRONMENT,
 	"parallel":    PARALLEL,
+	"timeout":     TIMEOUT,
+	"import":      IMPORT,
+	"severity":    SEVERITY,
+	"tags":        TAGS,
+	"during":      DURING,
+	"within":      WITHIN,
+	"true":        BOOLEAN,
+	"false":       BOOLEAN,
 }
 
 // LookupIdent returns the token type for an identifier.
@@ -172,11 +200,13 @@ func (p Position) String() string {
 	return fmt.Sprintf("%d:%d", p.Line, p.Column)
 }
 
-// Token represents a lexical token.
+// Token represents a lexical token. End is the position just past the
+// token's last character, so Pos and End together span its source text.
 type Token struct {
 	Type    TokenType
 	Literal string
 	Pos     Position
+	End     Position
 }
 
 func (t Token) String() string {
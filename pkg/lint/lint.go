@@ -0,0 +1,320 @@
+// Package lint provides static analysis rules for EnsuraScript programs.
+package lint
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ensurascript/ensura/pkg/ast"
+	"github.com/ensurascript/ensura/pkg/imply"
+	"github.com/ensurascript/ensura/pkg/lexer"
+)
+
+// Rule names for enabling/disabling individual checks.
+const (
+	RuleUnusedAlias     = "unused-alias"
+	RuleUnusedPolicy    = "unused-policy"
+	RuleNoHandler       = "no-handler"
+	RuleDuplicateEnsure = "duplicate-ensure"
+	RulePermissiveMode  = "permissive-mode"
+	RulePlaintextKey    = "plaintext-key"
+)
+
+// AllRules lists every known rule name.
+var AllRules = []string{
+	RuleUnusedAlias,
+	RuleUnusedPolicy,
+	RuleNoHandler,
+	RuleDuplicateEnsure,
+	RulePermissiveMode,
+	RulePlaintextKey,
+}
+
+// assumptionSuppressions maps simple assume statements to the lint rules
+// they silence. A declared assumption documents that the author accepts
+// what the rule warns about, so repeating the warning is noise.
+var assumptionSuppressions = map[string][]string{
+	"filesystem reliable":     {RuleNoHandler},
+	"permissions intentional": {RulePermissiveMode},
+	"secrets external":        {RulePlaintextKey},
+	"duplicates intentional":  {RuleDuplicateEnsure},
+}
+
+// Issue represents a single lint finding.
+type Issue struct {
+	Rule     string         `json:"rule"`
+	Position lexer.Position `json:"-"`
+	Message  string         `json:"message"`
+}
+
+func (i Issue) String() string {
+	return fmt.Sprintf("%s: [%s] %s", i.Position, i.Rule, i.Message)
+}
+
+// Linter runs lint rules over a parsed program.
+type Linter struct {
+	enabled    map[string]bool
+	suppressed map[string]bool
+	registry   *imply.Registry
+	issues     []Issue
+}
+
+// New creates a Linter with all rules enabled.
+func New() *Linter {
+	l := &Linter{
+		enabled:  make(map[string]bool),
+		registry: imply.NewRegistry(),
+	}
+	for _, rule := range AllRules {
+		l.enabled[rule] = true
+	}
+	return l
+}
+
+// Enable turns a rule on.
+func (l *Linter) Enable(rule string) {
+	l.enabled[rule] = true
+}
+
+// Disable turns a rule off.
+func (l *Linter) Disable(rule string) {
+	l.enabled[rule] = false
+}
+
+// Lint analyzes the program and returns all issues found.
+func (l *Linter) Lint(program *ast.Program) []Issue {
+	l.issues = nil
+
+	// Simple assume statements suppress the rules they acknowledge
+	l.suppressed = make(map[string]bool)
+	for _, stmt := range program.Statements {
+		if assume, ok := stmt.(*ast.AssumeStmt); ok && assume.Simple != "" {
+			for _, rule := range assumptionSuppressions[assume.Simple] {
+				l.suppressed[rule] = true
+			}
+		}
+	}
+
+	if l.active(RuleUnusedAlias) {
+		l.checkUnusedAliases(program)
+	}
+	if l.active(RuleUnusedPolicy) {
+		l.checkUnusedPolicies(program)
+	}
+
+	seen := make(map[string]lexer.Position)
+	l.lintStatements(program.Statements, seen, "")
+
+	return l.issues
+}
+
+// active reports whether a rule should run: it must be enabled and not
+// suppressed by an assumption in the current program.
+func (l *Linter) active(rule string) bool {
+	return l.enabled[rule] && !l.suppressed[rule]
+}
+
+func (l *Linter) addIssue(rule string, pos lexer.Position, msg string) {
+	l.issues = append(l.issues, Issue{Rule: rule, Position: pos, Message: msg})
+}
+
+func (l *Linter) checkUnusedAliases(program *ast.Program) {
+	declared := make(map[string]*ast.ResourceDecl)
+	for _, stmt := range program.Statements {
+		if decl, ok := stmt.(*ast.ResourceDecl); ok && decl.Alias != "" {
+			declared[decl.Alias] = decl
+		}
+	}
+	if len(declared) == 0 {
+		return
+	}
+
+	used := make(map[string]bool)
+	var markRefs func(statements []ast.Statement)
+	markRef := func(ref *ast.ResourceRef) {
+		if ref != nil && ref.Alias != "" {
+			used[ref.Alias] = true
+		}
+	}
+	markRefs = func(statements []ast.Statement) {
+		for _, stmt := range statements {
+			switch s := stmt.(type) {
+			case *ast.EnsureStmt:
+				markRef(s.Subject)
+				for _, rc := range s.RequiresResource {
+					markRef(rc.Ref)
+				}
+				for _, ref := range s.After {
+					markRef(ref)
+				}
+				for _, ref := range s.Before {
+					markRef(ref)
+				}
+			case *ast.OnBlock:
+				markRef(s.Subject)
+				markRefs(s.Statements)
+			case *ast.PolicyDecl:
+				markRefs(s.Statements)
+			case *ast.ForEachStmt:
+				markRef(s.Container)
+				markRefs(s.Statements)
+			case *ast.InvariantBlock:
+				markRefs(s.Statements)
+			case *ast.ParallelBlock:
+				markRefs(s.Statements)
+			}
+		}
+	}
+	markRefs(program.Statements)
+
+	for alias, decl := range declared {
+		if !used[alias] {
+			l.addIssue(RuleUnusedAlias, decl.Position,
+				fmt.Sprintf("resource alias %q is declared but never used", alias))
+		}
+	}
+}
+
+func (l *Linter) checkUnusedPolicies(program *ast.Program) {
+	declared := make(map[string]*ast.PolicyDecl)
+	for _, stmt := range program.Statements {
+		if decl, ok := stmt.(*ast.PolicyDecl); ok {
+			declared[decl.Name] = decl
+		}
+	}
+	if len(declared) == 0 {
+		return
+	}
+
+	applied := make(map[string]bool)
+	var markApplies func(statements []ast.Statement)
+	markApplies = func(statements []ast.Statement) {
+		for _, stmt := range statements {
+			switch s := stmt.(type) {
+			case *ast.ApplyStmt:
+				applied[s.PolicyName] = true
+			case *ast.OnBlock:
+				markApplies(s.Statements)
+			case *ast.PolicyDecl:
+				markApplies(s.Statements)
+			case *ast.ForEachStmt:
+				markApplies(s.Statements)
+			case *ast.InvariantBlock:
+				markApplies(s.Statements)
+			case *ast.ParallelBlock:
+				markApplies(s.Statements)
+			}
+		}
+	}
+	markApplies(program.Statements)
+
+	for name, decl := range declared {
+		if !applied[name] {
+			l.addIssue(RuleUnusedPolicy, decl.Position,
+				fmt.Sprintf("policy %q is declared but never applied", name))
+		}
+	}
+}
+
+// lintStatements walks a statement list. Binding has not run yet, so
+// ensures without an inline subject are scoped by the enclosing on-block's
+// subject, carried down the recursion.
+func (l *Linter) lintStatements(statements []ast.Statement, seen map[string]lexer.Position, enclosing string) {
+	for _, stmt := range statements {
+		switch s := stmt.(type) {
+		case *ast.EnsureStmt:
+			l.lintEnsure(s, seen, enclosing)
+		case *ast.OnBlock:
+			blockSubject := enclosing
+			if s.Subject != nil {
+				blockSubject = s.Subject.String()
+			}
+			l.lintStatements(s.Statements, seen, blockSubject)
+		case *ast.PolicyDecl:
+			// Policies get their own dedup scope; they may legitimately
+			// repeat conditions that also appear at top level.
+			l.lintStatements(s.Statements, make(map[string]lexer.Position), "")
+		case *ast.ForEachStmt:
+			l.lintStatements(s.Statements, make(map[string]lexer.Position), "")
+		case *ast.InvariantBlock:
+			l.lintStatements(s.Statements, seen, enclosing)
+		case *ast.ParallelBlock:
+			l.lintStatements(s.Statements, seen, enclosing)
+		}
+	}
+}
+
+func (l *Linter) lintEnsure(stmt *ast.EnsureStmt, seen map[string]lexer.Position, enclosing string) {
+	if l.active(RuleDuplicateEnsure) {
+		subject := enclosing
+		if stmt.Subject != nil {
+			subject = stmt.Subject.String()
+		}
+		// Guards are part of the key: the same condition under different
+		// when clauses targets different environments.
+		key := stmt.Condition + ":" + subject
+		if stmt.Guard != nil {
+			key += ":" + stmt.Guard.String()
+		}
+		if first, ok := seen[key]; ok {
+			msg := fmt.Sprintf("duplicate ensure %q (first at %s)", stmt.Condition, first)
+			if subject != "" {
+				msg = fmt.Sprintf("duplicate ensure %q on %s (first at %s)", stmt.Condition, subject, first)
+			}
+			l.addIssue(RuleDuplicateEnsure, stmt.Position, msg)
+		} else {
+			seen[key] = stmt.Position
+		}
+	}
+
+	if l.active(RuleNoHandler) && stmt.Handler == nil {
+		meta, known := l.registry.Get(stmt.Condition)
+		if !known || meta.DefaultHandler == "" {
+			l.addIssue(RuleNoHandler, stmt.Position,
+				fmt.Sprintf("ensure %q has no handler and no default handler is available", stmt.Condition))
+		}
+	}
+
+	if stmt.Handler == nil {
+		return
+	}
+
+	if l.active(RulePermissiveMode) {
+		if mode, ok := stmt.Handler.Args["mode"]; ok && isPermissiveMode(mode) {
+			l.addIssue(RulePermissiveMode, stmt.Handler.Position,
+				fmt.Sprintf("mode %q is overly permissive", mode))
+		}
+	}
+
+	if l.active(RulePlaintextKey) {
+		if key, ok := stmt.Handler.Args["key"]; ok && isPlaintextKey(key) {
+			l.addIssue(RulePlaintextKey, stmt.Handler.Position,
+				"key appears to be a plaintext secret; use an env: or file: reference")
+		}
+	}
+}
+
+// isPermissiveMode reports whether an octal mode grants write access to
+// group or other.
+func isPermissiveMode(mode string) bool {
+	trimmed := strings.TrimLeft(mode, "0")
+	if len(trimmed) < 3 {
+		return false
+	}
+	perm := trimmed[len(trimmed)-3:]
+	group := perm[1]
+	other := perm[2]
+	return hasWriteBit(group) || hasWriteBit(other)
+}
+
+func hasWriteBit(digit byte) bool {
+	return digit >= '0' && digit <= '7' && (digit-'0')&2 != 0
+}
+
+// isPlaintextKey reports whether a key argument is a literal secret rather
+// than an env: or file: reference.
+func isPlaintextKey(key string) bool {
+	return key != "" &&
+		!strings.HasPrefix(key, "env:") &&
+		!strings.HasPrefix(key, "file:")
+}
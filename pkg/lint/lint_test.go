@@ -0,0 +1,190 @@
+package lint
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ensurascript/ensura/pkg/parser"
+)
+
+func lintSource(t *testing.T, source string) []Issue {
+	t.Helper()
+	program, errs := parser.ParseString(source)
+	if len(errs) > 0 {
+		t.Fatalf("parse errors: %v", errs)
+	}
+	return New().Lint(program)
+}
+
+func hasIssue(issues []Issue, rule string) bool {
+	for _, issue := range issues {
+		if issue.Rule == rule {
+			return true
+		}
+	}
+	return false
+}
+
+func TestUnusedAlias(t *testing.T) {
+	issues := lintSource(t, `
+resource file "a.txt" as unused_file
+resource file "b.txt" as used_file
+
+ensure exists on used_file
+`)
+	if !hasIssue(issues, RuleUnusedAlias) {
+		t.Error("Expected unused-alias issue")
+	}
+	for _, issue := range issues {
+		if issue.Rule == RuleUnusedAlias && strings.Contains(issue.Message, "used_file") &&
+			!strings.Contains(issue.Message, "unused_file") {
+			t.Errorf("Wrong alias flagged: %s", issue.Message)
+		}
+	}
+}
+
+func TestUnusedPolicy(t *testing.T) {
+	issues := lintSource(t, `
+policy never_applied {
+  ensure exists
+}
+`)
+	if !hasIssue(issues, RuleUnusedPolicy) {
+		t.Error("Expected unused-policy issue")
+	}
+}
+
+func TestDuplicateEnsure(t *testing.T) {
+	issues := lintSource(t, `
+ensure exists on file "a.txt"
+ensure exists on file "a.txt"
+`)
+	if !hasIssue(issues, RuleDuplicateEnsure) {
+		t.Error("Expected duplicate-ensure issue")
+	}
+}
+
+func TestDuplicateEnsureScopedByBlock(t *testing.T) {
+	// The same condition in on-blocks for different resources is not a
+	// duplicate
+	issues := lintSource(t, `
+on file "a.txt" {
+  ensure exists
+}
+on file "b.txt" {
+  ensure exists
+}
+`)
+	if hasIssue(issues, RuleDuplicateEnsure) {
+		t.Error("Did not expect duplicate-ensure issue across different on-blocks")
+	}
+
+	// But repeating it for the same resource is
+	issues = lintSource(t, `
+on file "a.txt" {
+  ensure exists
+}
+on file "a.txt" {
+  ensure exists
+}
+`)
+	if !hasIssue(issues, RuleDuplicateEnsure) {
+		t.Error("Expected duplicate-ensure issue for repeated on-block subject")
+	}
+}
+
+func TestDuplicateEnsureGuards(t *testing.T) {
+	issues := lintSource(t, `
+on file "a.txt" {
+  ensure backed_up when environment == "prod"
+  ensure backed_up when environment == "dev"
+}
+`)
+	if hasIssue(issues, RuleDuplicateEnsure) {
+		t.Error("Did not expect duplicate-ensure issue for different guards")
+	}
+}
+
+func TestPermissiveMode(t *testing.T) {
+	issues := lintSource(t, `
+ensure permissions on file "a.txt" with posix mode "0777"
+`)
+	if !hasIssue(issues, RulePermissiveMode) {
+		t.Error("Expected permissive-mode issue")
+	}
+
+	issues = lintSource(t, `
+ensure permissions on file "a.txt" with posix mode "0600"
+`)
+	if hasIssue(issues, RulePermissiveMode) {
+		t.Error("Did not expect permissive-mode issue for 0600")
+	}
+}
+
+func TestPlaintextKey(t *testing.T) {
+	issues := lintSource(t, `
+ensure encrypted on file "a.txt" with AES:256 key "hunter2"
+`)
+	if !hasIssue(issues, RulePlaintextKey) {
+		t.Error("Expected plaintext-key issue")
+	}
+
+	issues = lintSource(t, `
+ensure encrypted on file "a.txt" with AES:256 key "env:SECRET_KEY"
+`)
+	if hasIssue(issues, RulePlaintextKey) {
+		t.Error("Did not expect plaintext-key issue for env: reference")
+	}
+}
+
+func TestNoHandler(t *testing.T) {
+	issues := lintSource(t, `
+ensure frobnicated on file "a.txt"
+`)
+	if !hasIssue(issues, RuleNoHandler) {
+		t.Error("Expected no-handler issue for unknown condition")
+	}
+
+	issues = lintSource(t, `
+ensure exists on file "a.txt"
+`)
+	if hasIssue(issues, RuleNoHandler) {
+		t.Error("Did not expect no-handler issue when a default exists")
+	}
+}
+
+func TestDisableRule(t *testing.T) {
+	program, errs := parser.ParseString(`
+ensure permissions on file "a.txt" with posix mode "0777"
+`)
+	if len(errs) > 0 {
+		t.Fatalf("parse errors: %v", errs)
+	}
+
+	l := New()
+	l.Disable(RulePermissiveMode)
+	issues := l.Lint(program)
+	if hasIssue(issues, RulePermissiveMode) {
+		t.Error("Expected disabled rule to produce no issues")
+	}
+}
+
+func TestAssumptionSuppressesRule(t *testing.T) {
+	issues := lintSource(t, `
+assume permissions intentional
+
+ensure permissions on file "a.txt" with posix mode "0777"
+`)
+	if hasIssue(issues, RulePermissiveMode) {
+		t.Error("Expected assumption to suppress permissive-mode issue")
+	}
+
+	issues = lintSource(t, `
+assume filesystem reliable
+
+ensure permissions on file "a.txt" with posix mode "0777"
+`)
+	if !hasIssue(issues, RulePermissiveMode) {
+		t.Error("Unrelated assumption should not suppress permissive-mode")
+	}
+}
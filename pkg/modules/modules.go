@@ -0,0 +1,211 @@
+// Package modules fetches shared policy bundles from git repositories and
+// caches them locally. A module reference names a repository and an
+// optional subdirectory, e.g. "github.com/org/ensura-policies//hardening".
+// Modules can be vendored into the working tree with `ensura get` and
+// pinned to a sha256 checksum for integrity.
+package modules
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ensurascript/ensura/pkg/ast"
+	"github.com/ensurascript/ensura/pkg/parser"
+)
+
+// VendorDir is the directory (relative to the working tree) that vendored
+// modules are copied into.
+const VendorDir = "ensura_modules"
+
+// Ref is a parsed module reference.
+type Ref struct {
+	Repo   string // repository host/path, e.g. github.com/org/ensura-policies
+	Subdir string // optional subdirectory within the repository
+}
+
+// ParseRef splits a module reference into repository and subdirectory.
+func ParseRef(s string) (Ref, error) {
+	if s == "" {
+		return Ref{}, fmt.Errorf("empty module reference")
+	}
+	repo, subdir, _ := strings.Cut(s, "//")
+	if repo == "" {
+		return Ref{}, fmt.Errorf("invalid module reference: %s", s)
+	}
+	return Ref{Repo: repo, Subdir: subdir}, nil
+}
+
+// DefaultCacheDir returns the directory used to cache fetched modules.
+func DefaultCacheDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(".", ".ensura", "modules")
+	}
+	return filepath.Join(home, ".ensura", "modules")
+}
+
+// Resolve returns the local directory holding a module's files. Vendored
+// copies win; otherwise the cache is used, fetching on a miss.
+func Resolve(reference string) (string, error) {
+	ref, err := ParseRef(reference)
+	if err != nil {
+		return "", err
+	}
+
+	vendored := filepath.Join(VendorDir, filepath.FromSlash(ref.Repo), filepath.FromSlash(ref.Subdir))
+	if info, err := os.Stat(vendored); err == nil && info.IsDir() {
+		return vendored, nil
+	}
+
+	return fetch(ref, DefaultCacheDir())
+}
+
+// fetch clones the repository into the cache (if not already present) and
+// returns the module directory.
+func fetch(ref Ref, cacheDir string) (string, error) {
+	repoDir := filepath.Join(cacheDir, filepath.FromSlash(ref.Repo))
+	if _, err := os.Stat(repoDir); os.IsNotExist(err) {
+		if err := os.MkdirAll(filepath.Dir(repoDir), 0755); err != nil {
+			return "", fmt.Errorf("failed to create module cache: %w", err)
+		}
+		cmd := exec.Command("git", "clone", "--depth", "1", "https://"+ref.Repo, repoDir)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			return "", fmt.Errorf("failed to fetch module %s: %v: %s", ref.Repo, err, strings.TrimSpace(string(out)))
+		}
+	}
+
+	dir := filepath.Join(repoDir, filepath.FromSlash(ref.Subdir))
+	info, err := os.Stat(dir)
+	if err != nil || !info.IsDir() {
+		return "", fmt.Errorf("module %s has no directory %q", ref.Repo, ref.Subdir)
+	}
+	return dir, nil
+}
+
+// Vendor copies a module into the vendor directory and returns its path.
+func Vendor(reference string) (string, error) {
+	ref, err := ParseRef(reference)
+	if err != nil {
+		return "", err
+	}
+	src, err := fetch(ref, DefaultCacheDir())
+	if err != nil {
+		return "", err
+	}
+	dst := filepath.Join(VendorDir, filepath.FromSlash(ref.Repo), filepath.FromSlash(ref.Subdir))
+	if err := copyDir(src, dst); err != nil {
+		return "", err
+	}
+	return dst, nil
+}
+
+// Checksum computes a sha256 over the module's .ens files, hashing file
+// names and contents in sorted order so the result is reproducible.
+func Checksum(dir string) (string, error) {
+	files, err := sourceFiles(dir)
+	if err != nil {
+		return "", err
+	}
+
+	h := sha256.New()
+	for _, file := range files {
+		data, err := os.ReadFile(filepath.Join(dir, file))
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(h, "%s\n", file)
+		h.Write(data)
+	}
+	return fmt.Sprintf("%x", h.Sum(nil)), nil
+}
+
+// LoadPolicies parses every .ens file in a module directory and returns
+// the policy declarations found.
+func LoadPolicies(dir string) ([]*ast.PolicyDecl, error) {
+	files, err := sourceFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var policies []*ast.PolicyDecl
+	for _, file := range files {
+		path := filepath.Join(dir, file)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		program, parseErrors := parser.ParseFile(string(data), path)
+		if len(parseErrors) > 0 {
+			return nil, fmt.Errorf("parse errors in %s: %s", path, strings.Join(parseErrors, "; "))
+		}
+		for _, stmt := range program.Statements {
+			if decl, ok := stmt.(*ast.PolicyDecl); ok {
+				policies = append(policies, decl)
+			}
+		}
+	}
+	return policies, nil
+}
+
+// sourceFiles returns the .ens files directly inside dir, sorted by name.
+func sourceFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read module directory: %w", err)
+	}
+	var files []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".ens") {
+			files = append(files, entry.Name())
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+func copyDir(src, dst string) error {
+	if err := os.MkdirAll(dst, 0755); err != nil {
+		return err
+	}
+	entries, err := os.ReadDir(src)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		srcPath := filepath.Join(src, entry.Name())
+		dstPath := filepath.Join(dst, entry.Name())
+		if entry.IsDir() {
+			if err := copyDir(srcPath, dstPath); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := copyFile(srcPath, dstPath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	_, err = io.Copy(out, in)
+	return err
+}
@@ -0,0 +1,96 @@
+package modules
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseRef(t *testing.T) {
+	ref, err := ParseRef("github.com/org/ensura-policies//hardening")
+	if err != nil {
+		t.Fatalf("ParseRef: %v", err)
+	}
+	if ref.Repo != "github.com/org/ensura-policies" {
+		t.Errorf("Repo = %q", ref.Repo)
+	}
+	if ref.Subdir != "hardening" {
+		t.Errorf("Subdir = %q", ref.Subdir)
+	}
+}
+
+func TestParseRefNoSubdir(t *testing.T) {
+	ref, err := ParseRef("github.com/org/ensura-policies")
+	if err != nil {
+		t.Fatalf("ParseRef: %v", err)
+	}
+	if ref.Repo != "github.com/org/ensura-policies" || ref.Subdir != "" {
+		t.Errorf("got %+v", ref)
+	}
+}
+
+func TestParseRefEmpty(t *testing.T) {
+	if _, err := ParseRef(""); err == nil {
+		t.Error("expected error for empty reference")
+	}
+}
+
+func TestChecksumDeterministic(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("a.ens", "policy base {\n  exists\n}\n")
+	write("b.ens", "policy other {\n  exists\n}\n")
+	write("notes.txt", "ignored")
+
+	first, err := Checksum(dir)
+	if err != nil {
+		t.Fatalf("Checksum: %v", err)
+	}
+	second, err := Checksum(dir)
+	if err != nil {
+		t.Fatalf("Checksum: %v", err)
+	}
+	if first != second {
+		t.Errorf("checksum not deterministic: %s vs %s", first, second)
+	}
+
+	write("a.ens", "policy base {\n  readable\n}\n")
+	changed, err := Checksum(dir)
+	if err != nil {
+		t.Fatalf("Checksum: %v", err)
+	}
+	if changed == first {
+		t.Error("checksum unchanged after content change")
+	}
+}
+
+func TestLoadPolicies(t *testing.T) {
+	dir := t.TempDir()
+	src := `policy hardened {
+  ensure exists
+  ensure permissions
+}
+
+policy audited {
+  ensure exists
+}
+`
+	if err := os.WriteFile(filepath.Join(dir, "policies.ens"), []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	policies, err := LoadPolicies(dir)
+	if err != nil {
+		t.Fatalf("LoadPolicies: %v", err)
+	}
+	if len(policies) != 2 {
+		t.Fatalf("expected 2 policies, got %d", len(policies))
+	}
+	if policies[0].Name != "hardened" || policies[1].Name != "audited" {
+		t.Errorf("got %q, %q", policies[0].Name, policies[1].Name)
+	}
+}
@@ -0,0 +1,111 @@
+// Package output defines the stable, versioned JSON shapes that ensura
+// emits for plans and check results. Downstream tooling keys on
+// schemaVersion; any field rename or removal requires bumping it. The
+// corresponding JSON Schemas live in docs/schema/.
+package output
+
+import (
+	"github.com/ensurascript/ensura/pkg/planner"
+	"github.com/ensurascript/ensura/pkg/runtime"
+)
+
+// SchemaVersion identifies the current output format. Bump it whenever a
+// field is renamed, removed, or changes meaning; adding fields is
+// backward compatible and does not require a bump.
+const SchemaVersion = 1
+
+// PlanOutput is the JSON shape of an execution plan.
+type PlanOutput struct {
+	SchemaVersion   int              `json:"schemaVersion"`
+	Steps           []PlanStep       `json:"steps"`
+	GlobalViolation *ViolationOutput `json:"globalViolation,omitempty"`
+}
+
+// PlanStep is one step of a plan.
+type PlanStep struct {
+	ID          string            `json:"id"`
+	Description string            `json:"description"`
+	Handler     string            `json:"handler"`
+	Args        map[string]string `json:"args,omitempty"`
+	IsInvariant bool              `json:"isInvariant"`
+	Severity    string            `json:"severity,omitempty"`
+	Tags        []string          `json:"tags,omitempty"`
+}
+
+// ViolationOutput describes global violation handling.
+type ViolationOutput struct {
+	Retry  int      `json:"retry"`
+	Notify []string `json:"notify,omitempty"`
+}
+
+// CheckOutput is the JSON shape of a check run.
+type CheckOutput struct {
+	SchemaVersion      int            `json:"schemaVersion"`
+	AllSatisfied       bool           `json:"allSatisfied"`
+	TotalChecks        int            `json:"totalChecks"`
+	TotalFailures      int            `json:"totalFailures"`
+	Duration           string         `json:"duration"`
+	FailuresBySeverity map[string]int `json:"failuresBySeverity,omitempty"`
+	Steps              []CheckStep    `json:"steps"`
+}
+
+// CheckStep is the result of checking one step.
+type CheckStep struct {
+	Description string `json:"description"`
+	Status      string `json:"status"`
+	Severity    string `json:"severity,omitempty"`
+	Message     string `json:"message,omitempty"`
+	Error       string `json:"error,omitempty"`
+}
+
+// NewPlanOutput converts a plan to its stable output shape.
+func NewPlanOutput(plan *planner.Plan) PlanOutput {
+	out := PlanOutput{
+		SchemaVersion: SchemaVersion,
+		Steps:         make([]PlanStep, len(plan.Steps)),
+	}
+	for i, step := range plan.Steps {
+		out.Steps[i] = PlanStep{
+			ID:          step.ID,
+			Description: step.Description,
+			Handler:     step.Handler,
+			Args:        step.HandlerArgs,
+			IsInvariant: step.IsInvariant,
+			Severity:    step.Severity,
+			Tags:        step.Tags,
+		}
+	}
+	if plan.GlobalViolation != nil {
+		out.GlobalViolation = &ViolationOutput{
+			Retry:  plan.GlobalViolation.Retry,
+			Notify: plan.GlobalViolation.Notify,
+		}
+	}
+	return out
+}
+
+// NewCheckOutput converts a run result to its stable output shape.
+func NewCheckOutput(result *runtime.RunResult) CheckOutput {
+	out := CheckOutput{
+		SchemaVersion:      SchemaVersion,
+		AllSatisfied:       result.AllSatisfied,
+		TotalChecks:        result.TotalChecks,
+		TotalFailures:      result.TotalFailures,
+		Duration:           result.EndTime.Sub(result.StartTime).String(),
+		FailuresBySeverity: result.FailuresBySeverity,
+		Steps:              make([]CheckStep, len(result.Steps)),
+	}
+	for i, step := range result.Steps {
+		cs := CheckStep{
+			Description: step.Step.Description,
+			Status:      step.Status.String(),
+			Severity:    step.Step.Severity,
+			Message:     step.Message,
+		}
+		if step.Error != nil {
+			cs.Error = step.Error.Error()
+		}
+		out.Steps[i] = cs
+	}
+	return out
+}
@@ -0,0 +1,99 @@
+package output
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/ensurascript/ensura/pkg/planner"
+	"github.com/ensurascript/ensura/pkg/runtime"
+)
+
+// These tests pin the published JSON shapes. If one fails because a key
+// changed or disappeared, that is a breaking change for downstream
+// tooling: either restore the key or bump SchemaVersion and update
+// docs/schema/.
+
+func marshalKeys(t *testing.T, v interface{}) map[string]interface{} {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func TestPlanOutputShape(t *testing.T) {
+	plan := &planner.Plan{
+		Steps: []*planner.Step{
+			{
+				ID:          "g1",
+				Description: "ensure exists on file \"a\"",
+				Handler:     "fs.native",
+				HandlerArgs: map[string]string{"mode": "0600"},
+				IsInvariant: true,
+				Severity:    "critical",
+				Tags:        []string{"base"},
+			},
+		},
+		GlobalViolation: nil,
+	}
+
+	m := marshalKeys(t, NewPlanOutput(plan))
+	if m["schemaVersion"] != float64(SchemaVersion) {
+		t.Errorf("Expected schemaVersion %d, got %v", SchemaVersion, m["schemaVersion"])
+	}
+
+	steps := m["steps"].([]interface{})
+	step := steps[0].(map[string]interface{})
+	for _, key := range []string{"id", "description", "handler", "args", "isInvariant", "severity", "tags"} {
+		if _, ok := step[key]; !ok {
+			t.Errorf("Expected plan step key %q", key)
+		}
+	}
+}
+
+func TestCheckOutputShape(t *testing.T) {
+	now := time.Now()
+	result := &runtime.RunResult{
+		StartTime:     now,
+		EndTime:       now.Add(time.Second),
+		AllSatisfied:  false,
+		TotalChecks:   1,
+		TotalFailures: 1,
+		FailuresBySeverity: map[string]int{
+			"high": 1,
+		},
+		Steps: []*runtime.StepResult{
+			{
+				Step:    &planner.Step{Description: "ensure exists on file \"a\"", Severity: "high"},
+				Status:  runtime.StepViolated,
+				Message: "file does not exist",
+			},
+		},
+	}
+
+	m := marshalKeys(t, NewCheckOutput(result))
+	if m["schemaVersion"] != float64(SchemaVersion) {
+		t.Errorf("Expected schemaVersion %d, got %v", SchemaVersion, m["schemaVersion"])
+	}
+	for _, key := range []string{"allSatisfied", "totalChecks", "totalFailures", "duration", "failuresBySeverity", "steps"} {
+		if _, ok := m[key]; !ok {
+			t.Errorf("Expected check output key %q", key)
+		}
+	}
+
+	step := m["steps"].([]interface{})[0].(map[string]interface{})
+	for _, key := range []string{"description", "status", "severity", "message"} {
+		if _, ok := step[key]; !ok {
+			t.Errorf("Expected check step key %q", key)
+		}
+	}
+	if step["status"] != "violated" {
+		t.Errorf("Expected status violated, got %v", step["status"])
+	}
+}
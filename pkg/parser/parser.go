@@ -3,10 +3,14 @@ package parser
 
 import (
 	"fmt"
+	"path/filepath"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/ensurascript/ensura/pkg/ast"
 	"github.com/ensurascript/ensura/pkg/lexer"
+	"github.com/ensurascript/ensura/pkg/timewindow"
 )
 
 // Parser parses EnsuraScript source code into an AST.
@@ -15,6 +19,8 @@ type Parser struct {
 	curToken  lexer.Token
 	peekToken lexer.Token
 	errors    []string
+	diags     []ast.Diag
+	comments  []lexer.Token
 }
 
 // New creates a new Parser.
@@ -43,19 +49,66 @@ func ParseFile(input, filename string) (*ast.Program, []string) {
 func (p *Parser) nextToken() {
 	p.curToken = p.peekToken
 	p.peekToken = p.l.NextToken()
-	// Skip comments
+	// Comments never reach the grammar, but are kept so a leading block
+	// can be attached to the next statement as its doc comment.
 	for p.peekToken.Type == lexer.COMMENT {
+		p.comments = append(p.comments, p.peekToken)
 		p.peekToken = p.l.NextToken()
 	}
 }
 
+// takeDocComment returns the run of comment lines ending directly above
+// the current token, joined by newlines, and discards all comments seen
+// since the last statement. Detached comments do not document anything.
+func (p *Parser) takeDocComment() string {
+	comments := p.comments
+	p.comments = nil
+
+	var lines []string
+	line := p.curToken.Pos.Line
+	for i := len(comments) - 1; i >= 0; i-- {
+		if comments[i].Pos.Line != line-1 {
+			break
+		}
+		lines = append([]string{strings.TrimSpace(comments[i].Literal)}, lines...)
+		line = comments[i].Pos.Line
+	}
+	return strings.Join(lines, "\n")
+}
+
+// setDocComment attaches a doc comment to the statement types that carry
+// one.
+func setDocComment(stmt ast.Statement, doc string) {
+	if doc == "" {
+		return
+	}
+	switch s := stmt.(type) {
+	case *ast.ResourceDecl:
+		s.DocComment = doc
+	case *ast.EnsureStmt:
+		s.DocComment = doc
+	case *ast.OnBlock:
+		s.DocComment = doc
+	case *ast.PolicyDecl:
+		s.DocComment = doc
+	case *ast.InvariantBlock:
+		s.DocComment = doc
+	}
+}
+
 // Errors returns all parser errors.
 func (p *Parser) Errors() []string {
 	return p.errors
 }
 
+// Diagnostics returns all parser errors with their source ranges.
+func (p *Parser) Diagnostics() []ast.Diag {
+	return p.diags
+}
+
 func (p *Parser) addError(msg string) {
 	p.errors = append(p.errors, fmt.Sprintf("%s: %s", p.curToken.Pos, msg))
+	p.diags = append(p.diags, ast.Diag{Pos: p.curToken.Pos, End: p.curToken.End, Msg: msg})
 }
 
 func (p *Parser) expectPeek(t lexer.TokenType) bool {
@@ -83,42 +136,110 @@ func (p *Parser) Parse() *ast.Program {
 	}
 
 	for p.curToken.Type != lexer.EOF {
+		before := len(p.errors)
+		doc := p.takeDocComment()
 		stmt := p.parseStatement()
 		if stmt != nil {
+			setDocComment(stmt, doc)
 			program.Statements = append(program.Statements, stmt)
+		} else if len(p.errors) > before {
+			// The statement failed partway through; skip to the next
+			// statement keyword so one mistake produces one diagnostic
+			// instead of a cascade.
+			p.synchronize(false)
+			continue
 		}
 		p.nextToken()
 	}
 
+	program.EndPos = p.curToken.End
 	return program
 }
 
+// atStatementStart reports whether the current token can begin a
+// top-level or block statement.
+func (p *Parser) atStatementStart() bool {
+	switch p.curToken.Type {
+	case lexer.RESOURCE, lexer.ENSURE, lexer.ON, lexer.POLICY, lexer.APPLY,
+		lexer.FOR, lexer.INVARIANT, lexer.ASSUME, lexer.IMPORT, lexer.PARALLEL:
+		return true
+	}
+	return false
+}
+
+// synchronize discards tokens after a parse error until the next point a
+// statement can begin. Inside a block, the closing brace is also a
+// recovery point so the enclosing statement can finish.
+func (p *Parser) synchronize(stopAtBrace bool) {
+	p.nextToken()
+	for p.curToken.Type != lexer.EOF && !p.atStatementStart() {
+		if stopAtBrace && p.curTokenIs(lexer.RBRACE) {
+			return
+		}
+		p.nextToken()
+	}
+}
+
+// parseStatement dispatches on the current token. Each case re-checks
+// for nil explicitly so a failed parse yields an untyped nil interface,
+// which the recovery logic in the statement loops relies on.
 func (p *Parser) parseStatement() ast.Statement {
 	switch p.curToken.Type {
 	case lexer.RESOURCE:
-		return p.parseResourceDecl()
+		if stmt := p.parseResourceDecl(); stmt != nil {
+			stmt.EndPos = p.curToken.End
+			return stmt
+		}
 	case lexer.ENSURE:
-		return p.parseEnsureStmt()
+		if stmt := p.parseEnsureStmt(); stmt != nil {
+			stmt.EndPos = p.curToken.End
+			return stmt
+		}
 	case lexer.ON:
-		return p.parseOnBlock()
+		if stmt := p.parseOnBlock(); stmt != nil {
+			return stmt
+		}
 	case lexer.POLICY:
-		return p.parsePolicyDecl()
+		if stmt := p.parsePolicyDecl(); stmt != nil {
+			stmt.EndPos = p.curToken.End
+			return stmt
+		}
 	case lexer.APPLY:
-		return p.parseApplyStmt()
+		if stmt := p.parseApplyStmt(); stmt != nil {
+			stmt.EndPos = p.curToken.End
+			return stmt
+		}
 	case lexer.FOR:
-		return p.parseForEachStmt()
+		if stmt := p.parseForEachStmt(); stmt != nil {
+			stmt.EndPos = p.curToken.End
+			return stmt
+		}
 	case lexer.INVARIANT:
-		return p.parseInvariantBlock()
+		if stmt := p.parseInvariantBlock(); stmt != nil {
+			stmt.EndPos = p.curToken.End
+			return stmt
+		}
 	case lexer.ASSUME:
-		return p.parseAssumeStmt()
+		if stmt := p.parseAssumeStmt(); stmt != nil {
+			stmt.EndPos = p.curToken.End
+			return stmt
+		}
+	case lexer.IMPORT:
+		if stmt := p.parseImportStmt(); stmt != nil {
+			stmt.EndPos = p.curToken.End
+			return stmt
+		}
 	case lexer.PARALLEL:
-		return p.parseParallelBlock()
+		if stmt := p.parseParallelBlock(); stmt != nil {
+			stmt.EndPos = p.curToken.End
+			return stmt
+		}
 	case lexer.COMMENT:
 		return nil
 	default:
 		p.addError(fmt.Sprintf("unexpected token: %s", p.curToken.Type))
-		return nil
 	}
+	return nil
 }
 
 func (p *Parser) parseResourceDecl() *ast.ResourceDecl {
@@ -181,7 +302,12 @@ func (p *Parser) parseResourceRef() *ast.ResourceRef {
 	switch p.curToken.Type {
 	case lexer.FILE, lexer.DIRECTORY, lexer.HTTP, lexer.DATABASE, lexer.SERVICE, lexer.PROCESS, lexer.CRON:
 		ref.ResourceType = p.curToken.Literal
-		if !p.expectPeek(lexer.STRING) {
+		// The path is a string literal, or an identifier naming a policy
+		// parameter (substituted at expansion time)
+		if p.peekTokenIs(lexer.STRING) || p.peekTokenIs(lexer.IDENT) {
+			p.nextToken()
+		} else {
+			p.addError(fmt.Sprintf("expected %s, got %s", lexer.STRING, p.peekToken.Type))
 			return nil
 		}
 		ref.Path = p.curToken.Literal
@@ -199,6 +325,7 @@ func (p *Parser) parseResourceRef() *ast.ResourceRef {
 		return nil
 	}
 
+	ref.EndPos = p.curToken.End
 	return ref
 }
 
@@ -236,17 +363,67 @@ func (p *Parser) parseEnsureStmt() *ast.EnsureStmt {
 		case p.peekTokenIs(lexer.WHEN):
 			p.nextToken()
 			stmt.Guard = p.parseGuardExpr()
+		case p.peekTokenIs(lexer.TIMEOUT):
+			p.nextToken()
+			if !p.expectPeek(lexer.STRING) {
+				return stmt
+			}
+			d, err := time.ParseDuration(p.curToken.Literal)
+			if err != nil {
+				p.addError(fmt.Sprintf("invalid timeout duration %q", p.curToken.Literal))
+			} else {
+				stmt.Timeout = d
+			}
+		case p.peekTokenIs(lexer.SEVERITY):
+			p.nextToken()
+			if !p.expectPeek(lexer.IDENT) {
+				return stmt
+			}
+			if !ast.ValidSeverity(p.curToken.Literal) {
+				p.addError(fmt.Sprintf("invalid severity %q (want critical, high, warning, or info)", p.curToken.Literal))
+			} else {
+				stmt.Severity = p.curToken.Literal
+			}
+		case p.peekTokenIs(lexer.TAGS):
+			p.nextToken()
+			stmt.Tags = append(stmt.Tags, p.parseTagList()...)
+		case p.peekTokenIs(lexer.WITHIN):
+			p.nextToken()
+			if !p.expectPeek(lexer.STRING) {
+				return stmt
+			}
+			if _, err := time.ParseDuration(p.curToken.Literal); err != nil {
+				p.addError(fmt.Sprintf("invalid within duration %q", p.curToken.Literal))
+			} else {
+				stmt.Within = p.curToken.Literal
+			}
+		case p.peekTokenIs(lexer.DURING):
+			p.nextToken()
+			if !p.expectPeek(lexer.STRING) {
+				return stmt
+			}
+			if _, err := timewindow.Parse(p.curToken.Literal); err != nil {
+				p.addError(fmt.Sprintf("invalid maintenance window %q: %v", p.curToken.Literal, err))
+			} else {
+				stmt.During = p.curToken.Literal
+			}
 		case p.peekTokenIs(lexer.REQUIRES):
 			p.nextToken()
 			p.nextToken()
-			if p.curTokenIs(lexer.IDENT) {
-				// Could be a condition or a resource reference
-				if p.peekTokenIs(lexer.STRING) {
-					// It's a resource reference with condition
-					ref := p.parseResourceRef()
-					stmt.RequiresResource = append(stmt.RequiresResource, ref)
-				} else {
-					stmt.Requires = append(stmt.Requires, p.curToken.Literal)
+			if p.curTokenIs(lexer.IDENT) && !p.peekTokenIs(lexer.STRING) {
+				// A bare identifier is a condition on the same subject
+				stmt.Requires = append(stmt.Requires, p.curToken.Literal)
+			} else {
+				// A resource reference, optionally followed by the
+				// condition required on it
+				ref := p.parseResourceRef()
+				if ref != nil {
+					rc := &ast.ResourceCondition{Ref: ref}
+					if p.peekTokenIs(lexer.IDENT) {
+						p.nextToken()
+						rc.Condition = p.curToken.Literal
+					}
+					stmt.RequiresResource = append(stmt.RequiresResource, rc)
 				}
 			}
 		case p.peekTokenIs(lexer.AFTER):
@@ -293,17 +470,44 @@ func (p *Parser) parseHandlerSpec() *ast.HandlerSpec {
 		}
 	}
 
+	// Plugin handlers name their executable directly: with plugin "my-handler"
+	if spec.Name == "plugin" && p.peekTokenIs(lexer.STRING) {
+		p.nextToken()
+		spec.Args["name"] = p.curToken.Literal
+	}
+
 	// Parse key-value arguments
 	for p.peekTokenIs(lexer.IDENT) || p.peekTokenIs(lexer.KEY) || p.peekTokenIs(lexer.MODE) {
 		p.nextToken()
 		key := p.curToken.Literal
-		// Value can be a string or an identifier (for policy parameter substitution)
-		if p.peekTokenIs(lexer.STRING) || p.peekTokenIs(lexer.IDENT) {
+		// Value can be a string or an identifier (for policy parameter
+		// substitution); key/mode also lex as keywords but are valid
+		// parameter names
+		if p.peekTokenIs(lexer.STRING) || p.peekTokenIs(lexer.IDENT) ||
+			p.peekTokenIs(lexer.KEY) || p.peekTokenIs(lexer.MODE) {
 			p.nextToken()
 			spec.Args[key] = p.curToken.Literal
+		} else if p.peekTokenIs(lexer.DURATION) || p.peekTokenIs(lexer.SIZE) ||
+			p.peekTokenIs(lexer.BOOLEAN) {
+			// Typed literals keep their kind so handlers get real
+			// durations, sizes, and flags instead of quoted strings
+			p.nextToken()
+			spec.Args[key] = p.curToken.Literal
+			if spec.ArgTypes == nil {
+				spec.ArgTypes = make(map[string]string)
+			}
+			switch p.curToken.Type {
+			case lexer.DURATION:
+				spec.ArgTypes[key] = ast.ArgDuration
+			case lexer.SIZE:
+				spec.ArgTypes[key] = ast.ArgSize
+			case lexer.BOOLEAN:
+				spec.ArgTypes[key] = ast.ArgBool
+			}
 		}
 	}
 
+	spec.EndPos = p.curToken.End
 	return spec
 }
 
@@ -330,11 +534,17 @@ func (p *Parser) parseGuardExpr() *ast.GuardExpr {
 		return nil
 	}
 
-	if !p.expectPeek(lexer.STRING) {
+	// The right-hand side is a string literal, or an identifier naming a
+	// policy parameter
+	if p.peekTokenIs(lexer.STRING) || p.peekTokenIs(lexer.IDENT) {
+		p.nextToken()
+	} else {
+		p.addError(fmt.Sprintf("expected string or identifier, got %s", p.peekToken.Type))
 		return nil
 	}
 	guard.Right = p.curToken.Literal
 
+	guard.EndPos = p.curToken.End
 	return guard
 }
 
@@ -345,17 +555,27 @@ func (p *Parser) parseOnBlock() ast.Statement {
 
 	// Check if it's "on violation"
 	if p.curTokenIs(lexer.ON_VIOLATION) || (p.curTokenIs(lexer.IDENT) && p.curToken.Literal == "violation") {
-		return p.parseOnViolationBlock(pos)
+		if block := p.parseOnViolationBlock(pos); block != nil {
+			block.EndPos = p.curToken.End
+			return block
+		}
+		return nil
 	}
 
 	block := &ast.OnBlock{Position: pos}
 	block.Subject = p.parseResourceRef()
 
+	if p.peekTokenIs(lexer.TAGS) {
+		p.nextToken()
+		block.Tags = p.parseTagList()
+	}
+
 	if !p.expectPeek(lexer.LBRACE) {
 		return nil
 	}
 
 	block.Statements = p.parseBlockStatements()
+	block.EndPos = p.curToken.End
 
 	return block
 }
@@ -380,16 +600,72 @@ func (p *Parser) parseOnViolationBlock(pos lexer.Position) *ast.OnViolationBlock
 				block.Handler.Retry = n
 			}
 		case lexer.NOTIFY:
-			if p.expectPeek(lexer.STRING) {
-				block.Handler.Notify = append(block.Handler.Notify, p.curToken.Literal)
-			}
+			p.parseNotifyRule(block.Handler)
 		}
 		p.nextToken()
 	}
 
+	block.Handler.EndPos = p.curToken.End
 	return block
 }
 
+// parseTagList parses the string literals following a tags keyword, which
+// is on the current token. At least one tag is required.
+func (p *Parser) parseTagList() []string {
+	var tags []string
+	for p.peekTokenIs(lexer.STRING) {
+		p.nextToken()
+		tags = append(tags, p.curToken.Literal)
+	}
+	if len(tags) == 0 {
+		p.addError(fmt.Sprintf("expected at least one tag string, got %s", p.peekToken.Type))
+	}
+	return tags
+}
+
+// parseNotifyRule parses a notify directive inside a violation block:
+// notify "target" [after N failures] [severity level]
+func (p *Parser) parseNotifyRule(handler *ast.ViolationHandler) {
+	if !p.expectPeek(lexer.STRING) {
+		return
+	}
+
+	rule := ast.NotifyRule{Target: p.curToken.Literal, AfterFailures: 1}
+	handler.Notify = append(handler.Notify, rule.Target)
+
+	if p.peekTokenIs(lexer.AFTER) {
+		p.nextToken()
+		if !p.expectPeek(lexer.NUMBER) {
+			return
+		}
+		n, _ := strconv.Atoi(p.curToken.Literal)
+		if n < 1 {
+			p.addError(fmt.Sprintf("notify threshold must be at least 1, got %d", n))
+			n = 1
+		}
+		rule.AfterFailures = n
+		if p.peekTokenIs(lexer.IDENT) && (p.peekToken.Literal == "failures" || p.peekToken.Literal == "failure") {
+			p.nextToken()
+		} else {
+			p.addError("expected 'failures' after notify threshold")
+		}
+	}
+
+	if p.peekTokenIs(lexer.SEVERITY) {
+		p.nextToken()
+		if !p.expectPeek(lexer.IDENT) {
+			return
+		}
+		if !ast.ValidSeverity(p.curToken.Literal) {
+			p.addError(fmt.Sprintf("invalid severity %q (want critical, high, warning, or info)", p.curToken.Literal))
+		} else {
+			rule.MinSeverity = p.curToken.Literal
+		}
+	}
+
+	handler.NotifyRules = append(handler.NotifyRules, rule)
+}
+
 func (p *Parser) parseViolationHandlerBlock() *ast.ViolationHandler {
 	handler := &ast.ViolationHandler{Position: p.curToken.Pos}
 
@@ -407,13 +683,12 @@ func (p *Parser) parseViolationHandlerBlock() *ast.ViolationHandler {
 				handler.Retry = n
 			}
 		case lexer.NOTIFY:
-			if p.expectPeek(lexer.STRING) {
-				handler.Notify = append(handler.Notify, p.curToken.Literal)
-			}
+			p.parseNotifyRule(handler)
 		}
 		p.nextToken()
 	}
 
+	handler.EndPos = p.curToken.End
 	return handler
 }
 
@@ -423,9 +698,15 @@ func (p *Parser) parseBlockStatements() []ast.Statement {
 	p.nextToken()
 
 	for !p.curTokenIs(lexer.RBRACE) && !p.curTokenIs(lexer.EOF) {
+		before := len(p.errors)
+		doc := p.takeDocComment()
 		stmt := p.parseStatement()
 		if stmt != nil {
+			setDocComment(stmt, doc)
 			statements = append(statements, stmt)
+		} else if len(p.errors) > before {
+			p.synchronize(true)
+			continue
 		}
 		p.nextToken()
 	}
@@ -463,8 +744,18 @@ func (p *Parser) parsePolicyParams() []ast.PolicyParam {
 	p.nextToken()
 
 	for !p.curTokenIs(lexer.RPAREN) && !p.curTokenIs(lexer.EOF) {
-		if p.curTokenIs(lexer.IDENT) {
-			params = append(params, ast.PolicyParam{Name: p.curToken.Literal})
+		// key/mode lex as keywords but are natural parameter names
+		if p.curTokenIs(lexer.IDENT) || p.curTokenIs(lexer.KEY) || p.curTokenIs(lexer.MODE) {
+			param := ast.PolicyParam{Name: p.curToken.Literal}
+			// Optional default value: mode = "0600"
+			if p.peekTokenIs(lexer.ASSIGN) {
+				p.nextToken()
+				if p.expectPeek(lexer.STRING) {
+					param.Default = p.curToken.Literal
+					param.HasDefault = true
+				}
+			}
+			params = append(params, param)
 		}
 		p.nextToken()
 		if p.curTokenIs(lexer.COMMA) {
@@ -487,28 +778,40 @@ func (p *Parser) parseApplyStmt() *ast.ApplyStmt {
 	// Optional arguments
 	if p.peekTokenIs(lexer.LPAREN) {
 		p.nextToken()
-		stmt.Args = p.parseApplyArgs()
+		p.parseApplyArgs(stmt)
 	}
 
 	return stmt
 }
 
-func (p *Parser) parseApplyArgs() []string {
-	var args []string
-
+func (p *Parser) parseApplyArgs(stmt *ast.ApplyStmt) {
 	p.nextToken()
 
 	for !p.curTokenIs(lexer.RPAREN) && !p.curTokenIs(lexer.EOF) {
-		if p.curTokenIs(lexer.STRING) {
-			args = append(args, p.curToken.Literal)
+		switch {
+		case (p.curTokenIs(lexer.IDENT) || p.curTokenIs(lexer.KEY) || p.curTokenIs(lexer.MODE)) && p.peekTokenIs(lexer.COLON):
+			// Named argument: key_ref: "env:KEY"
+			name := p.curToken.Literal
+			p.nextToken() // consume ':'
+			p.nextToken()
+			if p.curTokenIs(lexer.STRING) || p.curTokenIs(lexer.IDENT) {
+				if stmt.NamedArgs == nil {
+					stmt.NamedArgs = make(map[string]string)
+				}
+				stmt.NamedArgs[name] = p.curToken.Literal
+			} else {
+				p.addError(fmt.Sprintf("expected value for named argument %q, got %s", name, p.curToken.Type))
+			}
+		case p.curTokenIs(lexer.STRING) || p.curTokenIs(lexer.IDENT):
+			// Positional: string literals or identifiers (policy
+			// parameter references)
+			stmt.Args = append(stmt.Args, p.curToken.Literal)
 		}
 		p.nextToken()
 		if p.curTokenIs(lexer.COMMA) {
 			p.nextToken()
 		}
 	}
-
-	return args
 }
 
 func (p *Parser) parseForEachStmt() *ast.ForEachStmt {
@@ -531,6 +834,22 @@ func (p *Parser) parseForEachStmt() *ast.ForEachStmt {
 	p.nextToken()
 	stmt.Container = p.parseResourceRef()
 
+	// Optional glob filters: matching "*.conf" excluding "*.tmp" "*.bak"
+	for p.peekTokenIs(lexer.IDENT) &&
+		(p.peekToken.Literal == "matching" || p.peekToken.Literal == "excluding") {
+		p.nextToken()
+		clause := p.curToken.Literal
+		patterns := p.parseGlobList()
+		if patterns == nil {
+			return nil
+		}
+		if clause == "matching" {
+			stmt.Matching = append(stmt.Matching, patterns...)
+		} else {
+			stmt.Excluding = append(stmt.Excluding, patterns...)
+		}
+	}
+
 	if !p.expectPeek(lexer.LBRACE) {
 		return nil
 	}
@@ -540,6 +859,25 @@ func (p *Parser) parseForEachStmt() *ast.ForEachStmt {
 	return stmt
 }
 
+// parseGlobList reads one or more string patterns after a matching or
+// excluding keyword, validating each against filepath.Match syntax.
+func (p *Parser) parseGlobList() []string {
+	var patterns []string
+	for p.peekTokenIs(lexer.STRING) {
+		p.nextToken()
+		if _, err := filepath.Match(p.curToken.Literal, ""); err != nil {
+			p.addError(fmt.Sprintf("invalid glob pattern %q", p.curToken.Literal))
+			return nil
+		}
+		patterns = append(patterns, p.curToken.Literal)
+	}
+	if len(patterns) == 0 {
+		p.addError(fmt.Sprintf("expected at least one pattern string, got %s", p.peekToken.Type))
+		return nil
+	}
+	return patterns
+}
+
 func (p *Parser) parseInvariantBlock() *ast.InvariantBlock {
 	block := &ast.InvariantBlock{Position: p.curToken.Pos}
 
@@ -552,6 +890,32 @@ func (p *Parser) parseInvariantBlock() *ast.InvariantBlock {
 	return block
 }
 
+func (p *Parser) parseImportStmt() *ast.ImportStmt {
+	stmt := &ast.ImportStmt{Position: p.curToken.Pos}
+
+	// import policy "<ref>" [sha256 "<hex>"]
+	if !p.expectPeek(lexer.POLICY) {
+		return nil
+	}
+	stmt.Kind = p.curToken.Literal
+
+	if !p.expectPeek(lexer.STRING) {
+		return nil
+	}
+	stmt.Path = p.curToken.Literal
+
+	// Optional integrity pin
+	if p.peekTokenIs(lexer.IDENT) && p.peekToken.Literal == "sha256" {
+		p.nextToken()
+		if !p.expectPeek(lexer.STRING) {
+			return stmt
+		}
+		stmt.SHA256 = p.curToken.Literal
+	}
+
+	return stmt
+}
+
 func (p *Parser) parseAssumeStmt() *ast.AssumeStmt {
 	stmt := &ast.AssumeStmt{Position: p.curToken.Pos}
 
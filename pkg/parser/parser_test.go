@@ -2,6 +2,7 @@ package parser
 
 import (
 	"testing"
+	"time"
 
 	"github.com/ensurascript/ensura/pkg/ast"
 )
@@ -248,6 +249,241 @@ func TestParseOnViolation(t *testing.T) {
 	}
 }
 
+func TestParseNotifyEscalation(t *testing.T) {
+	input := `on violation {
+  notify "ops" after 3 failures
+  notify "security" after 10 failures
+  notify "dev"
+}`
+
+	program, errors := ParseString(input)
+	if len(errors) > 0 {
+		t.Fatalf("Parse errors: %v", errors)
+	}
+
+	violation, ok := program.Statements[0].(*ast.OnViolationBlock)
+	if !ok {
+		t.Fatalf("Expected OnViolationBlock, got %T", program.Statements[0])
+	}
+
+	rules := violation.Handler.NotifyRules
+	if len(rules) != 3 {
+		t.Fatalf("Expected 3 notify rules, got %d", len(rules))
+	}
+	if rules[0].Target != "ops" || rules[0].AfterFailures != 3 {
+		t.Errorf("Expected ops after 3, got %q after %d", rules[0].Target, rules[0].AfterFailures)
+	}
+	if rules[1].Target != "security" || rules[1].AfterFailures != 10 {
+		t.Errorf("Expected security after 10, got %q after %d", rules[1].Target, rules[1].AfterFailures)
+	}
+	if rules[2].Target != "dev" || rules[2].AfterFailures != 1 {
+		t.Errorf("Expected dev after 1, got %q after %d", rules[2].Target, rules[2].AfterFailures)
+	}
+
+	// Missing "failures" keyword should be a parse error
+	_, errors = ParseString(`on violation {
+  notify "ops" after 3
+}`)
+	if len(errors) == 0 {
+		t.Error("Expected error for missing 'failures' keyword, got none")
+	}
+}
+
+func TestParseSeverity(t *testing.T) {
+	input := `ensure exists on file "app.log" severity warning`
+
+	program, errors := ParseString(input)
+	if len(errors) > 0 {
+		t.Fatalf("Parse errors: %v", errors)
+	}
+
+	ensure, ok := program.Statements[0].(*ast.EnsureStmt)
+	if !ok {
+		t.Fatalf("Expected EnsureStmt, got %T", program.Statements[0])
+	}
+	if ensure.Severity != "warning" {
+		t.Errorf("Expected severity warning, got %q", ensure.Severity)
+	}
+
+	// Unknown severity levels should be a parse error
+	_, errors = ParseString(`ensure exists on file "app.log" severity urgent`)
+	if len(errors) == 0 {
+		t.Error("Expected error for unknown severity level, got none")
+	}
+}
+
+func TestParseNotifySeverity(t *testing.T) {
+	input := `on violation {
+  notify "pager" severity critical
+  notify "ops" after 3 failures severity high
+  notify "dev"
+}`
+
+	program, errors := ParseString(input)
+	if len(errors) > 0 {
+		t.Fatalf("Parse errors: %v", errors)
+	}
+
+	violation, ok := program.Statements[0].(*ast.OnViolationBlock)
+	if !ok {
+		t.Fatalf("Expected OnViolationBlock, got %T", program.Statements[0])
+	}
+
+	rules := violation.Handler.NotifyRules
+	if len(rules) != 3 {
+		t.Fatalf("Expected 3 notify rules, got %d", len(rules))
+	}
+	if rules[0].MinSeverity != "critical" {
+		t.Errorf("Expected pager severity critical, got %q", rules[0].MinSeverity)
+	}
+	if rules[1].AfterFailures != 3 || rules[1].MinSeverity != "high" {
+		t.Errorf("Expected ops after 3 severity high, got after %d severity %q",
+			rules[1].AfterFailures, rules[1].MinSeverity)
+	}
+	if rules[2].MinSeverity != "" {
+		t.Errorf("Expected dev to have no severity filter, got %q", rules[2].MinSeverity)
+	}
+}
+
+func TestParseErrorRecovery(t *testing.T) {
+	// Two independent mistakes with a valid statement between them: the
+	// parser should report both errors and still produce the valid AST.
+	input := `resource file as broken
+ensure exists on file "ok.txt"
+resource 42 "also-broken"
+ensure readable on file "ok.txt"`
+
+	program, errors := ParseString(input)
+	if len(errors) != 2 {
+		t.Fatalf("Expected 2 errors, got %d: %v", len(errors), errors)
+	}
+
+	var ensures int
+	for _, stmt := range program.Statements {
+		if _, ok := stmt.(*ast.EnsureStmt); ok {
+			ensures++
+		}
+	}
+	if ensures != 2 {
+		t.Errorf("Expected 2 ensure statements to survive recovery, got %d", ensures)
+	}
+}
+
+func TestParseErrorRecoveryInBlock(t *testing.T) {
+	input := `on file "app.db" {
+  ensure 42
+  ensure exists
+}
+ensure readable on file "other.txt"`
+
+	program, errors := ParseString(input)
+	if len(errors) != 1 {
+		t.Fatalf("Expected 1 error, got %d: %v", len(errors), errors)
+	}
+
+	block, ok := program.Statements[0].(*ast.OnBlock)
+	if !ok {
+		t.Fatalf("Expected OnBlock, got %T", program.Statements[0])
+	}
+	if len(block.Statements) != 1 {
+		t.Errorf("Expected 1 surviving statement in block, got %d", len(block.Statements))
+	}
+	if len(program.Statements) != 2 {
+		t.Errorf("Expected statement after block to survive, got %d statements", len(program.Statements))
+	}
+}
+
+func TestParseWithin(t *testing.T) {
+	input := `ensure backed_up on file "db.sqlite" within "24h"`
+
+	program, errors := ParseString(input)
+	if len(errors) > 0 {
+		t.Fatalf("Parse errors: %v", errors)
+	}
+
+	ensure, ok := program.Statements[0].(*ast.EnsureStmt)
+	if !ok {
+		t.Fatalf("Expected EnsureStmt, got %T", program.Statements[0])
+	}
+	if ensure.Within != "24h" {
+		t.Errorf("Expected within 24h, got %q", ensure.Within)
+	}
+
+	// Malformed durations should be a parse error
+	_, errors = ParseString(`ensure backed_up on file "db.sqlite" within "daily"`)
+	if len(errors) == 0 {
+		t.Error("Expected error for malformed within duration, got none")
+	}
+}
+
+func TestParseDuring(t *testing.T) {
+	input := `ensure running on service "nginx" during "Mon-Fri 09:00-17:00 UTC"`
+
+	program, errors := ParseString(input)
+	if len(errors) > 0 {
+		t.Fatalf("Parse errors: %v", errors)
+	}
+
+	ensure, ok := program.Statements[0].(*ast.EnsureStmt)
+	if !ok {
+		t.Fatalf("Expected EnsureStmt, got %T", program.Statements[0])
+	}
+	if ensure.During != "Mon-Fri 09:00-17:00 UTC" {
+		t.Errorf("Expected during clause, got %q", ensure.During)
+	}
+
+	// Malformed windows should be a parse error
+	_, errors = ParseString(`ensure running on service "nginx" during "whenever"`)
+	if len(errors) == 0 {
+		t.Error("Expected error for malformed maintenance window, got none")
+	}
+}
+
+func TestParseTags(t *testing.T) {
+	input := `ensure exists on file "audit.log" tags "security" "pci"`
+
+	program, errors := ParseString(input)
+	if len(errors) > 0 {
+		t.Fatalf("Parse errors: %v", errors)
+	}
+
+	ensure, ok := program.Statements[0].(*ast.EnsureStmt)
+	if !ok {
+		t.Fatalf("Expected EnsureStmt, got %T", program.Statements[0])
+	}
+	if len(ensure.Tags) != 2 || ensure.Tags[0] != "security" || ensure.Tags[1] != "pci" {
+		t.Errorf("Expected tags [security pci], got %v", ensure.Tags)
+	}
+
+	// A tags clause without any tag strings should be a parse error
+	_, errors = ParseString(`ensure exists on file "audit.log" tags`)
+	if len(errors) == 0 {
+		t.Error("Expected error for empty tags clause, got none")
+	}
+}
+
+func TestParseOnBlockTags(t *testing.T) {
+	input := `on file "app.db" tags "database" {
+  ensure exists
+}`
+
+	program, errors := ParseString(input)
+	if len(errors) > 0 {
+		t.Fatalf("Parse errors: %v", errors)
+	}
+
+	block, ok := program.Statements[0].(*ast.OnBlock)
+	if !ok {
+		t.Fatalf("Expected OnBlock, got %T", program.Statements[0])
+	}
+	if len(block.Tags) != 1 || block.Tags[0] != "database" {
+		t.Errorf("Expected tags [database], got %v", block.Tags)
+	}
+	if len(block.Statements) != 1 {
+		t.Fatalf("Expected 1 statement in block, got %d", len(block.Statements))
+	}
+}
+
 func TestParseGuard(t *testing.T) {
 	input := `ensure encrypted on file "secrets.db" when environment == "prod"`
 
@@ -307,6 +543,30 @@ assume filesystem reliable`
 	}
 }
 
+func TestParseTimeout(t *testing.T) {
+	input := `ensure reachable on http "https://example.com" timeout "30s"`
+
+	program, errors := ParseString(input)
+	if len(errors) > 0 {
+		t.Fatalf("Parse errors: %v", errors)
+	}
+
+	ensure, ok := program.Statements[0].(*ast.EnsureStmt)
+	if !ok {
+		t.Fatalf("Expected EnsureStmt, got %T", program.Statements[0])
+	}
+
+	if ensure.Timeout != 30*time.Second {
+		t.Errorf("Expected timeout 30s, got %v", ensure.Timeout)
+	}
+
+	// Invalid duration should be a parse error
+	_, errors = ParseString(`ensure exists on file "a" timeout "soon"`)
+	if len(errors) == 0 {
+		t.Error("Expected error for invalid timeout duration, got none")
+	}
+}
+
 func TestParseErrors(t *testing.T) {
 	tests := []struct {
 		input string
@@ -324,3 +584,142 @@ func TestParseErrors(t *testing.T) {
 		}
 	}
 }
+
+func TestParseDocComment(t *testing.T) {
+	input := `# Keep the audit log locked down.
+# Required by the compliance team.
+ensure exists on file "audit.log"
+
+# Baseline hardening for web hosts.
+policy hardened {
+	ensure exists
+}
+
+resource file "orphan.txt"`
+
+	program, errors := ParseString(input)
+	if len(errors) > 0 {
+		t.Fatalf("Parse errors: %v", errors)
+	}
+	if len(program.Statements) != 3 {
+		t.Fatalf("Expected 3 statements, got %d", len(program.Statements))
+	}
+
+	ensure := program.Statements[0].(*ast.EnsureStmt)
+	want := "Keep the audit log locked down.\nRequired by the compliance team."
+	if ensure.DocComment != want {
+		t.Errorf("Expected doc %q, got %q", want, ensure.DocComment)
+	}
+
+	policy := program.Statements[1].(*ast.PolicyDecl)
+	if policy.DocComment != "Baseline hardening for web hosts." {
+		t.Errorf("Expected policy doc, got %q", policy.DocComment)
+	}
+
+	res := program.Statements[2].(*ast.ResourceDecl)
+	if res.DocComment != "" {
+		t.Errorf("Expected no doc on resource, got %q", res.DocComment)
+	}
+}
+
+func TestParseDocCommentDetached(t *testing.T) {
+	input := `# A stray comment with a blank line after it.
+
+ensure exists on file "a.txt"`
+
+	program, errors := ParseString(input)
+	if len(errors) > 0 {
+		t.Fatalf("Parse errors: %v", errors)
+	}
+
+	ensure := program.Statements[0].(*ast.EnsureStmt)
+	if ensure.DocComment != "" {
+		t.Errorf("Expected no doc comment, got %q", ensure.DocComment)
+	}
+}
+
+func TestParseTypedHandlerArgs(t *testing.T) {
+	input := `ensure fresh on file "cache.db" with fs.native interval 30s max_size 10MB strict true`
+
+	program, errors := ParseString(input)
+	if len(errors) > 0 {
+		t.Fatalf("Parse errors: %v", errors)
+	}
+
+	ensure := program.Statements[0].(*ast.EnsureStmt)
+	if ensure.Handler == nil {
+		t.Fatal("Expected handler spec")
+	}
+
+	args := ensure.Handler.Args
+	if args["interval"] != "30s" || args["max_size"] != "10MB" || args["strict"] != "true" {
+		t.Errorf("Unexpected args: %v", args)
+	}
+
+	if d, ok := ensure.Handler.Duration("interval"); !ok || d != 30*time.Second {
+		t.Errorf("Expected interval 30s, got %v (ok=%v)", d, ok)
+	}
+	if n, ok := ensure.Handler.Size("max_size"); !ok || n != 10000000 {
+		t.Errorf("Expected max_size 10000000, got %d (ok=%v)", n, ok)
+	}
+	if b, ok := ensure.Handler.Bool("strict"); !ok || !b {
+		t.Errorf("Expected strict true, got %v (ok=%v)", b, ok)
+	}
+
+	// String-typed args are not visible through the typed accessors
+	if _, ok := ensure.Handler.Duration("max_size"); ok {
+		t.Error("Expected Duration to reject a size-typed arg")
+	}
+}
+
+func TestParseForEachFilters(t *testing.T) {
+	input := `for each file in directory "/secrets" matching "*.conf" excluding "*.tmp" "*.bak" {
+  ensure encrypted with AES:256 key "env:KEY"
+}`
+
+	program, errors := ParseString(input)
+	if len(errors) > 0 {
+		t.Fatalf("Parse errors: %v", errors)
+	}
+
+	forEach, ok := program.Statements[0].(*ast.ForEachStmt)
+	if !ok {
+		t.Fatalf("Expected ForEachStmt, got %T", program.Statements[0])
+	}
+
+	if len(forEach.Matching) != 1 || forEach.Matching[0] != "*.conf" {
+		t.Errorf("Expected matching ['*.conf'], got %v", forEach.Matching)
+	}
+	if len(forEach.Excluding) != 2 || forEach.Excluding[0] != "*.tmp" || forEach.Excluding[1] != "*.bak" {
+		t.Errorf("Expected excluding ['*.tmp', '*.bak'], got %v", forEach.Excluding)
+	}
+
+	// Malformed globs are rejected at parse time
+	_, errors = ParseString(`for each file in directory "/d" excluding "[" { ensure exists }`)
+	if len(errors) == 0 {
+		t.Error("Expected error for malformed glob, got none")
+	}
+}
+
+func TestParseForEachGlobContainer(t *testing.T) {
+	input := `for each file in glob "/etc/nginx/conf.d/*.conf" {
+  ensure readable with posix
+}`
+
+	program, errors := ParseString(input)
+	if len(errors) > 0 {
+		t.Fatalf("Parse errors: %v", errors)
+	}
+
+	forEach, ok := program.Statements[0].(*ast.ForEachStmt)
+	if !ok {
+		t.Fatalf("Expected ForEachStmt, got %T", program.Statements[0])
+	}
+
+	if forEach.Container.ResourceType != "glob" {
+		t.Errorf("Expected container type 'glob', got %q", forEach.Container.ResourceType)
+	}
+	if forEach.Container.Path != "/etc/nginx/conf.d/*.conf" {
+		t.Errorf("Expected container path '/etc/nginx/conf.d/*.conf', got %q", forEach.Container.Path)
+	}
+}
@@ -0,0 +1,173 @@
+package planner
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// StepChange describes a step whose handler or arguments changed between
+// two plans.
+type StepChange struct {
+	Old *Step
+	New *Step
+}
+
+// PlanDiff describes the differences between two plans.
+type PlanDiff struct {
+	Added   []*Step
+	Removed []*Step
+	Changed []StepChange
+}
+
+// Empty reports whether the diff contains no changes.
+func (d *PlanDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// diffKey identifies a step independently of its source position, so that
+// moving a guarantee within a file is not reported as a change.
+func diffKey(step *Step) string {
+	subject := ""
+	if step.Guarantee.Statement.Subject != nil {
+		subject = step.Guarantee.Statement.Subject.String()
+	}
+	return step.Guarantee.Statement.Condition + ":" + subject
+}
+
+func argsEqual(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if b[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// Diff compares this plan against a newer plan and returns the guarantees
+// that were added, removed, or changed.
+func (p *Plan) Diff(newer *Plan) *PlanDiff {
+	diff := &PlanDiff{}
+
+	oldSteps := make(map[string]*Step)
+	for _, step := range p.Steps {
+		oldSteps[diffKey(step)] = step
+	}
+
+	newKeys := make(map[string]bool)
+	for _, step := range newer.Steps {
+		key := diffKey(step)
+		newKeys[key] = true
+
+		old, ok := oldSteps[key]
+		if !ok {
+			diff.Added = append(diff.Added, step)
+			continue
+		}
+		if old.Handler != step.Handler || !argsEqual(old.HandlerArgs, step.HandlerArgs) {
+			diff.Changed = append(diff.Changed, StepChange{Old: old, New: step})
+		}
+	}
+
+	for _, step := range p.Steps {
+		if !newKeys[diffKey(step)] {
+			diff.Removed = append(diff.Removed, step)
+		}
+	}
+
+	sortSteps := func(steps []*Step) {
+		sort.Slice(steps, func(i, j int) bool {
+			return diffKey(steps[i]) < diffKey(steps[j])
+		})
+	}
+	sortSteps(diff.Added)
+	sortSteps(diff.Removed)
+	sort.Slice(diff.Changed, func(i, j int) bool {
+		return diffKey(diff.Changed[i].New) < diffKey(diff.Changed[j].New)
+	})
+
+	return diff
+}
+
+func formatArgs(args map[string]string) string {
+	if len(args) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(args))
+	for k := range args {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		parts = append(parts, fmt.Sprintf("%s=%s", k, args[k]))
+	}
+	return " (" + strings.Join(parts, ", ") + ")"
+}
+
+// String returns a human-readable representation of the diff.
+func (d *PlanDiff) String() string {
+	if d.Empty() {
+		return "No changes.\n"
+	}
+
+	var out strings.Builder
+
+	for _, step := range d.Added {
+		out.WriteString(fmt.Sprintf("+ %s [%s]%s\n",
+			step.Description, step.Handler, formatArgs(step.HandlerArgs)))
+	}
+	for _, step := range d.Removed {
+		out.WriteString(fmt.Sprintf("- %s [%s]%s\n",
+			step.Description, step.Handler, formatArgs(step.HandlerArgs)))
+	}
+	for _, change := range d.Changed {
+		out.WriteString(fmt.Sprintf("~ %s\n", change.New.Description))
+		out.WriteString(fmt.Sprintf("    old: %s%s\n",
+			change.Old.Handler, formatArgs(change.Old.HandlerArgs)))
+		out.WriteString(fmt.Sprintf("    new: %s%s\n",
+			change.New.Handler, formatArgs(change.New.HandlerArgs)))
+	}
+
+	out.WriteString(fmt.Sprintf("\n%d added, %d removed, %d changed\n",
+		len(d.Added), len(d.Removed), len(d.Changed)))
+
+	return out.String()
+}
+
+// ToJSON returns a JSON-compatible structure for the diff.
+func (d *PlanDiff) ToJSON() map[string]interface{} {
+	stepJSON := func(step *Step) map[string]interface{} {
+		return map[string]interface{}{
+			"description": step.Description,
+			"handler":     step.Handler,
+			"args":        step.HandlerArgs,
+		}
+	}
+
+	added := make([]map[string]interface{}, len(d.Added))
+	for i, step := range d.Added {
+		added[i] = stepJSON(step)
+	}
+	removed := make([]map[string]interface{}, len(d.Removed))
+	for i, step := range d.Removed {
+		removed[i] = stepJSON(step)
+	}
+	changed := make([]map[string]interface{}, len(d.Changed))
+	for i, change := range d.Changed {
+		changed[i] = map[string]interface{}{
+			"description": change.New.Description,
+			"old":         stepJSON(change.Old),
+			"new":         stepJSON(change.New),
+		}
+	}
+
+	return map[string]interface{}{
+		"added":   added,
+		"removed": removed,
+		"changed": changed,
+	}
+}
@@ -0,0 +1,216 @@
+package planner
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/ensurascript/ensura/pkg/ast"
+	"github.com/ensurascript/ensura/pkg/graph"
+)
+
+// PlanDocumentVersion is the current version of the persisted plan format.
+const PlanDocumentVersion = 1
+
+// planDocument is the serialized, self-contained form of a Plan.
+type planDocument struct {
+	Version         int                `json:"version"`
+	Steps           []planDocumentStep `json:"steps"`
+	GlobalViolation *planViolation     `json:"globalViolation,omitempty"`
+}
+
+type planDocumentStep struct {
+	ID          string            `json:"id"`
+	Condition   string            `json:"condition"`
+	Subject     *planSubject      `json:"subject,omitempty"`
+	Description string            `json:"description"`
+	Handler     string            `json:"handler"`
+	Args        map[string]string `json:"args,omitempty"`
+	IsInvariant bool              `json:"isInvariant,omitempty"`
+	Timeout     string            `json:"timeout,omitempty"`
+	Severity    string            `json:"severity,omitempty"`
+	Tags        []string          `json:"tags,omitempty"`
+	During      string            `json:"during,omitempty"`
+	Violation   *planViolation    `json:"violation,omitempty"`
+}
+
+type planSubject struct {
+	ResourceType string       `json:"resourceType,omitempty"`
+	Path         string       `json:"path,omitempty"`
+	Alias        string       `json:"alias,omitempty"`
+	Iterator     bool         `json:"iterator,omitempty"`
+	Container    *planSubject `json:"container,omitempty"`
+	Matching     []string     `json:"matching,omitempty"`
+	Excluding    []string     `json:"excluding,omitempty"`
+}
+
+func toPlanSubject(ref *ast.ResourceRef) *planSubject {
+	if ref == nil {
+		return nil
+	}
+	return &planSubject{
+		ResourceType: ref.ResourceType,
+		Path:         ref.Path,
+		Alias:        ref.Alias,
+		Iterator:     ref.IsIterator,
+		Container:    toPlanSubject(ref.Container),
+		Matching:     ref.Matching,
+		Excluding:    ref.Excluding,
+	}
+}
+
+func fromPlanSubject(ps *planSubject) *ast.ResourceRef {
+	if ps == nil {
+		return nil
+	}
+	return &ast.ResourceRef{
+		ResourceType: ps.ResourceType,
+		Path:         ps.Path,
+		Alias:        ps.Alias,
+		IsIterator:   ps.Iterator,
+		Container:    fromPlanSubject(ps.Container),
+		Matching:     ps.Matching,
+		Excluding:    ps.Excluding,
+	}
+}
+
+type planViolation struct {
+	Retry       int              `json:"retry,omitempty"`
+	Notify      []string         `json:"notify,omitempty"`
+	NotifyRules []planNotifyRule `json:"notifyRules,omitempty"`
+}
+
+type planNotifyRule struct {
+	Target        string `json:"target"`
+	AfterFailures int    `json:"afterFailures,omitempty"`
+	MinSeverity   string `json:"minSeverity,omitempty"`
+}
+
+func toPlanViolation(v *ast.ViolationHandler) *planViolation {
+	pv := &planViolation{
+		Retry:  v.Retry,
+		Notify: v.Notify,
+	}
+	for _, r := range v.NotifyRules {
+		pv.NotifyRules = append(pv.NotifyRules, planNotifyRule{
+			Target:        r.Target,
+			AfterFailures: r.AfterFailures,
+			MinSeverity:   r.MinSeverity,
+		})
+	}
+	return pv
+}
+
+func fromPlanViolation(pv *planViolation) *ast.ViolationHandler {
+	v := &ast.ViolationHandler{
+		Retry:  pv.Retry,
+		Notify: pv.Notify,
+	}
+	for _, r := range pv.NotifyRules {
+		v.NotifyRules = append(v.NotifyRules, ast.NotifyRule{
+			Target:        r.Target,
+			AfterFailures: r.AfterFailures,
+			MinSeverity:   r.MinSeverity,
+		})
+	}
+	return v
+}
+
+// MarshalDocument serializes the plan into a versioned, self-contained
+// document that can be executed later without recompiling the source.
+func (p *Plan) MarshalDocument() ([]byte, error) {
+	doc := planDocument{
+		Version: PlanDocumentVersion,
+		Steps:   make([]planDocumentStep, 0, len(p.Steps)),
+	}
+
+	for _, step := range p.Steps {
+		stmt := step.Guarantee.Statement
+		docStep := planDocumentStep{
+			ID:          step.ID,
+			Condition:   stmt.Condition,
+			Description: step.Description,
+			Handler:     step.Handler,
+			Args:        step.HandlerArgs,
+			IsInvariant: step.IsInvariant,
+			Severity:    step.Severity,
+			Tags:        step.Tags,
+			During:      step.During,
+		}
+		if step.Timeout > 0 {
+			docStep.Timeout = step.Timeout.String()
+		}
+		docStep.Subject = toPlanSubject(stmt.Subject)
+		if stmt.ViolationHandler != nil {
+			docStep.Violation = toPlanViolation(stmt.ViolationHandler)
+		}
+		doc.Steps = append(doc.Steps, docStep)
+	}
+
+	if p.GlobalViolation != nil {
+		doc.GlobalViolation = toPlanViolation(p.GlobalViolation)
+	}
+
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+// LoadPlan reconstructs a plan from a persisted plan document.
+func LoadPlan(data []byte) (*Plan, error) {
+	var doc planDocument
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("invalid plan document: %w", err)
+	}
+
+	if doc.Version != PlanDocumentVersion {
+		return nil, fmt.Errorf("unsupported plan document version %d (expected %d)",
+			doc.Version, PlanDocumentVersion)
+	}
+
+	plan := NewPlan()
+	for _, docStep := range doc.Steps {
+		stmt := &ast.EnsureStmt{
+			Condition: docStep.Condition,
+			Severity:  docStep.Severity,
+			Tags:      docStep.Tags,
+			During:    docStep.During,
+		}
+		stmt.Subject = fromPlanSubject(docStep.Subject)
+		if docStep.Violation != nil {
+			stmt.ViolationHandler = fromPlanViolation(docStep.Violation)
+		}
+
+		args := docStep.Args
+		if args == nil {
+			args = make(map[string]string)
+		}
+
+		var timeout time.Duration
+		if docStep.Timeout != "" {
+			d, err := time.ParseDuration(docStep.Timeout)
+			if err != nil {
+				return nil, fmt.Errorf("invalid timeout %q in plan document: %w", docStep.Timeout, err)
+			}
+			timeout = d
+			stmt.Timeout = d
+		}
+
+		plan.Steps = append(plan.Steps, &Step{
+			ID:          docStep.ID,
+			Guarantee:   &graph.Guarantee{ID: docStep.ID, Statement: stmt},
+			Description: docStep.Description,
+			Handler:     docStep.Handler,
+			HandlerArgs: args,
+			IsInvariant: docStep.IsInvariant,
+			Timeout:     timeout,
+			Severity:    docStep.Severity,
+			Tags:        docStep.Tags,
+			During:      docStep.During,
+		})
+	}
+
+	if doc.GlobalViolation != nil {
+		plan.GlobalViolation = fromPlanViolation(doc.GlobalViolation)
+	}
+
+	return plan, nil
+}
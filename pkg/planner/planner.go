@@ -3,9 +3,12 @@ package planner
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/ensurascript/ensura/pkg/ast"
+	"github.com/ensurascript/ensura/pkg/cronexpr"
 	"github.com/ensurascript/ensura/pkg/graph"
 )
 
@@ -17,6 +20,20 @@ type Step struct {
 	Handler     string
 	HandlerArgs map[string]string
 	IsInvariant bool
+	Timeout     time.Duration // per-step timeout (0 = use runtime default)
+	Severity    string        // critical, high, warning, or info ("" = high)
+	Tags        []string      // labels for run filtering
+	During      string        // maintenance window for repairs ("" = always)
+}
+
+// HasTag reports whether the step carries the given tag.
+func (s *Step) HasTag(tag string) bool {
+	for _, t := range s.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
 }
 
 // Plan represents the complete execution plan.
@@ -30,9 +47,126 @@ func NewPlan() *Plan {
 	return &Plan{}
 }
 
+// FilterByTags returns a plan containing only the steps selected by the
+// given tag filters. When only is non-empty, a step must carry at least
+// one of its tags to be kept; steps carrying any skip tag are dropped.
+// The global violation handler is carried over unchanged.
+func (p *Plan) FilterByTags(only, skip []string) *Plan {
+	filtered := &Plan{GlobalViolation: p.GlobalViolation}
+	for _, step := range p.Steps {
+		if len(only) > 0 {
+			keep := false
+			for _, tag := range only {
+				if step.HasTag(tag) {
+					keep = true
+					break
+				}
+			}
+			if !keep {
+				continue
+			}
+		}
+		skipped := false
+		for _, tag := range skip {
+			if step.HasTag(tag) {
+				skipped = true
+				break
+			}
+		}
+		if skipped {
+			continue
+		}
+		filtered.Steps = append(filtered.Steps, step)
+	}
+	return filtered
+}
+
+// MatchesPattern reports whether the step is selected by the given glob
+// pattern. The pattern is tried against the step ID, the condition, the
+// subject resource (both its `type "path"` form and the bare path), and
+// each tag, so `file "secrets.db"`, `encrypted`, and `step-*` all work.
+func (s *Step) MatchesPattern(pattern string) bool {
+	candidates := []string{s.ID, s.Guarantee.Statement.Condition}
+	if subject := s.Guarantee.Statement.Subject; subject != nil {
+		candidates = append(candidates, subject.String(), subject.Path)
+	}
+	candidates = append(candidates, s.Tags...)
+	for _, candidate := range candidates {
+		if candidate != "" && matchGlob(pattern, candidate) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchGlob matches s against a glob pattern where * matches any run of
+// characters (including path separators, unlike path.Match) and ?
+// matches any single character.
+func matchGlob(pattern, s string) bool {
+	// Backtracking pointers for the most recent *.
+	var starPat, starStr = -1, 0
+	p, i := 0, 0
+	for i < len(s) {
+		switch {
+		case p < len(pattern) && (pattern[p] == '?' || pattern[p] == s[i]):
+			p++
+			i++
+		case p < len(pattern) && pattern[p] == '*':
+			starPat, starStr = p, i
+			p++
+		case starPat >= 0:
+			starStr++
+			p = starPat + 1
+			i = starStr
+		default:
+			return false
+		}
+	}
+	for p < len(pattern) && pattern[p] == '*' {
+		p++
+	}
+	return p == len(pattern)
+}
+
+// FilterByPatterns returns a plan containing only the steps selected by
+// the given glob filters. When only is non-empty, a step must match at
+// least one of its patterns to be kept; steps matching any skip pattern
+// are dropped. The global violation handler is carried over unchanged.
+func (p *Plan) FilterByPatterns(only, skip []string) *Plan {
+	filtered := &Plan{GlobalViolation: p.GlobalViolation}
+	for _, step := range p.Steps {
+		if len(only) > 0 {
+			keep := false
+			for _, pattern := range only {
+				if step.MatchesPattern(pattern) {
+					keep = true
+					break
+				}
+			}
+			if !keep {
+				continue
+			}
+		}
+		skipped := false
+		for _, pattern := range skip {
+			if step.MatchesPattern(pattern) {
+				skipped = true
+				break
+			}
+		}
+		if skipped {
+			continue
+		}
+		filtered.Steps = append(filtered.Steps, step)
+	}
+	return filtered
+}
+
 // Planner creates execution plans from guarantee graphs.
 type Planner struct {
-	errors []string
+	errors        []string
+	extraDefaults map[string]string
+	capabilities  map[string][]string
 }
 
 // New creates a new Planner.
@@ -40,6 +174,23 @@ func New() *Planner {
 	return &Planner{}
 }
 
+// RegisterDefaultHandler adds a default handler for a condition beyond the
+// built-ins, used for user-defined conditions.
+func (p *Planner) RegisterDefaultHandler(condition, handler string) {
+	if p.extraDefaults == nil {
+		p.extraDefaults = make(map[string]string)
+	}
+	p.extraDefaults[condition] = handler
+}
+
+// SetHandlerCapabilities provides the supported conditions of each known
+// handler, keyed by handler name. When set, the planner rejects ensure
+// statements whose handler does not support the statement's condition.
+// A nil condition list means the handler accepts any condition.
+func (p *Planner) SetHandlerCapabilities(caps map[string][]string) {
+	p.capabilities = caps
+}
+
 // Errors returns all planning errors.
 func (p *Planner) Errors() []string {
 	return p.errors
@@ -55,7 +206,7 @@ func (p *Planner) CreatePlan(g *graph.Graph, program *ast.Program) (*Plan, error
 		// Report cycle if found
 		cycle := g.FindCycle()
 		if cycle != nil {
-			return nil, fmt.Errorf("cyclic dependency detected: %s", strings.Join(cycle, " -> "))
+			return nil, fmt.Errorf("cyclic dependency detected:\n%s", g.DescribeCycle(cycle))
 		}
 		return nil, err
 	}
@@ -80,6 +231,10 @@ func (p *Planner) createStep(guarantee *graph.Guarantee, isInvariant bool) *Step
 		Guarantee:   guarantee,
 		Description: p.generateDescription(stmt),
 		IsInvariant: isInvariant,
+		Timeout:     stmt.Timeout,
+		Severity:    stmt.Severity,
+		Tags:        stmt.Tags,
+		During:      stmt.During,
 	}
 
 	// Extract handler information
@@ -87,14 +242,98 @@ func (p *Planner) createStep(guarantee *graph.Guarantee, isInvariant bool) *Step
 		step.Handler = stmt.Handler.Name
 		step.HandlerArgs = stmt.Handler.Args
 	} else {
-		// Use default handler based on condition
-		step.Handler = p.getDefaultHandler(stmt.Condition)
+		// Use default handler based on condition and subject type
+		resourceType := ""
+		if stmt.Subject != nil {
+			resourceType = stmt.Subject.ResourceType
+		}
+		step.Handler = p.getDefaultHandler(stmt.Condition, resourceType)
 		step.HandlerArgs = make(map[string]string)
 	}
 
+	// Size literals reach handlers as plain byte counts, since adapters
+	// take sizes as integer strings. Durations and booleans already read
+	// naturally as strings.
+	if stmt.Handler != nil {
+		for key, kind := range stmt.Handler.ArgTypes {
+			if kind != ast.ArgSize {
+				continue
+			}
+			bytes, ok := stmt.Handler.Size(key)
+			if !ok {
+				continue
+			}
+			args := make(map[string]string, len(step.HandlerArgs))
+			for k, v := range step.HandlerArgs {
+				args[k] = v
+			}
+			args[key] = strconv.FormatInt(bytes, 10)
+			step.HandlerArgs = args
+		}
+	}
+
+	// A freshness clause becomes an ordinary handler argument, so any
+	// time-aware adapter can honor it without new plumbing. The handler
+	// args are copied first: the map may be shared with the AST.
+	if stmt.Within != "" && step.HandlerArgs["within"] == "" {
+		args := make(map[string]string, len(step.HandlerArgs)+1)
+		for k, v := range step.HandlerArgs {
+			args[k] = v
+		}
+		args["within"] = stmt.Within
+		step.HandlerArgs = args
+	}
+
+	p.validateCapability(stmt, step.Handler, stmt.Handler != nil)
+	p.validateSchedule(stmt, step)
+
 	return step
 }
 
+// validateSchedule rejects malformed cron expressions at compile time rather
+// than letting the cron adapter install a broken crontab.
+func (p *Planner) validateSchedule(stmt *ast.EnsureStmt, step *Step) {
+	if step.Handler != "cron.native" {
+		return
+	}
+	schedule, ok := step.HandlerArgs["schedule"]
+	if !ok {
+		return
+	}
+	if err := cronexpr.Validate(schedule); err != nil {
+		p.errors = append(p.errors, fmt.Sprintf("%s: invalid cron schedule %q: %v",
+			stmt.Position, schedule, err))
+	}
+}
+
+// validateCapability fails fast when a handler is asked about a condition it
+// does not support, instead of letting it error at runtime. Handler names
+// the user wrote explicitly (with ...) must exist in the registry; names
+// resolved from defaults are left for the runtime, since some default
+// mappings are satisfied by plugins registered later.
+func (p *Planner) validateCapability(stmt *ast.EnsureStmt, handler string, explicit bool) {
+	if p.capabilities == nil || handler == "" {
+		return
+	}
+	supported, known := p.capabilities[handler]
+	if !known {
+		if explicit {
+			p.errors = append(p.errors, fmt.Sprintf("%s: unknown handler %q", stmt.Position, handler))
+		}
+		return
+	}
+	if supported == nil {
+		return
+	}
+	for _, c := range supported {
+		if c == stmt.Condition {
+			return
+		}
+	}
+	p.errors = append(p.errors, fmt.Sprintf("%s: handler %q does not support condition %q (supported: %s)",
+		stmt.Position, handler, stmt.Condition, strings.Join(supported, ", ")))
+}
+
 func (p *Planner) generateDescription(stmt *ast.EnsureStmt) string {
 	var parts []string
 	parts = append(parts, "Ensure", stmt.Condition)
@@ -110,27 +349,80 @@ func (p *Planner) generateDescription(stmt *ast.EnsureStmt) string {
 	return strings.Join(parts, " ")
 }
 
-func (p *Planner) getDefaultHandler(condition string) string {
+func (p *Planner) getDefaultHandler(condition, resourceType string) string {
+	// Account conditions share names with filesystem ones (exists), so the
+	// subject's resource type wins when it maps to its own handler domain.
+	byType := map[string]string{
+		"user":      "account.native",
+		"group":     "account.native",
+		"s3":        "s3.aws",
+		"env":       "env.native",
+		"datastore": "datastore.native",
+	}
+	if handler, ok := byType[resourceType]; ok {
+		return handler
+	}
+
 	defaults := map[string]string{
-		"exists":      "fs.native",
-		"readable":    "fs.native",
-		"writable":    "fs.native",
-		"encrypted":   "AES:256",
-		"permissions": "posix",
-		"checksum":    "fs.native",
-		"content":     "fs.native",
-		"running":     "process.native",
-		"stopped":     "process.native",
-		"listening":   "service.native",
-		"healthy":     "service.native",
-		"reachable":   "http.get",
-		"status_code": "http.get",
-		"tls":         "http.get",
-		"scheduled":   "cron.native",
-		"backed_up":   "backup.native",
-		"stable":      "db.native",
+		"exists":             "fs.native",
+		"readable":           "fs.native",
+		"writable":           "fs.native",
+		"encrypted":          "AES:256",
+		"rotate_key":         "AES:256",
+		"permissions":        "posix",
+		"checksum":           "fs.native",
+		"content":            "fs.native",
+		"size_below":         "fs.native",
+		"size_above":         "fs.native",
+		"newer_than":         "fs.native",
+		"is_symlink":         "fs.native",
+		"empty":              "fs.native",
+		"not_empty":          "fs.native",
+		"file_count_below":   "fs.native",
+		"contains":           "fs.native",
+		"no_world_writable":  "fs.native",
+		"matches":            "fs.native",
+		"json_path":          "fs.native",
+		"rendered":           "template.native",
+		"valid":              "cert.native",
+		"expires_after":      "cert.native",
+		"issued_by":          "cert.native",
+		"san_contains":       "cert.native",
+		"running":            "process.native",
+		"stopped":            "process.native",
+		"listening":          "service.native",
+		"healthy":            "service.native",
+		"open":               "tcp.native",
+		"closed":             "tcp.native",
+		"resolves":           "dns.native",
+		"resolves_to":        "dns.native",
+		"reachable":          "http.get",
+		"status_code":        "http.get",
+		"tls":                "http.get",
+		"cert_expires_after": "http.get",
+		"cert_issuer":        "http.get",
+		"body_contains":      "http.get",
+		"latency_below":      "http.get",
+		"uid":                "account.native",
+		"gid":                "account.native",
+		"member_of":          "account.native",
+		"shell":              "account.native",
+		"home":               "account.native",
+		"installed":          "pkg.native",
+		"scheduled":          "cron.native",
+		"mounted":            "mount.native",
+		"free_space_above":   "mount.native",
+		"value":              "sysctl.native",
+		"max_age":            "s3.aws",
+		"set":                "env.native",
+		"equals":             "env.native",
+		"backed_up":          "backup.native",
+		"stable":             "db.native",
 	}
 
+	if handler, ok := p.extraDefaults[condition]; ok {
+		return handler
+	}
 	if handler, ok := defaults[condition]; ok {
 		return handler
 	}
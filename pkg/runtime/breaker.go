@@ -0,0 +1,74 @@
+package runtime
+
+import (
+	"sync"
+	"time"
+)
+
+// DefaultBreakerCooldown is how long a tripped step stays suppressed when
+// no cooldown is configured.
+const DefaultBreakerCooldown = 5 * time.Minute
+
+// breakerState tracks the failure history of a single step.
+type breakerState struct {
+	consecutiveFailures int
+	openUntil           time.Time
+}
+
+// breaker suppresses steps that keep failing, so chronically broken
+// guarantees stop burning repair attempts and spamming notifications.
+// After the cooldown expires the step gets one trial run; another failure
+// re-opens the breaker immediately.
+type breaker struct {
+	threshold int
+	cooldown  time.Duration
+
+	mu    sync.Mutex
+	steps map[string]*breakerState
+}
+
+func newBreaker(threshold int, cooldown time.Duration) *breaker {
+	if cooldown <= 0 {
+		cooldown = DefaultBreakerCooldown
+	}
+	return &breaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		steps:     make(map[string]*breakerState),
+	}
+}
+
+// suppressed reports whether the step should be skipped this pass, and if
+// so until when.
+func (b *breaker) suppressed(stepID string) (time.Time, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	state, ok := b.steps[stepID]
+	if !ok || !time.Now().Before(state.openUntil) {
+		return time.Time{}, false
+	}
+	return state.openUntil, true
+}
+
+// observe records the outcome of a step execution. Failures accumulate
+// toward the threshold; any success resets the step's history.
+func (b *breaker) observe(stepID string, status StepStatus) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch status {
+	case StepFailed:
+		state, ok := b.steps[stepID]
+		if !ok {
+			state = &breakerState{}
+			b.steps[stepID] = state
+		}
+		state.consecutiveFailures++
+		if state.consecutiveFailures >= b.threshold {
+			state.openUntil = time.Now().Add(b.cooldown)
+		}
+	case StepSatisfied, StepRepaired:
+		delete(b.steps, stepID)
+	}
+}
@@ -0,0 +1,75 @@
+package runtime
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+	"time"
+)
+
+// sensitiveArgNames lists handler argument names whose values are redacted
+// in structured logs unless they are env:/file: references.
+var sensitiveArgNames = map[string]bool{
+	"key":      true,
+	"password": true,
+	"secret":   true,
+	"token":    true,
+}
+
+// redactArgs returns a copy of args with sensitive literal values masked.
+func redactArgs(args map[string]string, redact bool) map[string]string {
+	if len(args) == 0 {
+		return nil
+	}
+	out := make(map[string]string, len(args))
+	for k, v := range args {
+		if redact && sensitiveArgNames[strings.ToLower(k)] &&
+			!strings.HasPrefix(v, "env:") && !strings.HasPrefix(v, "file:") {
+			out[k] = "[REDACTED]"
+		} else {
+			out[k] = v
+		}
+	}
+	return out
+}
+
+// printResultJSON writes one JSON object per step and one per run,
+// suitable for shipping to log aggregators.
+func (r *Runtime) printResultJSON(w io.Writer, result *RunResult) {
+	enc := json.NewEncoder(w)
+
+	for _, step := range result.Steps {
+		entry := map[string]interface{}{
+			"time":        result.EndTime.Format(time.RFC3339Nano),
+			"event":       "step",
+			"id":          step.Step.ID,
+			"description": step.Step.Description,
+			"handler":     step.Step.Handler,
+			"status":      step.Status.String(),
+			"attempts":    step.Attempts,
+		}
+		if args := redactArgs(step.Step.HandlerArgs, r.config.Redact); args != nil {
+			entry["args"] = args
+		}
+		if step.Message != "" {
+			entry["message"] = step.Message
+		}
+		if step.Error != nil {
+			entry["error"] = step.Error.Error()
+		}
+		enc.Encode(entry)
+	}
+
+	enc.Encode(map[string]interface{}{
+		"time":         result.EndTime.Format(time.RFC3339Nano),
+		"event":        "run",
+		"startTime":    result.StartTime.Format(time.RFC3339Nano),
+		"endTime":      result.EndTime.Format(time.RFC3339Nano),
+		"durationMs":   result.EndTime.Sub(result.StartTime).Milliseconds(),
+		"checks":       result.TotalChecks,
+		"repairs":      result.TotalRepairs,
+		"failures":     result.TotalFailures,
+		"suppressed":   result.TotalSuppressed,
+		"allSatisfied": result.AllSatisfied,
+	})
+}
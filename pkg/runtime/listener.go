@@ -0,0 +1,64 @@
+package runtime
+
+import (
+	"github.com/ensurascript/ensura/pkg/planner"
+)
+
+// Listener receives events from the enforcement loop. Embedders can stream
+// progress to UIs, logs, or metrics without parsing printed output.
+type Listener interface {
+	OnRunStart(plan *planner.Plan)
+	OnStepStart(step *planner.Step)
+	OnStepComplete(result *StepResult)
+	OnViolation(result *StepResult)
+	OnRepair(result *StepResult)
+	OnRunComplete(result *RunResult)
+}
+
+// BaseListener is a no-op Listener. Embed it to implement only the events
+// you care about.
+type BaseListener struct{}
+
+func (BaseListener) OnRunStart(plan *planner.Plan)     {}
+func (BaseListener) OnStepStart(step *planner.Step)    {}
+func (BaseListener) OnStepComplete(result *StepResult) {}
+func (BaseListener) OnViolation(result *StepResult)    {}
+func (BaseListener) OnRepair(result *StepResult)       {}
+func (BaseListener) OnRunComplete(result *RunResult)   {}
+
+// AddListener registers a listener for runtime events.
+func (r *Runtime) AddListener(l Listener) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.listeners = append(r.listeners, l)
+}
+
+func (r *Runtime) notifyRunStart(plan *planner.Plan) {
+	for _, l := range r.listeners {
+		l.OnRunStart(plan)
+	}
+}
+
+func (r *Runtime) notifyStepStart(step *planner.Step) {
+	for _, l := range r.listeners {
+		l.OnStepStart(step)
+	}
+}
+
+func (r *Runtime) notifyStepComplete(result *StepResult) {
+	for _, l := range r.listeners {
+		l.OnStepComplete(result)
+		switch result.Status {
+		case StepViolated, StepFailed:
+			l.OnViolation(result)
+		case StepRepaired:
+			l.OnRepair(result)
+		}
+	}
+}
+
+func (r *Runtime) notifyRunComplete(result *RunResult) {
+	for _, l := range r.listeners {
+		l.OnRunComplete(result)
+	}
+}
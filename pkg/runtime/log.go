@@ -0,0 +1,81 @@
+package runtime
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// LogLevel is the minimum severity a log line needs to be emitted.
+type LogLevel int
+
+const (
+	// LogDefault is the zero value and behaves like LogInfo.
+	LogDefault LogLevel = iota
+	LogDebug
+	LogInfo
+	LogWarn
+	LogError
+)
+
+func (l LogLevel) String() string {
+	switch l {
+	case LogDebug:
+		return "debug"
+	case LogWarn:
+		return "warn"
+	case LogError:
+		return "error"
+	default:
+		return "info"
+	}
+}
+
+// ParseLogLevel converts a level name to a LogLevel.
+func ParseLogLevel(name string) (LogLevel, error) {
+	switch name {
+	case "debug":
+		return LogDebug, nil
+	case "info":
+		return LogInfo, nil
+	case "warn", "warning":
+		return LogWarn, nil
+	case "error":
+		return LogError, nil
+	}
+	return LogDefault, fmt.Errorf("unknown log level %q (want debug, info, warn, or error)", name)
+}
+
+// effective resolves LogDefault to LogInfo.
+func (l LogLevel) effective() LogLevel {
+	if l == LogDefault {
+		return LogInfo
+	}
+	return l
+}
+
+// logf writes one line to the configured logger if the level clears the
+// configured threshold. Info lines keep their bare text form so existing
+// output is unchanged; other levels are prefixed, and in JSON log format
+// every line becomes a structured entry.
+func (r *Runtime) logf(level LogLevel, format string, args ...interface{}) {
+	w := r.config.Logger
+	if w == nil || level.effective() < r.config.LogLevel.effective() {
+		return
+	}
+	msg := fmt.Sprintf(format, args...)
+	if r.config.LogFormat == "json" {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"time":  time.Now().Format(time.RFC3339Nano),
+			"event": "log",
+			"level": level.String(),
+			"msg":   msg,
+		})
+		return
+	}
+	if level.effective() == LogInfo {
+		fmt.Fprintln(w, msg)
+		return
+	}
+	fmt.Fprintf(w, "%s: %s\n", level, msg)
+}
@@ -0,0 +1,147 @@
+package runtime
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Metrics collects enforcement loop statistics and exposes them in the
+// Prometheus text exposition format.
+type Metrics struct {
+	mu sync.Mutex
+
+	runsTotal     int64
+	checksTotal   int64
+	repairsTotal  int64
+	failuresTotal int64
+
+	lastRunDuration time.Duration
+	lastRunTime     time.Time
+	lastInterval    time.Duration
+	prevRunStart    time.Time
+
+	interval time.Duration
+
+	stepStatus map[string]StepStatus
+}
+
+// NewMetrics creates an empty metrics collector.
+func NewMetrics(interval time.Duration) *Metrics {
+	return &Metrics{
+		interval:   interval,
+		stepStatus: make(map[string]StepStatus),
+	}
+}
+
+// ObserveRun records the outcome of a single enforcement pass.
+func (m *Metrics) ObserveRun(result *RunResult) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.runsTotal++
+	m.checksTotal += int64(result.TotalChecks)
+	m.repairsTotal += int64(result.TotalRepairs)
+	m.failuresTotal += int64(result.TotalFailures)
+
+	m.lastRunDuration = result.EndTime.Sub(result.StartTime)
+	m.lastRunTime = result.EndTime
+	if !m.prevRunStart.IsZero() {
+		m.lastInterval = result.StartTime.Sub(m.prevRunStart)
+	}
+	m.prevRunStart = result.StartTime
+
+	for _, step := range result.Steps {
+		m.stepStatus[step.Step.Description] = step.Status
+	}
+}
+
+// escapeLabel escapes a Prometheus label value.
+func escapeLabel(v string) string {
+	v = strings.ReplaceAll(v, `\`, `\\`)
+	v = strings.ReplaceAll(v, "\n", `\n`)
+	v = strings.ReplaceAll(v, `"`, `\"`)
+	return v
+}
+
+// Render returns the metrics in Prometheus text exposition format.
+func (m *Metrics) Render() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var out strings.Builder
+
+	counter := func(name, help string, value int64) {
+		out.WriteString(fmt.Sprintf("# HELP %s %s\n", name, help))
+		out.WriteString(fmt.Sprintf("# TYPE %s counter\n", name))
+		out.WriteString(fmt.Sprintf("%s %d\n", name, value))
+	}
+	gauge := func(name, help string, value float64) {
+		out.WriteString(fmt.Sprintf("# HELP %s %s\n", name, help))
+		out.WriteString(fmt.Sprintf("# TYPE %s gauge\n", name))
+		out.WriteString(fmt.Sprintf("%s %g\n", name, value))
+	}
+
+	counter("ensura_runs_total", "Total number of enforcement passes.", m.runsTotal)
+	counter("ensura_checks_total", "Total number of guarantee checks.", m.checksTotal)
+	counter("ensura_repairs_total", "Total number of successful repairs.", m.repairsTotal)
+	counter("ensura_failures_total", "Total number of violated or failed guarantees.", m.failuresTotal)
+
+	gauge("ensura_last_run_duration_seconds", "Duration of the most recent enforcement pass.",
+		m.lastRunDuration.Seconds())
+	if !m.lastRunTime.IsZero() {
+		gauge("ensura_last_run_timestamp_seconds", "Completion time of the most recent enforcement pass.",
+			float64(m.lastRunTime.Unix()))
+	}
+	gauge("ensura_loop_interval_seconds", "Configured interval between enforcement passes.",
+		m.interval.Seconds())
+	if m.lastInterval > 0 {
+		gauge("ensura_last_interval_seconds", "Observed interval between the last two enforcement passes.",
+			m.lastInterval.Seconds())
+	}
+
+	if len(m.stepStatus) > 0 {
+		out.WriteString("# HELP ensura_step_status Status of each plan step (0=pending 1=satisfied 2=violated 3=repaired 4=failed).\n")
+		out.WriteString("# TYPE ensura_step_status gauge\n")
+		steps := make([]string, 0, len(m.stepStatus))
+		for step := range m.stepStatus {
+			steps = append(steps, step)
+		}
+		sort.Strings(steps)
+		for _, step := range steps {
+			out.WriteString(fmt.Sprintf("ensura_step_status{step=\"%s\"} %d\n",
+				escapeLabel(step), m.stepStatus[step]))
+		}
+	}
+
+	return out.String()
+}
+
+// ServeHTTP serves the metrics endpoint.
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	fmt.Fprint(w, m.Render())
+}
+
+// startMetricsListener serves /metrics on the configured address until the
+// context is cancelled.
+func (r *Runtime) startMetricsListener(ctx context.Context) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", r.metrics)
+	server := &http.Server{Addr: r.config.MetricsAddr, Handler: mux}
+
+	go func() {
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			r.logf(LogError, "metrics listener error: %v", err)
+		}
+	}()
+
+	go func() {
+		<-ctx.Done()
+		server.Close()
+	}()
+}
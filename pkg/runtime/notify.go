@@ -0,0 +1,108 @@
+package runtime
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ensurascript/ensura/pkg/ast"
+)
+
+// NotifyFunc delivers a single violation notification to a target.
+type NotifyFunc func(target, message string)
+
+// notifierState tracks the notification history of a single step.
+type notifierState struct {
+	failures int                  // consecutive failing runs
+	notified map[string]time.Time // target -> last notification
+}
+
+// notifier decides when notify targets actually fire. Notifications go out
+// on the transition into violation (once the target's escalation threshold
+// is reached), are re-sent at most every renotify interval while the
+// violation persists, and a recovery notice goes to previously notified
+// targets when the step becomes satisfied again.
+type notifier struct {
+	send     NotifyFunc
+	renotify time.Duration // 0 = notify on state transitions only
+
+	mu    sync.Mutex
+	steps map[string]*notifierState
+}
+
+func newNotifier(send NotifyFunc, renotify time.Duration) *notifier {
+	return &notifier{
+		send:     send,
+		renotify: renotify,
+		steps:    make(map[string]*notifierState),
+	}
+}
+
+// observe records a step result and fires any notifications that are due.
+func (n *notifier) observe(result *StepResult, handler *ast.ViolationHandler) {
+	if handler == nil {
+		return
+	}
+
+	rules := handler.NotifyRules
+	if len(rules) == 0 {
+		// Plans loaded from older documents may only carry plain targets.
+		for _, target := range handler.Notify {
+			rules = append(rules, ast.NotifyRule{Target: target, AfterFailures: 1})
+		}
+	}
+	if len(rules) == 0 {
+		return
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	stepID := result.Step.ID
+	state := n.steps[stepID]
+
+	switch result.Status {
+	case StepViolated, StepFailed:
+		if state == nil {
+			state = &notifierState{notified: make(map[string]time.Time)}
+			n.steps[stepID] = state
+		}
+		state.failures++
+
+		for _, rule := range rules {
+			if rule.MinSeverity != "" &&
+				ast.SeverityRank(result.Step.Severity) < ast.SeverityRank(rule.MinSeverity) {
+				continue
+			}
+			threshold := rule.AfterFailures
+			if threshold < 1 {
+				threshold = 1
+			}
+			if state.failures < threshold {
+				continue
+			}
+			last, seen := state.notified[rule.Target]
+			if seen && (n.renotify <= 0 || time.Since(last) < n.renotify) {
+				continue
+			}
+			n.send(rule.Target, fmt.Sprintf("%s: %s (%d consecutive failures)",
+				result.Step.Description, result.Status, state.failures))
+			state.notified[rule.Target] = time.Now()
+		}
+
+	case StepSatisfied, StepRepaired:
+		if state == nil {
+			return
+		}
+		targets := make([]string, 0, len(state.notified))
+		for target := range state.notified {
+			targets = append(targets, target)
+		}
+		sort.Strings(targets)
+		for _, target := range targets {
+			n.send(target, fmt.Sprintf("%s: recovered", result.Step.Description))
+		}
+		delete(n.steps, stepID)
+	}
+}
@@ -6,11 +6,14 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/ensurascript/ensura/pkg/ast"
 	"github.com/ensurascript/ensura/pkg/planner"
+	"github.com/ensurascript/ensura/pkg/timewindow"
 )
 
 // HandlerResult represents the result of a handler check or enforce operation.
@@ -21,8 +24,11 @@ type HandlerResult struct {
 }
 
 // Handler is the interface that all handlers must implement.
+// SupportedConditions lists the condition names the handler understands;
+// a nil slice means the handler accepts any condition (e.g. exec, plugin).
 type Handler interface {
 	Name() string
+	SupportedConditions() []string
 	Check(ctx context.Context, subject *ast.ResourceRef, condition string, args map[string]string) HandlerResult
 	Enforce(ctx context.Context, subject *ast.ResourceRef, condition string, args map[string]string) HandlerResult
 }
@@ -55,6 +61,18 @@ func (r *HandlerRegistry) Get(name string) (Handler, bool) {
 	return h, ok
 }
 
+// Capabilities returns the supported conditions of every registered handler,
+// keyed by handler name. Handlers that accept any condition map to nil.
+func (r *HandlerRegistry) Capabilities() map[string][]string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	caps := make(map[string][]string, len(r.handlers))
+	for name, h := range r.handlers {
+		caps[name] = h.SupportedConditions()
+	}
+	return caps
+}
+
 // StepStatus represents the status of a step execution.
 type StepStatus int
 
@@ -64,6 +82,7 @@ const (
 	StepViolated
 	StepRepaired
 	StepFailed
+	StepSuppressed
 )
 
 func (s StepStatus) String() string {
@@ -78,6 +97,8 @@ func (s StepStatus) String() string {
 		return "repaired"
 	case StepFailed:
 		return "failed"
+	case StepSuppressed:
+		return "suppressed"
 	default:
 		return "unknown"
 	}
@@ -94,23 +115,82 @@ type StepResult struct {
 
 // RunResult contains the result of a complete run.
 type RunResult struct {
-	StartTime     time.Time
-	EndTime       time.Time
-	Steps         []*StepResult
-	AllSatisfied  bool
-	TotalChecks   int
-	TotalRepairs  int
-	TotalFailures int
+	StartTime       time.Time
+	EndTime         time.Time
+	Steps           []*StepResult
+	AllSatisfied    bool
+	TotalChecks     int
+	TotalRepairs    int
+	TotalFailures   int
+	TotalSuppressed int
+
+	// FailuresBySeverity counts violated and failed steps keyed by their
+	// severity level (unannotated steps count as "high").
+	FailuresBySeverity map[string]int
+}
+
+// BlockingFailures counts violated and failed steps at severity high or
+// above. Check-style commands use it to decide the exit status: warning
+// and info violations are reported but do not fail the run.
+func (r *RunResult) BlockingFailures() int {
+	n := 0
+	for _, step := range r.Steps {
+		if step.Status != StepViolated && step.Status != StepFailed {
+			continue
+		}
+		if ast.SeverityRank(step.Step.Severity) >= ast.SeverityRank(ast.SeverityHigh) {
+			n++
+		}
+	}
+	return n
+}
+
+// StatusByStep returns each step's status keyed by guarantee ID, in the
+// form graph.VisualizeStatus accepts.
+func (r *RunResult) StatusByStep() map[string]string {
+	status := make(map[string]string, len(r.Steps))
+	for _, step := range r.Steps {
+		status[step.Step.ID] = step.Status.String()
+	}
+	return status
 }
 
 // Config holds runtime configuration.
 type Config struct {
-	Interval   time.Duration // time between enforcement loops
-	MaxRetries int           // default max retries per step
-	DryRun     bool          // if true, only check without enforcing
-	CheckOnly  bool          // if true, run once and exit
-	Redact     bool          // if true, redact secrets in logs
-	Logger     io.Writer     // log output
+	Interval    time.Duration // time between enforcement loops
+	MaxRetries  int           // default max retries per step
+	StepTimeout time.Duration // default per-step timeout (0 = no timeout)
+	DryRun      bool          // if true, only check without enforcing
+	CheckOnly   bool          // if true, run once and exit
+	Redact      bool          // if true, redact secrets in logs
+	Logger      io.Writer     // log output
+	LogFormat   string        // "text" (default) or "json"
+	LogLevel    LogLevel      // minimum level to log (zero value = info)
+	WatchMode   bool          // re-check file guarantees on filesystem events
+
+	// BreakerThreshold is the number of consecutive failed runs after which
+	// a step is suppressed for BreakerCooldown. 0 disables the circuit
+	// breaker.
+	BreakerThreshold int
+	BreakerCooldown  time.Duration
+
+	// NotifyFunc delivers violation notifications; defaults to logging
+	// on Logger.
+	NotifyFunc NotifyFunc
+
+	// RenotifyInterval caps how often a still-violated step re-notifies.
+	// 0 means notifications fire on state transitions only.
+	RenotifyInterval time.Duration
+
+	// MetricsAddr, if non-empty, starts an HTTP listener on this address
+	// serving Prometheus metrics at /metrics.
+	MetricsAddr string
+
+	// OnResult, if set, is invoked after every enforcement pass.
+	OnResult func(*RunResult)
+
+	// Listeners receive runtime events; see Listener.
+	Listeners []Listener
 }
 
 // DefaultConfig returns the default configuration.
@@ -127,10 +207,14 @@ func DefaultConfig() *Config {
 
 // Runtime executes the enforcement loop.
 type Runtime struct {
-	config   *Config
-	registry *HandlerRegistry
-	plan     *planner.Plan
-	mu       sync.Mutex
+	config    *Config
+	registry  *HandlerRegistry
+	plan      *planner.Plan
+	metrics   *Metrics
+	breaker   *breaker
+	notifier  *notifier
+	listeners []Listener
+	mu        sync.Mutex
 }
 
 // New creates a new Runtime.
@@ -138,24 +222,61 @@ func New(plan *planner.Plan, registry *HandlerRegistry, config *Config) *Runtime
 	if config == nil {
 		config = DefaultConfig()
 	}
-	return &Runtime{
-		config:   config,
-		registry: registry,
-		plan:     plan,
+	rt := &Runtime{
+		config:    config,
+		registry:  registry,
+		plan:      plan,
+		metrics:   NewMetrics(config.Interval),
+		listeners: config.Listeners,
+	}
+	if config.BreakerThreshold > 0 {
+		rt.breaker = newBreaker(config.BreakerThreshold, config.BreakerCooldown)
 	}
+	send := config.NotifyFunc
+	if send == nil {
+		send = func(target, message string) {
+			rt.logf(LogWarn, "NOTIFY [%s] %s", target, message)
+		}
+	}
+	rt.notifier = newNotifier(send, config.RenotifyInterval)
+	return rt
+}
+
+// Metrics returns the runtime's metrics collector.
+func (r *Runtime) Metrics() *Metrics {
+	return r.metrics
+}
+
+// SwapPlan atomically replaces the plan used by subsequent enforcement
+// passes. A pass already in progress finishes with the old plan.
+func (r *Runtime) SwapPlan(plan *planner.Plan) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.plan = plan
 }
 
 // Run executes the enforcement loop.
 func (r *Runtime) Run(ctx context.Context) error {
+	if r.config.MetricsAddr != "" {
+		r.startMetricsListener(ctx)
+	}
+
 	if r.config.CheckOnly {
 		result := r.runOnce(ctx)
 		r.printResult(result)
+		if r.config.OnResult != nil {
+			r.config.OnResult(result)
+		}
 		if !result.AllSatisfied {
 			return fmt.Errorf("one or more guarantees not satisfied")
 		}
 		return nil
 	}
 
+	if r.config.WatchMode {
+		return r.runWatch(ctx)
+	}
+
 	// Continuous loop
 	for {
 		select {
@@ -164,6 +285,9 @@ func (r *Runtime) Run(ctx context.Context) error {
 		default:
 			result := r.runOnce(ctx)
 			r.printResult(result)
+			if r.config.OnResult != nil {
+				r.config.OnResult(result)
+			}
 
 			// Wait for next interval
 			select {
@@ -181,6 +305,11 @@ func (r *Runtime) RunOnce(ctx context.Context) *RunResult {
 }
 
 func (r *Runtime) runOnce(ctx context.Context) *RunResult {
+	return r.runPass(ctx, nil)
+}
+
+// runPass executes the steps accepted by filter (nil = all steps).
+func (r *Runtime) runPass(ctx context.Context, filter func(*planner.Step) bool) *RunResult {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
@@ -189,10 +318,38 @@ func (r *Runtime) runOnce(ctx context.Context) *RunResult {
 		Steps:     make([]*StepResult, 0, len(r.plan.Steps)),
 	}
 
+	r.notifyRunStart(r.plan)
+
 	allSatisfied := true
 
 	for _, step := range r.plan.Steps {
+		if filter != nil && !filter(step) {
+			continue
+		}
+		if r.breaker != nil {
+			if until, open := r.breaker.suppressed(step.ID); open {
+				result.Steps = append(result.Steps, &StepResult{
+					Step:    step,
+					Status:  StepSuppressed,
+					Message: fmt.Sprintf("suppressed until %s after repeated failures", until.Format(time.RFC3339)),
+				})
+				result.TotalSuppressed++
+				allSatisfied = false
+				continue
+			}
+		}
+
+		r.notifyStepStart(step)
 		stepResult := r.executeStep(ctx, step)
+		r.notifyStepComplete(stepResult)
+		if r.breaker != nil {
+			r.breaker.observe(step.ID, stepResult.Status)
+		}
+		violationHandler := step.Guarantee.Statement.ViolationHandler
+		if violationHandler == nil {
+			violationHandler = r.plan.GlobalViolation
+		}
+		r.notifier.observe(stepResult, violationHandler)
 		result.Steps = append(result.Steps, stepResult)
 		result.TotalChecks++
 
@@ -204,6 +361,14 @@ func (r *Runtime) runOnce(ctx context.Context) *RunResult {
 		case StepViolated, StepFailed:
 			allSatisfied = false
 			result.TotalFailures++
+			if result.FailuresBySeverity == nil {
+				result.FailuresBySeverity = make(map[string]int)
+			}
+			severity := step.Severity
+			if severity == "" {
+				severity = ast.SeverityHigh
+			}
+			result.FailuresBySeverity[severity]++
 			// For sequential execution, we continue but track failures
 		}
 
@@ -212,6 +377,8 @@ func (r *Runtime) runOnce(ctx context.Context) *RunResult {
 		case <-ctx.Done():
 			result.EndTime = time.Now()
 			result.AllSatisfied = false
+			r.metrics.ObserveRun(result)
+			r.notifyRunComplete(result)
 			return result
 		default:
 		}
@@ -219,6 +386,8 @@ func (r *Runtime) runOnce(ctx context.Context) *RunResult {
 
 	result.EndTime = time.Now()
 	result.AllSatisfied = allSatisfied
+	r.metrics.ObserveRun(result)
+	r.notifyRunComplete(result)
 	return result
 }
 
@@ -227,6 +396,18 @@ func (r *Runtime) executeStep(ctx context.Context, step *planner.Step) *StepResu
 		Step: step,
 	}
 
+	// Apply per-step timeout, falling back to the configured default, so a
+	// hanging handler cannot stall the whole loop.
+	timeout := step.Timeout
+	if timeout == 0 {
+		timeout = r.config.StepTimeout
+	}
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
 	// Get handler
 	handler, ok := r.registry.Get(step.Handler)
 	if !ok {
@@ -235,10 +416,53 @@ func (r *Runtime) executeStep(ctx context.Context, step *planner.Step) *StepResu
 		return result
 	}
 
-	// Get subject
-	subject := step.Guarantee.Statement.Subject
+	// Resolve subjects: a for-each iterator expands to the container's
+	// current entries at enforcement time
+	subjects, err := resolveSubjects(step)
+	if err != nil {
+		result.Status = StepFailed
+		result.Error = err
+		return result
+	}
+	if len(subjects) == 0 {
+		result.Status = StepSatisfied
+		result.Message = fmt.Sprintf("no entries match in %s", step.Guarantee.Statement.Subject.Container.Path)
+		return result
+	}
+	if len(subjects) == 1 {
+		return r.enforceSubject(ctx, handler, step, subjects[0])
+	}
+
+	// Aggregate per-entry results; the worst status wins
+	result.Status = StepSatisfied
+	satisfied := 0
+	for _, subject := range subjects {
+		sub := r.enforceSubject(ctx, handler, step, subject)
+		result.Attempts += sub.Attempts
+		if sub.Status == StepSatisfied || sub.Status == StepRepaired {
+			satisfied++
+		}
+		if statusRank(sub.Status) > statusRank(result.Status) {
+			result.Status = sub.Status
+			result.Message = fmt.Sprintf("%s: %s", subject.Path, sub.Message)
+			result.Error = sub.Error
+		}
+	}
+	if result.Status == StepSatisfied {
+		result.Message = fmt.Sprintf("%d entries satisfied", satisfied)
+	}
+	return result
+}
+
+// enforceSubject runs the check-then-repair loop for one concrete subject.
+func (r *Runtime) enforceSubject(ctx context.Context, handler Handler, step *planner.Step, subject *ast.ResourceRef) *StepResult {
+	result := &StepResult{
+		Step: step,
+	}
 
 	// Check
+	r.logf(LogDebug, "checking %s via %s args=%v",
+		step.Description, step.Handler, redactArgs(step.HandlerArgs, r.config.Redact))
 	checkResult := handler.Check(ctx, subject, step.Guarantee.Statement.Condition, step.HandlerArgs)
 	result.Attempts++
 
@@ -256,6 +480,22 @@ func (r *Runtime) executeStep(ctx context.Context, step *planner.Step) *StepResu
 		return result
 	}
 
+	// Repairs declared with a during clause only run inside their
+	// maintenance window; the violation is still reported.
+	if step.During != "" {
+		window, err := timewindow.Parse(step.During)
+		if err != nil {
+			result.Status = StepFailed
+			result.Error = fmt.Errorf("invalid maintenance window %q: %w", step.During, err)
+			return result
+		}
+		if !window.Contains(time.Now()) {
+			r.logf(LogDebug, "repair of %s deferred: outside maintenance window %q", step.Description, step.During)
+			result.Message = fmt.Sprintf("%s (repair deferred until window %q)", checkResult.Message, step.During)
+			return result
+		}
+	}
+
 	// Get retry count
 	maxRetries := r.config.MaxRetries
 	if step.Guarantee.Statement.ViolationHandler != nil && step.Guarantee.Statement.ViolationHandler.Retry > 0 {
@@ -268,8 +508,10 @@ func (r *Runtime) executeStep(ctx context.Context, step *planner.Step) *StepResu
 	for attempt := 0; attempt < maxRetries; attempt++ {
 		result.Attempts++
 
+		r.logf(LogDebug, "repair attempt %d/%d for %s", attempt+1, maxRetries, step.Description)
 		enforceResult := handler.Enforce(ctx, subject, step.Guarantee.Statement.Condition, step.HandlerArgs)
 		if enforceResult.Error != nil {
+			r.logf(LogDebug, "repair attempt %d for %s failed: %v", attempt+1, step.Description, enforceResult.Error)
 			result.Error = enforceResult.Error
 			continue
 		}
@@ -288,31 +530,149 @@ func (r *Runtime) executeStep(ctx context.Context, step *planner.Step) *StepResu
 	return result
 }
 
+// resolveSubjects returns the concrete subjects a step runs against. A
+// for-each iterator expands to the container's entries, honoring the
+// loop's matching and excluding globs.
+func resolveSubjects(step *planner.Step) ([]*ast.ResourceRef, error) {
+	subject := step.Guarantee.Statement.Subject
+	if subject == nil || !subject.IsIterator || subject.Container == nil {
+		return []*ast.ResourceRef{subject}, nil
+	}
+
+	paths, err := expandContainer(subject.Container)
+	if err != nil {
+		return nil, err
+	}
+
+	var subjects []*ast.ResourceRef
+	for _, path := range paths {
+		info, err := os.Lstat(path)
+		if err != nil {
+			// The entry disappeared between listing and stat; skip it.
+			continue
+		}
+		if info.IsDir() != (subject.ResourceType == "directory") {
+			continue
+		}
+		if !matchesFilters(filepath.Base(path), subject.Matching, subject.Excluding) {
+			continue
+		}
+		item := *subject
+		item.Path = path
+		item.IsIterator = false
+		item.Container = nil
+		subjects = append(subjects, &item)
+	}
+	return subjects, nil
+}
+
+// expandContainer lists the candidate paths inside a for-each container.
+// A glob container matches its pattern directly, while a directory
+// container lists its entries; a directory path may itself contain glob
+// characters and then covers every directory it matches.
+func expandContainer(container *ast.ResourceRef) ([]string, error) {
+	if container.ResourceType == "glob" {
+		paths, err := filepath.Glob(container.Path)
+		if err != nil {
+			return nil, fmt.Errorf("cannot expand for-each over %s: %w", container.Path, err)
+		}
+		return paths, nil
+	}
+
+	dirs := []string{container.Path}
+	if strings.ContainsAny(container.Path, "*?[") {
+		var err error
+		dirs, err = filepath.Glob(container.Path)
+		if err != nil {
+			return nil, fmt.Errorf("cannot expand for-each over %s: %w", container.Path, err)
+		}
+	}
+
+	var paths []string
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return nil, fmt.Errorf("cannot expand for-each over %s: %w", dir, err)
+		}
+		for _, entry := range entries {
+			paths = append(paths, filepath.Join(dir, entry.Name()))
+		}
+	}
+	return paths, nil
+}
+
+// matchesFilters applies for-each glob filters to an entry name. An
+// empty matching list keeps everything; excluding always wins.
+func matchesFilters(name string, matching, excluding []string) bool {
+	if len(matching) > 0 {
+		kept := false
+		for _, pat := range matching {
+			if ok, _ := filepath.Match(pat, name); ok {
+				kept = true
+				break
+			}
+		}
+		if !kept {
+			return false
+		}
+	}
+	for _, pat := range excluding {
+		if ok, _ := filepath.Match(pat, name); ok {
+			return false
+		}
+	}
+	return true
+}
+
+// statusRank orders step statuses from best to worst so aggregated
+// for-each results surface the most serious outcome.
+func statusRank(s StepStatus) int {
+	switch s {
+	case StepSatisfied:
+		return 0
+	case StepRepaired:
+		return 1
+	case StepViolated:
+		return 2
+	case StepFailed:
+		return 3
+	}
+	return 0
+}
+
 func (r *Runtime) printResult(result *RunResult) {
 	w := r.config.Logger
 	if w == nil {
 		return
 	}
 
+	if r.config.LogFormat == "json" {
+		r.printResultJSON(w, result)
+		return
+	}
+
 	duration := result.EndTime.Sub(result.StartTime)
 
-	fmt.Fprintf(w, "\n[%s] Enforcement run completed in %v\n",
+	r.logf(LogInfo, "\n[%s] Enforcement run completed in %v",
 		result.EndTime.Format(time.RFC3339), duration)
-	fmt.Fprintf(w, "  Checks: %d, Repairs: %d, Failures: %d\n",
+	r.logf(LogInfo, "  Checks: %d, Repairs: %d, Failures: %d",
 		result.TotalChecks, result.TotalRepairs, result.TotalFailures)
+	if result.TotalSuppressed > 0 {
+		r.logf(LogInfo, "  Suppressed: %d", result.TotalSuppressed)
+	}
 
 	if result.AllSatisfied {
-		fmt.Fprintf(w, "  Status: ALL SATISFIED\n")
+		r.logf(LogInfo, "  Status: ALL SATISFIED")
 	} else {
-		fmt.Fprintf(w, "  Status: VIOLATIONS DETECTED\n")
+		r.logf(LogWarn, "  Status: VIOLATIONS DETECTED")
 		for _, step := range result.Steps {
-			if step.Status == StepViolated || step.Status == StepFailed {
-				fmt.Fprintf(w, "    - %s: %s\n", step.Step.Description, step.Status)
+			if step.Status == StepViolated || step.Status == StepFailed || step.Status == StepSuppressed {
+				r.logf(LogWarn, "    - %s: %s", step.Step.Description, step.Status)
 				if step.Message != "" {
-					fmt.Fprintf(w, "      Message: %s\n", step.Message)
+					r.logf(LogWarn, "      Message: %s", step.Message)
 				}
 				if step.Error != nil {
-					fmt.Fprintf(w, "      Error: %v\n", step.Error)
+					r.logf(LogWarn, "      Error: %v", step.Error)
 				}
 			}
 		}
@@ -0,0 +1,92 @@
+package runtime
+
+import (
+	"context"
+	"time"
+
+	"github.com/ensurascript/ensura/pkg/planner"
+)
+
+// RunEventType discriminates the events on a RunStream channel.
+type RunEventType int
+
+const (
+	// EventRunStart marks the beginning of an enforcement pass.
+	EventRunStart RunEventType = iota
+	// EventStep carries the result of one executed step.
+	EventStep
+	// EventRunEnd marks the end of an enforcement pass and carries its
+	// aggregate result.
+	EventRunEnd
+)
+
+func (t RunEventType) String() string {
+	switch t {
+	case EventRunStart:
+		return "run_start"
+	case EventStep:
+		return "step"
+	case EventRunEnd:
+		return "run_end"
+	default:
+		return "unknown"
+	}
+}
+
+// RunEvent is one event on the stream returned by RunStream.
+type RunEvent struct {
+	Type RunEventType
+	Time time.Time
+	Step *StepResult // set for EventStep
+	Run  *RunResult  // set for EventRunEnd
+}
+
+// streamListener forwards runtime events onto a channel until stopped.
+type streamListener struct {
+	BaseListener
+	events chan<- RunEvent
+	stop   chan struct{}
+}
+
+func (l *streamListener) send(ev RunEvent) {
+	ev.Time = time.Now()
+	select {
+	case <-l.stop:
+	case l.events <- ev:
+	}
+}
+
+func (l *streamListener) OnRunStart(plan *planner.Plan) {
+	l.send(RunEvent{Type: EventRunStart})
+}
+
+func (l *streamListener) OnStepComplete(result *StepResult) {
+	l.send(RunEvent{Type: EventStep, Step: result})
+}
+
+func (l *streamListener) OnRunComplete(result *RunResult) {
+	l.send(RunEvent{Type: EventRunEnd, Run: result})
+}
+
+// RunStream starts the enforcement loop and returns a channel of its
+// events: a run start marker, one event per executed step, and a run end
+// marker carrying the pass's RunResult, repeating each interval until
+// ctx is cancelled (or after one pass in check-only mode). The channel
+// is closed when the loop stops; the caller must drain it, since the
+// loop blocks on slow consumers.
+func (r *Runtime) RunStream(ctx context.Context) <-chan RunEvent {
+	events := make(chan RunEvent, 1)
+	listener := &streamListener{
+		events: events,
+		stop:   make(chan struct{}),
+	}
+	r.AddListener(listener)
+
+	go func() {
+		defer close(events)
+		r.Run(ctx)
+		close(listener.stop)
+	}()
+
+	return events
+}
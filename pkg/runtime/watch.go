@@ -0,0 +1,111 @@
+package runtime
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ensurascript/ensura/pkg/planner"
+)
+
+// watchedPaths returns the filesystem paths backing file and directory
+// guarantees in the current plan.
+func (r *Runtime) watchedPaths() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	seen := make(map[string]bool)
+	var paths []string
+	for _, step := range r.plan.Steps {
+		subject := step.Guarantee.Statement.Subject
+		if subject == nil || subject.Path == "" {
+			continue
+		}
+		if subject.ResourceType != "file" && subject.ResourceType != "directory" {
+			continue
+		}
+		if !seen[subject.Path] {
+			seen[subject.Path] = true
+			paths = append(paths, subject.Path)
+		}
+	}
+	return paths
+}
+
+// pathMatches reports whether a changed path affects the given subject path:
+// either the path itself or something underneath it.
+func pathMatches(subjectPath, changed string) bool {
+	if subjectPath == changed {
+		return true
+	}
+	return strings.HasPrefix(changed, strings.TrimSuffix(subjectPath, "/")+"/")
+}
+
+// runWatch re-checks file-backed guarantees as soon as their paths change,
+// while keeping the regular interval pass for everything else.
+func (r *Runtime) runWatch(ctx context.Context) error {
+	events := make(chan string, 64)
+	if paths := r.watchedPaths(); len(paths) > 0 {
+		if err := watchPaths(ctx, paths, events); err != nil {
+			return fmt.Errorf("watch mode unavailable: %w", err)
+		}
+	}
+
+	// Initial full pass so we start from a known state.
+	result := r.runOnce(ctx)
+	r.printResult(result)
+	if r.config.OnResult != nil {
+		r.config.OnResult(result)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+
+		case path := <-events:
+			// Coalesce bursts of events (editors often touch a file
+			// several times in quick succession).
+			changed := map[string]bool{path: true}
+			settle := time.After(200 * time.Millisecond)
+		drain:
+			for {
+				select {
+				case p := <-events:
+					changed[p] = true
+				case <-settle:
+					break drain
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+
+			result := r.runPass(ctx, func(step *planner.Step) bool {
+				subject := step.Guarantee.Statement.Subject
+				if subject == nil {
+					return false
+				}
+				for p := range changed {
+					if pathMatches(subject.Path, p) {
+						return true
+					}
+				}
+				return false
+			})
+			if len(result.Steps) > 0 {
+				r.printResult(result)
+				if r.config.OnResult != nil {
+					r.config.OnResult(result)
+				}
+			}
+
+		case <-time.After(r.config.Interval):
+			result := r.runOnce(ctx)
+			r.printResult(result)
+			if r.config.OnResult != nil {
+				r.config.OnResult(result)
+			}
+		}
+	}
+}
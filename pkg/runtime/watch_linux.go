@@ -0,0 +1,75 @@
+//go:build linux
+
+package runtime
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"unsafe"
+)
+
+// watchPaths delivers the path of each filesystem change affecting the
+// given paths on the events channel until ctx is done. It talks to inotify
+// directly so no external dependency is needed.
+func watchPaths(ctx context.Context, paths []string, events chan<- string) error {
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC)
+	if err != nil {
+		return fmt.Errorf("inotify init: %w", err)
+	}
+
+	// Watch the parent directory of each file so delete/recreate cycles
+	// are still observed; directories are watched directly.
+	const mask = syscall.IN_MODIFY | syscall.IN_ATTRIB | syscall.IN_CREATE |
+		syscall.IN_DELETE | syscall.IN_MOVED_FROM | syscall.IN_MOVED_TO
+
+	watched := make(map[int32]string)
+	for _, path := range paths {
+		dir := path
+		if info, err := os.Stat(path); err != nil || !info.IsDir() {
+			dir = filepath.Dir(path)
+		}
+		wd, err := syscall.InotifyAddWatch(fd, dir, mask)
+		if err != nil {
+			syscall.Close(fd)
+			return fmt.Errorf("watch %s: %w", dir, err)
+		}
+		watched[int32(wd)] = dir
+	}
+
+	// Closing the descriptor unblocks the reader below.
+	go func() {
+		<-ctx.Done()
+		syscall.Close(fd)
+	}()
+
+	go func() {
+		buf := make([]byte, 4096)
+		for {
+			n, err := syscall.Read(fd, buf)
+			if err != nil {
+				return
+			}
+			offset := 0
+			for offset+syscall.SizeofInotifyEvent <= n {
+				event := (*syscall.InotifyEvent)(unsafe.Pointer(&buf[offset]))
+				path := watched[event.Wd]
+				if event.Len > 0 {
+					name := buf[offset+syscall.SizeofInotifyEvent : offset+syscall.SizeofInotifyEvent+int(event.Len)]
+					path = filepath.Join(path, strings.TrimRight(string(name), "\x00"))
+				}
+				select {
+				case events <- path:
+				case <-ctx.Done():
+					return
+				}
+				offset += syscall.SizeofInotifyEvent + int(event.Len)
+			}
+		}
+	}()
+
+	return nil
+}
@@ -0,0 +1,58 @@
+//go:build !linux
+
+package runtime
+
+import (
+	"context"
+	"os"
+	"time"
+)
+
+// watchPaths falls back to polling modification times on platforms without
+// inotify, checking once per second.
+func watchPaths(ctx context.Context, paths []string, events chan<- string) error {
+	type stamp struct {
+		exists  bool
+		size    int64
+		modTime time.Time
+	}
+
+	snapshot := func(path string) stamp {
+		info, err := os.Stat(path)
+		if err != nil {
+			return stamp{}
+		}
+		return stamp{exists: true, size: info.Size(), modTime: info.ModTime()}
+	}
+
+	last := make(map[string]stamp, len(paths))
+	for _, path := range paths {
+		last[path] = snapshot(path)
+	}
+
+	go func() {
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				for _, path := range paths {
+					current := snapshot(path)
+					if current == last[path] {
+						continue
+					}
+					last[path] = current
+					select {
+					case events <- path:
+					case <-ctx.Done():
+						return
+					}
+				}
+			}
+		}
+	}()
+
+	return nil
+}
@@ -0,0 +1,36 @@
+package secrets
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// awsProvider resolves aws:secret-id references through AWS Secrets Manager
+// via the aws CLI, which carries its own credential chain (instance profile,
+// SSO, environment). Shelling out avoids vendoring the SDK.
+type awsProvider struct{}
+
+func (p *awsProvider) Scheme() string {
+	return "aws"
+}
+
+func (p *awsProvider) Resolve(ref string) ([]byte, error) {
+	if ref == "" {
+		return nil, fmt.Errorf("aws reference has no secret id")
+	}
+	if _, err := exec.LookPath("aws"); err != nil {
+		return nil, fmt.Errorf("aws CLI not found in PATH")
+	}
+
+	cmd := exec.Command("aws", "secretsmanager", "get-secret-value",
+		"--secret-id", ref, "--query", "SecretString", "--output", "text")
+	output, err := cmd.Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return nil, fmt.Errorf("aws secretsmanager failed: %s", strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return nil, err
+	}
+	return []byte(strings.TrimSuffix(string(output), "\n")), nil
+}
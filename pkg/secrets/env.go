@@ -0,0 +1,21 @@
+package secrets
+
+import (
+	"fmt"
+	"os"
+)
+
+// envProvider resolves env:NAME references from the process environment.
+type envProvider struct{}
+
+func (p *envProvider) Scheme() string {
+	return "env"
+}
+
+func (p *envProvider) Resolve(ref string) ([]byte, error) {
+	value := os.Getenv(ref)
+	if value == "" {
+		return nil, fmt.Errorf("environment variable %s is not set", ref)
+	}
+	return []byte(value), nil
+}
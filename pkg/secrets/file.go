@@ -0,0 +1,21 @@
+package secrets
+
+import (
+	"fmt"
+	"os"
+)
+
+// fileProvider resolves file:/path references by reading the file.
+type fileProvider struct{}
+
+func (p *fileProvider) Scheme() string {
+	return "file"
+}
+
+func (p *fileProvider) Resolve(ref string) ([]byte, error) {
+	data, err := os.ReadFile(ref)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key file: %w", err)
+	}
+	return data, nil
+}
@@ -0,0 +1,40 @@
+package secrets
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// gcpProvider resolves gcp:secret-name references through GCP Secret Manager
+// via the gcloud CLI. An optional #version suffix selects a version
+// (default latest).
+type gcpProvider struct{}
+
+func (p *gcpProvider) Scheme() string {
+	return "gcp"
+}
+
+func (p *gcpProvider) Resolve(ref string) ([]byte, error) {
+	if ref == "" {
+		return nil, fmt.Errorf("gcp reference has no secret name")
+	}
+	if _, err := exec.LookPath("gcloud"); err != nil {
+		return nil, fmt.Errorf("gcloud CLI not found in PATH")
+	}
+
+	name, version, _ := strings.Cut(ref, "#")
+	if version == "" {
+		version = "latest"
+	}
+
+	cmd := exec.Command("gcloud", "secrets", "versions", "access", version, "--secret", name)
+	output, err := cmd.Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return nil, fmt.Errorf("gcloud secrets access failed: %s", strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return nil, err
+	}
+	return output, nil
+}
@@ -0,0 +1,64 @@
+// Package secrets resolves secret references for handlers that take key or
+// credential arguments. A reference is a scheme-prefixed string such as
+// env:API_KEY, file:/etc/key, or vault:secret/data/app#token; references
+// without a scheme resolve to their literal value.
+package secrets
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Provider resolves references for a single scheme.
+type Provider interface {
+	// Scheme returns the reference prefix this provider handles, without
+	// the trailing colon (e.g. "env", "vault").
+	Scheme() string
+	// Resolve returns the secret value for the reference with the scheme
+	// prefix already stripped.
+	Resolve(ref string) ([]byte, error)
+}
+
+// Resolver dispatches secret references to registered providers.
+type Resolver struct {
+	providers map[string]Provider
+}
+
+// NewResolver creates a resolver with all built-in providers.
+func NewResolver() *Resolver {
+	r := &Resolver{
+		providers: make(map[string]Provider),
+	}
+	r.Register(&envProvider{})
+	r.Register(&fileProvider{})
+	r.Register(&vaultProvider{})
+	r.Register(&awsProvider{})
+	r.Register(&gcpProvider{})
+	return r
+}
+
+// Register adds a provider, replacing any previous one for the same scheme.
+func (r *Resolver) Register(p Provider) {
+	r.providers[p.Scheme()] = p
+}
+
+// Resolve looks up the provider for the reference's scheme and resolves it.
+// References without a colon are returned as literal values.
+func (r *Resolver) Resolve(ref string) ([]byte, error) {
+	if ref == "" {
+		return nil, fmt.Errorf("secret reference is empty")
+	}
+
+	scheme, rest, found := strings.Cut(ref, ":")
+	if !found {
+		return []byte(ref), nil
+	}
+
+	provider, ok := r.providers[scheme]
+	if !ok {
+		// Unknown prefixes (e.g. a Windows path like C:\key) are treated
+		// as literals rather than rejected.
+		return []byte(ref), nil
+	}
+	return provider.Resolve(rest)
+}
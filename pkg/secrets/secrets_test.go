@@ -0,0 +1,119 @@
+package secrets
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolveLiteral(t *testing.T) {
+	r := NewResolver()
+
+	value, err := r.Resolve("plain-value")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(value) != "plain-value" {
+		t.Errorf("Expected literal value, got %q", value)
+	}
+
+	// Unknown schemes fall through as literals
+	value, err = r.Resolve("weird:thing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(value) != "weird:thing" {
+		t.Errorf("Expected unknown scheme to resolve literally, got %q", value)
+	}
+
+	if _, err := r.Resolve(""); err == nil {
+		t.Error("Expected error for empty reference")
+	}
+}
+
+func TestResolveEnv(t *testing.T) {
+	r := NewResolver()
+	t.Setenv("ENSURA_TEST_SECRET", "s3cret")
+
+	value, err := r.Resolve("env:ENSURA_TEST_SECRET")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(value) != "s3cret" {
+		t.Errorf("Expected env value, got %q", value)
+	}
+
+	if _, err := r.Resolve("env:ENSURA_TEST_UNSET"); err == nil {
+		t.Error("Expected error for unset variable")
+	}
+}
+
+func TestResolveFile(t *testing.T) {
+	r := NewResolver()
+
+	tmpDir := t.TempDir()
+	keyFile := filepath.Join(tmpDir, "key")
+	if err := os.WriteFile(keyFile, []byte("file-key"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	value, err := r.Resolve("file:" + keyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(value) != "file-key" {
+		t.Errorf("Expected file contents, got %q", value)
+	}
+
+	if _, err := r.Resolve("file:" + filepath.Join(tmpDir, "missing")); err == nil {
+		t.Error("Expected error for missing key file")
+	}
+}
+
+func TestResolveVault(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("X-Vault-Token") != "test-token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch req.URL.Path {
+		case "/v1/secret/app":
+			w.Write([]byte(`{"data": {"token": "kv1-value"}}`))
+		case "/v1/secret/data/app":
+			w.Write([]byte(`{"data": {"data": {"token": "kv2-value"}}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	t.Setenv("VAULT_ADDR", server.URL)
+	t.Setenv("VAULT_TOKEN", "test-token")
+
+	r := NewResolver()
+
+	value, err := r.Resolve("vault:secret/app#token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(value) != "kv1-value" {
+		t.Errorf("Expected KV v1 value, got %q", value)
+	}
+
+	value, err = r.Resolve("vault:secret/data/app#token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(value) != "kv2-value" {
+		t.Errorf("Expected KV v2 value, got %q", value)
+	}
+
+	if _, err := r.Resolve("vault:secret/app#missing"); err == nil {
+		t.Error("Expected error for missing field")
+	}
+	if _, err := r.Resolve("vault:secret/other"); err == nil {
+		t.Error("Expected error for missing secret")
+	}
+}
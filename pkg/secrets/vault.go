@@ -0,0 +1,95 @@
+package secrets
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// vaultProvider resolves vault:path#field references against a HashiCorp
+// Vault server using its HTTP API. The server address and token come from
+// the standard VAULT_ADDR and VAULT_TOKEN environment variables.
+type vaultProvider struct {
+	client *http.Client
+}
+
+func (p *vaultProvider) Scheme() string {
+	return "vault"
+}
+
+func (p *vaultProvider) Resolve(ref string) ([]byte, error) {
+	addr := os.Getenv("VAULT_ADDR")
+	if addr == "" {
+		return nil, fmt.Errorf("VAULT_ADDR is not set")
+	}
+	token := os.Getenv("VAULT_TOKEN")
+	if token == "" {
+		return nil, fmt.Errorf("VAULT_TOKEN is not set")
+	}
+
+	path, field, _ := strings.Cut(ref, "#")
+	if path == "" {
+		return nil, fmt.Errorf("vault reference has no path")
+	}
+
+	if p.client == nil {
+		p.client = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	url := strings.TrimSuffix(addr, "/") + "/v1/" + strings.TrimPrefix(path, "/")
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Vault-Token", token)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("vault request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("vault returned status %d for %s", resp.StatusCode, path)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var payload struct {
+		Data map[string]json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("invalid vault response: %w", err)
+	}
+
+	fields := payload.Data
+	// KV version 2 nests the secret under data.data.
+	if nested, ok := fields["data"]; ok {
+		var inner map[string]json.RawMessage
+		if err := json.Unmarshal(nested, &inner); err == nil && len(inner) > 0 {
+			fields = inner
+		}
+	}
+
+	if field == "" {
+		field = "value"
+	}
+	raw, ok := fields[field]
+	if !ok {
+		return nil, fmt.Errorf("field %q not found in vault secret %s", field, path)
+	}
+
+	var value string
+	if err := json.Unmarshal(raw, &value); err != nil {
+		// Non-string fields are returned as their JSON encoding.
+		return []byte(raw), nil
+	}
+	return []byte(value), nil
+}
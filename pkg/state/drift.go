@@ -0,0 +1,124 @@
+package state
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// StepDrift summarizes how a single guarantee behaved across runs.
+type StepDrift struct {
+	Description string `json:"description"`
+	Runs        int    `json:"runs"`
+	Satisfied   int    `json:"satisfied"`
+	Violated    int    `json:"violated"`
+	Repaired    int    `json:"repaired"`
+	Transitions int    `json:"transitions"` // flips between satisfied and violated
+	Flapping    bool   `json:"flapping"`
+}
+
+// DriftReport summarizes guarantee stability across enforcement runs.
+type DriftReport struct {
+	Runs  int         `json:"runs"`
+	Steps []StepDrift `json:"steps"`
+}
+
+// FlappingThreshold is the number of state transitions after which a
+// guarantee is considered flapping.
+const FlappingThreshold = 2
+
+// Drift analyzes run records and reports guarantees that flip between
+// satisfied and violated, and how often each was repaired. Records may be
+// in any order; they are analyzed oldest first.
+func Drift(records []*RunRecord) *DriftReport {
+	ordered := make([]*RunRecord, len(records))
+	copy(ordered, records)
+	sort.Slice(ordered, func(i, j int) bool {
+		return ordered[i].StartTime.Before(ordered[j].StartTime)
+	})
+
+	type history struct {
+		drift   StepDrift
+		lastOK  bool
+		hasLast bool
+	}
+
+	byStep := make(map[string]*history)
+	var order []string
+
+	for _, record := range ordered {
+		for _, step := range record.Steps {
+			h, ok := byStep[step.Description]
+			if !ok {
+				h = &history{drift: StepDrift{Description: step.Description}}
+				byStep[step.Description] = h
+				order = append(order, step.Description)
+			}
+
+			h.drift.Runs++
+			stepOK := false
+			switch step.Status {
+			case "satisfied":
+				h.drift.Satisfied++
+				stepOK = true
+			case "repaired":
+				// A repair means the guarantee had drifted since the last
+				// pass, even though the run ended with it satisfied.
+				h.drift.Repaired++
+				h.drift.Transitions++
+				stepOK = true
+			default:
+				h.drift.Violated++
+			}
+
+			if h.hasLast && h.lastOK != stepOK {
+				h.drift.Transitions++
+			}
+			h.lastOK = stepOK
+			h.hasLast = true
+		}
+	}
+
+	report := &DriftReport{Runs: len(ordered)}
+	for _, desc := range order {
+		h := byStep[desc]
+		h.drift.Flapping = h.drift.Transitions >= FlappingThreshold
+		report.Steps = append(report.Steps, h.drift)
+	}
+
+	// Most unstable guarantees first
+	sort.SliceStable(report.Steps, func(i, j int) bool {
+		return report.Steps[i].Transitions > report.Steps[j].Transitions
+	})
+
+	return report
+}
+
+// String returns a human-readable representation of the drift report.
+func (r *DriftReport) String() string {
+	var out strings.Builder
+
+	out.WriteString(fmt.Sprintf("Drift Report (%d runs)\n", r.Runs))
+	out.WriteString("======================\n\n")
+
+	if len(r.Steps) == 0 {
+		out.WriteString("No step history recorded.\n")
+		return out.String()
+	}
+
+	for _, step := range r.Steps {
+		marker := "  "
+		if step.Flapping {
+			marker = "! "
+		}
+		out.WriteString(fmt.Sprintf("%s%s\n", marker, step.Description))
+		out.WriteString(fmt.Sprintf("      satisfied=%d violated=%d repaired=%d transitions=%d",
+			step.Satisfied, step.Violated, step.Repaired, step.Transitions))
+		if step.Flapping {
+			out.WriteString("  FLAPPING")
+		}
+		out.WriteString("\n")
+	}
+
+	return out.String()
+}
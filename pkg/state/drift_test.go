@@ -0,0 +1,99 @@
+package state
+
+import (
+	"testing"
+	"time"
+)
+
+func runWithStatus(start time.Time, desc, status string) *RunRecord {
+	return &RunRecord{
+		StartTime: start,
+		EndTime:   start.Add(time.Second),
+		Steps: []StepRecord{
+			{Description: desc, Status: status},
+		},
+	}
+}
+
+func TestDriftStable(t *testing.T) {
+	base := time.Now()
+	records := []*RunRecord{
+		runWithStatus(base, "Ensure exists", "satisfied"),
+		runWithStatus(base.Add(time.Minute), "Ensure exists", "satisfied"),
+		runWithStatus(base.Add(2*time.Minute), "Ensure exists", "satisfied"),
+	}
+
+	report := Drift(records)
+	if len(report.Steps) != 1 {
+		t.Fatalf("Expected 1 step, got %d", len(report.Steps))
+	}
+	step := report.Steps[0]
+	if step.Transitions != 0 {
+		t.Errorf("Expected 0 transitions, got %d", step.Transitions)
+	}
+	if step.Flapping {
+		t.Error("Stable guarantee should not be flapping")
+	}
+}
+
+func TestDriftFlapping(t *testing.T) {
+	base := time.Now()
+	records := []*RunRecord{
+		runWithStatus(base, "Ensure exists", "satisfied"),
+		runWithStatus(base.Add(time.Minute), "Ensure exists", "violated"),
+		runWithStatus(base.Add(2*time.Minute), "Ensure exists", "satisfied"),
+		runWithStatus(base.Add(3*time.Minute), "Ensure exists", "violated"),
+	}
+
+	report := Drift(records)
+	step := report.Steps[0]
+	if step.Transitions < FlappingThreshold {
+		t.Errorf("Expected at least %d transitions, got %d", FlappingThreshold, step.Transitions)
+	}
+	if !step.Flapping {
+		t.Error("Expected guarantee to be flagged as flapping")
+	}
+}
+
+func TestDriftCountsRepairs(t *testing.T) {
+	base := time.Now()
+	records := []*RunRecord{
+		runWithStatus(base, "Ensure exists", "satisfied"),
+		runWithStatus(base.Add(time.Minute), "Ensure exists", "repaired"),
+		runWithStatus(base.Add(2*time.Minute), "Ensure exists", "repaired"),
+	}
+
+	report := Drift(records)
+	step := report.Steps[0]
+	if step.Repaired != 2 {
+		t.Errorf("Expected 2 repairs, got %d", step.Repaired)
+	}
+	if !step.Flapping {
+		t.Error("Repeatedly repaired guarantee should be flapping")
+	}
+}
+
+func TestDriftOrdersUnstableFirst(t *testing.T) {
+	base := time.Now()
+	records := []*RunRecord{
+		{
+			StartTime: base,
+			Steps: []StepRecord{
+				{Description: "stable", Status: "satisfied"},
+				{Description: "unstable", Status: "satisfied"},
+			},
+		},
+		{
+			StartTime: base.Add(time.Minute),
+			Steps: []StepRecord{
+				{Description: "stable", Status: "satisfied"},
+				{Description: "unstable", Status: "violated"},
+			},
+		},
+	}
+
+	report := Drift(records)
+	if report.Steps[0].Description != "unstable" {
+		t.Errorf("Expected unstable guarantee first, got %q", report.Steps[0].Description)
+	}
+}
@@ -0,0 +1,149 @@
+// Package state persists run history for EnsuraScript enforcement runs.
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ensurascript/ensura/pkg/runtime"
+)
+
+// StepRecord is the persisted form of a single step result.
+type StepRecord struct {
+	Description string `json:"description"`
+	Status      string `json:"status"`
+	Attempts    int    `json:"attempts"`
+	Message     string `json:"message,omitempty"`
+	Error       string `json:"error,omitempty"`
+}
+
+// RunRecord is the persisted form of a complete enforcement run.
+type RunRecord struct {
+	ID            string       `json:"id"`
+	Source        string       `json:"source,omitempty"`
+	StartTime     time.Time    `json:"startTime"`
+	EndTime       time.Time    `json:"endTime"`
+	AllSatisfied  bool         `json:"allSatisfied"`
+	TotalChecks   int          `json:"totalChecks"`
+	TotalRepairs  int          `json:"totalRepairs"`
+	TotalFailures int          `json:"totalFailures"`
+	Steps         []StepRecord `json:"steps"`
+}
+
+// Store records run history as one JSON file per run in a directory.
+type Store struct {
+	dir string
+}
+
+// DefaultDir returns the default history directory under the user's home.
+func DefaultDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(".", ".ensura", "history")
+	}
+	return filepath.Join(home, ".ensura", "history")
+}
+
+// Open opens (creating if necessary) a store at the given directory.
+func Open(dir string) (*Store, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create state directory: %w", err)
+	}
+	return &Store{dir: dir}, nil
+}
+
+// Record persists a run result and returns the stored record.
+func (s *Store) Record(source string, result *runtime.RunResult) (*RunRecord, error) {
+	record := &RunRecord{
+		ID:            result.StartTime.UTC().Format("20060102-150405.000000000"),
+		Source:        source,
+		StartTime:     result.StartTime,
+		EndTime:       result.EndTime,
+		AllSatisfied:  result.AllSatisfied,
+		TotalChecks:   result.TotalChecks,
+		TotalRepairs:  result.TotalRepairs,
+		TotalFailures: result.TotalFailures,
+	}
+
+	for _, step := range result.Steps {
+		stepRecord := StepRecord{
+			Description: step.Step.Description,
+			Status:      step.Status.String(),
+			Attempts:    step.Attempts,
+			Message:     step.Message,
+		}
+		if step.Error != nil {
+			stepRecord.Error = step.Error.Error()
+		}
+		record.Steps = append(record.Steps, stepRecord)
+	}
+
+	data, err := json.MarshalIndent(record, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	path := filepath.Join(s.dir, record.ID+".json")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return nil, fmt.Errorf("failed to write run record: %w", err)
+	}
+
+	return record, nil
+}
+
+// List returns all stored run records, newest first.
+func (s *Store) List() ([]*RunRecord, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var records []*RunRecord
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		record, err := s.load(filepath.Join(s.dir, entry.Name()))
+		if err != nil {
+			// Skip unreadable records rather than failing the listing
+			continue
+		}
+		records = append(records, record)
+	}
+
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].StartTime.After(records[j].StartTime)
+	})
+
+	return records, nil
+}
+
+// Get returns a single run record by ID.
+func (s *Store) Get(id string) (*RunRecord, error) {
+	path := filepath.Join(s.dir, id+".json")
+	record, err := s.load(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("run %s not found", id)
+		}
+		return nil, err
+	}
+	return record, nil
+}
+
+func (s *Store) load(path string) (*RunRecord, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var record RunRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
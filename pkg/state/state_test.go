@@ -0,0 +1,108 @@
+package state
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ensurascript/ensura/pkg/planner"
+	"github.com/ensurascript/ensura/pkg/runtime"
+)
+
+func sampleResult(start time.Time, satisfied bool) *runtime.RunResult {
+	status := runtime.StepSatisfied
+	if !satisfied {
+		status = runtime.StepFailed
+	}
+	return &runtime.RunResult{
+		StartTime:    start,
+		EndTime:      start.Add(time.Second),
+		AllSatisfied: satisfied,
+		TotalChecks:  1,
+		Steps: []*runtime.StepResult{
+			{
+				Step:     &planner.Step{Description: "Ensure exists on file \"a.txt\""},
+				Status:   status,
+				Attempts: 1,
+				Message:  "test",
+				Error:    errIfFailed(satisfied),
+			},
+		},
+	}
+}
+
+func errIfFailed(satisfied bool) error {
+	if satisfied {
+		return nil
+	}
+	return errors.New("boom")
+}
+
+func TestRecordAndGet(t *testing.T) {
+	store, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	record, err := store.Record("config.ens", sampleResult(time.Now(), true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if record.ID == "" {
+		t.Error("Expected record to have an ID")
+	}
+
+	loaded, err := store.Get(record.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Source != "config.ens" {
+		t.Errorf("Expected source config.ens, got %q", loaded.Source)
+	}
+	if len(loaded.Steps) != 1 {
+		t.Fatalf("Expected 1 step, got %d", len(loaded.Steps))
+	}
+	if loaded.Steps[0].Status != "satisfied" {
+		t.Errorf("Expected status satisfied, got %q", loaded.Steps[0].Status)
+	}
+}
+
+func TestListNewestFirst(t *testing.T) {
+	store, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	base := time.Now()
+	if _, err := store.Record("a.ens", sampleResult(base, true)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Record("b.ens", sampleResult(base.Add(time.Minute), false)); err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := store.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("Expected 2 records, got %d", len(records))
+	}
+	if records[0].Source != "b.ens" {
+		t.Errorf("Expected newest record first, got %q", records[0].Source)
+	}
+	if records[0].Steps[0].Error == "" {
+		t.Error("Expected error preserved on failed step")
+	}
+}
+
+func TestGetMissing(t *testing.T) {
+	store, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := store.Get("does-not-exist"); err == nil {
+		t.Error("Expected error for missing run")
+	}
+}
@@ -0,0 +1,100 @@
+// Package stdlib ships the built-in policy library. Policies are written
+// in EnsuraScript, compiled into the binary, and applied with a std.
+// prefix: apply std.secure_file("env:KEY").
+package stdlib
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/ensurascript/ensura/pkg/ast"
+	"github.com/ensurascript/ensura/pkg/parser"
+)
+
+// sources holds the EnsuraScript source of every built-in policy, keyed by
+// its short name.
+var sources = map[string]string{
+	"secure_file": `policy secure_file(key_ref, mode = "0600") {
+  ensure exists
+  ensure encrypted with AES:256 key key_ref
+  ensure permissions with posix mode mode
+}`,
+
+	"hardened_dir": `policy hardened_dir(mode = "0750") {
+  ensure exists
+  ensure permissions with posix mode mode
+  ensure no_world_writable
+}`,
+
+	"web_endpoint": `policy web_endpoint(expected_status = "200") {
+  ensure reachable
+  ensure status_code with http.get expected_status expected_status
+}`,
+
+	"rotated_log": `policy rotated_log(max_bytes = "10485760") {
+  ensure exists
+  ensure size_below with fs.native bytes max_bytes
+}`,
+
+	"scheduled_backup": `policy scheduled_backup(schedule, command) {
+  ensure scheduled with cron.native schedule schedule command command
+}`,
+}
+
+// summaries gives a one-line description per policy for listings.
+var summaries = map[string]string{
+	"secure_file":      "encrypt a file and restrict its permissions",
+	"hardened_dir":     "lock down a directory's permissions",
+	"web_endpoint":     "keep an HTTP endpoint reachable with the expected status",
+	"rotated_log":      "keep a log file below a size limit",
+	"scheduled_backup": "install a cron job running a backup command",
+}
+
+var (
+	loadOnce sync.Once
+	decls    map[string]*ast.PolicyDecl
+)
+
+func load() {
+	decls = make(map[string]*ast.PolicyDecl)
+	for name, src := range sources {
+		program, errs := parser.ParseString(src)
+		if len(errs) > 0 {
+			continue
+		}
+		for _, stmt := range program.Statements {
+			if decl, ok := stmt.(*ast.PolicyDecl); ok {
+				decls[name] = decl
+			}
+		}
+	}
+}
+
+// Policy returns the parsed declaration of a built-in policy by its short
+// name (without the std. prefix).
+func Policy(name string) (*ast.PolicyDecl, bool) {
+	loadOnce.Do(load)
+	decl, ok := decls[name]
+	return decl, ok
+}
+
+// Names returns the short names of all built-in policies, sorted.
+func Names() []string {
+	names := make([]string, 0, len(sources))
+	for name := range sources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Source returns the EnsuraScript source of a built-in policy.
+func Source(name string) (string, bool) {
+	src, ok := sources[name]
+	return src, ok
+}
+
+// Summary returns the one-line description of a built-in policy.
+func Summary(name string) string {
+	return summaries[name]
+}
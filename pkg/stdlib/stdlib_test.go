@@ -0,0 +1,28 @@
+package stdlib
+
+import "testing"
+
+func TestAllPoliciesParse(t *testing.T) {
+	for _, name := range Names() {
+		decl, ok := Policy(name)
+		if !ok {
+			t.Errorf("Built-in policy %q failed to parse", name)
+			continue
+		}
+		if decl.Name != name {
+			t.Errorf("Policy %q declares name %q", name, decl.Name)
+		}
+		if Summary(name) == "" {
+			t.Errorf("Policy %q has no summary", name)
+		}
+	}
+}
+
+func TestSource(t *testing.T) {
+	if _, ok := Source("secure_file"); !ok {
+		t.Error("Expected source for secure_file")
+	}
+	if _, ok := Source("nonexistent"); ok {
+		t.Error("Did not expect source for unknown policy")
+	}
+}
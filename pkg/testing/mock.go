@@ -0,0 +1,176 @@
+// Package testing provides mock handlers for exercising EnsuraScript plans
+// without touching the real filesystem or network.
+package testing
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/ensurascript/ensura/pkg/ast"
+	"github.com/ensurascript/ensura/pkg/runtime"
+)
+
+// Outcome scripts the result of check and enforce calls for a guarantee.
+type Outcome struct {
+	CheckSuccess   bool // result of Check before any successful Enforce
+	EnforceSuccess bool // whether Enforce succeeds (making later Checks pass)
+}
+
+// Satisfied scripts a guarantee that is already satisfied.
+func Satisfied() Outcome {
+	return Outcome{CheckSuccess: true}
+}
+
+// Repairable scripts a guarantee that is violated but can be repaired.
+func Repairable() Outcome {
+	return Outcome{CheckSuccess: false, EnforceSuccess: true}
+}
+
+// Broken scripts a guarantee that is violated and cannot be repaired.
+func Broken() Outcome {
+	return Outcome{CheckSuccess: false, EnforceSuccess: false}
+}
+
+// Call records a single handler invocation.
+type Call struct {
+	Condition string
+	Path      string
+	Args      map[string]string
+}
+
+// MockHandler implements runtime.Handler with scriptable outcomes.
+// Outcomes are keyed by condition and subject path; after a successful
+// Enforce, subsequent Checks for the same guarantee report success so
+// repairs behave as they would with a real handler.
+type MockHandler struct {
+	name string
+
+	mu             sync.Mutex
+	outcomes       map[string]Outcome
+	defaultOutcome Outcome
+	enforced       map[string]bool
+
+	CheckCalls   []Call
+	EnforceCalls []Call
+}
+
+// NewMockHandler creates a mock handler with the given name. By default
+// every check succeeds.
+func NewMockHandler(name string) *MockHandler {
+	return &MockHandler{
+		name:           name,
+		outcomes:       make(map[string]Outcome),
+		defaultOutcome: Satisfied(),
+		enforced:       make(map[string]bool),
+	}
+}
+
+// Name returns the handler name.
+func (m *MockHandler) Name() string {
+	return m.name
+}
+
+// SupportedConditions returns nil: a mock can be scripted for any condition.
+func (m *MockHandler) SupportedConditions() []string {
+	return nil
+}
+
+// Script sets the outcome for a specific condition and subject path.
+// An empty path scripts the outcome for the condition on any subject.
+func (m *MockHandler) Script(condition, path string, outcome Outcome) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.outcomes[outcomeKey(condition, path)] = outcome
+}
+
+// SetDefault sets the outcome used when no scripted outcome matches.
+func (m *MockHandler) SetDefault(outcome Outcome) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.defaultOutcome = outcome
+}
+
+// Check returns the scripted check result.
+func (m *MockHandler) Check(ctx context.Context, subject *ast.ResourceRef, condition string, args map[string]string) runtime.HandlerResult {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	path := subjectPath(subject)
+	m.CheckCalls = append(m.CheckCalls, Call{Condition: condition, Path: path, Args: args})
+
+	key := outcomeKey(condition, path)
+	if m.enforced[key] {
+		return runtime.HandlerResult{
+			Success: true,
+			Message: fmt.Sprintf("mock: %s repaired on %s", condition, path),
+		}
+	}
+
+	outcome := m.lookupOutcome(condition, path)
+	if outcome.CheckSuccess {
+		return runtime.HandlerResult{
+			Success: true,
+			Message: fmt.Sprintf("mock: %s satisfied on %s", condition, path),
+		}
+	}
+	return runtime.HandlerResult{
+		Success: false,
+		Message: fmt.Sprintf("mock: %s violated on %s", condition, path),
+	}
+}
+
+// Enforce returns the scripted enforce result.
+func (m *MockHandler) Enforce(ctx context.Context, subject *ast.ResourceRef, condition string, args map[string]string) runtime.HandlerResult {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	path := subjectPath(subject)
+	m.EnforceCalls = append(m.EnforceCalls, Call{Condition: condition, Path: path, Args: args})
+
+	outcome := m.lookupOutcome(condition, path)
+	if outcome.EnforceSuccess {
+		m.enforced[outcomeKey(condition, path)] = true
+		return runtime.HandlerResult{
+			Success: true,
+			Message: fmt.Sprintf("mock: enforced %s on %s", condition, path),
+		}
+	}
+	return runtime.HandlerResult{
+		Success: false,
+		Error:   fmt.Errorf("mock: cannot enforce %s on %s", condition, path),
+	}
+}
+
+func (m *MockHandler) lookupOutcome(condition, path string) Outcome {
+	if outcome, ok := m.outcomes[outcomeKey(condition, path)]; ok {
+		return outcome
+	}
+	if outcome, ok := m.outcomes[outcomeKey(condition, "")]; ok {
+		return outcome
+	}
+	return m.defaultOutcome
+}
+
+func outcomeKey(condition, path string) string {
+	return condition + ":" + path
+}
+
+func subjectPath(subject *ast.ResourceRef) string {
+	if subject == nil {
+		return ""
+	}
+	if subject.Alias != "" {
+		return subject.Alias
+	}
+	return subject.Path
+}
+
+// NewRegistry creates a handler registry containing the given mocks.
+func NewRegistry(mocks ...*MockHandler) *runtime.HandlerRegistry {
+	registry := runtime.NewHandlerRegistry()
+	for _, m := range mocks {
+		registry.Register(m)
+	}
+	return registry
+}
@@ -0,0 +1,102 @@
+package testing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ensurascript/ensura/pkg/binder"
+	"github.com/ensurascript/ensura/pkg/graph"
+	"github.com/ensurascript/ensura/pkg/imply"
+	"github.com/ensurascript/ensura/pkg/parser"
+	"github.com/ensurascript/ensura/pkg/planner"
+	"github.com/ensurascript/ensura/pkg/runtime"
+)
+
+func compilePlan(t *testing.T, source string) *planner.Plan {
+	t.Helper()
+
+	program, errs := parser.ParseString(source)
+	if len(errs) > 0 {
+		t.Fatalf("parse errors: %v", errs)
+	}
+
+	b := binder.New()
+	program = b.Bind(program)
+	if len(b.Errors()) > 0 {
+		t.Fatalf("binding errors: %v", b.Errors())
+	}
+	program = b.ExpandPolicies(program)
+
+	expander := imply.NewExpander()
+	program = expander.Expand(program)
+
+	g := graph.Build(program)
+	plan, err := planner.New().CreatePlan(g, program)
+	if err != nil {
+		t.Fatalf("planning failed: %v", err)
+	}
+	return plan
+}
+
+func TestMockHandlerSatisfied(t *testing.T) {
+	plan := compilePlan(t, `ensure exists on file "a.txt"`)
+
+	mock := NewMockHandler("fs.native")
+	rt := runtime.New(plan, NewRegistry(mock), nil)
+
+	result := rt.RunOnce(context.Background())
+	if !result.AllSatisfied {
+		t.Error("Expected all guarantees satisfied")
+	}
+	if len(mock.CheckCalls) != 1 {
+		t.Errorf("Expected 1 check call, got %d", len(mock.CheckCalls))
+	}
+	if len(mock.EnforceCalls) != 0 {
+		t.Errorf("Expected no enforce calls, got %d", len(mock.EnforceCalls))
+	}
+}
+
+func TestMockHandlerRepair(t *testing.T) {
+	plan := compilePlan(t, `ensure exists on file "a.txt"`)
+
+	mock := NewMockHandler("fs.native")
+	mock.Script("exists", "a.txt", Repairable())
+	rt := runtime.New(plan, NewRegistry(mock), nil)
+
+	result := rt.RunOnce(context.Background())
+	if !result.AllSatisfied {
+		t.Error("Expected all guarantees satisfied after repair")
+	}
+	if result.TotalRepairs != 1 {
+		t.Errorf("Expected 1 repair, got %d", result.TotalRepairs)
+	}
+	if len(mock.EnforceCalls) != 1 {
+		t.Errorf("Expected 1 enforce call, got %d", len(mock.EnforceCalls))
+	}
+}
+
+func TestMockHandlerBroken(t *testing.T) {
+	plan := compilePlan(t, `ensure exists on file "a.txt"`)
+
+	mock := NewMockHandler("fs.native")
+	mock.Script("exists", "a.txt", Broken())
+	rt := runtime.New(plan, NewRegistry(mock), nil)
+
+	result := rt.RunOnce(context.Background())
+	if result.AllSatisfied {
+		t.Error("Expected a failure for a broken guarantee")
+	}
+	if result.TotalFailures != 1 {
+		t.Errorf("Expected 1 failure, got %d", result.TotalFailures)
+	}
+}
+
+func TestMockHandlerDefaultOutcome(t *testing.T) {
+	mock := NewMockHandler("fs.native")
+	mock.SetDefault(Broken())
+
+	result := mock.Check(context.Background(), nil, "exists", nil)
+	if result.Success {
+		t.Error("Expected default outcome to apply")
+	}
+}
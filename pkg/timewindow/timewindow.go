@@ -0,0 +1,205 @@
+// Package timewindow parses maintenance window specifications like
+// "Mon-Fri 09:00-17:00 UTC". The parser rejects malformed windows at
+// compile time, and the runtime asks a parsed window whether the current
+// instant falls inside it before attempting repairs.
+package timewindow
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// dayIndex maps day-of-week abbreviations to time.Weekday values.
+var dayIndex = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// Window represents a recurring weekly time window.
+type Window struct {
+	spec  string
+	days  [7]bool // indexed by time.Weekday
+	start int     // minutes since midnight, inclusive
+	end   int     // minutes since midnight, exclusive
+	loc   *time.Location
+}
+
+// Parse parses a window specification of the form
+//
+//	[days] HH:MM-HH:MM [zone]
+//
+// where days is a comma-separated list of day abbreviations or day
+// ranges (Mon, Mon-Fri, Sat,Sun; omitted = every day) and zone is UTC,
+// Local, or an IANA location name (omitted = local time). The time range
+// may span midnight: "22:00-06:00" covers late evening through early
+// morning.
+func Parse(spec string) (*Window, error) {
+	parts := strings.Fields(strings.TrimSpace(spec))
+	if len(parts) == 0 {
+		return nil, fmt.Errorf("window is empty")
+	}
+	if len(parts) > 3 {
+		return nil, fmt.Errorf("expected at most 3 fields (days, times, zone), got %d", len(parts))
+	}
+
+	w := &Window{spec: spec, loc: time.Local}
+
+	// The time range is the one field containing a colon; day and zone
+	// fields are optional on either side of it.
+	timeIdx := -1
+	for i, part := range parts {
+		if strings.Contains(part, ":") {
+			if timeIdx >= 0 {
+				return nil, fmt.Errorf("multiple time ranges in %q", spec)
+			}
+			timeIdx = i
+		}
+	}
+	if timeIdx < 0 {
+		return nil, fmt.Errorf("missing time range (want HH:MM-HH:MM)")
+	}
+
+	if timeIdx > 1 {
+		return nil, fmt.Errorf("unexpected field %q before time range", parts[1])
+	}
+	if timeIdx == 1 {
+		if err := w.parseDays(parts[0]); err != nil {
+			return nil, err
+		}
+	} else {
+		for i := range w.days {
+			w.days[i] = true
+		}
+	}
+
+	if err := w.parseTimes(parts[timeIdx]); err != nil {
+		return nil, err
+	}
+
+	if timeIdx < len(parts)-1 {
+		loc, err := loadLocation(parts[len(parts)-1])
+		if err != nil {
+			return nil, err
+		}
+		w.loc = loc
+	}
+
+	return w, nil
+}
+
+// parseDays parses a comma-separated list of day abbreviations and
+// day ranges. Ranges may wrap the week: Fri-Mon covers Fri, Sat, Sun,
+// and Mon.
+func (w *Window) parseDays(spec string) error {
+	for _, item := range strings.Split(spec, ",") {
+		if idx := strings.Index(item, "-"); idx >= 0 {
+			lo, ok := dayIndex[strings.ToLower(item[:idx])]
+			if !ok {
+				return fmt.Errorf("unknown day %q", item[:idx])
+			}
+			hi, ok := dayIndex[strings.ToLower(item[idx+1:])]
+			if !ok {
+				return fmt.Errorf("unknown day %q", item[idx+1:])
+			}
+			for d := lo; ; d = (d + 1) % 7 {
+				w.days[d] = true
+				if d == hi {
+					break
+				}
+			}
+			continue
+		}
+		d, ok := dayIndex[strings.ToLower(item)]
+		if !ok {
+			return fmt.Errorf("unknown day %q", item)
+		}
+		w.days[d] = true
+	}
+	return nil
+}
+
+// parseTimes parses the HH:MM-HH:MM range.
+func (w *Window) parseTimes(spec string) error {
+	idx := strings.Index(spec, "-")
+	if idx < 0 {
+		return fmt.Errorf("time range %q must be HH:MM-HH:MM", spec)
+	}
+	start, err := parseClock(spec[:idx])
+	if err != nil {
+		return err
+	}
+	end, err := parseClock(spec[idx+1:])
+	if err != nil {
+		return err
+	}
+	if start == end {
+		return fmt.Errorf("time range %q is empty", spec)
+	}
+	w.start = start
+	w.end = end
+	return nil
+}
+
+// parseClock converts HH:MM to minutes since midnight.
+func parseClock(s string) (int, error) {
+	idx := strings.Index(s, ":")
+	if idx < 0 {
+		return 0, fmt.Errorf("time %q must be HH:MM", s)
+	}
+	hour, err := strconv.Atoi(s[:idx])
+	if err != nil || hour < 0 || hour > 23 {
+		return 0, fmt.Errorf("hour %q out of range 0-23", s[:idx])
+	}
+	min, err := strconv.Atoi(s[idx+1:])
+	if err != nil || min < 0 || min > 59 {
+		return 0, fmt.Errorf("minute %q out of range 0-59", s[idx+1:])
+	}
+	return hour*60 + min, nil
+}
+
+func loadLocation(name string) (*time.Location, error) {
+	switch name {
+	case "UTC":
+		return time.UTC, nil
+	case "Local":
+		return time.Local, nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("unknown time zone %q", name)
+	}
+	return loc, nil
+}
+
+// Contains reports whether t falls inside the window. Windows that span
+// midnight attribute the portion after midnight to the previous day's
+// window: "Fri 22:00-02:00" includes Saturday 01:00.
+func (w *Window) Contains(t time.Time) bool {
+	t = t.In(w.loc)
+	m := t.Hour()*60 + t.Minute()
+
+	if w.start < w.end {
+		return w.days[t.Weekday()] && m >= w.start && m < w.end
+	}
+
+	// Spans midnight
+	if m >= w.start {
+		return w.days[t.Weekday()]
+	}
+	if m < w.end {
+		yesterday := (t.Weekday() + 6) % 7
+		return w.days[yesterday]
+	}
+	return false
+}
+
+func (w *Window) String() string {
+	return w.spec
+}
@@ -0,0 +1,106 @@
+package timewindow
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseValid(t *testing.T) {
+	valid := []string{
+		"Mon-Fri 09:00-17:00 UTC",
+		"09:00-17:00",
+		"Sat,Sun 00:00-23:59",
+		"Fri 22:00-02:00 UTC",
+		"Mon-Fri 09:00-17:00 Local",
+		"Fri-Mon 10:00-11:00",
+	}
+	for _, spec := range valid {
+		if _, err := Parse(spec); err != nil {
+			t.Errorf("Parse(%q): unexpected error: %v", spec, err)
+		}
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	invalid := []string{
+		"",
+		"Mon-Fri",
+		"Mon-Fri 9am-5pm",
+		"Mon-Fri 09:00-17:00 UTC extra",
+		"Monday 09:00-17:00",
+		"Mon-Fri 25:00-17:00",
+		"Mon-Fri 09:60-17:00",
+		"Mon-Fri 09:00-09:00",
+		"Mon-Fri 09:00-17:00 Mars/Olympus",
+	}
+	for _, spec := range invalid {
+		if _, err := Parse(spec); err == nil {
+			t.Errorf("Parse(%q): expected error, got none", spec)
+		}
+	}
+}
+
+func TestContains(t *testing.T) {
+	w, err := Parse("Mon-Fri 09:00-17:00 UTC")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	// Wednesday 2026-01-07
+	inside := time.Date(2026, 1, 7, 12, 0, 0, 0, time.UTC)
+	if !w.Contains(inside) {
+		t.Errorf("expected %v inside window", inside)
+	}
+	evening := time.Date(2026, 1, 7, 18, 0, 0, 0, time.UTC)
+	if w.Contains(evening) {
+		t.Errorf("expected %v outside window", evening)
+	}
+	// Saturday 2026-01-10
+	weekend := time.Date(2026, 1, 10, 12, 0, 0, 0, time.UTC)
+	if w.Contains(weekend) {
+		t.Errorf("expected %v outside window", weekend)
+	}
+	// End is exclusive
+	closing := time.Date(2026, 1, 7, 17, 0, 0, 0, time.UTC)
+	if w.Contains(closing) {
+		t.Errorf("expected %v outside window", closing)
+	}
+}
+
+func TestContainsSpansMidnight(t *testing.T) {
+	w, err := Parse("Fri 22:00-02:00 UTC")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	// Friday 2026-01-09 23:00 is inside
+	friday := time.Date(2026, 1, 9, 23, 0, 0, 0, time.UTC)
+	if !w.Contains(friday) {
+		t.Errorf("expected %v inside window", friday)
+	}
+	// Saturday 2026-01-10 01:00 belongs to Friday's window
+	saturday := time.Date(2026, 1, 10, 1, 0, 0, 0, time.UTC)
+	if !w.Contains(saturday) {
+		t.Errorf("expected %v inside window", saturday)
+	}
+	// Saturday 2026-01-10 23:00 is outside
+	late := time.Date(2026, 1, 10, 23, 0, 0, 0, time.UTC)
+	if w.Contains(late) {
+		t.Errorf("expected %v outside window", late)
+	}
+}
+
+func TestContainsZoneConversion(t *testing.T) {
+	w, err := Parse("Mon-Fri 09:00-17:00 UTC")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	// 08:00 in UTC+2 is 06:00 UTC: outside the window even though the
+	// local clock reads inside it.
+	plus2 := time.FixedZone("UTC+2", 2*3600)
+	morning := time.Date(2026, 1, 7, 8, 0, 0, 0, plus2)
+	if w.Contains(morning) {
+		t.Errorf("expected %v outside window", morning)
+	}
+}